@@ -1,44 +1,607 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/ai"
+	aimodels "github.com/abhinavxd/libredesk/internal/ai/models"
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	medModels "github.com/abhinavxd/libredesk/internal/media/models"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/valyala/fasthttp"
 	"github.com/zerodha/fastglue"
 )
 
+const (
+	// Conversation messages are fetched in pages when building the summary context.
+	maxSummaryPages    = 10
+	maxSummaryPageSize = 100
+
+	// Maximum time to write a single streamed event to the client.
+	sseWriteTimeout = 10 * time.Second
+	// Interval of the comments written while streaming to find out if the client has closed the connection.
+	sseHeartbeatInterval = 5 * time.Second
+)
+
 type aiCompletionReq struct {
 	PromptKey string `json:"prompt_key"`
 	Content   string `json:"content"`
+	// ConversationUUID is optional, when set the conversation and contact are available as prompt variables.
+	ConversationUUID string `json:"conversation_uuid"`
+}
+
+type aiBudgetReq struct {
+	TeamID            int   `json:"team_id"`
+	RoleID            int   `json:"role_id"`
+	MonthlyTokenLimit int64 `json:"monthly_token_limit"`
+}
+
+type summarizeReq struct {
+	// SaveAsNote stores the summary as a private note on the conversation.
+	SaveAsNote bool `json:"save_as_note"`
+}
+
+type summarizeResp struct {
+	Summary string           `json:"summary"`
+	Note    *cmodels.Message `json:"note,omitempty"`
 }
 
 type providerUpdateReq struct {
-	Provider string `json:"provider"`
-	APIKey   string `json:"api_key"`
+	// Name of the provider config, defaults to `provider` as the built-in configs are named after their provider.
+	Name      string            `json:"name"`
+	Provider  string            `json:"provider"`
+	APIKey    string            `json:"api_key"`
+	Model     string            `json:"model"`
+	MaxTokens int               `json:"max_tokens"`
+	BaseURL   string            `json:"base_url"`
+	Headers   map[string]string `json:"headers"`
+	IsDefault bool              `json:"is_default"`
+	// EmbeddingModel is used to embed the knowledge base for reply suggestions.
+	EmbeddingModel string `json:"embedding_model"`
+}
+
+func (p providerUpdateReq) config() aimodels.ProviderConfig {
+	return aimodels.ProviderConfig{
+		APIKey:         p.APIKey,
+		Model:          p.Model,
+		MaxTokens:      p.MaxTokens,
+		BaseURL:        p.BaseURL,
+		Headers:        p.Headers,
+		EmbeddingModel: p.EmbeddingModel,
+	}
 }
 
 // handleAICompletion handles AI completion requests
 func handleAICompletion(r *fastglue.Request) error {
 	var (
-		app = r.Context.(*App)
-		req = aiCompletionReq{}
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = aiCompletionReq{}
 	)
 
 	if err := r.Decode(&req, "json"); err != nil {
 		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
 	}
 
-	resp, err := app.ai.Completion(req.PromptKey, req.Content)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	var conversation *cmodels.Conversation
+	if req.ConversationUUID != "" {
+		if conversation, err = enforceConversationAccess(app, req.ConversationUUID, user); err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+	}
+
+	resp, err := app.ai.Completion(req.PromptKey, req.Content, promptData(user, conversation), user)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
 	return r.SendEnvelope(resp)
 }
 
-// handleGetAIPrompts returns AI prompts
+// handleAICompletionStream streams the AI completion as server-sent events: `delta` events carry chunks of the response,
+// followed by a `done` or an `error` event. The provider request is cancelled when the client closes the connection.
+func handleAICompletionStream(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = aiCompletionReq{}
+	)
+
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	var conversation *cmodels.Conversation
+	if req.ConversationUUID != "" {
+		if conversation, err = enforceConversationAccess(app, req.ConversationUUID, user); err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+	}
+
+	// Errors such as a missing prompt or an exhausted budget are returned as a regular error response.
+	stream, err := app.ai.NewCompletionStream(req.PromptKey, req.Content, promptData(user, conversation), user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	r.RequestCtx.SetContentType("text/event-stream")
+	r.RequestCtx.Response.Header.Set("Cache-Control", "no-cache")
+	// Disable response buffering in nginx.
+	r.RequestCtx.Response.Header.Set("X-Accel-Buffering", "no")
+
+	// The server write timeout applies to the whole response, the deadline is extended for every event instead.
+	var (
+		conn = r.RequestCtx.Conn()
+		mu   sync.Mutex
+	)
+	send := func(w *bufio.Writer, event string, v any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		conn.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+		return writeSSE(w, event, v)
+	}
+	r.RequestCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		// fasthttp does not report closed connections, heartbeats are written while waiting for the provider so that
+		// the provider request is cancelled and no longer metered once the client has gone away.
+		ctx, cancel := context.WithCancel(context.Background())
+		heartbeatDone := make(chan struct{})
+		go func() {
+			defer close(heartbeatDone)
+			ticker := time.NewTicker(sseHeartbeatInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					mu.Lock()
+					conn.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+					_, err := w.WriteString(": heartbeat\n\n")
+					if err == nil {
+						err = w.Flush()
+					}
+					mu.Unlock()
+					if err != nil {
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+
+		err := stream.Run(ctx, func(delta string) error {
+			// Writing fails once the client has closed the connection, which aborts the stream.
+			return send(w, "delta", map[string]string{"text": delta})
+		})
+		// The writer must not be used once this function returns.
+		cancel()
+		<-heartbeatDone
+		// The client has closed the connection.
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		if err != nil {
+			if e, ok := err.(envelope.Error); ok {
+				send(w, "error", map[string]string{"message": e.Message})
+			} else {
+				app.lo.Error("error streaming AI completion", "user_id", user.ID, "error", err)
+			}
+			return
+		}
+		send(w, "done", map[string]string{})
+	})
+	return nil
+}
+
+// writeSSE writes a server-sent event with v as JSON data and flushes it to the client.
+func writeSSE(w *bufio.Writer, event string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// promptData returns the variables available to prompt templates, conversation may be nil.
+func promptData(user umodels.User, conversation *cmodels.Conversation) map[string]any {
+	var (
+		conv    = map[string]any{"ReferenceNumber": "", "Subject": "", "Priority": "", "Status": "", "UUID": ""}
+		contact = map[string]any{"FirstName": "", "LastName": "", "FullName": "", "Email": ""}
+	)
+	if conversation != nil {
+		conv = map[string]any{
+			"ReferenceNumber": conversation.ReferenceNumber,
+			"Subject":         conversation.Subject.String,
+			"Priority":        conversation.Priority.String,
+			"Status":          conversation.Status.String,
+			"UUID":            conversation.UUID,
+		}
+		contact = map[string]any{
+			"FirstName": conversation.Contact.FirstName,
+			"LastName":  conversation.Contact.LastName,
+			"FullName":  conversation.Contact.FullName(),
+			"Email":     conversation.Contact.Email.String,
+		}
+	}
+	return map[string]any{
+		"Conversation": conv,
+		"Contact":      contact,
+		"Agent": map[string]any{
+			"FirstName": user.FirstName,
+			"LastName":  user.LastName,
+			"FullName":  user.FullName(),
+			"Email":     user.Email.String,
+		},
+	}
+}
+
+// handleSummarizeConversation summarizes a conversation using the full message thread.
+func handleSummarizeConversation(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = summarizeReq{}
+	)
+
+	if len(r.RequestCtx.PostBody()) > 0 {
+		if err := r.Decode(&req, "json"); err != nil {
+			return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+		}
+	}
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	// Check access to conversation.
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	// Saving the summary as a note requires permission to write messages.
+	if req.SaveAsNote {
+		if ok, err := app.authz.Enforce(user, "messages", "write"); err != nil || !ok {
+			return sendErrorEnvelope(r, envelope.NewError(envelope.PermissionError, app.i18n.Ts("globals.messages.denied", "name", "{globals.terms.permission}"), nil))
+		}
+	}
+
+	// Fetch messages newest first until the token budget is filled or there are no more messages.
+	var (
+		messages = make([]cmodels.Message, 0)
+		budget   = app.ai.SummaryTokenBudget()
+		tokens   = 0
+	)
+	for page := 1; page <= maxSummaryPages; page++ {
+		msgs, pageSize, err := app.conversation.GetConversationMessages(uuid, page, maxSummaryPageSize)
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		messages = append(messages, msgs...)
+		for _, m := range msgs {
+			tokens += ai.EstimateTokens(m.TextContent)
+		}
+		if len(msgs) < pageSize || tokens >= budget {
+			break
+		}
+	}
+
+	summary, err := app.ai.SummarizeConversation(messages, user.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	resp := summarizeResp{Summary: summary}
+	if req.SaveAsNote {
+		note, err := app.conversation.SendPrivateNote([]medModels.Media{}, user.ID, uuid, textToHTML(summary))
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		resp.Note = &note
+	}
+	return r.SendEnvelope(resp)
+}
+
+// textToHTML escapes plain text and converts line breaks so it renders as a message.
+func textToHTML(s string) string {
+	var out []string
+	for _, p := range strings.Split(strings.TrimSpace(s), "\n\n") {
+		out = append(out, "<p>"+strings.ReplaceAll(html.EscapeString(strings.TrimSpace(p)), "\n", "<br>")+"</p>")
+	}
+	return strings.Join(out, "")
+}
+
+// handleGetAIPrompts returns the AI prompts the user is allowed to use, users who can manage AI get all prompts.
 func handleGetAIPrompts(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	prompts, err := app.ai.GetPrompts()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if ok, err := app.authz.Enforce(user, "ai", "manage"); err == nil && ok {
+		return r.SendEnvelope(prompts)
+	}
+	var resp = make([]aimodels.Prompt, 0, len(prompts))
+	for _, p := range prompts {
+		if p.AllowedFor(user.Roles) {
+			resp = append(resp, p)
+		}
+	}
+	return r.SendEnvelope(resp)
+}
+
+// handleGetAIPrompt returns an AI prompt along with its content.
+func handleGetAIPrompt(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	prompt, err := app.ai.GetPrompt(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(prompt)
+}
+
+// handleCreateAIPrompt creates a new AI prompt.
+func handleCreateAIPrompt(r *fastglue.Request) error {
+	var (
+		app    = r.Context.(*App)
+		auser  = r.RequestCtx.UserValue("user").(amodels.User)
+		prompt = aimodels.Prompt{}
+	)
+	if err := r.Decode(&prompt, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	created, err := app.ai.CreatePrompt(prompt, auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(created)
+}
+
+// handleUpdateAIPrompt updates an AI prompt, the previous content is kept as a version.
+func handleUpdateAIPrompt(r *fastglue.Request) error {
+	var (
+		app    = r.Context.(*App)
+		auser  = r.RequestCtx.UserValue("user").(amodels.User)
+		prompt = aimodels.Prompt{}
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&prompt, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	updated, err := app.ai.UpdatePrompt(id, prompt, auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(updated)
+}
+
+// handleDeleteAIPrompt deletes an AI prompt.
+func handleDeleteAIPrompt(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	if err := app.ai.DeletePrompt(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetAIPromptVersions returns the version history of an AI prompt.
+func handleGetAIPromptVersions(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	versions, err := app.ai.GetPromptVersions(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(versions)
+}
+
+// handleRollbackAIPrompt restores an AI prompt to a previous version.
+func handleRollbackAIPrompt(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	version, err := strconv.Atoi(r.RequestCtx.UserValue("version").(string))
+	if err != nil || version == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`version`"), nil, envelope.InputError)
+	}
+	prompt, err := app.ai.RollbackPrompt(id, version, auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(prompt)
+}
+
+// handleGetAIRedactionLogs returns the audit records of prompts that had PII redacted.
+func handleGetAIRedactionLogs(r *fastglue.Request) error {
+	var (
+		app         = r.Context.(*App)
+		page, _     = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page")))
+		pageSize, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page_size")))
+		total       = 0
+	)
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	logs, err := app.ai.GetRedactionLogs(page, pageSize)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if len(logs) > 0 {
+		total = logs[0].Total
+	}
+	return r.SendEnvelope(envelope.PageResults{
+		Results:    logs,
+		Total:      total,
+		PerPage:    pageSize,
+		TotalPages: (total + pageSize - 1) / pageSize,
+		Page:       page,
+	})
+}
+
+// handleGetAIUsage returns the AI usage grouped by user, prompt key, provider, model or day.
+// The range defaults to the current month, `to` is inclusive.
+func handleGetAIUsage(r *fastglue.Request) error {
+	var (
+		app       = r.Context.(*App)
+		groupBy   = string(r.RequestCtx.QueryArgs().Peek("group_by"))
+		teamID, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("team_id")))
+		now       = time.Now()
+		from      = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		to        = now
+	)
+	if groupBy == "" {
+		groupBy = ai.UsageGroupUser
+	}
+	if v := string(r.RequestCtx.QueryArgs().Peek("from")); v != "" {
+		t, err := time.ParseInLocation(time.DateOnly, v, now.Location())
+		if err != nil {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`from`"), nil, envelope.InputError)
+		}
+		from = t
+	}
+	if v := string(r.RequestCtx.QueryArgs().Peek("to")); v != "" {
+		t, err := time.ParseInLocation(time.DateOnly, v, now.Location())
+		if err != nil {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`to`"), nil, envelope.InputError)
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+
+	usage, err := app.ai.GetUsageSummary(from, to, groupBy, teamID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(usage)
+}
+
+// handleGetAIBudgets returns the monthly AI budgets along with the tokens used this month.
+func handleGetAIBudgets(r *fastglue.Request) error {
 	var (
 		app = r.Context.(*App)
 	)
-	resp, err := app.ai.GetPrompts()
+	budgets, err := app.ai.GetBudgets()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(budgets)
+}
+
+// handleCreateAIBudget creates a monthly AI budget for a team or a role.
+func handleCreateAIBudget(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req aiBudgetReq
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	budget, err := app.ai.CreateBudget(req.TeamID, req.RoleID, req.MonthlyTokenLimit)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(budget)
+}
+
+// handleUpdateAIBudget updates the token limit of a monthly AI budget.
+func handleUpdateAIBudget(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req aiBudgetReq
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	budget, err := app.ai.UpdateBudget(id, req.MonthlyTokenLimit)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(budget)
+}
+
+// handleDeleteAIBudget deletes a monthly AI budget.
+func handleDeleteAIBudget(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	if err := app.ai.DeleteBudget(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetAIProviders returns AI providers and their settings.
+func handleGetAIProviders(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	resp, err := app.ai.GetProviders()
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -54,8 +617,41 @@ func handleUpdateAIProvider(r *fastglue.Request) error {
 	if err := r.Decode(&req, "json"); err != nil {
 		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
 	}
-	if err := app.ai.UpdateProvider(req.Provider, req.APIKey); err != nil {
+	if req.Name == "" {
+		req.Name = req.Provider
+	}
+	if err := app.ai.UpdateProvider(req.Name, req.config(), req.IsDefault); err != nil {
 		return sendErrorEnvelope(r, err)
 	}
 	return r.SendEnvelope("Provider updated successfully")
 }
+
+// handleCreateAIProvider adds a new AI provider config
+func handleCreateAIProvider(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req providerUpdateReq
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	if req.Name == "" {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`name`"), nil))
+	}
+	if err := app.ai.CreateProvider(req.Name, req.Provider, req.config(), req.IsDefault); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleDeleteAIProvider deletes an AI provider config
+func handleDeleteAIProvider(r *fastglue.Request) error {
+	var (
+		app  = r.Context.(*App)
+		name = r.RequestCtx.UserValue("name").(string)
+	)
+	if err := app.ai.DeleteProvider(name); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
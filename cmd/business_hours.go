@@ -1,11 +1,13 @@
 package main
 
 import (
+	"errors"
 	"strconv"
 
 	businessHours "github.com/abhinavxd/libredesk/internal/business_hours"
 	models "github.com/abhinavxd/libredesk/internal/business_hours/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/netutil"
 	"github.com/valyala/fasthttp"
 	"github.com/zerodha/fastglue"
 )
@@ -54,8 +56,14 @@ func handleCreateBusinessHours(r *fastglue.Request) error {
 	if businessHours.Name == "" {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.empty", "name", "`name`"), nil, envelope.InputError)
 	}
+	if err := validateWorkingHours(app, businessHours); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := validateHolidays(app, businessHours); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
 
-	createdBusinessHours, err := app.businessHours.Create(businessHours.Name, businessHours.Description, businessHours.IsAlwaysOpen, businessHours.Hours, businessHours.Holidays)
+	createdBusinessHours, err := app.businessHours.Create(businessHours.Name, businessHours.Description, businessHours.IsAlwaysOpen, businessHours.Hours, businessHours.Holidays, businessHours.HolidaysURL)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -94,9 +102,89 @@ func handleUpdateBusinessHours(r *fastglue.Request) error {
 	if businessHours.Name == "" {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`name`"), nil, envelope.InputError)
 	}
-	updatedBusinessHours, err := app.businessHours.Update(id, businessHours.Name, businessHours.Description, businessHours.IsAlwaysOpen, businessHours.Hours, businessHours.Holidays)
+	if err := validateWorkingHours(app, businessHours); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := validateHolidays(app, businessHours); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	updatedBusinessHours, err := app.businessHours.Update(id, businessHours.Name, businessHours.Description, businessHours.IsAlwaysOpen, businessHours.Hours, businessHours.Holidays, businessHours.HolidaysURL)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
 	return r.SendEnvelope(updatedBusinessHours)
 }
+
+// validateWorkingHours validates the working intervals of business hours that are not always open.
+func validateWorkingHours(app *App, bh models.BusinessHours) error {
+	if bh.IsAlwaysOpen {
+		return nil
+	}
+	err := businessHours.ValidateHours(bh.Hours)
+	if err == nil {
+		return nil
+	}
+
+	var hoursErr *businessHours.HoursError
+	if !errors.As(err, &hoursErr) {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`hours`"), nil)
+	}
+	if errors.Is(err, businessHours.ErrOverlappingIntervals) {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("admin.businessHours.overlappingIntervals", "day", hoursErr.Day), nil)
+	}
+	return envelope.NewError(envelope.InputError, app.i18n.Ts("admin.businessHours.invalidIntervals", "day", hoursErr.Day), nil)
+}
+
+// handleImportBusinessHoursHolidays imports the holidays of an uploaded iCalendar file into the business hours with the given id.
+func handleImportBusinessHoursHolidays(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+
+	form, err := r.RequestCtx.MultipartForm()
+	if err != nil {
+		app.lo.Error("error parsing form data", "error", err)
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil, envelope.InputError)
+	}
+	files, ok := form.File["file"]
+	if !ok || len(files) == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.file}"), nil, envelope.InputError)
+	}
+
+	file, err := files[0].Open()
+	if err != nil {
+		app.lo.Error("error reading uploaded file", "error", err)
+		return r.SendErrorEnvelope(fasthttp.StatusInternalServerError, app.i18n.Ts("globals.messages.errorReading", "name", "{globals.terms.file}"), nil, envelope.GeneralError)
+	}
+	defer file.Close()
+
+	businessHour, err := app.businessHours.ImportHolidays(id, file)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(businessHour)
+}
+
+// validateHolidays validates the holidays and the holidays iCalendar URL of business hours.
+func validateHolidays(app *App, bh models.BusinessHours) error {
+	// Calendars are fetched by the server, local and private addresses are not allowed.
+	if bh.HolidaysURL.String != "" {
+		if err := netutil.ValidatePublicURL(bh.HolidaysURL.String, "http", "https"); err != nil {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`holidays_url`"), nil)
+		}
+	}
+
+	err := businessHours.ValidateHolidays(bh.Holidays)
+	if err == nil {
+		return nil
+	}
+	var holidayErr *businessHours.HolidayError
+	if !errors.As(err, &holidayErr) {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`holidays`"), nil)
+	}
+	return envelope.NewError(envelope.InputError, app.i18n.Ts("admin.businessHours.invalidHoliday", "name", holidayErr.Name), nil)
+}
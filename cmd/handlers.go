@@ -51,6 +51,9 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/api/v1/views/{id}/conversations", perm(handleGetViewConversations, "conversations:read"))
 	g.GET("/api/v1/conversations/{uuid}", perm(handleGetConversation, "conversations:read"))
 	g.GET("/api/v1/conversations/{uuid}/participants", perm(handleGetConversationParticipants, "conversations:read"))
+	g.GET("/api/v1/conversations/{uuid}/followers", perm(handleGetConversationFollowers, "conversations:read"))
+	g.POST("/api/v1/conversations/{uuid}/follow", perm(handleFollowConversation, "conversations:read"))
+	g.DELETE("/api/v1/conversations/{uuid}/follow", perm(handleUnfollowConversation, "conversations:read"))
 	g.PUT("/api/v1/conversations/{uuid}/assignee/user", perm(handleUpdateUserAssignee, "conversations:update_user_assignee"))
 	g.PUT("/api/v1/conversations/{uuid}/assignee/team", perm(handleUpdateTeamAssignee, "conversations:update_team_assignee"))
 	g.PUT("/api/v1/conversations/{uuid}/assignee/user/remove", perm(handleRemoveUserAssignee, "conversations:update_user_assignee"))
@@ -59,6 +62,8 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.PUT("/api/v1/conversations/{uuid}/status", perm(handleUpdateConversationStatus, "conversations:update_status"))
 	g.PUT("/api/v1/conversations/{uuid}/last-seen", perm(handleUpdateConversationAssigneeLastSeen, "conversations:read"))
 	g.POST("/api/v1/conversations/{uuid}/tags", perm(handleUpdateConversationtags, "conversations:update_tags"))
+	g.POST("/api/v1/conversations/{uuid}/summarize", perm(handleSummarizeConversation, "messages:read"))
+	g.POST("/api/v1/conversations/{uuid}/suggest-reply", perm(handleSuggestReply, "messages:write"))
 	g.GET("/api/v1/conversations/{cuuid}/messages/{uuid}", perm(handleGetMessage, "messages:read"))
 	g.GET("/api/v1/conversations/{uuid}/messages", perm(handleGetMessages, "messages:read"))
 	g.POST("/api/v1/conversations/{cuuid}/messages", perm(handleSendMessage, "messages:write"))
@@ -117,6 +122,18 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.POST("/api/v1/agents/reset-password", tryAuth(handleResetPassword))
 	g.POST("/api/v1/agents/set-password", tryAuth(handleSetPassword))
 
+	// Notifications.
+	g.GET("/api/v1/notifications", auth(handleGetNotifications))
+	g.PUT("/api/v1/notifications/read-all", auth(handleMarkAllNotificationsRead))
+	g.PUT("/api/v1/notifications/{id}/read", auth(handleMarkNotificationRead))
+	g.GET("/api/v1/notifications/preferences", auth(handleGetNotificationPreferences))
+	g.PUT("/api/v1/notifications/preferences", auth(handleUpdateNotificationPreferences))
+	g.GET("/api/v1/notifications/push/vapid-public-key", auth(handleGetVAPIDPublicKey))
+	g.POST("/api/v1/notifications/push/subscriptions", auth(handleCreatePushSubscription))
+	g.DELETE("/api/v1/notifications/push/subscriptions", auth(handleDeletePushSubscription))
+	g.GET("/api/v1/notifications/digest", auth(handleGetDigestSettings))
+	g.PUT("/api/v1/notifications/digest", auth(handleUpdateDigestSettings))
+
 	// Contacts.
 	g.GET("/api/v1/contacts", perm(handleGetContacts, "contacts:read_all"))
 	g.GET("/api/v1/contacts/{id}", perm(handleGetContact, "contacts:read"))
@@ -135,6 +152,11 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.POST("/api/v1/teams", perm(handleCreateTeam, "teams:manage"))
 	g.PUT("/api/v1/teams/{id}", perm(handleUpdateTeam, "teams:manage"))
 	g.DELETE("/api/v1/teams/{id}", perm(handleDeleteTeam, "teams:manage"))
+	g.GET("/api/v1/teams/{id}/notification-channels", perm(handleGetTeamNotificationChannels, "teams:manage"))
+	g.POST("/api/v1/teams/{id}/notification-channels", perm(handleCreateTeamNotificationChannel, "teams:manage"))
+	g.PUT("/api/v1/teams/{id}/notification-channels/{channel_id}", perm(handleUpdateTeamNotificationChannel, "teams:manage"))
+	g.DELETE("/api/v1/teams/{id}/notification-channels/{channel_id}", perm(handleDeleteTeamNotificationChannel, "teams:manage"))
+	g.POST("/api/v1/teams/{id}/notification-channels/{channel_id}/test", perm(handleTestTeamNotificationChannel, "teams:manage"))
 
 	// Automations.
 	g.GET("/api/v1/automations/rules", perm(handleGetAutomationRules, "automations:manage"))
@@ -170,10 +192,30 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.PUT("/api/v1/webhooks/{id}/toggle", perm(handleToggleWebhook, "webhooks:manage"))
 	g.POST("/api/v1/webhooks/{id}/test", perm(handleTestWebhook, "webhooks:manage"))
 
+	// Inbound webhooks.
+	g.GET("/api/v1/inbound-webhooks", perm(handleGetInboundWebhooks, "webhooks:manage"))
+	g.GET("/api/v1/inbound-webhooks/{id}", perm(handleGetInboundWebhook, "webhooks:manage"))
+	g.POST("/api/v1/inbound-webhooks", perm(handleCreateInboundWebhook, "webhooks:manage"))
+	g.PUT("/api/v1/inbound-webhooks/{id}", perm(handleUpdateInboundWebhook, "webhooks:manage"))
+	g.DELETE("/api/v1/inbound-webhooks/{id}", perm(handleDeleteInboundWebhook, "webhooks:manage"))
+
 	// Reports.
 	g.GET("/api/v1/reports/overview/sla", perm(handleOverviewSLA, "reports:manage"))
 	g.GET("/api/v1/reports/overview/counts", perm(handleOverviewCounts, "reports:manage"))
 	g.GET("/api/v1/reports/overview/charts", perm(handleOverviewCharts, "reports:manage"))
+	g.GET("/api/v1/reports/sla", perm(handleSLAReport, "reports:manage"))
+	g.GET("/api/v1/reports/sla/export", perm(handleExportSLAReport, "reports:manage"))
+	g.GET("/api/v1/reports/agents", perm(handleAgentPerformanceReport, "reports:manage"))
+	g.GET("/api/v1/reports/agents/export", perm(handleExportAgentPerformanceReport, "reports:manage"))
+	g.GET("/api/v1/reports/csat", perm(handleCSATReport, "reports:manage"))
+	g.GET("/api/v1/reports/csat/export", perm(handleExportCSATReport, "reports:manage"))
+	g.GET("/api/v1/reports/csat/responses", perm(handleGetCSATResponses, "reports:manage"))
+	g.GET("/api/v1/reports/schedules", perm(handleGetScheduledReports, "reports:manage"))
+	g.GET("/api/v1/reports/schedules/{id}", perm(handleGetScheduledReport, "reports:manage"))
+	g.POST("/api/v1/reports/schedules", perm(handleCreateScheduledReport, "reports:manage"))
+	g.PUT("/api/v1/reports/schedules/{id}", perm(handleUpdateScheduledReport, "reports:manage"))
+	g.DELETE("/api/v1/reports/schedules/{id}", perm(handleDeleteScheduledReport, "reports:manage"))
+	g.POST("/api/v1/reports/schedules/{id}/send", perm(handleSendScheduledReport, "reports:manage"))
 
 	// Templates.
 	g.GET("/api/v1/templates", perm(handleGetTemplates, "templates:manage"))
@@ -188,6 +230,7 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.POST("/api/v1/business-hours", perm(handleCreateBusinessHours, "business_hours:manage"))
 	g.PUT("/api/v1/business-hours/{id}", perm(handleUpdateBusinessHours, "business_hours:manage"))
 	g.DELETE("/api/v1/business-hours/{id}", perm(handleDeleteBusinessHour, "business_hours:manage"))
+	g.POST("/api/v1/business-hours/{id}/holidays/import", perm(handleImportBusinessHoursHolidays, "business_hours:manage"))
 
 	// SLAs.
 	g.GET("/api/v1/sla", perm(handleGetSLAs, "sla:manage"))
@@ -198,8 +241,30 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 
 	// AI completions.
 	g.GET("/api/v1/ai/prompts", auth(handleGetAIPrompts))
+	g.GET("/api/v1/ai/prompts/{id}", perm(handleGetAIPrompt, "ai:manage"))
+	g.POST("/api/v1/ai/prompts", perm(handleCreateAIPrompt, "ai:manage"))
+	g.PUT("/api/v1/ai/prompts/{id}", perm(handleUpdateAIPrompt, "ai:manage"))
+	g.DELETE("/api/v1/ai/prompts/{id}", perm(handleDeleteAIPrompt, "ai:manage"))
+	g.GET("/api/v1/ai/prompts/{id}/versions", perm(handleGetAIPromptVersions, "ai:manage"))
+	g.POST("/api/v1/ai/prompts/{id}/versions/{version}/rollback", perm(handleRollbackAIPrompt, "ai:manage"))
+	g.GET("/api/v1/ai/knowledge/documents", perm(handleGetKnowledgeDocuments, "ai:manage"))
+	g.GET("/api/v1/ai/knowledge/documents/{id}", perm(handleGetKnowledgeDocument, "ai:manage"))
+	g.POST("/api/v1/ai/knowledge/documents", perm(handleCreateKnowledgeDocument, "ai:manage"))
+	g.PUT("/api/v1/ai/knowledge/documents/{id}", perm(handleUpdateKnowledgeDocument, "ai:manage"))
+	g.DELETE("/api/v1/ai/knowledge/documents/{id}", perm(handleDeleteKnowledgeDocument, "ai:manage"))
+	g.POST("/api/v1/ai/knowledge/reindex", perm(handleReindexKnowledge, "ai:manage"))
+	g.GET("/api/v1/ai/redaction-logs", perm(handleGetAIRedactionLogs, "ai:manage"))
+	g.GET("/api/v1/ai/usage", perm(handleGetAIUsage, "ai:manage"))
+	g.GET("/api/v1/ai/budgets", perm(handleGetAIBudgets, "ai:manage"))
+	g.POST("/api/v1/ai/budgets", perm(handleCreateAIBudget, "ai:manage"))
+	g.PUT("/api/v1/ai/budgets/{id}", perm(handleUpdateAIBudget, "ai:manage"))
+	g.DELETE("/api/v1/ai/budgets/{id}", perm(handleDeleteAIBudget, "ai:manage"))
 	g.POST("/api/v1/ai/completion", auth(handleAICompletion))
+	g.POST("/api/v1/ai/completion/stream", auth(handleAICompletionStream))
+	g.GET("/api/v1/ai/providers", perm(handleGetAIProviders, "ai:manage"))
 	g.PUT("/api/v1/ai/provider", perm(handleUpdateAIProvider, "ai:manage"))
+	g.POST("/api/v1/ai/providers", perm(handleCreateAIProvider, "ai:manage"))
+	g.DELETE("/api/v1/ai/providers/{name}", perm(handleDeleteAIProvider, "ai:manage"))
 
 	// Custom attributes.
 	g.GET("/api/v1/custom-attributes", auth(handleGetCustomAttributes))
@@ -230,12 +295,17 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	// FIXME: Don't need three separate routes for the same thing.
 	g.GET("/assets/{all:*}", serveFrontendStaticFiles)
 	g.GET("/images/{all:*}", serveFrontendStaticFiles)
+	// Service worker showing push notifications, served from the root for its scope to cover the app.
+	g.GET("/sw.js", serveFrontendStaticFiles)
 	g.GET("/static/public/{all:*}", serveStaticFiles)
 
 	// Public pages.
 	g.GET("/csat/{uuid}", handleShowCSAT)
 	g.POST("/csat/{uuid}", handleUpdateCSATResponse)
 
+	// Signed inbound webhook receiver.
+	g.POST("/webhooks/inbound/{uuid}", handleReceiveInboundWebhook)
+
 	// Health check.
 	g.GET("/health", handleHealthCheck)
 }
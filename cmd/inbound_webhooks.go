@@ -0,0 +1,205 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/abhinavxd/libredesk/internal/webhook/models"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+var inboundWebhookActions = []string{
+	models.InboundActionSendPrivateNote,
+	models.InboundActionAddTags,
+	models.InboundActionSetTags,
+	models.InboundActionRemoveTags,
+	models.InboundActionSetStatus,
+}
+
+// handleGetInboundWebhooks returns all inbound webhooks from the database.
+func handleGetInboundWebhooks(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	webhooks, err := app.webhook.GetAllInbound()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	// Hide secrets.
+	for i := range webhooks {
+		webhooks[i].Secret = strings.Repeat(stringutil.PasswordDummy, 10)
+	}
+	return r.SendEnvelope(webhooks)
+}
+
+// handleGetInboundWebhook returns a specific inbound webhook by ID.
+func handleGetInboundWebhook(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+
+	webhook, err := app.webhook.GetInbound(id, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	// Hide secret in the response.
+	webhook.Secret = strings.Repeat(stringutil.PasswordDummy, 10)
+
+	return r.SendEnvelope(webhook)
+}
+
+// handleCreateInboundWebhook creates a new inbound webhook in the database.
+// If no secret is provided one is generated and returned once in the response.
+func handleCreateInboundWebhook(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		webhook = models.InboundWebhook{}
+	)
+	if err := r.Decode(&webhook, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), err.Error(), envelope.InputError)
+	}
+
+	if err := validateInboundWebhook(app, &webhook); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	if webhook.Secret == "" {
+		secret, err := stringutil.RandomAlphanumeric(32)
+		if err != nil {
+			app.lo.Error("error generating inbound webhook secret", "error", err)
+			return r.SendErrorEnvelope(fasthttp.StatusInternalServerError, app.i18n.Ts("globals.messages.errorCreating", "name", "{globals.terms.webhook}"), nil, envelope.GeneralError)
+		}
+		webhook.Secret = secret
+	}
+
+	webhook, err := app.webhook.CreateInbound(webhook)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	return r.SendEnvelope(webhook)
+}
+
+// handleUpdateInboundWebhook updates an existing inbound webhook in the database.
+func handleUpdateInboundWebhook(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		webhook = models.InboundWebhook{}
+		id, _   = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+
+	if err := r.Decode(&webhook, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), err.Error(), envelope.InputError)
+	}
+
+	if err := validateInboundWebhook(app, &webhook); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	// If secret is empty or contains dummy characters, preserve the existing secret.
+	if webhook.Secret == "" || strings.Contains(webhook.Secret, stringutil.PasswordDummy) {
+		existing, err := app.webhook.GetInbound(id, "")
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		webhook.Secret = existing.Secret
+	}
+
+	updated, err := app.webhook.UpdateInbound(id, webhook)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	// Clear secret before returning
+	updated.Secret = strings.Repeat(stringutil.PasswordDummy, 10)
+
+	return r.SendEnvelope(updated)
+}
+
+// handleDeleteInboundWebhook deletes an inbound webhook from the database.
+func handleDeleteInboundWebhook(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+
+	if err := app.webhook.DeleteInbound(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	return r.SendEnvelope(true)
+}
+
+// handleReceiveInboundWebhook receives a signed payload from an external system and applies
+// the inbound webhook's action. The raw body must be signed with the webhook secret using HMAC-SHA256
+// and sent in the `X-Libredesk-Signature` header as `sha256=<hex digest>`.
+func handleReceiveInboundWebhook(r *fastglue.Request) error {
+	var (
+		app       = r.Context.(*App)
+		uuid      = r.RequestCtx.UserValue("uuid").(string)
+		signature = string(r.RequestCtx.Request.Header.Peek("X-Libredesk-Signature"))
+	)
+
+	result, err := app.webhook.ProcessInbound(uuid, r.RequestCtx.PostBody(), signature)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	return r.SendEnvelope(result)
+}
+
+// validateInboundWebhook validates the inbound webhook data and sets defaults.
+func validateInboundWebhook(app *App, webhook *models.InboundWebhook) error {
+	if webhook.Name == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`name`"), nil)
+	}
+	if !slices.Contains(inboundWebhookActions, webhook.Action) {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`action`"), nil)
+	}
+	if webhook.ContactLookup == "" {
+		webhook.ContactLookup = models.ContactLookupEmail
+	}
+	if webhook.ContactLookup != models.ContactLookupEmail && webhook.ContactLookup != models.ContactLookupCustomAttribute {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`contact_lookup`"), nil)
+	}
+	if webhook.Mapping.ContactField == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`mapping.contact_field`"), nil)
+	}
+	if webhook.ContactLookup == models.ContactLookupCustomAttribute && webhook.Mapping.ContactAttribute == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`mapping.contact_attribute`"), nil)
+	}
+	if len(webhook.Secret) > 255 {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`secret`"), nil)
+	}
+	if webhook.Action == models.InboundActionSetStatus {
+		mapping := webhook.Mapping
+		if mapping.SnoozeDuration != "" {
+			if d, err := time.ParseDuration(mapping.SnoozeDuration); err != nil || d <= 0 {
+				return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`mapping.snooze_duration`"), nil)
+			}
+		}
+		// Snoozing needs a duration from the payload or a static one.
+		if mapping.Status == cmodels.StatusSnoozed && mapping.SnoozeDurationField == "" && mapping.SnoozeDuration == "" {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`mapping.snooze_duration`"), nil)
+		}
+	}
+	return nil
+}
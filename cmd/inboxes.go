@@ -173,5 +173,10 @@ func validateInbox(app *App, inbox imodels.Inbox) error {
 	if inbox.Channel == "" {
 		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "channel"), nil)
 	}
+	if inbox.OutOfHoursReply != "" {
+		if err := app.tmpl.ParseHTMLTemplate(inbox.OutOfHoursReply); err != nil {
+			return envelope.NewError(envelope.InputError, app.i18n.T("admin.inbox.invalidOutOfHoursReply"), nil)
+		}
+	}
 	return nil
 }
@@ -8,12 +8,14 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"html/template"
 
 	activitylog "github.com/abhinavxd/libredesk/internal/activity_log"
 	"github.com/abhinavxd/libredesk/internal/ai"
+	aimodels "github.com/abhinavxd/libredesk/internal/ai/models"
 	auth_ "github.com/abhinavxd/libredesk/internal/auth"
 	"github.com/abhinavxd/libredesk/internal/authz"
 	"github.com/abhinavxd/libredesk/internal/autoassigner"
@@ -25,6 +27,7 @@ import (
 	"github.com/abhinavxd/libredesk/internal/conversation/status"
 	"github.com/abhinavxd/libredesk/internal/csat"
 	customAttribute "github.com/abhinavxd/libredesk/internal/custom_attribute"
+	"github.com/abhinavxd/libredesk/internal/digest"
 	"github.com/abhinavxd/libredesk/internal/inbox"
 	"github.com/abhinavxd/libredesk/internal/inbox/channel/email"
 	imodels "github.com/abhinavxd/libredesk/internal/inbox/models"
@@ -34,9 +37,13 @@ import (
 	"github.com/abhinavxd/libredesk/internal/media/stores/s3"
 	notifier "github.com/abhinavxd/libredesk/internal/notification"
 	emailnotifier "github.com/abhinavxd/libredesk/internal/notification/providers/email"
+	matrixnotifier "github.com/abhinavxd/libredesk/internal/notification/providers/matrix"
+	webhooknotifier "github.com/abhinavxd/libredesk/internal/notification/providers/webhook"
+	webpushnotifier "github.com/abhinavxd/libredesk/internal/notification/providers/webpush"
 	"github.com/abhinavxd/libredesk/internal/oidc"
 	"github.com/abhinavxd/libredesk/internal/report"
 	"github.com/abhinavxd/libredesk/internal/role"
+	scheduledreport "github.com/abhinavxd/libredesk/internal/scheduled_report"
 	"github.com/abhinavxd/libredesk/internal/search"
 	"github.com/abhinavxd/libredesk/internal/setting"
 	"github.com/abhinavxd/libredesk/internal/sla"
@@ -44,6 +51,7 @@ import (
 	"github.com/abhinavxd/libredesk/internal/team"
 	tmpl "github.com/abhinavxd/libredesk/internal/template"
 	"github.com/abhinavxd/libredesk/internal/user"
+	usernotification "github.com/abhinavxd/libredesk/internal/user_notification"
 	"github.com/abhinavxd/libredesk/internal/view"
 	"github.com/abhinavxd/libredesk/internal/webhook"
 	"github.com/abhinavxd/libredesk/internal/ws"
@@ -106,6 +114,8 @@ func initFlags() {
 	f.Bool("yes", false, "skip confirmation prompt")
 	f.Bool("upgrade", false, "upgrade the database schema")
 	f.Bool("set-system-user-password", false, "set password for the system user")
+	f.Bool("backfill-rollups", false, "roll up existing conversations and messages for the reports")
+	f.String("backfill-from", "", "date (YYYY-MM-DD) to backfill the report rollups from, defaults to the first conversation")
 
 	if err := f.Parse(os.Args[1:]); err != nil {
 		log.Fatalf("loading flags: %v", err)
@@ -228,6 +238,7 @@ func initConversations(
 		Lo:                       initLogger("conversation_manager"),
 		OutgoingMessageQueueSize: ko.MustInt("message.outgoing_queue_size"),
 		IncomingMessageQueueSize: ko.MustInt("message.incoming_queue_size"),
+		OutOfHoursReplyInterval:  ko.Duration("conversation.out_of_hours_reply_interval"),
 	})
 	if err != nil {
 		log.Fatalf("error initializing conversation manager: %v", err)
@@ -281,9 +292,10 @@ func initMacro(db *sqlx.DB, i18n *i18n.I18n) *macro.Manager {
 func initBusinessHours(db *sqlx.DB, i18n *i18n.I18n) *businesshours.Manager {
 	var lo = initLogger("business-hours")
 	m, err := businesshours.New(businesshours.Opts{
-		DB:   db,
-		Lo:   lo,
-		I18n: i18n,
+		DB:                  db,
+		Lo:                  lo,
+		I18n:                i18n,
+		HolidaySyncInterval: ko.Duration("business_hours.holiday_sync_interval"),
 	})
 	if err != nil {
 		log.Fatalf("error initializing business hours manager: %v", err)
@@ -489,13 +501,13 @@ func initInbox(db *sqlx.DB, i18n *i18n.I18n) *inbox.Manager {
 }
 
 // initAutomationEngine initializes the automation engine.
-func initAutomationEngine(db *sqlx.DB, i18n *i18n.I18n) *automation.Engine {
+func initAutomationEngine(db *sqlx.DB, i18n *i18n.I18n, priority *priority.Manager, team *team.Manager) *automation.Engine {
 	var lo = initLogger("automation_engine")
 	engine, err := automation.New(automation.Opts{
 		DB:   db,
 		Lo:   lo,
 		I18n: i18n,
-	})
+	}, priority, team)
 	if err != nil {
 		log.Fatalf("error initializing automation engine: %v", err)
 	}
@@ -516,7 +528,7 @@ func initAutoAssigner(teamManager *team.Manager, userManager *user.Manager, conv
 }
 
 // initNotifier initializes the notifier service with available providers.
-func initNotifier() *notifier.Service {
+func initNotifier(webPush *webpushnotifier.WebPush) *notifier.Service {
 	smtpCfg := email.SMTPConfig{}
 	if err := ko.UnmarshalWithConf("notification.email", &smtpCfg, koanf.UnmarshalConf{Tag: "json"}); err != nil {
 		log.Fatalf("error unmarshalling email notification provider config: %v", err)
@@ -530,13 +542,104 @@ func initNotifier() *notifier.Service {
 		log.Fatalf("error initializing email notifier: %v", err)
 	}
 
+	chatTimeout := ko.Duration("notification.chat.timeout")
+	slackNotifier, err := webhooknotifier.New(webhooknotifier.Opts{
+		Lo:       initLogger("slack-notifier"),
+		Provider: notifier.ProviderSlack,
+		Timeout:  chatTimeout,
+	})
+	if err != nil {
+		log.Fatalf("error initializing slack notifier: %v", err)
+	}
+	mattermostNotifier, err := webhooknotifier.New(webhooknotifier.Opts{
+		Lo:       initLogger("mattermost-notifier"),
+		Provider: notifier.ProviderMattermost,
+		Timeout:  chatTimeout,
+	})
+	if err != nil {
+		log.Fatalf("error initializing mattermost notifier: %v", err)
+	}
+	matrixNotifier := matrixnotifier.New(matrixnotifier.Opts{
+		Lo:      initLogger("matrix-notifier"),
+		Timeout: chatTimeout,
+	})
+
 	notifierProviders := map[string]notifier.Notifier{
-		emailNotifier.Name(): emailNotifier,
+		emailNotifier.Name():      emailNotifier,
+		slackNotifier.Name():      slackNotifier,
+		mattermostNotifier.Name(): mattermostNotifier,
+		matrixNotifier.Name():     matrixNotifier,
+		webPush.Name():            webPush,
 	}
 
 	return notifier.NewService(notifierProviders, ko.MustInt("notification.concurrency"), ko.MustInt("notification.queue_size"), initLogger("notifier"))
 }
 
+// initDigest inits digest manager.
+func initDigest(db *sqlx.DB, i18n *i18n.I18n, templateStore *tmpl.Manager, notifier *notifier.Service) *digest.Manager {
+	m, err := digest.New(templateStore, notifier, digest.Opts{
+		DB:              db,
+		Lo:              initLogger("digest"),
+		I18n:            i18n,
+		DefaultTimezone: cmp.Or(ko.String("app.timezone"), "UTC"),
+	})
+	if err != nil {
+		log.Fatalf("error initializing digest manager: %v", err)
+	}
+	return m
+}
+
+// initScheduledReport inits scheduled report manager.
+func initScheduledReport(db *sqlx.DB, i18n *i18n.I18n, reportStore *report.Manager, templateStore *tmpl.Manager, notifier *notifier.Service) *scheduledreport.Manager {
+	m, err := scheduledreport.New(reportStore, templateStore, notifier, scheduledreport.Opts{
+		DB:   db,
+		Lo:   initLogger("scheduled_report"),
+		I18n: i18n,
+	})
+	if err != nil {
+		log.Fatalf("error initializing scheduled report manager: %v", err)
+	}
+	return m
+}
+
+// initWebPush inits the Web Push notifier, generating and saving the VAPID key pair on first run.
+func initWebPush(settings *setting.Manager) *webpushnotifier.WebPush {
+	var (
+		publicKey  = ko.String("notification.webpush.vapid_public_key")
+		privateKey = ko.String("notification.webpush.vapid_private_key")
+	)
+	if privateKey == "" {
+		var err error
+		if publicKey, privateKey, err = webpushnotifier.GenerateVAPIDKeys(); err != nil {
+			log.Fatalf("error generating VAPID keys: %v", err)
+		}
+		if err := settings.Update(map[string]string{
+			"notification.webpush.vapid_public_key":  publicKey,
+			"notification.webpush.vapid_private_key": privateKey,
+		}); err != nil {
+			log.Fatalf("error saving VAPID keys: %v", err)
+		}
+	}
+
+	// Push services contact the subject about issues with the pushed messages.
+	subject := ko.String("app.root_url")
+	if !strings.HasPrefix(subject, "https://") {
+		subject = "mailto:" + ko.String("notification.email.email_address")
+	}
+
+	webPush, err := webpushnotifier.New(webpushnotifier.Opts{
+		Lo:              initLogger("webpush-notifier"),
+		VAPIDPublicKey:  publicKey,
+		VAPIDPrivateKey: privateKey,
+		Subject:         subject,
+		Timeout:         ko.Duration("notification.chat.timeout"),
+	})
+	if err != nil {
+		log.Fatalf("error initializing web push notifier: %v", err)
+	}
+	return webPush
+}
+
 // initEmailInbox initializes the email inbox.
 func initEmailInbox(inboxRecord imodels.Inbox, msgStore inbox.MessageStore, usrStore inbox.UserStore) (inbox.Inbox, error) {
 	var config email.Config
@@ -780,10 +883,26 @@ func initPriority(db *sqlx.DB, i18n *i18n.I18n) *priority.Manager {
 // initAI inits AI manager.
 func initAI(db *sqlx.DB, i18n *i18n.I18n) *ai.Manager {
 	lo := initLogger("ai")
+
+	var redaction aimodels.RedactionConfig
+	if err := ko.UnmarshalWithConf("ai.redaction", &redaction, koanf.UnmarshalConf{Tag: "json"}); err != nil {
+		log.Fatalf("error unmarshalling AI redaction config: %v", err)
+	}
+
 	m, err := ai.New(ai.Opts{
-		DB:   db,
-		Lo:   lo,
-		I18n: i18n,
+		DB:                     db,
+		Lo:                     lo,
+		I18n:                   i18n,
+		SummaryTokenBudget:     ko.Int("ai.summary_token_budget"),
+		TriageTimeout:          ko.Duration("ai.triage_timeout"),
+		TriageCacheTTL:         ko.Duration("ai.triage_cache_ttl"),
+		KnowledgeTopK:          ko.Int("ai.knowledge_top_k"),
+		KnowledgeIndexInterval: ko.Duration("ai.knowledge_index_interval"),
+		KnowledgeMaxAge:        ko.Duration("ai.knowledge_max_age"),
+		KnowledgeMaxCandidates: ko.Int("ai.knowledge_max_candidates"),
+		StreamTimeout:          ko.Duration("ai.stream_timeout"),
+		RequestTimeout:         ko.Duration("ai.request_timeout"),
+		Redaction:              redaction,
 	})
 	if err != nil {
 		log.Fatalf("error initializing AI manager: %v", err)
@@ -847,6 +966,20 @@ func initReport(db *sqlx.DB, i18n *i18n.I18n) *report.Manager {
 	return m
 }
 
+// initUserNotification inits user notification manager.
+func initUserNotification(db *sqlx.DB, i18n *i18n.I18n, wsHub *ws.Hub, notifier *notifier.Service, template *tmpl.Manager, userManager *user.Manager) *usernotification.Manager {
+	lo := initLogger("user_notification")
+	m, err := usernotification.New(wsHub, notifier, template, userManager, usernotification.Opts{
+		DB:   db,
+		Lo:   lo,
+		I18n: i18n,
+	})
+	if err != nil {
+		log.Fatalf("error initializing user notification manager: %v", err)
+	}
+	return m
+}
+
 // initWebhook inits webhook manager.
 func initWebhook(db *sqlx.DB, i18n *i18n.I18n) *webhook.Manager {
 	var lo = initLogger("webhook")
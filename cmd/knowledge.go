@@ -0,0 +1,233 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+const (
+	maxKnowledgeTitleLen = 255
+	// Latest messages used to draft a reply suggestion.
+	suggestionMessagePageSize = 50
+)
+
+// knowledgeDocumentExtensions are the document file types that can be uploaded to the knowledge base.
+var knowledgeDocumentExtensions = []string{"txt", "md", "markdown", "html", "htm"}
+
+type knowledgeDocumentReq struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// handleSuggestReply drafts a reply for the conversation grounded on similar resolved conversations and knowledge base documents.
+func handleSuggestReply(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	conversation, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	messages, _, err := app.conversation.GetConversationMessages(uuid, 1, suggestionMessagePageSize)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	suggestion, err := app.ai.SuggestReply(conversation.ID, messages, promptData(user, conversation), user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(suggestion)
+}
+
+// handleGetKnowledgeDocuments returns all knowledge base documents.
+func handleGetKnowledgeDocuments(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	docs, err := app.ai.GetKnowledgeDocuments()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(docs)
+}
+
+// handleGetKnowledgeDocument returns a knowledge base document along with its content.
+func handleGetKnowledgeDocument(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	doc, err := app.ai.GetKnowledgeDocument(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(doc)
+}
+
+// handleCreateKnowledgeDocument adds a document to the knowledge base, either as JSON or as an uploaded text, markdown or HTML file.
+func handleCreateKnowledgeDocument(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req knowledgeDocumentReq
+	)
+
+	if strings.HasPrefix(string(r.RequestCtx.Request.Header.ContentType()), "multipart/form-data") {
+		var err error
+		if req, err = readKnowledgeDocumentUpload(r); err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+	} else if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+
+	if err := validateKnowledgeDocument(app, &req); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	doc, err := app.ai.CreateKnowledgeDocument(req.Title, req.Content)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(doc)
+}
+
+// handleUpdateKnowledgeDocument updates a knowledge base document.
+func handleUpdateKnowledgeDocument(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req knowledgeDocumentReq
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	if err := validateKnowledgeDocument(app, &req); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	doc, err := app.ai.UpdateKnowledgeDocument(id, req.Title, req.Content)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(doc)
+}
+
+// handleDeleteKnowledgeDocument deletes a knowledge base document.
+func handleDeleteKnowledgeDocument(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	if err := app.ai.DeleteKnowledgeDocument(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleReindexKnowledge queues all knowledge sources for indexing.
+func handleReindexKnowledge(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	if err := app.ai.ReindexKnowledge(); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// readKnowledgeDocumentUpload reads an uploaded knowledge base document, the title defaults to the file name.
+func readKnowledgeDocumentUpload(r *fastglue.Request) (knowledgeDocumentReq, error) {
+	var app = r.Context.(*App)
+
+	form, err := r.RequestCtx.MultipartForm()
+	if err != nil {
+		app.lo.Error("error parsing form data", "error", err)
+		return knowledgeDocumentReq{}, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil)
+	}
+
+	files, ok := form.File["file"]
+	if !ok || len(files) == 0 {
+		return knowledgeDocumentReq{}, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.file}"), nil)
+	}
+	fileHeader := files[0]
+
+	fileName := stringutil.SanitizeFilename(fileHeader.Filename)
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	if !slices.Contains(knowledgeDocumentExtensions, ext) {
+		return knowledgeDocumentReq{}, envelope.NewError(envelope.InputError, app.i18n.T("media.fileTypeNotAllowed"), nil)
+	}
+
+	consts := app.consts.Load().(*constants)
+	if bytesToMegabytes(fileHeader.Size) > float64(consts.MaxFileUploadSizeMB) {
+		return knowledgeDocumentReq{}, envelope.NewError(envelope.InputError, app.i18n.Ts("media.fileSizeTooLarge", "size", strconv.Itoa(consts.MaxFileUploadSizeMB)+"MB"), nil)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		app.lo.Error("error reading uploaded file", "error", err)
+		return knowledgeDocumentReq{}, envelope.NewError(envelope.GeneralError, app.i18n.Ts("globals.messages.errorReading", "name", "{globals.terms.file}"), nil)
+	}
+	defer file.Close()
+
+	b, err := io.ReadAll(file)
+	if err != nil {
+		app.lo.Error("error reading uploaded file", "error", err)
+		return knowledgeDocumentReq{}, envelope.NewError(envelope.GeneralError, app.i18n.Ts("globals.messages.errorReading", "name", "{globals.terms.file}"), nil)
+	}
+
+	req := knowledgeDocumentReq{
+		Title:   strings.TrimSuffix(fileName, filepath.Ext(fileName)),
+		Content: string(b),
+	}
+	if v, ok := form.Value["title"]; ok && len(v) > 0 && strings.TrimSpace(v[0]) != "" {
+		req.Title = v[0]
+	}
+	if ext == "html" || ext == "htm" {
+		req.Content = stringutil.HTML2Text(req.Content)
+	}
+	return req, nil
+}
+
+// validateKnowledgeDocument validates and trims a knowledge base document.
+func validateKnowledgeDocument(app *App, req *knowledgeDocumentReq) error {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Content = strings.TrimSpace(req.Content)
+	if req.Title == "" || utf8.RuneCountInString(req.Title) > maxKnowledgeTitleLen {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`title`"), nil)
+	}
+	if req.Content == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`content`"), nil)
+	}
+	if !utf8.ValidString(req.Content) {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`content`"), nil)
+	}
+	return nil
+}
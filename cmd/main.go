@@ -21,9 +21,12 @@ import (
 	"github.com/abhinavxd/libredesk/internal/colorlog"
 	"github.com/abhinavxd/libredesk/internal/csat"
 	customAttribute "github.com/abhinavxd/libredesk/internal/custom_attribute"
+	"github.com/abhinavxd/libredesk/internal/digest"
 	"github.com/abhinavxd/libredesk/internal/macro"
 	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	webpushnotifier "github.com/abhinavxd/libredesk/internal/notification/providers/webpush"
 	"github.com/abhinavxd/libredesk/internal/report"
+	scheduledreport "github.com/abhinavxd/libredesk/internal/scheduled_report"
 	"github.com/abhinavxd/libredesk/internal/search"
 	"github.com/abhinavxd/libredesk/internal/sla"
 	"github.com/abhinavxd/libredesk/internal/view"
@@ -41,6 +44,7 @@ import (
 	"github.com/abhinavxd/libredesk/internal/team"
 	"github.com/abhinavxd/libredesk/internal/template"
 	"github.com/abhinavxd/libredesk/internal/user"
+	usernotification "github.com/abhinavxd/libredesk/internal/user_notification"
 	"github.com/abhinavxd/libredesk/internal/webhook"
 	"github.com/knadh/go-i18n"
 	"github.com/knadh/koanf/v2"
@@ -91,9 +95,13 @@ type App struct {
 	search          *search.Manager
 	activityLog     *activitylog.Manager
 	notifier        *notifier.Service
+	webPush         *webpushnotifier.WebPush
 	customAttribute *customAttribute.Manager
 	report          *report.Manager
 	webhook         *webhook.Manager
+	notification    *usernotification.Manager
+	digest          *digest.Manager
+	scheduledReport *scheduledreport.Manager
 
 	// Global state that stores data on an available app update.
 	update *AppUpdate
@@ -163,6 +171,12 @@ func main() {
 	settings := initSettings(db)
 	loadSettings(settings)
 
+	// Backfill report rollups.
+	if ko.Bool("backfill-rollups") {
+		backfillRollups(ctx, db, fs)
+		os.Exit(0)
+	}
+
 	// Fallback for config typo. Logs a warning but continues to work with the incorrect key.
 	// Uses 'message.message_outgoing_scan_interval' (correct key) as default key, falls back to the common typo.
 	msgOutgoingScanIntervalKey := "message.message_outgoing_scan_interval"
@@ -198,13 +212,29 @@ func main() {
 		webhook                     = initWebhook(db, i18n)
 		user                        = initUser(i18n, db)
 		wsHub                       = initWS(user)
-		notifier                    = initNotifier()
-		automation                  = initAutomationEngine(db, i18n)
+		webPush                     = initWebPush(settings)
+		notifier                    = initNotifier(webPush)
+		automation                  = initAutomationEngine(db, i18n, priority, team)
 		sla                         = initSLA(db, team, settings, businessHours, notifier, template, user, i18n)
 		conversation                = initConversations(i18n, sla, status, priority, wsHub, notifier, db, inbox, user, team, media, settings, csat, automation, template, webhook)
 		autoassigner                = initAutoAssigner(team, user, conversation)
+		ai                          = initAI(db, i18n)
+		notification                = initUserNotification(db, i18n, wsHub, notifier, template, user)
+		digest                      = initDigest(db, i18n, template, notifier)
+		report                      = initReport(db, i18n)
+		scheduledReport             = initScheduledReport(db, i18n, report, template, notifier)
 	)
 	automation.SetConversationStore(conversation)
+	sla.SetConversationStore(conversation)
+	webhook.SetConversationStore(conversation)
+	webhook.SetUserStore(user)
+	conversation.SetAIStore(ai)
+	conversation.SetBusinessHoursStore(businessHours)
+	conversation.SetNotificationStore(notification)
+	sla.SetNotificationStore(notification)
+	webPush.SetSubscriptionStore(notification)
+	ai.SetTemplateStore(template)
+	ai.SetConversationStore(conversation)
 
 	startInboxes(ctx, inbox, conversation, user)
 	go automation.Run(ctx, automationWorkers)
@@ -214,9 +244,14 @@ func main() {
 	go webhook.Run(ctx)
 	go notifier.Run(ctx)
 	go sla.Run(ctx, slaEvaluationInterval)
+	go ai.RunKnowledgeIndexer(ctx)
+	go businessHours.RunHolidaySync(ctx)
 	go sla.SendNotifications(ctx)
 	go media.DeleteUnlinkedMedia(ctx)
 	go user.MonitorAgentAvailability(ctx)
+	go digest.Run(ctx)
+	go scheduledReport.Run(ctx)
+	go report.RunRollups(ctx)
 
 	var app = &App{
 		lo:              lo,
@@ -234,21 +269,25 @@ func main() {
 		priority:        priority,
 		tmpl:            template,
 		notifier:        notifier,
+		webPush:         webPush,
 		consts:          atomic.Value{},
 		conversation:    conversation,
+		notification:    notification,
+		digest:          digest,
+		scheduledReport: scheduledReport,
 		automation:      automation,
 		businessHours:   businessHours,
 		activityLog:     initActivityLog(db, i18n),
 		customAttribute: initCustomAttribute(db, i18n),
 		authz:           initAuthz(i18n),
 		view:            initView(db, i18n),
-		report:          initReport(db, i18n),
+		report:          report,
 		csat:            initCSAT(db, i18n),
 		search:          initSearch(db, i18n),
 		role:            initRole(db, i18n),
 		tag:             initTag(db, i18n),
 		macro:           initMacro(db, i18n),
-		ai:              initAI(db, i18n),
+		ai:              ai,
 		webhook:         webhook,
 	}
 	app.consts.Store(constants)
@@ -4,8 +4,10 @@ import (
 	"strconv"
 
 	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	medModels "github.com/abhinavxd/libredesk/internal/media/models"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
 	"github.com/valyala/fasthttp"
 	"github.com/zerodha/fastglue"
 )
@@ -17,6 +19,7 @@ type messageReq struct {
 	To          []string `json:"to"`
 	CC          []string `json:"cc"`
 	BCC         []string `json:"bcc"`
+	Mentions    []int    `json:"mentions"`
 }
 
 // handleGetMessages returns messages for a conversation.
@@ -166,6 +169,7 @@ func handleSendMessage(r *fastglue.Request) error {
 		if err != nil {
 			return sendErrorEnvelope(r, err)
 		}
+		app.conversation.NotifyMentions(message, mentionedAgents(app, req.Mentions, *conv), user)
 		return r.SendEnvelope(message)
 	}
 	message, err := app.conversation.QueueReply(media, conv.InboxID, user.ID, cuuid, req.Message, req.To, req.CC, req.BCC, map[string]any{} /**meta**/)
@@ -174,3 +178,23 @@ func handleSendMessage(r *fastglue.Request) error {
 	}
 	return r.SendEnvelope(message)
 }
+
+// mentionedAgents returns the mentioned agents that can access the conversation, mentions of other users are dropped
+// so that private notes are not leaked to them.
+func mentionedAgents(app *App, userIDs []int, conversation cmodels.Conversation) []umodels.User {
+	var agents = make([]umodels.User, 0, len(userIDs))
+	for _, id := range userIDs {
+		agent, err := app.user.GetAgent(id, "")
+		if err != nil {
+			app.lo.Warn("skipping mention of unknown agent", "user_id", id, "error", err)
+			continue
+		}
+		allowed, err := app.authz.EnforceConversationAccess(agent, conversation)
+		if err != nil || !allowed {
+			app.lo.Warn("skipping mention of agent without access to conversation", "user_id", id, "conversation_uuid", conversation.UUID)
+			continue
+		}
+		agents = append(agents, agent)
+	}
+	return agents
+}
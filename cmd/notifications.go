@@ -0,0 +1,210 @@
+package main
+
+import (
+	"strconv"
+
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	dmodels "github.com/abhinavxd/libredesk/internal/digest/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/netutil"
+	unmodels "github.com/abhinavxd/libredesk/internal/user_notification/models"
+	"github.com/zerodha/fastglue"
+)
+
+const (
+	defaultNotificationsPageSize = 20
+	maxNotificationsPageSize     = 100
+)
+
+// notificationsResp is the response of the notifications list.
+type notificationsResp struct {
+	envelope.PageResults
+	UnreadCount int `json:"unread_count"`
+}
+
+// handleGetNotifications returns the notifications of the current agent.
+func handleGetNotifications(r *fastglue.Request) error {
+	var (
+		app         = r.Context.(*App)
+		auser       = r.RequestCtx.UserValue("user").(amodels.User)
+		unreadOnly  = string(r.RequestCtx.QueryArgs().Peek("unread")) == "true"
+		page, _     = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page")))
+		pageSize, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page_size")))
+	)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxNotificationsPageSize {
+		pageSize = defaultNotificationsPageSize
+	}
+	notifications, total, err := app.notification.GetAll(auser.ID, unreadOnly, page, pageSize)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	unread, err := app.notification.UnreadCount(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	resp := notificationsResp{
+		PageResults: envelope.PageResults{
+			Results:    notifications,
+			Total:      total,
+			PerPage:    pageSize,
+			TotalPages: (total + pageSize - 1) / pageSize,
+			Page:       page,
+		},
+		UnreadCount: unread,
+	}
+	return r.SendEnvelope(resp)
+}
+
+// handleMarkNotificationRead marks a notification of the current agent as read.
+func handleMarkNotificationRead(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	id, err := strconv.ParseInt(r.RequestCtx.UserValue("id").(string), 10, 64)
+	if err != nil || id <= 0 {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil))
+	}
+	if err := app.notification.MarkRead(auser.ID, id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleMarkAllNotificationsRead marks all notifications of the current agent as read.
+func handleMarkAllNotificationsRead(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	if err := app.notification.MarkAllRead(auser.ID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetNotificationPreferences returns the channels the current agent gets each notification type on.
+func handleGetNotificationPreferences(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	prefs, err := app.notification.GetPreferences(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(prefs)
+}
+
+// handleUpdateNotificationPreferences updates the channels the current agent gets notification types on.
+func handleUpdateNotificationPreferences(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = []unmodels.Preference{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	if err := app.notification.UpdatePreferences(auser.ID, req); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	prefs, err := app.notification.GetPreferences(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(prefs)
+}
+
+// pushSubscriptionReq is a browser's push subscription, as serialized by PushSubscription.toJSON().
+type pushSubscriptionReq struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// handleGetVAPIDPublicKey returns the VAPID public key browsers subscribe to push notifications with.
+func handleGetVAPIDPublicKey(r *fastglue.Request) error {
+	app := r.Context.(*App)
+	return r.SendEnvelope(map[string]string{"public_key": app.webPush.PublicKey()})
+}
+
+// handleCreatePushSubscription saves a push subscription of the current agent's browser.
+func handleCreatePushSubscription(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = pushSubscriptionReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	// Push services are only reachable over HTTPS and messages are sent to the endpoint by the server,
+	// so it must not point to the local network.
+	if len(req.Endpoint) > 2048 || netutil.ValidatePublicURL(req.Endpoint, "https") != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`endpoint`"), nil))
+	}
+	if req.Keys.P256dh == "" || req.Keys.Auth == "" {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`keys`"), nil))
+	}
+
+	sub, err := app.notification.SavePushSubscription(auser.ID, req.Endpoint, req.Keys.P256dh, req.Keys.Auth, string(r.RequestCtx.UserAgent()))
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(sub)
+}
+
+// handleDeletePushSubscription deletes a push subscription of the current agent's browser.
+func handleDeletePushSubscription(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = pushSubscriptionReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	if req.Endpoint == "" {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`endpoint`"), nil))
+	}
+	if err := app.notification.DeleteUserPushSubscription(auser.ID, req.Endpoint); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetDigestSettings returns the digest email settings of the current agent.
+func handleGetDigestSettings(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	settings, err := app.digest.GetSettings(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(settings)
+}
+
+// handleUpdateDigestSettings updates the digest email settings of the current agent.
+func handleUpdateDigestSettings(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = dmodels.Settings{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	settings, err := app.digest.UpdateSettings(auser.ID, req)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(settings)
+}
@@ -1,8 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
 	"strconv"
+	"time"
 
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/report"
+	rmodels "github.com/abhinavxd/libredesk/internal/report/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/stuffbin"
 	"github.com/zerodha/fastglue"
 )
 
@@ -43,3 +53,238 @@ func handleOverviewSLA(r *fastglue.Request) error {
 	}
 	return r.SendEnvelope(sla)
 }
+
+// handleSLAReport retrieves SLA performance grouped by team, agent, policy, inbox or priority.
+func handleSLAReport(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	opts, err := parseSLAReportOptions(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	rows, err := app.report.GetSLAReport(opts)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(rows)
+}
+
+// handleExportSLAReport exports SLA performance as a CSV file.
+func handleExportSLAReport(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	opts, err := parseSLAReportOptions(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	rows, err := app.report.GetSLAReport(opts)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteSLAReportCSV(&buf, rows); err != nil {
+		app.lo.Error("error writing SLA report CSV", "error", err)
+		return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.report}"), nil))
+	}
+	filename := fmt.Sprintf("sla-report-%s-%s.csv", opts.From.Format(time.DateOnly), opts.To.AddDate(0, 0, -1).Format(time.DateOnly))
+	r.RequestCtx.Response.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	r.RequestCtx.Response.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	r.RequestCtx.SetBody(buf.Bytes())
+	return nil
+}
+
+// handleAgentPerformanceReport retrieves the performance of agents, optionally of a team and inbox.
+func handleAgentPerformanceReport(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	opts, err := parseAgentPerformanceOptions(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	rows, err := app.report.GetAgentPerformanceReport(opts)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(rows)
+}
+
+// handleExportAgentPerformanceReport exports the performance of agents as a CSV file.
+func handleExportAgentPerformanceReport(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	opts, err := parseAgentPerformanceOptions(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	rows, err := app.report.GetAgentPerformanceReport(opts)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteAgentPerformanceReportCSV(&buf, rows); err != nil {
+		app.lo.Error("error writing agent performance report CSV", "error", err)
+		return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.report}"), nil))
+	}
+	filename := fmt.Sprintf("agent-report-%s-%s.csv", opts.From.Format(time.DateOnly), opts.To.AddDate(0, 0, -1).Format(time.DateOnly))
+	r.RequestCtx.Response.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	r.RequestCtx.Response.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	r.RequestCtx.SetBody(buf.Bytes())
+	return nil
+}
+
+// handleCSATReport retrieves CSAT scores and response rates grouped by agent, team, inbox or tag.
+func handleCSATReport(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	opts, err := parseCSATReportOptions(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	rows, err := app.report.GetCSATReport(opts)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(rows)
+}
+
+// handleExportCSATReport exports CSAT scores and response rates as a CSV file.
+func handleExportCSATReport(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	opts, err := parseCSATReportOptions(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	rows, err := app.report.GetCSATReport(opts)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSATReportCSV(&buf, rows); err != nil {
+		app.lo.Error("error writing CSAT report CSV", "error", err)
+		return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.report}"), nil))
+	}
+	filename := fmt.Sprintf("csat-report-%s-%s.csv", opts.From.Format(time.DateOnly), opts.To.AddDate(0, 0, -1).Format(time.DateOnly))
+	r.RequestCtx.Response.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	r.RequestCtx.Response.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	r.RequestCtx.SetBody(buf.Bytes())
+	return nil
+}
+
+// handleGetCSATResponses retrieves a page of answered CSAT surveys with their feedback.
+func handleGetCSATResponses(r *fastglue.Request) error {
+	var (
+		app         = r.Context.(*App)
+		order       = string(r.RequestCtx.QueryArgs().Peek("order"))
+		orderBy     = string(r.RequestCtx.QueryArgs().Peek("order_by"))
+		filters     = string(r.RequestCtx.QueryArgs().Peek("filters"))
+		page, _     = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page")))
+		pageSize, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page_size")))
+		total       = 0
+	)
+	responses, err := app.csat.GetAllResponses(order, orderBy, filters, page, pageSize)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if len(responses) > 0 {
+		total = responses[0].Total
+	}
+	return r.SendEnvelope(envelope.PageResults{
+		Results:    responses,
+		Total:      total,
+		PerPage:    pageSize,
+		TotalPages: (total + pageSize - 1) / pageSize,
+		Page:       page,
+	})
+}
+
+// parseSLAReportOptions parses the SLA report options from the query args.
+func parseSLAReportOptions(r *fastglue.Request) (rmodels.SLAReportOptions, error) {
+	from, to, err := parseReportPeriod(r)
+	if err != nil {
+		return rmodels.SLAReportOptions{}, err
+	}
+	args := r.RequestCtx.QueryArgs()
+	return rmodels.SLAReportOptions{
+		GroupBy:  string(args.Peek("group_by")),
+		Interval: string(args.Peek("interval")),
+		From:     from,
+		To:       to,
+	}, nil
+}
+
+// parseCSATReportOptions parses the CSAT report options from the query args.
+func parseCSATReportOptions(r *fastglue.Request) (rmodels.CSATReportOptions, error) {
+	from, to, err := parseReportPeriod(r)
+	if err != nil {
+		return rmodels.CSATReportOptions{}, err
+	}
+	args := r.RequestCtx.QueryArgs()
+	return rmodels.CSATReportOptions{
+		GroupBy:  string(args.Peek("group_by")),
+		Interval: string(args.Peek("interval")),
+		From:     from,
+		To:       to,
+	}, nil
+}
+
+// parseAgentPerformanceOptions parses the agent performance report options from the query args.
+func parseAgentPerformanceOptions(r *fastglue.Request) (rmodels.AgentPerformanceOptions, error) {
+	var (
+		app  = r.Context.(*App)
+		args = r.RequestCtx.QueryArgs()
+		opts rmodels.AgentPerformanceOptions
+		err  error
+	)
+	if opts.From, opts.To, err = parseReportPeriod(r); err != nil {
+		return opts, err
+	}
+	if v := string(args.Peek("team_id")); v != "" {
+		if opts.TeamID, err = strconv.Atoi(v); err != nil {
+			return opts, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`team_id`"), nil)
+		}
+	}
+	if v := string(args.Peek("inbox_id")); v != "" {
+		if opts.InboxID, err = strconv.Atoi(v); err != nil {
+			return opts, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`inbox_id`"), nil)
+		}
+	}
+	return opts, nil
+}
+
+// parseReportPeriod parses the period of a report from the query args and returns its start and exclusive end.
+// `from` and `to` are inclusive dates, the report covers the last 30 days if not set.
+func parseReportPeriod(r *fastglue.Request) (time.Time, time.Time, error) {
+	var (
+		app  = r.Context.(*App)
+		args = r.RequestCtx.QueryArgs()
+		to   = time.Now().UTC().Truncate(24 * time.Hour)
+		from = to.AddDate(0, 0, -29)
+		err  error
+	)
+	if v := string(args.Peek("to")); v != "" {
+		if to, err = time.Parse(time.DateOnly, v); err != nil {
+			return from, to, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`to`"), nil)
+		}
+	}
+	if v := string(args.Peek("from")); v != "" {
+		if from, err = time.Parse(time.DateOnly, v); err != nil {
+			return from, to, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`from`"), nil)
+		}
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// backfillRollups rolls up the existing conversations for the reports.
+func backfillRollups(ctx context.Context, db *sqlx.DB, fs stuffbin.FileSystem) {
+	var from time.Time
+	if v := ko.String("backfill-from"); v != "" {
+		var err error
+		if from, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			log.Fatalf("invalid `--backfill-from` date %q: %v", v, err)
+		}
+	}
+	r := initReport(db, initI18n(fs))
+	log.Printf("backfilling report rollups")
+	if err := r.BackfillRollups(ctx, from); err != nil {
+		log.Fatalf("error backfilling report rollups: %v", err)
+	}
+	log.Printf("report rollups backfilled")
+}
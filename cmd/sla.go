@@ -1,15 +1,27 @@
 package main
 
 import (
+	"slices"
 	"strconv"
 	"time"
 
+	amodels "github.com/abhinavxd/libredesk/internal/automation/models"
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	stmodels "github.com/abhinavxd/libredesk/internal/conversation/status/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	smodels "github.com/abhinavxd/libredesk/internal/sla/models"
 	"github.com/valyala/fasthttp"
 	"github.com/zerodha/fastglue"
 )
 
+var (
+	// slaEscalationMetrics are the metrics an SLA escalation can run on.
+	slaEscalationMetrics = []string{"first_response", "resolution", "next_response", "all"}
+
+	// slaEscalationActions are the conversation actions an SLA escalation can run.
+	slaEscalationActions = []string{amodels.ActionAssignTeam, amodels.ActionAssignUser, amodels.ActionSetPriority, amodels.ActionAddTags, amodels.ActionSendPrivateNote}
+)
+
 // handleGetSLAs returns all SLAs.
 func handleGetSLAs(r *fastglue.Request) error {
 	var (
@@ -54,7 +66,7 @@ func handleCreateSLA(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, err)
 	}
 
-	createdSLA, err := app.sla.Create(sla.Name, sla.Description, sla.FirstResponseTime, sla.ResolutionTime, sla.NextResponseTime, sla.Notifications)
+	createdSLA, err := app.sla.Create(sla.Name, sla.Description, sla.FirstResponseTime, sla.ResolutionTime, sla.NextResponseTime, sla.Notifications, sla.PauseStatuses, sla.Conditions, sla.Escalations)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -82,7 +94,7 @@ func handleUpdateSLA(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, err)
 	}
 
-	updatedSLA, err := app.sla.Update(id, sla.Name, sla.Description, sla.FirstResponseTime, sla.ResolutionTime, sla.NextResponseTime, sla.Notifications)
+	updatedSLA, err := app.sla.Update(id, sla.Name, sla.Description, sla.FirstResponseTime, sla.ResolutionTime, sla.NextResponseTime, sla.Notifications, sla.PauseStatuses, sla.Conditions, sla.Escalations)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -174,6 +186,70 @@ func validateSLA(app *App, sla *smodels.SLAPolicy) error {
 		}
 	}
 
+	// Validate pause statuses, resolved and closed conversations already stop the SLA clock.
+	if len(sla.PauseStatuses) > 0 {
+		statuses, err := app.status.GetAll()
+		if err != nil {
+			return err
+		}
+		for _, name := range sla.PauseStatuses {
+			exists := slices.ContainsFunc(statuses, func(s stmodels.Status) bool { return s.Name == name })
+			if !exists || name == cmodels.StatusResolved || name == cmodels.StatusClosed {
+				return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`pause_statuses`"), nil)
+			}
+		}
+	}
+
+	// Validate match conditions, at most two groups of automation rules are evaluated.
+	if !sla.Conditions.IsEmpty() {
+		if len(sla.Conditions.Groups) > 2 {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`conditions`"), nil)
+		}
+		if sla.Conditions.GroupOperator != amodels.OperatorAnd && sla.Conditions.GroupOperator != amodels.OperatorOR {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`group_operator`"), nil)
+		}
+		for _, group := range sla.Conditions.Groups {
+			if len(group.Rules) > 0 && group.LogicalOp != amodels.OperatorAnd && group.LogicalOp != amodels.OperatorOR {
+				return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`logical_op`"), nil)
+			}
+			for _, rule := range group.Rules {
+				if rule.Field == "" || rule.Operator == "" {
+					return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`field`, `operator`"), nil)
+				}
+			}
+		}
+	}
+
+	// Validate escalations, warnings run a delay before the deadline and breaches run immediately or a delay after the breach.
+	for _, e := range sla.Escalations {
+		if e.Type != "warning" && e.Type != "breach" {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`type`"), nil)
+		}
+		if !slices.Contains(slaEscalationMetrics, e.Metric) {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`metric`"), nil)
+		}
+		if e.Type == "warning" && e.TimeDelay == "" {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`time_delay`"), nil)
+		}
+		if e.TimeDelay != "" {
+			td, err := time.ParseDuration(e.TimeDelay)
+			if err != nil || td.Minutes() < 1 {
+				return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`time_delay`"), nil)
+			}
+		}
+		if len(e.Actions) == 0 {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`actions`"), nil)
+		}
+		for _, action := range e.Actions {
+			if !slices.Contains(slaEscalationActions, action.Type) {
+				return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`actions`"), nil)
+			}
+			if len(action.Value) == 0 || action.Value[0] == "" {
+				return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`value`"), nil)
+			}
+		}
+	}
+
 	// Validate next response time duration string if not empty.
 	if sla.NextResponseTime.String != "" {
 		nrt, err := time.ParseDuration(sla.NextResponseTime.String)
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/abhinavxd/libredesk/internal/team/models"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+// handleGetTeamNotificationChannels returns the notification channels of a team.
+func handleGetTeamNotificationChannels(r *fastglue.Request) error {
+	var (
+		app       = r.Context.(*App)
+		teamID, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if teamID < 1 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	channels, err := app.team.GetNotificationChannels(teamID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	for i := range channels {
+		hideNotificationChannelSecrets(&channels[i])
+	}
+	return r.SendEnvelope(channels)
+}
+
+// handleCreateTeamNotificationChannel creates a notification channel for a team.
+func handleCreateTeamNotificationChannel(r *fastglue.Request) error {
+	var (
+		app       = r.Context.(*App)
+		teamID, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		req       = models.NotificationChannel{}
+	)
+	if teamID < 1 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	req.TeamID = teamID
+	if err := validateNotificationChannel(app, req); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	channel, err := app.team.CreateNotificationChannel(req)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	hideNotificationChannelSecrets(&channel)
+	return r.SendEnvelope(channel)
+}
+
+// handleUpdateTeamNotificationChannel updates a notification channel of a team.
+func handleUpdateTeamNotificationChannel(r *fastglue.Request) error {
+	var (
+		app       = r.Context.(*App)
+		teamID, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		id, _     = strconv.Atoi(r.RequestCtx.UserValue("channel_id").(string))
+		req       = models.NotificationChannel{}
+	)
+	if teamID < 1 || id < 1 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil))
+	}
+	req.TeamID, req.ID = teamID, id
+
+	// Preserve the existing secrets if they are unchanged.
+	existing, err := app.team.GetNotificationChannel(teamID, id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if strings.Contains(req.Config.URL, stringutil.PasswordDummy) {
+		req.Config.URL = existing.Config.URL
+	}
+	if strings.Contains(req.Config.AccessToken, stringutil.PasswordDummy) {
+		req.Config.AccessToken = existing.Config.AccessToken
+	}
+
+	if err := validateNotificationChannel(app, req); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	channel, err := app.team.UpdateNotificationChannel(req)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	hideNotificationChannelSecrets(&channel)
+	return r.SendEnvelope(channel)
+}
+
+// handleDeleteTeamNotificationChannel deletes a notification channel of a team.
+func handleDeleteTeamNotificationChannel(r *fastglue.Request) error {
+	var (
+		app       = r.Context.(*App)
+		teamID, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		id, _     = strconv.Atoi(r.RequestCtx.UserValue("channel_id").(string))
+	)
+	if teamID < 1 || id < 1 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	if err := app.team.DeleteNotificationChannel(teamID, id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleTestTeamNotificationChannel posts a test message to a notification channel of a team.
+func handleTestTeamNotificationChannel(r *fastglue.Request) error {
+	var (
+		app       = r.Context.(*App)
+		teamID, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		id, _     = strconv.Atoi(r.RequestCtx.UserValue("channel_id").(string))
+	)
+	if teamID < 1 || id < 1 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.invalid", "name", "`id`"), nil, envelope.InputError)
+	}
+	channel, err := app.team.GetNotificationChannel(teamID, id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	team, err := app.team.Get(teamID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	// Send right away to report errors of the chat platform back.
+	if err := app.notifier.SendNow(notifier.Message{
+		Subject:    app.i18n.T("admin.team.notificationChannel.testSubject"),
+		AltContent: app.i18n.Ts("admin.team.notificationChannel.testContent", "team", team.Name),
+		Provider:   channel.Provider,
+		Chat:       channel.Target(),
+	}); err != nil {
+		app.lo.Error("error sending test message to team notification channel", "team_id", teamID, "channel_id", id, "error", err)
+		return r.SendErrorEnvelope(fasthttp.StatusBadGateway, app.i18n.Ts("admin.team.notificationChannel.testFailed", "error", err.Error()), nil, envelope.GeneralError)
+	}
+	return r.SendEnvelope(true)
+}
+
+// validateNotificationChannel validates a team notification channel.
+func validateNotificationChannel(app *App, c models.NotificationChannel) error {
+	if c.Name == "" || len(c.Name) > 140 {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`name`"), nil)
+	}
+	if !slices.Contains(notifier.ChatProviders, c.Provider) {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`provider`"), nil)
+	}
+	if u, err := url.ParseRequestURI(c.Config.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`url`"), nil)
+	}
+	if c.Provider == notifier.ProviderMatrix {
+		if c.Config.RoomID == "" {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`room_id`"), nil)
+		}
+		if c.Config.AccessToken == "" {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`access_token`"), nil)
+		}
+	}
+	for _, event := range c.Events {
+		if !slices.Contains(models.ChannelEvents, event) {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.invalid", "name", "`events`"), nil)
+		}
+	}
+	return nil
+}
+
+// hideNotificationChannelSecrets hides the secrets of a notification channel, incoming webhook URLs embed a token.
+func hideNotificationChannelSecrets(c *models.NotificationChannel) {
+	if c.Provider == notifier.ProviderMatrix {
+		if c.Config.AccessToken != "" {
+			c.Config.AccessToken = strings.Repeat(stringutil.PasswordDummy, 10)
+		}
+		return
+	}
+	if c.Config.URL != "" {
+		c.Config.URL = strings.Repeat(stringutil.PasswordDummy, 10)
+	}
+}
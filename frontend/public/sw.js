@@ -0,0 +1,41 @@
+// Service worker showing the push notifications sent to agents.
+
+// Take control of open tabs right away, so clicked notifications can navigate them.
+self.addEventListener('activate', (event) => {
+  event.waitUntil(self.clients.claim())
+})
+
+self.addEventListener('push', (event) => {
+  if (!event.data) return
+  let data = {}
+  try {
+    data = event.data.json()
+  } catch {
+    data = { title: event.data.text() }
+  }
+  event.waitUntil(
+    self.registration.showNotification(data.title || 'Libredesk', {
+      body: data.body || '',
+      data: { url: data.url || '/' }
+    })
+  )
+})
+
+// Focus an open tab of the app on the notification's link, or open a new one.
+self.addEventListener('notificationclick', (event) => {
+  event.notification.close()
+  const url = new URL(event.notification.data?.url || '/', self.location.origin).href
+  event.waitUntil(
+    self.clients.matchAll({ type: 'window', includeUncontrolled: true }).then((clients) => {
+      for (const client of clients) {
+        if (new URL(client.url).origin === self.location.origin && 'focus' in client) {
+          return client
+            .focus()
+            .then(() => client.navigate(url))
+            .catch(() => self.clients.openWindow(url))
+        }
+      }
+      return self.clients.openWindow(url)
+    })
+  )
+})
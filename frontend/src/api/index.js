@@ -100,6 +100,12 @@ const updateBusinessHours = (id, data) =>
     }
   })
 const deleteBusinessHours = (id) => http.delete(`/api/v1/business-hours/${id}`)
+const importBusinessHoursHolidays = (id, data) =>
+  http.post(`/api/v1/business-hours/${id}/holidays/import`, data, {
+    headers: {
+      'Content-Type': 'multipart/form-data'
+    }
+  })
 
 const getAllSLAs = () => http.get('/api/v1/sla')
 const getSLA = (id) => http.get(`/api/v1/sla/${id}`)
@@ -204,6 +210,15 @@ const blockContact = (id, data) => http.put(`/api/v1/contacts/${id}/block`, data
   }
 })
 const getTeam = (id) => http.get(`/api/v1/teams/${id}`)
+const getTeamNotificationChannels = (id) => http.get(`/api/v1/teams/${id}/notification-channels`)
+const createTeamNotificationChannel = (id, data) =>
+  http.post(`/api/v1/teams/${id}/notification-channels`, data)
+const updateTeamNotificationChannel = (id, channelId, data) =>
+  http.put(`/api/v1/teams/${id}/notification-channels/${channelId}`, data)
+const deleteTeamNotificationChannel = (id, channelId) =>
+  http.delete(`/api/v1/teams/${id}/notification-channels/${channelId}`)
+const testTeamNotificationChannel = (id, channelId) =>
+  http.post(`/api/v1/teams/${id}/notification-channels/${channelId}/test`)
 const getTeams = () => http.get('/api/v1/teams')
 const updateTeam = (id, data) => http.put(`/api/v1/teams/${id}`, data, {
   headers: {
@@ -235,6 +250,17 @@ const getUser = (id) => http.get(`/api/v1/agents/${id}`)
 const deleteUserAvatar = () => http.delete('/api/v1/agents/me/avatar')
 const getCurrentUser = () => http.get('/api/v1/agents/me')
 const getCurrentUserTeams = () => http.get('/api/v1/agents/me/teams')
+const getNotifications = (params) => http.get('/api/v1/notifications', { params })
+const markNotificationRead = (id) => http.put(`/api/v1/notifications/${id}/read`)
+const markAllNotificationsRead = () => http.put('/api/v1/notifications/read-all')
+const getNotificationPreferences = () => http.get('/api/v1/notifications/preferences')
+const updateNotificationPreferences = (data) => http.put('/api/v1/notifications/preferences', data)
+const getVAPIDPublicKey = () => http.get('/api/v1/notifications/push/vapid-public-key')
+const createPushSubscription = (data) => http.post('/api/v1/notifications/push/subscriptions', data)
+const deletePushSubscription = (data) =>
+  http.delete('/api/v1/notifications/push/subscriptions', { data })
+const getDigestSettings = () => http.get('/api/v1/notifications/digest')
+const updateDigestSettings = (data) => http.put('/api/v1/notifications/digest', data)
 const updateCurrentUserAvailability = (data) => http.put('/api/v1/agents/me/availability', data, {
   headers: {
     'Content-Type': 'application/json'
@@ -316,6 +342,9 @@ const sendMessage = (uuid, data) =>
   })
 const getConversation = (uuid) => http.get(`/api/v1/conversations/${uuid}`)
 const getConversationParticipants = (uuid) => http.get(`/api/v1/conversations/${uuid}/participants`)
+const getConversationFollowers = (uuid) => http.get(`/api/v1/conversations/${uuid}/followers`)
+const followConversation = (uuid) => http.post(`/api/v1/conversations/${uuid}/follow`)
+const unfollowConversation = (uuid) => http.delete(`/api/v1/conversations/${uuid}/follow`)
 const getAllMacros = () => http.get('/api/v1/macros')
 const getMacro = (id) => http.get(`/api/v1/macros/${id}`)
 const createMacro = (data) =>
@@ -354,6 +383,21 @@ const uploadMedia = (data) =>
 const getOverviewCounts = () => http.get('/api/v1/reports/overview/counts')
 const getOverviewCharts = (params) => http.get('/api/v1/reports/overview/charts', { params })
 const getOverviewSLA = (params) => http.get('/api/v1/reports/overview/sla', { params })
+const getSLAReport = (params) => http.get('/api/v1/reports/sla', { params })
+const exportSLAReport = (params) =>
+  http.get('/api/v1/reports/sla/export', { params, responseType: 'blob' })
+const getAgentPerformanceReport = (params) => http.get('/api/v1/reports/agents', { params })
+const exportAgentPerformanceReport = (params) =>
+  http.get('/api/v1/reports/agents/export', { params, responseType: 'blob' })
+const getCSATReport = (params) => http.get('/api/v1/reports/csat', { params })
+const exportCSATReport = (params) =>
+  http.get('/api/v1/reports/csat/export', { params, responseType: 'blob' })
+const getCSATResponses = (params) => http.get('/api/v1/reports/csat/responses', { params })
+const getScheduledReports = () => http.get('/api/v1/reports/schedules')
+const createScheduledReport = (data) => http.post('/api/v1/reports/schedules', data)
+const updateScheduledReport = (id, data) => http.put(`/api/v1/reports/schedules/${id}`, data)
+const deleteScheduledReport = (id) => http.delete(`/api/v1/reports/schedules/${id}`)
+const sendScheduledReport = (id) => http.post(`/api/v1/reports/schedules/${id}/send`)
 const getLanguage = (lang) => http.get(`/api/v1/lang/${lang}`)
 const createInbox = (data) =>
   http.post('/api/v1/inboxes', data, {
@@ -386,11 +430,105 @@ const updateView = (id, data) =>
   })
 const deleteView = (id) => http.delete(`/api/v1/views/me/${id}`)
 const getAiPrompts = () => http.get('/api/v1/ai/prompts')
+const getAiPrompt = (id) => http.get(`/api/v1/ai/prompts/${id}`)
+const createAiPrompt = (data) => http.post('/api/v1/ai/prompts', data, {
+  headers: {
+    'Content-Type': 'application/json'
+  }
+})
+const updateAiPrompt = (id, data) => http.put(`/api/v1/ai/prompts/${id}`, data, {
+  headers: {
+    'Content-Type': 'application/json'
+  }
+})
+const deleteAiPrompt = (id) => http.delete(`/api/v1/ai/prompts/${id}`)
+const getAiPromptVersions = (id) => http.get(`/api/v1/ai/prompts/${id}/versions`)
+const rollbackAiPrompt = (id, version) => http.post(`/api/v1/ai/prompts/${id}/versions/${version}/rollback`)
+const suggestReply = (uuid) => http.post(`/api/v1/conversations/${uuid}/suggest-reply`)
+const summarizeConversation = (uuid, data) => http.post(`/api/v1/conversations/${uuid}/summarize`, data, {
+  headers: {
+    'Content-Type': 'application/json'
+  }
+})
+const getKnowledgeDocuments = () => http.get('/api/v1/ai/knowledge/documents')
+const getKnowledgeDocument = (id) => http.get(`/api/v1/ai/knowledge/documents/${id}`)
+const createKnowledgeDocument = (data) => http.post('/api/v1/ai/knowledge/documents', data, {
+  headers: {
+    'Content-Type': data instanceof FormData ? 'multipart/form-data' : 'application/json'
+  }
+})
+const updateKnowledgeDocument = (id, data) => http.put(`/api/v1/ai/knowledge/documents/${id}`, data, {
+  headers: {
+    'Content-Type': 'application/json'
+  }
+})
+const deleteKnowledgeDocument = (id) => http.delete(`/api/v1/ai/knowledge/documents/${id}`)
+const reindexKnowledge = () => http.post('/api/v1/ai/knowledge/reindex')
+const getAiRedactionLogs = (params) => http.get('/api/v1/ai/redaction-logs', { params })
+const getAiUsage = (params) => http.get('/api/v1/ai/usage', { params })
+const getAiBudgets = () => http.get('/api/v1/ai/budgets')
+const createAiBudget = (data) => http.post('/api/v1/ai/budgets', data, {
+  headers: {
+    'Content-Type': 'application/json'
+  }
+})
+const updateAiBudget = (id, data) => http.put(`/api/v1/ai/budgets/${id}`, data, {
+  headers: {
+    'Content-Type': 'application/json'
+  }
+})
+const deleteAiBudget = (id) => http.delete(`/api/v1/ai/budgets/${id}`)
 const aiCompletion = (data) => http.post('/api/v1/ai/completion', data, {
   headers: {
     'Content-Type': 'application/json'
   }
 })
+/**
+ * Streams an AI completion, onDelta is called with each chunk of the response as it is generated.
+ * Aborting the signal cancels the completion on the server.
+ * Errors returned before the stream starts are thrown as regular axios-like errors.
+ */
+const aiCompletionStream = async (data, onDelta, signal) => {
+  const resp = await fetch('/api/v1/ai/completion/stream', {
+    method: 'POST',
+    credentials: 'same-origin',
+    headers: {
+      'Content-Type': 'application/json',
+      'X-CSRFTOKEN': getCSRFToken()
+    },
+    body: JSON.stringify(data),
+    signal
+  })
+  if (!resp.ok) {
+    const body = await resp.json().catch(() => ({}))
+    throw { response: { status: resp.status, data: body } }
+  }
+
+  const reader = resp.body.getReader()
+  const decoder = new TextDecoder()
+  let buffer = ''
+  for (;;) {
+    const { done, value } = await reader.read()
+    if (done) break
+    buffer += decoder.decode(value, { stream: true })
+
+    let idx
+    while ((idx = buffer.indexOf('\n\n')) !== -1) {
+      const raw = buffer.slice(0, idx)
+      buffer = buffer.slice(idx + 2)
+      let event = 'message'
+      let payload = ''
+      for (const line of raw.split('\n')) {
+        if (line.startsWith('event:')) event = line.slice(6).trim()
+        else if (line.startsWith('data:')) payload += line.slice(5).trim()
+      }
+      const parsed = payload ? JSON.parse(payload) : {}
+      if (event === 'delta') onDelta(parsed.text)
+      else if (event === 'error') throw { response: { status: 500, data: parsed } }
+      else if (event === 'done') return
+    }
+  }
+}
 const updateAIProvider = (data) => http.put('/api/v1/ai/provider', data, {
   headers: {
     'Content-Type': 'application/json'
@@ -458,6 +596,7 @@ export default {
   createBusinessHours,
   updateBusinessHours,
   deleteBusinessHours,
+  importBusinessHoursHolidays,
   getAllSLAs,
   getSLA,
   createSLA,
@@ -471,7 +610,32 @@ export default {
   getOverviewCharts,
   getOverviewCounts,
   getOverviewSLA,
+  getSLAReport,
+  exportSLAReport,
+  getAgentPerformanceReport,
+  exportAgentPerformanceReport,
+  getCSATReport,
+  exportCSATReport,
+  getCSATResponses,
+  getScheduledReports,
+  createScheduledReport,
+  updateScheduledReport,
+  deleteScheduledReport,
+  sendScheduledReport,
   getConversationParticipants,
+  getConversationFollowers,
+  followConversation,
+  unfollowConversation,
+  getNotifications,
+  markNotificationRead,
+  markAllNotificationsRead,
+  getNotificationPreferences,
+  updateNotificationPreferences,
+  getVAPIDPublicKey,
+  createPushSubscription,
+  deletePushSubscription,
+  getDigestSettings,
+  updateDigestSettings,
   getConversationMessage,
   getConversationMessages,
   getCurrentUser,
@@ -510,6 +674,11 @@ export default {
   toggleInbox,
   createTeam,
   updateTeam,
+  getTeamNotificationChannels,
+  createTeamNotificationChannel,
+  updateTeamNotificationChannel,
+  deleteTeamNotificationChannel,
+  testTeamNotificationChannel,
   getSettings,
   updateSettings,
   createOIDC,
@@ -541,7 +710,28 @@ export default {
   updateView,
   deleteView,
   getAiPrompts,
+  getAiPrompt,
+  createAiPrompt,
+  updateAiPrompt,
+  deleteAiPrompt,
+  getAiPromptVersions,
+  rollbackAiPrompt,
+  suggestReply,
+  summarizeConversation,
+  getKnowledgeDocuments,
+  getKnowledgeDocument,
+  createKnowledgeDocument,
+  updateKnowledgeDocument,
+  deleteKnowledgeDocument,
+  reindexKnowledge,
+  getAiRedactionLogs,
+  getAiUsage,
+  getAiBudgets,
+  createAiBudget,
+  updateAiBudget,
+  deleteAiBudget,
   aiCompletion,
+  aiCompletionStream,
   searchConversations,
   searchMessages,
   searchContacts,
@@ -34,6 +34,9 @@ export function useActivityLogFilters () {
             }, {
                 label: 'Agent online',
                 value: 'agent_online'
+            }, {
+                label: 'Agent offline',
+                value: 'agent_offline'
             }]
         },
     }))
@@ -141,6 +141,16 @@ export function useConversationFilters () {
         }
     }))
 
+    // SLA conditions are evaluated again on priority change, so unlike new conversation rules they can match tags.
+    const slaConditionFilters = computed(() => ({
+        ...newConversationFilters.value,
+        tags: {
+            label: t('globals.terms.tag', 2),
+            type: FIELD_TYPE.TAG,
+            operators: FIELD_OPERATORS.TAG
+        }
+    }))
+
     const conversationFilters = computed(() => ({
         status: {
             label: t('globals.terms.status'),
@@ -195,6 +205,11 @@ export function useConversationFilters () {
             type: FIELD_TYPE.SELECT,
             operators: FIELD_OPERATORS.SELECT,
             options: iStore.options
+        },
+        tags: {
+            label: t('globals.terms.tag', 2),
+            type: FIELD_TYPE.TAG,
+            operators: FIELD_OPERATORS.TAG
         }
     }))
 
@@ -268,6 +283,12 @@ export function useConversationFilters () {
                 name: t('globals.terms.tag', 2).toLowerCase()
             }),
             type: FIELD_TYPE.TAG
+        },
+        ai_triage: {
+            label: t('globals.messages.apply', {
+                name: t('globals.terms.aiTriage').toLowerCase()
+            }),
+            type: FIELD_TYPE.TRIAGE_TAXONOMY
         }
     }))
 
@@ -325,6 +346,7 @@ export function useConversationFilters () {
         conversationsListFilters,
         conversationFilters,
         newConversationFilters,
+        slaConditionFilters,
         conversationActions,
         macroActions,
         contactCustomAttributes,
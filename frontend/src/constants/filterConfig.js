@@ -6,6 +6,7 @@ export const FIELD_TYPE = {
     RICHTEXT: 'richtext',
     BOOLEAN: 'boolean',
     DATE: 'date',
+    TRIAGE_TAXONOMY: 'triage_taxonomy',
 }
 
 export const OPERATOR = {
@@ -39,4 +40,5 @@ export const FIELD_OPERATORS = {
         OPERATOR.LESS_THAN
     ],
     NUMBER: [OPERATOR.EQUALS, OPERATOR.NOT_EQUALS, OPERATOR.GREATER_THAN, OPERATOR.LESS_THAN],
+    TAG: [OPERATOR.CONTAINS, OPERATOR.NOT_CONTAINS, OPERATOR.SET, OPERATOR.NOT_SET],
 }
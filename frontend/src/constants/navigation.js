@@ -3,6 +3,26 @@ export const reportsNavItems = [
     titleKey: 'globals.terms.overview',
     href: '/reports/overview',
     permission: 'reports:manage'
+  },
+  {
+    titleKey: 'globals.terms.sla',
+    href: '/reports/sla',
+    permission: 'reports:manage'
+  },
+  {
+    titleKey: 'globals.terms.agent',
+    href: '/reports/agents',
+    permission: 'reports:manage'
+  },
+  {
+    titleKey: 'globals.terms.csat',
+    href: '/reports/csat',
+    permission: 'reports:manage'
+  },
+  {
+    titleKey: 'globals.terms.scheduledReport',
+    href: '/reports/schedules',
+    permission: 'reports:manage'
   }
 ]
 
@@ -149,6 +169,10 @@ export const accountNavItems = [
   {
     titleKey: 'globals.terms.profile',
     href: '/account/profile'
+  },
+  {
+    titleKey: 'globals.terms.notification',
+    href: '/account/notifications'
   }
 ]
 
@@ -2,4 +2,6 @@ export const WS_EVENT = {
     NEW_MESSAGE: 'new_message',
     MESSAGE_PROP_UPDATE: 'message_prop_update',
     CONVERSATION_PROP_UPDATE: 'conversation_prop_update',
+    NOTIFICATION: 'notification',
+    NOTIFICATION_READ: 'notification_read',
 }
\ No newline at end of file
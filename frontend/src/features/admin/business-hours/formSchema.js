@@ -6,10 +6,15 @@ export const createFormSchema = (t) => z.object({
     name: z.string().min(1, t('globals.messages.required')),
     description: z.string().min(1, t('globals.messages.required')),
     is_always_open: z.boolean(),
+    holidays_url: z.string().url(t('form.error.validUrl')).optional().or(z.literal('')).nullable(),
     hours: z.record(
         z.object({
-            open: z.string().regex(timeRegex, t('form.error.time.invalid')),
-            close: z.string().regex(timeRegex, t('form.error.time.invalid')),
+            intervals: z.array(
+                z.object({
+                    open: z.string().regex(timeRegex, t('form.error.time.invalid')),
+                    close: z.string().regex(timeRegex, t('form.error.time.invalid')),
+                })
+            ).min(1, t('admin.businessHours.openClose.required'))
         })
     ).optional()
 }).superRefine((data, ctx) => {
@@ -22,10 +27,12 @@ export const createFormSchema = (t) => z.object({
             })
         } else {
             for (const day in data.hours) {
-                if (!data.hours[day].open || !data.hours[day].close) {
+                // An interval that closes before it opens ends on the next day, but it cannot be empty.
+                const invalid = data.hours[day].intervals.some((i) => !i.open || !i.close || i.open === i.close)
+                if (invalid) {
                     ctx.addIssue({
                         code: z.ZodIssueCode.custom,
-                        message: t('globals.messages.required'),
+                        message: t('admin.businessHours.invalidIntervals', { day }),
                         path: ['hours', day]
                     })
                 }
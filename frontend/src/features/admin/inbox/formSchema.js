@@ -6,6 +6,9 @@ export const createFormSchema = (t) => z.object({
   from: z.string().min(1, t('globals.messages.required')),
   enabled: z.boolean().optional(),
   csat_enabled: z.boolean().optional(),
+  business_hours_id: z.number().optional().nullable(),
+  out_of_hours_reply_enabled: z.boolean().optional(),
+  out_of_hours_reply: z.string().optional(),
   imap: z.object({
     host: z.string().min(1, t('globals.messages.required')),
     port: z.number().min(1).max(65535),
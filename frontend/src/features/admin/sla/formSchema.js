@@ -21,6 +21,7 @@ export const createFormSchema = (t) =>
             next_response_time: z.string().nullable().optional().refine(val => !val || isGoHourMinuteDuration(val), {
                 message: t('globals.messages.goHourMinuteDuration'),
             }),
+            pause_statuses: z.array(z.string()).optional().default([]),
             notifications: z
                 .array(
                     z
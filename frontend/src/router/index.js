@@ -57,6 +57,30 @@ const routes = [
             name: 'overview',
             component: () => import('@/views/reports/OverviewView.vue'),
             meta: { title: 'Overview' }
+          },
+          {
+            path: 'sla',
+            name: 'sla-report',
+            component: () => import('@/views/reports/SLAReportView.vue'),
+            meta: { title: 'SLA' }
+          },
+          {
+            path: 'agents',
+            name: 'agent-report',
+            component: () => import('@/views/reports/AgentReportView.vue'),
+            meta: { title: 'Agents' }
+          },
+          {
+            path: 'csat',
+            name: 'csat-report',
+            component: () => import('@/views/reports/CSATReportView.vue'),
+            meta: { title: 'CSAT' }
+          },
+          {
+            path: 'schedules',
+            name: 'scheduled-reports',
+            component: () => import('@/views/reports/ScheduledReportsView.vue'),
+            meta: { title: 'Scheduled reports' }
           }
         ]
       },
@@ -160,6 +184,12 @@ const routes = [
             name: 'profile',
             component: () => import('@/views/account/profile/ProfileEditView.vue'),
             meta: { title: 'Edit Profile' }
+          },
+          {
+            path: 'notifications',
+            name: 'account-notifications',
+            component: () => import('@/views/account/notifications/NotificationPreferencesView.vue'),
+            meta: { title: 'Notifications' }
           }
         ]
       },
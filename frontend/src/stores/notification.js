@@ -0,0 +1,98 @@
+import { ref } from 'vue'
+import { defineStore } from 'pinia'
+import { handleHTTPError } from '@/utils/http'
+import { useEmitter } from '@/composables/useEmitter'
+import { EMITTER_EVENTS } from '@/constants/emitterEvents'
+import api from '@/api'
+
+export const useNotificationStore = defineStore('notification', () => {
+    const notifications = ref([])
+    const unreadCount = ref(0)
+    const total = ref(0)
+    const page = ref(1)
+    const loading = ref(false)
+    const emitter = useEmitter()
+
+    const fetchNotifications = async (nextPage = 1) => {
+        loading.value = true
+        try {
+            const response = await api.getNotifications({ page: nextPage, page_size: 20 })
+            const data = response?.data?.data || {}
+            const results = data.results || []
+            notifications.value = nextPage === 1 ? results : [...notifications.value, ...results]
+            total.value = data.total || 0
+            unreadCount.value = data.unread_count || 0
+            page.value = nextPage
+        } catch (error) {
+            emitter.emit(EMITTER_EVENTS.SHOW_TOAST, {
+                variant: 'destructive',
+                description: handleHTTPError(error).message
+            })
+        } finally {
+            loading.value = false
+        }
+    }
+
+    const hasMore = () => notifications.value.length < total.value
+
+    // Adds a notification pushed over the websocket.
+    const addNotification = (notification) => {
+        if (notifications.value.some(n => n.id === notification.id)) return
+        notifications.value.unshift(notification)
+        total.value++
+        if (!notification.read_at) unreadCount.value++
+    }
+
+    // Marks notifications as read on a read event, either a single notification by ID or all.
+    const setRead = ({ id, all }) => {
+        const now = new Date().toISOString()
+        if (all) {
+            notifications.value.forEach(n => { n.read_at = n.read_at || now })
+            unreadCount.value = 0
+            return
+        }
+        const notification = notifications.value.find(n => n.id === id)
+        if (notification && !notification.read_at) {
+            notification.read_at = now
+            unreadCount.value = Math.max(0, unreadCount.value - 1)
+        }
+    }
+
+    const markRead = async (id) => {
+        try {
+            await api.markNotificationRead(id)
+            setRead({ id })
+        } catch (error) {
+            emitter.emit(EMITTER_EVENTS.SHOW_TOAST, {
+                variant: 'destructive',
+                description: handleHTTPError(error).message
+            })
+        }
+    }
+
+    const markAllRead = async () => {
+        try {
+            await api.markAllNotificationsRead()
+            setRead({ all: true })
+        } catch (error) {
+            emitter.emit(EMITTER_EVENTS.SHOW_TOAST, {
+                variant: 'destructive',
+                description: handleHTTPError(error).message
+            })
+        }
+    }
+
+    return {
+        notifications,
+        unreadCount,
+        total,
+        page,
+        loading,
+        hasMore,
+        fetchNotifications,
+        addNotification,
+        setRead,
+        markRead,
+        markAllRead
+    }
+})
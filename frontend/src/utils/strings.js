@@ -64,4 +64,12 @@ export function getInitials (firstName = '', lastName = '') {
   const firstInitial = firstName.charAt(0).toUpperCase() || ''
   const lastInitial = lastName.charAt(0).toUpperCase() || ''
   return `${firstInitial}${lastInitial}`
-}
\ No newline at end of file
+}
+export function escapeHTML (text = '') {
+  return text
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;')
+    .replace(/'/g, '&#39;')
+}
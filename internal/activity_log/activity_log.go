@@ -117,9 +117,9 @@ func (al *Manager) Away(actorID int, actorEmail, ip string, targetID int, target
 	return al.create(
 		models.AgentAway, /* activity type*/
 		description,
-		actorID,           /*actor_id*/
-		umodels.UserModel, /*target_model_type*/
-		actorID,           /*target_model_id*/
+		actorID,                         /*actor_id*/
+		umodels.UserModel,               /*target_model_type*/
+		targetUserID(actorID, targetID), /*target_model_id*/
 		ip,
 	)
 }
@@ -135,9 +135,9 @@ func (al *Manager) AwayReassigned(actorID int, actorEmail, ip string, targetID i
 	return al.create(
 		models.AgentAwayReassigned, /* activity type*/
 		description,
-		actorID,           /*actor_id*/
-		umodels.UserModel, /*target_model_type*/
-		actorID,           /*target_model_id*/
+		actorID,                         /*actor_id*/
+		umodels.UserModel,               /*target_model_type*/
+		targetUserID(actorID, targetID), /*target_model_id*/
 		ip,
 	)
 }
@@ -153,9 +153,9 @@ func (al *Manager) Online(actorID int, actorEmail, ip string, targetID int, targ
 	return al.create(
 		models.AgentOnline, /* activity type*/
 		description,
-		actorID,           /*actor_id*/
-		umodels.UserModel, /*target_model_type*/
-		actorID,           /*target_model_id*/
+		actorID,                         /*actor_id*/
+		umodels.UserModel,               /*target_model_type*/
+		targetUserID(actorID, targetID), /*target_model_id*/
 		ip,
 	)
 }
@@ -181,6 +181,14 @@ func (al *Manager) UserAvailability(actorID int, actorEmail, status, ip, targetE
 	return nil
 }
 
+// targetUserID returns the user whose status changed, the actor changes their own status if no target is given.
+func targetUserID(actorID, targetID int) int {
+	if targetID != 0 {
+		return targetID
+	}
+	return actorID
+}
+
 // create creates a new activity log in DB.
 func (m *Manager) create(activityType, activityDescription string, actorID int, targetModelType string, targetModelID int, ip string) error {
 	var activityLog models.ActivityLog
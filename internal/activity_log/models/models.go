@@ -10,6 +10,7 @@ const (
 	AgentAway           = "agent_away"
 	AgentAwayReassigned = "agent_away_reassigned"
 	AgentOnline         = "agent_online"
+	AgentOffline        = "agent_offline"
 )
 
 type ActivityLog struct {
@@ -2,14 +2,15 @@
 package ai
 
 import (
-	"database/sql"
+	"context"
 	"embed"
-	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/abhinavxd/libredesk/internal/ai/models"
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
 	"github.com/jmoiron/sqlx"
 	"github.com/knadh/go-i18n"
 	"github.com/zerodha/logf"
@@ -24,9 +25,23 @@ var (
 )
 
 type Manager struct {
-	q    queries
-	lo   *logf.Logger
-	i18n *i18n.I18n
+	q                  queries
+	db                 *sqlx.DB
+	lo                 *logf.Logger
+	i18n               *i18n.I18n
+	summaryTokenBudget int
+	triageTimeout      time.Duration
+	triageCache        *triageCache
+	templateStore      templateStore
+	redactor           *redactor
+	conversationStore  conversationStore
+
+	knowledgeTopK          int
+	knowledgeIndexInterval time.Duration
+	knowledgeMaxAge        time.Duration
+	knowledgeMaxCandidates int
+	streamTimeout          time.Duration
+	requestTimeout         time.Duration
 }
 
 // Opts contains options for initializing the Manager.
@@ -34,14 +49,67 @@ type Opts struct {
 	DB   *sqlx.DB
 	I18n *i18n.I18n
 	Lo   *logf.Logger
+	// SummaryTokenBudget is the maximum number of tokens of conversation context sent for summarization.
+	SummaryTokenBudget int
+	// TriageTimeout is the maximum time to wait for the provider when triaging a conversation.
+	TriageTimeout time.Duration
+	// TriageCacheTTL is how long triage results are cached for identical content and taxonomy.
+	TriageCacheTTL time.Duration
+	// KnowledgeTopK is the number of knowledge base chunks used to ground a reply suggestion.
+	KnowledgeTopK int
+	// KnowledgeIndexInterval is how often resolved conversations and documents are indexed.
+	KnowledgeIndexInterval time.Duration
+	// KnowledgeMaxAge is how long resolved conversations are used for reply suggestions after they were indexed.
+	KnowledgeMaxAge time.Duration
+	// KnowledgeMaxCandidates is the maximum number of chunks compared with the conversation on each suggestion.
+	KnowledgeMaxCandidates int
+	// StreamTimeout is the maximum duration of a streamed completion.
+	StreamTimeout time.Duration
+	// RequestTimeout is the timeout of provider requests that are not streamed, each provider has its own default if 0.
+	RequestTimeout time.Duration
+	// Redaction configures the masking of PII in prompts.
+	Redaction models.RedactionConfig
 }
 
 // queries contains prepared SQL queries.
 type queries struct {
-	GetDefaultProvider *sqlx.Stmt `query:"get-default-provider"`
-	GetPrompt          *sqlx.Stmt `query:"get-prompt"`
-	GetPrompts         *sqlx.Stmt `query:"get-prompts"`
-	SetOpenAIKey       *sqlx.Stmt `query:"set-openai-key"`
+	GetProviders        *sqlx.Stmt `query:"get-providers"`
+	InsertProvider      *sqlx.Stmt `query:"insert-provider"`
+	DeleteProvider      *sqlx.Stmt `query:"delete-provider"`
+	GetPrompt           *sqlx.Stmt `query:"get-prompt"`
+	GetPrompts          *sqlx.Stmt `query:"get-prompts"`
+	InsertPrompt        *sqlx.Stmt `query:"insert-prompt"`
+	UpdatePrompt        *sqlx.Stmt `query:"update-prompt"`
+	DeletePrompt        *sqlx.Stmt `query:"delete-prompt"`
+	InsertPromptVersion *sqlx.Stmt `query:"insert-prompt-version"`
+	GetPromptVersions   *sqlx.Stmt `query:"get-prompt-versions"`
+	GetPromptVersion    *sqlx.Stmt `query:"get-prompt-version"`
+
+	GetKnowledgeDocuments             *sqlx.Stmt `query:"get-knowledge-documents"`
+	GetKnowledgeDocument              *sqlx.Stmt `query:"get-knowledge-document"`
+	InsertKnowledgeDocument           *sqlx.Stmt `query:"insert-knowledge-document"`
+	UpdateKnowledgeDocument           *sqlx.Stmt `query:"update-knowledge-document"`
+	DeleteKnowledgeDocument           *sqlx.Stmt `query:"delete-knowledge-document"`
+	ResetKnowledgeIndex               *sqlx.Stmt `query:"reset-knowledge-index"`
+	GetUnindexedKnowledgeDocuments    *sqlx.Stmt `query:"get-unindexed-knowledge-documents"`
+	GetUnindexedResolvedConversations *sqlx.Stmt `query:"get-unindexed-resolved-conversations"`
+	UpsertConversationKnowledgeSource *sqlx.Stmt `query:"upsert-conversation-knowledge-source"`
+	DeleteKnowledgeChunks             *sqlx.Stmt `query:"delete-knowledge-chunks"`
+	InsertKnowledgeChunk              *sqlx.Stmt `query:"insert-knowledge-chunk"`
+	SetKnowledgeSourceIndexed         *sqlx.Stmt `query:"set-knowledge-source-indexed"`
+	SearchKnowledgeChunks             *sqlx.Stmt `query:"search-knowledge-chunks"`
+
+	InsertRedactionLog   *sqlx.Stmt `query:"insert-redaction-log"`
+	GetRedactionLogs     *sqlx.Stmt `query:"get-redaction-logs"`
+	InsertUsage          *sqlx.Stmt `query:"insert-usage"`
+	GetUsageSummary      *sqlx.Stmt `query:"get-usage-summary"`
+	GetBudgets           *sqlx.Stmt `query:"get-budgets"`
+	InsertBudget         *sqlx.Stmt `query:"insert-budget"`
+	UpdateBudget         *sqlx.Stmt `query:"update-budget"`
+	DeleteBudget         *sqlx.Stmt `query:"delete-budget"`
+	UpdateProviderConfig *sqlx.Stmt `query:"update-provider-config"`
+	UnsetDefaultProvider *sqlx.Stmt `query:"unset-default-provider"`
+	SetDefaultProvider   *sqlx.Stmt `query:"set-default-provider"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -50,113 +118,86 @@ func New(opts Opts) (*Manager, error) {
 	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
 		return nil, err
 	}
-	return &Manager{
-		q:    q,
-		lo:   opts.Lo,
-		i18n: opts.I18n,
-	}, nil
-}
-
-// Completion sends a prompt to the default provider and returns the response.
-func (m *Manager) Completion(k string, prompt string) (string, error) {
-	systemPrompt, err := m.getPrompt(k)
-	if err != nil {
-		return "", err
+	if opts.SummaryTokenBudget <= 0 {
+		opts.SummaryTokenBudget = defaultSummaryTokenBudget
 	}
-
-	client, err := m.getDefaultProviderClient()
-	if err != nil {
-		m.lo.Error("error getting provider client", "error", err)
-		return "", envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	if opts.TriageTimeout <= 0 {
+		opts.TriageTimeout = defaultTriageTimeout
 	}
-
-	payload := PromptPayload{
-		SystemPrompt: systemPrompt,
-		UserPrompt:   prompt,
+	if opts.TriageCacheTTL <= 0 {
+		opts.TriageCacheTTL = defaultTriageCacheTTL
 	}
-
-	response, err := client.SendPrompt(payload)
-	if err != nil {
-		if errors.Is(err, ErrInvalidAPIKey) {
-			m.lo.Error("error invalid API key", "error", err)
-			return "", envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "OpenAI API Key"), nil)
-		}
-		if errors.Is(err, ErrApiKeyNotSet) {
-			m.lo.Error("error API key not set", "error", err)
-			return "", envelope.NewError(envelope.InputError, m.i18n.Ts("ai.apiKeyNotSet", "provider", "OpenAI"), nil)
-		}
-		m.lo.Error("error sending prompt to provider", "error", err)
-		return "", envelope.NewError(envelope.GeneralError, err.Error(), nil)
+	if opts.KnowledgeTopK <= 0 {
+		opts.KnowledgeTopK = defaultKnowledgeTopK
 	}
-
-	return response, nil
-}
-
-// GetPrompts returns a list of prompts from the database.
-func (m *Manager) GetPrompts() ([]models.Prompt, error) {
-	var prompts = make([]models.Prompt, 0)
-	if err := m.q.GetPrompts.Select(&prompts); err != nil {
-		m.lo.Error("error fetching prompts", "error", err)
-		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.template")), nil)
+	if opts.KnowledgeIndexInterval <= 0 {
+		opts.KnowledgeIndexInterval = defaultKnowledgeIndexInterval
 	}
-	return prompts, nil
-}
-
-// UpdateProvider updates a provider.
-func (m *Manager) UpdateProvider(provider, apiKey string) error {
-	switch ProviderType(provider) {
-	case ProviderOpenAI:
-		return m.setOpenAIAPIKey(apiKey)
-	default:
-		m.lo.Error("unsupported provider type", "provider", provider)
-		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.invalid", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	if opts.KnowledgeMaxAge <= 0 {
+		opts.KnowledgeMaxAge = defaultKnowledgeMaxAge
 	}
-}
-
-// setOpenAIAPIKey sets the OpenAI API key in the database.
-func (m *Manager) setOpenAIAPIKey(apiKey string) error {
-	if _, err := m.q.SetOpenAIKey.Exec(apiKey); err != nil {
-		m.lo.Error("error setting OpenAI API key", "error", err)
-		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "OpenAI API Key"), nil)
+	if opts.KnowledgeMaxCandidates <= 0 {
+		opts.KnowledgeMaxCandidates = defaultKnowledgeMaxCandidates
+	}
+	if opts.StreamTimeout <= 0 {
+		opts.StreamTimeout = defaultStreamTimeout
 	}
-	return nil
+	redactor, err := newRedactor(opts.Redaction)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:                  q,
+		db:                 opts.DB,
+		lo:                 opts.Lo,
+		i18n:               opts.I18n,
+		summaryTokenBudget: opts.SummaryTokenBudget,
+		triageTimeout:      opts.TriageTimeout,
+		triageCache: &triageCache{
+			ttl:     opts.TriageCacheTTL,
+			entries: make(map[string]triageCacheEntry),
+		},
+		knowledgeTopK:          opts.KnowledgeTopK,
+		knowledgeIndexInterval: opts.KnowledgeIndexInterval,
+		knowledgeMaxAge:        opts.KnowledgeMaxAge,
+		knowledgeMaxCandidates: opts.KnowledgeMaxCandidates,
+		streamTimeout:          opts.StreamTimeout,
+		requestTimeout:         opts.RequestTimeout,
+		redactor:               redactor,
+	}, nil
 }
 
-// getPrompt returns a prompt from the database.
-func (m *Manager) getPrompt(k string) (string, error) {
-	var p models.Prompt
-	if err := m.q.GetPrompt.Get(&p, k); err != nil {
-		if err == sql.ErrNoRows {
-			m.lo.Error("error prompt not found", "key", k)
-			return "", envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.template")), nil)
-		}
-		m.lo.Error("error fetching prompt", "error", err)
-		return "", envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.template")), nil)
+// Completion renders the prompt with data and sends it to the default provider, falling back to the other configured providers on error, and returns the response.
+// Prompts restricted to roles the user does not have cannot be used.
+func (m *Manager) Completion(k string, prompt string, data any, user umodels.User) (string, error) {
+	payload, err := m.completionPayload(k, prompt, data, user)
+	if err != nil {
+		return "", err
 	}
-	return p.Content, nil
+	return m.sendPrompt(context.Background(), payload)
 }
 
-// getDefaultProviderClient returns a ProviderClient for the default provider.
-func (m *Manager) getDefaultProviderClient() (ProviderClient, error) {
-	var p models.Provider
-
-	if err := m.q.GetDefaultProvider.Get(&p); err != nil {
-		m.lo.Error("error fetching provider details", "error", err)
-		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.provider")), nil)
+// completionPayload returns the provider payload for the prompt with the passed key, rendered with data.
+func (m *Manager) completionPayload(k string, prompt string, data any, user umodels.User) (PromptPayload, error) {
+	p, err := m.getPrompt(0, k)
+	if err != nil {
+		return PromptPayload{}, err
+	}
+	if !p.AllowedFor(user.Roles) {
+		return PromptPayload{}, envelope.NewError(envelope.PermissionError, m.i18n.Ts("globals.messages.denied", "name", m.i18n.Ts("globals.terms.prompt")), nil)
 	}
 
-	switch ProviderType(p.Provider) {
-	case ProviderOpenAI:
-		config := struct {
-			APIKey string `json:"api_key"`
-		}{}
-		if err := json.Unmarshal([]byte(p.Config), &config); err != nil {
-			m.lo.Error("error parsing provider config", "error", err)
-			return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorParsing", "name", m.i18n.Ts("globals.terms.provider")), nil)
-		}
-		return NewOpenAIClient(config.APIKey, m.lo), nil
-	default:
-		m.lo.Error("unsupported provider type", "provider", p.Provider)
-		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.invalid", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	systemPrompt, err := m.renderPrompt(p.Content, data)
+	if err != nil {
+		return PromptPayload{}, err
 	}
+
+	return PromptPayload{
+		SystemPrompt: systemPrompt,
+		UserPrompt:   prompt,
+		Model:        p.Model.String,
+		Temperature:  p.Temperature,
+		PromptKey:    k,
+		UserID:       user.ID,
+	}, nil
 }
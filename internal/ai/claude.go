@@ -0,0 +1,275 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/logf"
+)
+
+const (
+	claudeDefaultBaseURL   = "https://api.anthropic.com"
+	claudeDefaultModel     = "claude-3-5-haiku-latest"
+	claudeDefaultMaxTokens = 1024
+	claudeAPIVersion       = "2023-06-01"
+
+	// claudeDefaultTimeout is the timeout of requests that are not streamed.
+	claudeDefaultTimeout = 30 * time.Second
+)
+
+// ClaudeClient is a ProviderClient for the Anthropic Messages API.
+type ClaudeClient struct {
+	apikey    string
+	model     string
+	maxTokens int
+	baseURL   string
+	headers   map[string]string
+	lo        *logf.Logger
+	client    *http.Client
+	// streamClient has no timeout as streamed responses are bounded by the request context instead.
+	streamClient *http.Client
+}
+
+// NewClaudeClient returns a new ClaudeClient, empty config values and a zero timeout fall back to defaults.
+func NewClaudeClient(config models.ProviderConfig, timeout time.Duration, lo *logf.Logger) *ClaudeClient {
+	if timeout <= 0 {
+		timeout = claudeDefaultTimeout
+	}
+	c := &ClaudeClient{
+		apikey:       config.APIKey,
+		model:        config.Model,
+		maxTokens:    config.MaxTokens,
+		baseURL:      strings.TrimRight(config.BaseURL, "/"),
+		headers:      config.Headers,
+		lo:           lo,
+		client:       &http.Client{Timeout: timeout},
+		streamClient: &http.Client{},
+	}
+	if c.model == "" {
+		c.model = claudeDefaultModel
+	}
+	if c.maxTokens <= 0 {
+		c.maxTokens = claudeDefaultMaxTokens
+	}
+	if c.baseURL == "" {
+		c.baseURL = claudeDefaultBaseURL
+	}
+	return c
+}
+
+// SendPrompt sends a prompt to the Anthropic Messages API and returns the response text along with the token usage.
+func (c *ClaudeClient) SendPrompt(ctx context.Context, payload PromptPayload) (PromptResponse, error) {
+	if c.apikey == "" {
+		return PromptResponse{}, ErrApiKeyNotSet
+	}
+
+	model, temperature := c.model, defaultTemperature
+	if payload.Model != "" {
+		model = payload.Model
+	}
+	if payload.Temperature.Valid {
+		temperature = payload.Temperature.Float64
+	}
+
+	requestBody := map[string]any{
+		"model":      model,
+		"max_tokens": c.maxTokens,
+		"system":     payload.SystemPrompt,
+		"messages": []map[string]string{
+			{"role": "user", "content": payload.UserPrompt},
+		},
+		"temperature": temperature,
+	}
+
+	bodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		c.lo.Error("error marshalling request body", "error", err)
+		return PromptResponse{}, fmt.Errorf("marshalling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, fasthttp.MethodPost, c.baseURL+"/v1/messages", bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		c.lo.Error("error creating request", "error", err)
+		return PromptResponse{}, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("x-api-key", c.apikey)
+	req.Header.Set("anthropic-version", claudeAPIVersion)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.lo.Error("error making HTTP request", "error", err)
+		return PromptResponse{}, fmt.Errorf("making HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return PromptResponse{}, ErrInvalidAPIKey
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		c.lo.Error("non-ok response received from anthropic API", "status", resp.Status, "code", resp.StatusCode, "response_text", body)
+		return PromptResponse{}, fmt.Errorf("API error: %s, body: %s", resp.Status, body)
+	}
+
+	var responseBody struct {
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&responseBody); err != nil {
+		return PromptResponse{}, fmt.Errorf("decoding response body: %w", err)
+	}
+
+	var out strings.Builder
+	for _, block := range responseBody.Content {
+		if block.Type == "text" {
+			out.WriteString(block.Text)
+		}
+	}
+	if out.Len() > 0 {
+		return PromptResponse{
+			Text:         out.String(),
+			Model:        responseBody.Model,
+			InputTokens:  responseBody.Usage.InputTokens,
+			OutputTokens: responseBody.Usage.OutputTokens,
+		}, nil
+	}
+	return PromptResponse{}, fmt.Errorf("no response found")
+}
+
+// StreamPrompt streams a prompt from the Anthropic Messages API, onDelta is called with each chunk of generated text.
+func (c *ClaudeClient) StreamPrompt(ctx context.Context, payload PromptPayload, onDelta func(string) error) (PromptResponse, error) {
+	if c.apikey == "" {
+		return PromptResponse{}, ErrApiKeyNotSet
+	}
+
+	model, temperature := c.model, defaultTemperature
+	if payload.Model != "" {
+		model = payload.Model
+	}
+	if payload.Temperature.Valid {
+		temperature = payload.Temperature.Float64
+	}
+
+	bodyBytes, err := json.Marshal(map[string]any{
+		"model":      model,
+		"max_tokens": c.maxTokens,
+		"system":     payload.SystemPrompt,
+		"messages": []map[string]string{
+			{"role": "user", "content": payload.UserPrompt},
+		},
+		"temperature": temperature,
+		"stream":      true,
+	})
+	if err != nil {
+		c.lo.Error("error marshalling request body", "error", err)
+		return PromptResponse{}, fmt.Errorf("marshalling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, fasthttp.MethodPost, c.baseURL+"/v1/messages", bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		c.lo.Error("error creating request", "error", err)
+		return PromptResponse{}, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("x-api-key", c.apikey)
+	req.Header.Set("anthropic-version", claudeAPIVersion)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.streamClient.Do(req)
+	if err != nil {
+		return PromptResponse{}, fmt.Errorf("making HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return PromptResponse{}, ErrInvalidAPIKey
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		c.lo.Error("non-ok response received from anthropic API", "status", resp.Status, "code", resp.StatusCode, "response_text", body)
+		return PromptResponse{}, fmt.Errorf("API error: %s, body: %s", resp.Status, body)
+	}
+
+	var (
+		out      PromptResponse
+		text     strings.Builder
+		finished bool
+	)
+	err = readSSE(resp.Body, func(_, data string) error {
+		var ev struct {
+			Type    string `json:"type"`
+			Message struct {
+				Model string `json:"model"`
+				Usage struct {
+					InputTokens int `json:"input_tokens"`
+				} `json:"usage"`
+			} `json:"message"`
+			Delta struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"delta"`
+			Usage struct {
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return fmt.Errorf("decoding stream event: %w", err)
+		}
+
+		switch ev.Type {
+		case "message_start":
+			out.Model, out.InputTokens = ev.Message.Model, ev.Message.Usage.InputTokens
+		case "content_block_delta":
+			if ev.Delta.Type != "text_delta" || ev.Delta.Text == "" {
+				return nil
+			}
+			text.WriteString(ev.Delta.Text)
+			return onDelta(ev.Delta.Text)
+		case "message_delta":
+			out.OutputTokens = ev.Usage.OutputTokens
+		case "message_stop":
+			finished = true
+			return errStreamDone
+		case "error":
+			return fmt.Errorf("API error: %s", ev.Error.Message)
+		}
+		return nil
+	})
+	out.Text = text.String()
+	if err != nil {
+		return out, err
+	}
+	if !finished {
+		return out, fmt.Errorf("stream ended unexpectedly")
+	}
+	return out, nil
+}
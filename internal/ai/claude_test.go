@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/zerodha/logf"
+)
+
+func TestClaudeClientSendPrompt(t *testing.T) {
+	lo := logf.New(logf.Opts{})
+
+	tests := []struct {
+		name        string
+		apiKey      string
+		status      int
+		response    string
+		expected    PromptResponse
+		expectError error
+	}{
+		{
+			name:     "Success",
+			apiKey:   "test-key",
+			status:   http.StatusOK,
+			response: `{"model":"claude-test-20250101","content":[{"type":"text","text":"Hello"},{"type":"text","text":" there"}],"usage":{"input_tokens":12,"output_tokens":3}}`,
+			expected: PromptResponse{Text: "Hello there", Model: "claude-test-20250101", InputTokens: 12, OutputTokens: 3},
+		},
+		{
+			name:        "Invalid API key",
+			apiKey:      "bad-key",
+			status:      http.StatusUnauthorized,
+			response:    `{"type":"error","error":{"type":"authentication_error"}}`,
+			expectError: ErrInvalidAPIKey,
+		},
+		{
+			name:        "API key not set",
+			apiKey:      "",
+			expectError: ErrApiKeyNotSet,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/messages", r.URL.Path)
+				assert.Equal(t, tt.apiKey, r.Header.Get("x-api-key"))
+				assert.Equal(t, claudeAPIVersion, r.Header.Get("anthropic-version"))
+
+				var body struct {
+					Model     string `json:"model"`
+					MaxTokens int    `json:"max_tokens"`
+					System    string `json:"system"`
+				}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "claude-test", body.Model)
+				assert.Equal(t, 64, body.MaxTokens)
+				assert.Equal(t, "system", body.System)
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			client := NewClaudeClient(models.ProviderConfig{
+				APIKey:    tt.apiKey,
+				Model:     "claude-test",
+				MaxTokens: 64,
+				BaseURL:   srv.URL + "/",
+			}, 0, &lo)
+
+			resp, err := client.SendPrompt(context.Background(), PromptPayload{SystemPrompt: "system", UserPrompt: "user"})
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, resp)
+		})
+	}
+}
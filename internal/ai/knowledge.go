@@ -0,0 +1,431 @@
+package ai
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	authzModels "github.com/abhinavxd/libredesk/internal/authz/models"
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultKnowledgeTopK          = 5
+	defaultKnowledgeIndexInterval = 5 * time.Minute
+	defaultKnowledgeMaxAge        = 365 * 24 * time.Hour
+	defaultKnowledgeMaxCandidates = 20000
+
+	// Number of documents and conversations indexed per indexer run.
+	knowledgeIndexBatchSize = 20
+
+	// Chunk size and overlap in characters, overlap must be less than half the chunk size.
+	knowledgeChunkSize    = 1500
+	knowledgeChunkOverlap = 200
+
+	// Maximum number of texts sent in a single embeddings request.
+	knowledgeEmbedBatchSize = 64
+
+	// Conversation messages are fetched in pages when building a transcript for indexing.
+	knowledgeMaxMessagePages    = 10
+	knowledgeMessagePageSize    = 100
+	knowledgeTranscriptTokenLen = 20000
+
+	// Approximate number of tokens of the latest messages used to search the knowledge base.
+	suggestionQueryTokenBudget = 500
+
+	suggestReplyPromptKey = "suggest_reply"
+)
+
+var ErrEmbeddingsNotSupported = errors.New("no provider with embeddings support configured")
+
+// conversationStore fetches conversation messages for indexing resolved conversations.
+type conversationStore interface {
+	GetConversationMessages(conversationUUID string, page, pageSize int) ([]cmodels.Message, int, error)
+}
+
+// conversationAccess is the conversations a user can read, as in the conversation lists.
+type conversationAccess struct {
+	All        bool
+	Assigned   bool
+	TeamInbox  bool
+	Unassigned bool
+}
+
+// newConversationAccess returns the conversations the user can read based on their permissions.
+func newConversationAccess(user umodels.User) conversationAccess {
+	return conversationAccess{
+		All:        slices.Contains(user.Permissions, authzModels.PermConversationsReadAll),
+		Assigned:   slices.Contains(user.Permissions, authzModels.PermConversationsReadAssigned),
+		TeamInbox:  slices.Contains(user.Permissions, authzModels.PermConversationsReadTeamInbox),
+		Unassigned: slices.Contains(user.Permissions, authzModels.PermConversationsReadUnassigned),
+	}
+}
+
+// resolvedConversation is a resolved conversation pending indexing.
+type resolvedConversation struct {
+	ID              int    `db:"id"`
+	UUID            string `db:"uuid"`
+	ReferenceNumber string `db:"reference_number"`
+	Subject         string `db:"subject"`
+}
+
+// SetConversationStore sets the conversation store used to index resolved conversations.
+func (m *Manager) SetConversationStore(store conversationStore) {
+	m.conversationStore = store
+}
+
+// GetKnowledgeDocuments returns all knowledge base documents without their content.
+func (m *Manager) GetKnowledgeDocuments() ([]models.KnowledgeSource, error) {
+	var docs = make([]models.KnowledgeSource, 0)
+	if err := m.q.GetKnowledgeDocuments.Select(&docs); err != nil {
+		m.lo.Error("error fetching knowledge documents", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.document")), nil)
+	}
+	return docs, nil
+}
+
+// GetKnowledgeDocument returns a knowledge base document.
+func (m *Manager) GetKnowledgeDocument(id int) (models.KnowledgeSource, error) {
+	var doc models.KnowledgeSource
+	if err := m.q.GetKnowledgeDocument.Get(&doc, id); err != nil {
+		if err == sql.ErrNoRows {
+			return doc, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.document")), nil)
+		}
+		m.lo.Error("error fetching knowledge document", "id", id, "error", err)
+		return doc, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.document")), nil)
+	}
+	return doc, nil
+}
+
+// CreateKnowledgeDocument adds a document to the knowledge base, it is embedded on the next indexer run.
+func (m *Manager) CreateKnowledgeDocument(title, content string) (models.KnowledgeSource, error) {
+	var doc models.KnowledgeSource
+	if err := m.q.InsertKnowledgeDocument.Get(&doc, title, content); err != nil {
+		m.lo.Error("error inserting knowledge document", "error", err)
+		return doc, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", m.i18n.Ts("globals.terms.document")), nil)
+	}
+	return doc, nil
+}
+
+// UpdateKnowledgeDocument updates a knowledge base document, it is embedded again on the next indexer run.
+func (m *Manager) UpdateKnowledgeDocument(id int, title, content string) (models.KnowledgeSource, error) {
+	var doc models.KnowledgeSource
+	if err := m.q.UpdateKnowledgeDocument.Get(&doc, id, title, content); err != nil {
+		if err == sql.ErrNoRows {
+			return doc, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.document")), nil)
+		}
+		m.lo.Error("error updating knowledge document", "id", id, "error", err)
+		return doc, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.document")), nil)
+	}
+	return doc, nil
+}
+
+// DeleteKnowledgeDocument deletes a knowledge base document and its chunks.
+func (m *Manager) DeleteKnowledgeDocument(id int) error {
+	res, err := m.q.DeleteKnowledgeDocument.Exec(id)
+	if err != nil {
+		m.lo.Error("error deleting knowledge document", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorDeleting", "name", m.i18n.Ts("globals.terms.document")), nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.document")), nil)
+	}
+	return nil
+}
+
+// ReindexKnowledge marks all knowledge sources for indexing, e.g. after the embedding model was changed.
+func (m *Manager) ReindexKnowledge() error {
+	if _, err := m.q.ResetKnowledgeIndex.Exec(); err != nil {
+		m.lo.Error("error resetting knowledge index", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.document")), nil)
+	}
+	return nil
+}
+
+// RunKnowledgeIndexer periodically chunks and embeds resolved conversations and knowledge base documents.
+func (m *Manager) RunKnowledgeIndexer(ctx context.Context) {
+	ticker := time.NewTicker(m.knowledgeIndexInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.indexKnowledge(ctx); err != nil {
+				m.lo.Error("error indexing knowledge base", "error", err)
+			}
+		}
+	}
+}
+
+// SuggestReply drafts a reply for the conversation grounded on the most similar knowledge base chunks.
+// Messages are expected newest first, as returned by `GetConversationMessages`, private notes are not used.
+// Only resolved conversations the user can access are used.
+func (m *Manager) SuggestReply(conversationID int, messages []cmodels.Message, data any, user umodels.User) (models.ReplySuggestion, error) {
+	messages = publicMessages(messages)
+	transcript, ok := BuildConversationContext(messages, m.summaryTokenBudget)
+	if !ok {
+		return models.ReplySuggestion{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.empty", "name", m.i18n.Ts("globals.terms.message")), nil)
+	}
+	query, _ := BuildConversationContext(messages, suggestionQueryTokenBudget)
+	if query == "" {
+		query = transcript
+	}
+
+	client, provider, err := m.embeddingClient()
+	if err != nil {
+		if errors.Is(err, ErrEmbeddingsNotSupported) {
+			return models.ReplySuggestion{}, envelope.NewError(envelope.InputError, m.i18n.T("ai.embeddingsNotSupported"), nil)
+		}
+		return models.ReplySuggestion{}, err
+	}
+	vectors, err := client.Embed(m.redactTexts([]string{query}))
+	if err != nil {
+		m.lo.Error("error embedding suggestion query", "provider", provider.Name, "error", err)
+		return models.ReplySuggestion{}, m.providerError(ProviderType(provider.Provider), err)
+	}
+
+	var (
+		matches = make([]models.KnowledgeMatch, 0)
+		access  = newConversationAccess(user)
+	)
+	if err := m.q.SearchKnowledgeChunks.Select(&matches, pq.Float64Array(normalize(vectors[0])), client.EmbeddingModel(), conversationID, m.knowledgeTopK,
+		m.knowledgeMaxAge.Seconds(), access.All, access.Assigned, user.ID, access.TeamInbox, pq.Array(user.Teams.IDs()), access.Unassigned,
+		m.knowledgeMaxCandidates); err != nil {
+		m.lo.Error("error searching knowledge chunks", "error", err)
+		return models.ReplySuggestion{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.document")), nil)
+	}
+
+	draft, err := m.Completion(suggestReplyPromptKey, buildSuggestionPrompt(matches, transcript), data, user)
+	if err != nil {
+		return models.ReplySuggestion{}, err
+	}
+	return models.ReplySuggestion{
+		Draft:   strings.TrimSpace(draft),
+		Sources: matches,
+	}, nil
+}
+
+// indexKnowledge indexes a batch of pending documents and resolved conversations.
+func (m *Manager) indexKnowledge(ctx context.Context) error {
+	client, _, err := m.embeddingClient()
+	if err != nil {
+		if errors.Is(err, ErrEmbeddingsNotSupported) {
+			m.lo.Debug("skipping knowledge indexing, no provider with embeddings support")
+			return nil
+		}
+		return err
+	}
+
+	var docs = make([]models.KnowledgeSource, 0)
+	if err := m.q.GetUnindexedKnowledgeDocuments.Select(&docs, knowledgeIndexBatchSize); err != nil {
+		return fmt.Errorf("fetching unindexed knowledge documents: %w", err)
+	}
+	for _, doc := range docs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := m.indexSource(client, doc.ID, doc.Content); err != nil {
+			m.lo.Error("error indexing knowledge document", "id", doc.ID, "error", err)
+		}
+	}
+
+	if m.conversationStore == nil {
+		return nil
+	}
+	var conversations = make([]resolvedConversation, 0)
+	if err := m.q.GetUnindexedResolvedConversations.Select(&conversations, knowledgeIndexBatchSize); err != nil {
+		return fmt.Errorf("fetching unindexed resolved conversations: %w", err)
+	}
+	for _, c := range conversations {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := m.indexConversation(client, c); err != nil {
+			m.lo.Error("error indexing resolved conversation", "uuid", c.UUID, "error", err)
+		}
+	}
+	return nil
+}
+
+// indexConversation stores the public transcript of a resolved conversation as a knowledge source and indexes it.
+func (m *Manager) indexConversation(client EmbeddingClient, c resolvedConversation) error {
+	var messages = make([]cmodels.Message, 0)
+	for page := 1; page <= knowledgeMaxMessagePages; page++ {
+		msgs, pageSize, err := m.conversationStore.GetConversationMessages(c.UUID, page, knowledgeMessagePageSize)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, msgs...)
+		if len(msgs) < pageSize {
+			break
+		}
+	}
+	transcript, _ := BuildConversationContext(publicMessages(messages), knowledgeTranscriptTokenLen)
+
+	title := "#" + c.ReferenceNumber
+	if c.Subject != "" {
+		title += " " + c.Subject
+	}
+	if utf8.RuneCountInString(title) > 255 {
+		title = string([]rune(title)[:255])
+	}
+
+	var sourceID int
+	if err := m.q.UpsertConversationKnowledgeSource.Get(&sourceID, c.ID, title, transcript); err != nil {
+		return fmt.Errorf("upserting knowledge source: %w", err)
+	}
+	return m.indexSource(client, sourceID, transcript)
+}
+
+// indexSource chunks and embeds the content and replaces the chunks of the knowledge source.
+func (m *Manager) indexSource(client EmbeddingClient, sourceID int, content string) error {
+	var (
+		chunks  = chunkText(content, knowledgeChunkSize, knowledgeChunkOverlap)
+		vectors = make([][]float64, 0, len(chunks))
+	)
+	for i := 0; i < len(chunks); i += knowledgeEmbedBatchSize {
+		end := min(i+knowledgeEmbedBatchSize, len(chunks))
+		v, err := client.Embed(m.redactTexts(chunks[i:end]))
+		if err != nil {
+			return fmt.Errorf("embedding chunks: %w", err)
+		}
+		vectors = append(vectors, v...)
+	}
+
+	tx, err := m.db.BeginTxx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Stmtx(m.q.DeleteKnowledgeChunks).Exec(sourceID); err != nil {
+		return fmt.Errorf("deleting knowledge chunks: %w", err)
+	}
+	for i, chunk := range chunks {
+		if _, err := tx.Stmtx(m.q.InsertKnowledgeChunk).Exec(sourceID, i, chunk, pq.Float64Array(normalize(vectors[i])), client.EmbeddingModel()); err != nil {
+			return fmt.Errorf("inserting knowledge chunk: %w", err)
+		}
+	}
+	if _, err := tx.Stmtx(m.q.SetKnowledgeSourceIndexed).Exec(sourceID); err != nil {
+		return fmt.Errorf("marking knowledge source indexed: %w", err)
+	}
+	return tx.Commit()
+}
+
+// embeddingClient returns the client of the first provider, default provider first, that can be authenticated with and supports embeddings.
+func (m *Manager) embeddingClient() (EmbeddingClient, models.Provider, error) {
+	providers, err := m.getProviders()
+	if err != nil {
+		return nil, models.Provider{}, err
+	}
+	for _, p := range providers {
+		if config, err := m.parseProviderConfig(p); err != nil || !hasCredentials(p, config) {
+			continue
+		}
+		client, err := m.newProviderClient(p)
+		if err != nil {
+			continue
+		}
+		if ec, ok := client.(EmbeddingClient); ok {
+			return ec, p, nil
+		}
+	}
+	return nil, models.Provider{}, ErrEmbeddingsNotSupported
+}
+
+// buildSuggestionPrompt formats the knowledge matches as numbered sources followed by the conversation transcript.
+func buildSuggestionPrompt(matches []models.KnowledgeMatch, transcript string) string {
+	var b strings.Builder
+	if len(matches) > 0 {
+		b.WriteString("Knowledge sources:\n\n")
+		for i, match := range matches {
+			kind := "Document"
+			if match.SourceType == "conversation" {
+				kind = "Resolved conversation"
+			}
+			fmt.Fprintf(&b, "[%d] %s: %s\n%s\n\n", i+1, kind, match.Title, strings.TrimSpace(match.Content))
+		}
+	}
+	b.WriteString("Conversation:\n\n")
+	b.WriteString(transcript)
+	return b.String()
+}
+
+// publicMessages returns the messages without private notes.
+func publicMessages(messages []cmodels.Message) []cmodels.Message {
+	var out = make([]cmodels.Message, 0, len(messages))
+	for _, msg := range messages {
+		if !msg.Private {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// chunkText splits text into chunks of at most size characters that overlap by overlap characters.
+// Chunks are broken at paragraph, line, sentence or word boundaries where possible.
+func chunkText(text string, size, overlap int) []string {
+	var (
+		runes  = []rune(strings.TrimSpace(text))
+		chunks []string
+	)
+	for start := 0; start < len(runes); {
+		end := start + size
+		if end >= len(runes) {
+			chunks = append(chunks, strings.TrimSpace(string(runes[start:])))
+			break
+		}
+		if i := lastBreak(runes[start:end]); i > size/2 {
+			end = start + i
+		}
+		if chunk := strings.TrimSpace(string(runes[start:end])); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		start = end - overlap
+	}
+	return chunks
+}
+
+// lastBreak returns the rune offset just after the last paragraph, line, sentence or word break in the second half of r, or -1.
+func lastBreak(r []rune) int {
+	s := string(r)
+	for _, sep := range []string{"\n\n", "\n", ". ", " "} {
+		i := strings.LastIndex(s, sep)
+		if i < 0 {
+			continue
+		}
+		if n := utf8.RuneCountInString(s[:i+len(sep)]); n > len(r)/2 {
+			return n
+		}
+	}
+	return -1
+}
+
+// normalize scales the vector to unit length so the dot product of two vectors is their cosine similarity.
+func normalize(v []float64) []float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := math.Sqrt(sum)
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/knadh/go-i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodha/logf"
+)
+
+func TestChunkText(t *testing.T) {
+	t.Run("Empty text", func(t *testing.T) {
+		assert.Empty(t, chunkText("  \n ", 100, 10))
+	})
+
+	t.Run("Short text is a single chunk", func(t *testing.T) {
+		assert.Equal(t, []string{"Hello world"}, chunkText(" Hello world ", 100, 10))
+	})
+
+	t.Run("Breaks at paragraphs", func(t *testing.T) {
+		text := strings.Repeat("a", 60) + "\n\n" + strings.Repeat("b", 60)
+		chunks := chunkText(text, 100, 10)
+		assert.Len(t, chunks, 2)
+		assert.Equal(t, strings.Repeat("a", 60), chunks[0])
+		assert.True(t, strings.HasSuffix(chunks[1], strings.Repeat("b", 60)))
+	})
+
+	t.Run("Long text without breaks", func(t *testing.T) {
+		text := strings.Repeat("é", 250)
+		chunks := chunkText(text, 100, 20)
+		assert.Len(t, chunks, 3)
+		for _, c := range chunks {
+			assert.LessOrEqual(t, utf8.RuneCountInString(c), 100)
+		}
+		// Consecutive chunks overlap.
+		assert.Equal(t, 100+100+90, utf8.RuneCountInString(strings.Join(chunks, "")))
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, []float64{0.6, 0.8}, normalize([]float64{3, 4}))
+	assert.Equal(t, []float64{0, 0}, normalize([]float64{0, 0}))
+
+	var sum float64
+	for _, x := range normalize([]float64{1, 2, 3, 4}) {
+		sum += x * x
+	}
+	assert.InDelta(t, 1, math.Sqrt(sum), 1e-9)
+}
+
+func TestBuildSuggestionPrompt(t *testing.T) {
+	prompt := buildSuggestionPrompt([]models.KnowledgeMatch{
+		{SourceType: "conversation", Title: "#12 Refund", Content: "Refunds take 5 days."},
+		{SourceType: "document", Title: "Billing FAQ", Content: " Invoices are sent monthly. "},
+	}, "Customer: Where is my refund?")
+
+	assert.Equal(t, "Knowledge sources:\n\n"+
+		"[1] Resolved conversation: #12 Refund\nRefunds take 5 days.\n\n"+
+		"[2] Document: Billing FAQ\nInvoices are sent monthly.\n\n"+
+		"Conversation:\n\nCustomer: Where is my refund?", prompt)
+
+	assert.Equal(t, "Conversation:\n\nhi", buildSuggestionPrompt(nil, "hi"))
+}
+
+func TestNewConversationAccess(t *testing.T) {
+	access := newConversationAccess(umodels.User{Permissions: []string{"conversations:read", "conversations:read_assigned", "conversations:read_team_inbox"}})
+	assert.Equal(t, conversationAccess{Assigned: true, TeamInbox: true}, access)
+
+	access = newConversationAccess(umodels.User{Permissions: []string{"conversations:read_all", "conversations:read_unassigned"}})
+	assert.Equal(t, conversationAccess{All: true, Unassigned: true}, access)
+
+	// Users without conversation permissions get no resolved conversations.
+	assert.Equal(t, conversationAccess{}, newConversationAccess(umodels.User{Permissions: []string{"messages:write"}}))
+}
+
+func TestSuggestReplySearchBound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"data": [{"index": 0, "embedding": [3, 4]}]}`))
+	}))
+	defer srv.Close()
+
+	errSearch := errors.New("search failed")
+	config := `{"api_key": "key", "base_url": "` + srv.URL + `"}`
+	db := dbtest.New(func(query string, args []driver.Value) (dbtest.Result, error) {
+		if strings.Contains(query, "FROM ai_providers") {
+			return dbtest.Result{
+				Columns: []string{"id", "name", "provider", "config", "is_default"},
+				Rows:    [][]any{{1, "openai", "openai", config, true}},
+			}, nil
+		}
+		// The search fails so that no completion is requested.
+		return dbtest.Result{}, errSearch
+	})
+	var q queries
+	require.NoError(t, dbutil.ScanSQLFile("queries.sql", &q, db.DB, efs))
+	i18n, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	require.NoError(t, err)
+	lo := logf.New(logf.Opts{})
+	m := &Manager{q: q, db: db.DB, lo: &lo, i18n: i18n, summaryTokenBudget: 1000, knowledgeTopK: 5, knowledgeMaxCandidates: 300}
+
+	messages := []cmodels.Message{{Type: cmodels.MessageIncoming, SenderType: cmodels.SenderTypeContact, TextContent: "I was charged twice"}}
+	_, err = m.SuggestReply(7, messages, nil, umodels.User{ID: 2})
+	assert.Error(t, err)
+
+	searches := db.Queries("WITH candidates AS")
+	require.Len(t, searches, 1)
+	args := searches[0].Args
+	require.Len(t, args, 12)
+	assert.Equal(t, int64(7), args[2])
+	assert.Equal(t, int64(5), args[3])
+	// Only the most recent candidates are compared with the conversation.
+	assert.Equal(t, int64(300), args[11])
+}
@@ -1,9 +1,16 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+)
 
 type Provider struct {
-	ID        string    `db:"id"`
+	ID        int       `db:"id"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 	Name      string    `db:"name"`
@@ -12,6 +19,22 @@ type Provider struct {
 	IsDefault bool      `db:"is_default"`
 }
 
+// ProviderInfo is the provider detail exposed to the settings UI, the API key is never returned.
+type ProviderInfo struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Provider  string `json:"provider"`
+	IsDefault bool   `json:"is_default"`
+	Model     string `json:"model"`
+	MaxTokens int    `json:"max_tokens"`
+	BaseURL   string `json:"base_url"`
+	APIKeySet bool   `json:"api_key_set"`
+	// EmbeddingModel is the model used to embed knowledge base content, empty for providers without embeddings support.
+	EmbeddingModel string `json:"embedding_model"`
+	// Headers contains the configured header names, values are masked.
+	Headers map[string]string `json:"headers"`
+}
+
 type Prompt struct {
 	ID        int       `db:"id" json:"id"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
@@ -19,4 +42,189 @@ type Prompt struct {
 	Title     string    `db:"title" json:"title"`
 	Key       string    `db:"key" json:"key"`
 	Content   string    `db:"content" json:"content,omitempty"`
+	// Model and Temperature override the provider defaults when set.
+	Model        null.String    `db:"model" json:"model"`
+	Temperature  null.Float64   `db:"temperature" json:"temperature"`
+	AllowedRoles pq.StringArray `db:"allowed_roles" json:"allowed_roles"`
+	Version      int            `db:"version" json:"version"`
+}
+
+// AllowedFor returns true if a user with the passed roles may use the prompt, prompts without roles are allowed for everyone.
+func (p Prompt) AllowedFor(roles []string) bool {
+	if len(p.AllowedRoles) == 0 {
+		return true
+	}
+	for _, r := range roles {
+		if slices.Contains(p.AllowedRoles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// PromptVersion is a snapshot of a prompt, one is recorded every time a prompt is created or updated.
+type PromptVersion struct {
+	ID           int            `db:"id" json:"id"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
+	PromptID     int            `db:"prompt_id" json:"prompt_id"`
+	Version      int            `db:"version" json:"version"`
+	Title        string         `db:"title" json:"title"`
+	Content      string         `db:"content" json:"content"`
+	Model        null.String    `db:"model" json:"model"`
+	Temperature  null.Float64   `db:"temperature" json:"temperature"`
+	AllowedRoles pq.StringArray `db:"allowed_roles" json:"allowed_roles"`
+	CreatedBy    null.Int       `db:"created_by" json:"created_by"`
+}
+
+// ProviderConfig is the config stored as JSON for each provider.
+type ProviderConfig struct {
+	APIKey    string `json:"api_key"`
+	Model     string `json:"model,omitempty"`
+	MaxTokens int    `json:"max_tokens,omitempty"`
+	BaseURL   string `json:"base_url,omitempty"`
+	// Headers are additional HTTP headers sent with every request, e.g. for proxies or OpenAI compatible gateways.
+	Headers map[string]string `json:"headers,omitempty"`
+	// EmbeddingModel is the model used for knowledge base embeddings, only supported by OpenAI compatible providers.
+	EmbeddingModel string `json:"embedding_model,omitempty"`
+}
+
+// TriageTaxonomy is the admin-defined taxonomy conversations are classified into by the AI triage automation action.
+type TriageTaxonomy struct {
+	Categories []TriageLabel `json:"categories"`
+	Urgencies  []TriageLabel `json:"urgencies"`
+	Sentiments []TriageLabel `json:"sentiments"`
+	// Attributes holds the conversation custom attribute keys the chosen labels are stored in, empty keys are skipped.
+	Attributes TriageAttributes `json:"attributes"`
+}
+
+// TriageAttributes maps each triage dimension to a conversation custom attribute key.
+type TriageAttributes struct {
+	Category  string `json:"category"`
+	Urgency   string `json:"urgency"`
+	Sentiment string `json:"sentiment"`
+}
+
+// TriageLabel is a label in the triage taxonomy along with the changes applied to the conversation when it is chosen.
+type TriageLabel struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	PriorityID  int      `json:"priority_id"`
+	TeamID      int      `json:"team_id"`
+}
+
+// TriageResult is the classification returned by the AI triage, unmatched dimensions are empty.
+type TriageResult struct {
+	Category  string `json:"category"`
+	Urgency   string `json:"urgency"`
+	Sentiment string `json:"sentiment"`
+}
+
+// Labels returns the taxonomy labels chosen in the result.
+func (t TriageTaxonomy) Labels(r TriageResult) []TriageLabel {
+	var out []TriageLabel
+	for _, l := range t.Categories {
+		if r.Category != "" && l.Name == r.Category {
+			out = append(out, l)
+		}
+	}
+	for _, l := range t.Urgencies {
+		if r.Urgency != "" && l.Name == r.Urgency {
+			out = append(out, l)
+		}
+	}
+	for _, l := range t.Sentiments {
+		if r.Sentiment != "" && l.Name == r.Sentiment {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// KnowledgeSource is a resolved conversation transcript or an uploaded document used to ground reply suggestions.
+type KnowledgeSource struct {
+	ID             int       `db:"id" json:"id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+	Type           string    `db:"type" json:"type"`
+	Title          string    `db:"title" json:"title"`
+	Content        string    `db:"content" json:"content,omitempty"`
+	ConversationID null.Int  `db:"conversation_id" json:"conversation_id"`
+	IndexedAt      null.Time `db:"indexed_at" json:"indexed_at"`
+	Chunks         int       `db:"chunks" json:"chunks"`
+}
+
+// KnowledgeMatch is a knowledge base chunk retrieved for a reply suggestion.
+type KnowledgeMatch struct {
+	ChunkID          int64       `db:"id" json:"chunk_id"`
+	SourceID         int         `db:"source_id" json:"source_id"`
+	SourceType       string      `db:"type" json:"source_type"`
+	Title            string      `db:"title" json:"title"`
+	ConversationUUID null.String `db:"conversation_uuid" json:"conversation_uuid"`
+	Content          string      `db:"content" json:"content"`
+	Score            float64     `db:"score" json:"score"`
+}
+
+// ReplySuggestion is a drafted reply along with the knowledge sources it was grounded on.
+type ReplySuggestion struct {
+	Draft   string           `json:"draft"`
+	Sources []KnowledgeMatch `json:"sources"`
+}
+
+// RedactionConfig configures the masking of PII in prompts before they are sent to AI providers.
+type RedactionConfig struct {
+	Enabled bool `json:"enabled"`
+	// Detectors are the built-in detectors to use: card, email and phone.
+	Detectors []string `json:"detectors"`
+	// Patterns are additional named regular expressions to redact.
+	Patterns []RedactionPattern `json:"patterns"`
+	// Restore replaces the placeholders in the provider response with the original values.
+	Restore bool `json:"restore"`
+}
+
+// RedactionPattern is a custom regular expression redacted from prompts.
+type RedactionPattern struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+// RedactionLog is the audit record of a prompt that had PII redacted, the redacted values themselves are never stored.
+type RedactionLog struct {
+	ID        int64     `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UserID    null.Int  `db:"user_id" json:"user_id"`
+	PromptKey string    `db:"prompt_key" json:"prompt_key"`
+	// Redactions maps each detector to the number of values it redacted.
+	Redactions json.RawMessage `db:"redactions" json:"redactions"`
+	Total      int             `db:"total" json:"-"`
+}
+
+// UsageSummary is the AI usage aggregated by a report dimension such as user, prompt key, provider, model or day.
+type UsageSummary struct {
+	Key            string `db:"key" json:"key"`
+	Label          string `db:"label" json:"label"`
+	Requests       int    `db:"requests" json:"requests"`
+	FailedRequests int    `db:"failed_requests" json:"failed_requests"`
+	InputTokens    int64  `db:"input_tokens" json:"input_tokens"`
+	OutputTokens   int64  `db:"output_tokens" json:"output_tokens"`
+	TotalTokens    int64  `db:"total_tokens" json:"total_tokens"`
+	AvgLatencyMS   int    `db:"avg_latency_ms" json:"avg_latency_ms"`
+}
+
+// Budget is the monthly token limit shared by the members of a team or role.
+type Budget struct {
+	ID                int       `db:"id" json:"id"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	TeamID            null.Int  `db:"team_id" json:"team_id"`
+	RoleID            null.Int  `db:"role_id" json:"role_id"`
+	ScopeName         string    `db:"scope_name" json:"scope_name"`
+	MonthlyTokenLimit int64     `db:"monthly_token_limit" json:"monthly_token_limit"`
+	// UsedTokens is the number of tokens used by the members of the team or role in the current calendar month.
+	UsedTokens int64 `db:"used_tokens" json:"used_tokens"`
+}
+
+// Exceeded returns true if the budget is used up for the current month.
+func (b Budget) Exceeded() bool {
+	return b.UsedTokens >= b.MonthlyTokenLimit
 }
@@ -2,92 +2,342 @@ package ai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/abhinavxd/libredesk/internal/ai/models"
 	"github.com/valyala/fasthttp"
 	"github.com/zerodha/logf"
 )
 
+const (
+	openAIDefaultBaseURL   = "https://api.openai.com/v1"
+	openAIDefaultModel     = "gpt-4o-mini"
+	openAIDefaultMaxTokens = 1024
+
+	openAIDefaultEmbeddingModel = "text-embedding-3-small"
+
+	// openAIDefaultTimeout is the timeout of requests that are not streamed.
+	openAIDefaultTimeout = 10 * time.Second
+)
+
+// OpenAIClient is a ProviderClient for the OpenAI chat completions API and OpenAI compatible APIs.
 type OpenAIClient struct {
-	apikey string
-	lo     *logf.Logger
-	client *http.Client
+	apikey         string
+	model          string
+	embeddingModel string
+	maxTokens      int
+	baseURL        string
+	headers        map[string]string
+	lo             *logf.Logger
+	client         *http.Client
+	// streamClient has no timeout as streamed responses are bounded by the request context instead.
+	streamClient *http.Client
 }
 
-func NewOpenAIClient(apiKey string, lo *logf.Logger) *OpenAIClient {
-	return &OpenAIClient{
-		apikey: apiKey,
-		lo:     lo,
-		client: &http.Client{Timeout: 10 * time.Second},
+// NewOpenAIClient returns a new OpenAIClient, empty config values and a zero timeout fall back to defaults.
+func NewOpenAIClient(config models.ProviderConfig, timeout time.Duration, lo *logf.Logger) *OpenAIClient {
+	if timeout <= 0 {
+		timeout = openAIDefaultTimeout
+	}
+	o := &OpenAIClient{
+		apikey:         config.APIKey,
+		model:          config.Model,
+		embeddingModel: config.EmbeddingModel,
+		maxTokens:      config.MaxTokens,
+		baseURL:        strings.TrimRight(config.BaseURL, "/"),
+		headers:        config.Headers,
+		lo:             lo,
+		client:         &http.Client{Timeout: timeout},
+		streamClient:   &http.Client{},
+	}
+	if o.model == "" {
+		o.model = openAIDefaultModel
 	}
+	if o.maxTokens <= 0 {
+		o.maxTokens = openAIDefaultMaxTokens
+	}
+	if o.baseURL == "" {
+		o.baseURL = openAIDefaultBaseURL
+	}
+	if o.embeddingModel == "" {
+		o.embeddingModel = openAIDefaultEmbeddingModel
+	}
+	return o
 }
 
-// SendPrompt sends a prompt to the OpenAI API and returns the response text.
-func (o *OpenAIClient) SendPrompt(payload PromptPayload) (string, error) {
-	if o.apikey == "" {
-		return "", ErrApiKeyNotSet
+// SendPrompt sends a prompt to the OpenAI API and returns the response text along with the token usage.
+func (o *OpenAIClient) SendPrompt(ctx context.Context, payload PromptPayload) (PromptResponse, error) {
+	if o.apikey == "" && o.baseURL == openAIDefaultBaseURL {
+		return PromptResponse{}, ErrApiKeyNotSet
+	}
+
+	model, temperature := o.model, defaultTemperature
+	if payload.Model != "" {
+		model = payload.Model
+	}
+	if payload.Temperature.Valid {
+		temperature = payload.Temperature.Float64
 	}
 
-	apiURL := "https://api.openai.com/v1/chat/completions"
+	apiURL := o.baseURL + "/chat/completions"
 	requestBody := map[string]interface{}{
-		"model": "gpt-4o-mini",
+		"model": model,
 		"messages": []map[string]string{
 			{"role": "system", "content": payload.SystemPrompt},
 			{"role": "user", "content": payload.UserPrompt},
 		},
-		"max_tokens":  1024,
-		"temperature": 0.7,
+		"max_tokens":  o.maxTokens,
+		"temperature": temperature,
 	}
 
 	bodyBytes, err := json.Marshal(requestBody)
 	if err != nil {
 		o.lo.Error("error marshalling request body", "error", err)
-		return "", fmt.Errorf("marshalling request body: %w", err)
+		return PromptResponse{}, fmt.Errorf("marshalling request body: %w", err)
 	}
 
-	req, err := http.NewRequest(fasthttp.MethodPost, apiURL, bytes.NewBuffer(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, fasthttp.MethodPost, apiURL, bytes.NewBuffer(bodyBytes))
 	if err != nil {
 		o.lo.Error("error creating request", "error", err)
-		return "", fmt.Errorf("error creating request: %w", err)
+		return PromptResponse{}, fmt.Errorf("error creating request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+o.apikey)
-	req.Header.Set("Content-Type", "application/json")
+	o.setHeaders(req)
 
 	resp, err := o.client.Do(req)
 	if err != nil {
 		o.lo.Error("error making HTTP request", "error", err)
-		return "", fmt.Errorf("making HTTP request: %w", err)
+		return PromptResponse{}, fmt.Errorf("making HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return "", ErrInvalidAPIKey
+		return PromptResponse{}, ErrInvalidAPIKey
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		o.lo.Error("non-ok response received from openai API", "status", resp.Status, "code", resp.StatusCode, "response_text", body)
-		return "", fmt.Errorf("API error: %s, body: %s", resp.Status, body)
+		return PromptResponse{}, fmt.Errorf("API error: %s, body: %s", resp.Status, body)
 	}
 
 	var responseBody struct {
+		Model   string `json:"model"`
 		Choices []struct {
 			Message struct {
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&responseBody); err != nil {
-		return "", fmt.Errorf("decoding response body: %w", err)
+		return PromptResponse{}, fmt.Errorf("decoding response body: %w", err)
 	}
 
 	if len(responseBody.Choices) > 0 {
-		return responseBody.Choices[0].Message.Content, nil
+		return PromptResponse{
+			Text:         responseBody.Choices[0].Message.Content,
+			Model:        responseBody.Model,
+			InputTokens:  responseBody.Usage.PromptTokens,
+			OutputTokens: responseBody.Usage.CompletionTokens,
+		}, nil
+	}
+	return PromptResponse{}, fmt.Errorf("no response found")
+}
+
+// StreamPrompt streams a prompt from the OpenAI API, onDelta is called with each chunk of generated text.
+func (o *OpenAIClient) StreamPrompt(ctx context.Context, payload PromptPayload, onDelta func(string) error) (PromptResponse, error) {
+	if o.apikey == "" && o.baseURL == openAIDefaultBaseURL {
+		return PromptResponse{}, ErrApiKeyNotSet
+	}
+
+	model, temperature := o.model, defaultTemperature
+	if payload.Model != "" {
+		model = payload.Model
+	}
+	if payload.Temperature.Valid {
+		temperature = payload.Temperature.Float64
+	}
+
+	bodyBytes, err := json.Marshal(map[string]any{
+		"model": model,
+		"messages": []map[string]string{
+			{"role": "system", "content": payload.SystemPrompt},
+			{"role": "user", "content": payload.UserPrompt},
+		},
+		"max_tokens":  o.maxTokens,
+		"temperature": temperature,
+		"stream":      true,
+		// The token usage is sent in the last chunk.
+		"stream_options": map[string]bool{"include_usage": true},
+	})
+	if err != nil {
+		o.lo.Error("error marshalling request body", "error", err)
+		return PromptResponse{}, fmt.Errorf("marshalling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, fasthttp.MethodPost, o.baseURL+"/chat/completions", bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		o.lo.Error("error creating request", "error", err)
+		return PromptResponse{}, fmt.Errorf("error creating request: %w", err)
+	}
+
+	o.setHeaders(req)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := o.streamClient.Do(req)
+	if err != nil {
+		return PromptResponse{}, fmt.Errorf("making HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return PromptResponse{}, ErrInvalidAPIKey
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		o.lo.Error("non-ok response received from openai API", "status", resp.Status, "code", resp.StatusCode, "response_text", body)
+		return PromptResponse{}, fmt.Errorf("API error: %s, body: %s", resp.Status, body)
+	}
+
+	var (
+		out      PromptResponse
+		text     strings.Builder
+		finished bool
+	)
+	err = readSSE(resp.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			finished = true
+			return errStreamDone
+		}
+		var chunk struct {
+			Model   string `json:"model"`
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+			} `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("decoding stream chunk: %w", err)
+		}
+		if chunk.Model != "" {
+			out.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			out.InputTokens, out.OutputTokens = chunk.Usage.PromptTokens, chunk.Usage.CompletionTokens
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta.Content == "" {
+				continue
+			}
+			text.WriteString(c.Delta.Content)
+			if err := onDelta(c.Delta.Content); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	out.Text = text.String()
+	if err != nil {
+		return out, err
+	}
+	if !finished {
+		return out, fmt.Errorf("stream ended unexpectedly")
+	}
+	return out, nil
+}
+
+// setHeaders sets the auth, content type and configured headers of a request. OpenAI compatible APIs, e.g. a local
+// model server, may not need an API key, the Authorization header is left out without one.
+func (o *OpenAIClient) setHeaders(req *http.Request) {
+	if o.apikey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apikey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range o.headers {
+		req.Header.Set(k, v)
+	}
+}
+
+// EmbeddingModel returns the model used for embeddings.
+func (o *OpenAIClient) EmbeddingModel() string {
+	return o.embeddingModel
+}
+
+// Embed returns the embedding vectors for the passed texts using the OpenAI embeddings API, in the same order as the texts.
+func (o *OpenAIClient) Embed(texts []string) ([][]float64, error) {
+	if o.apikey == "" && o.baseURL == openAIDefaultBaseURL {
+		return nil, ErrApiKeyNotSet
+	}
+
+	bodyBytes, err := json.Marshal(map[string]any{
+		"model": o.embeddingModel,
+		"input": texts,
+	})
+	if err != nil {
+		o.lo.Error("error marshalling request body", "error", err)
+		return nil, fmt.Errorf("marshalling request body: %w", err)
+	}
+
+	req, err := http.NewRequest(fasthttp.MethodPost, o.baseURL+"/embeddings", bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		o.lo.Error("error creating request", "error", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	o.setHeaders(req)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		o.lo.Error("error making HTTP request", "error", err)
+		return nil, fmt.Errorf("making HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		o.lo.Error("non-ok response received from openai embeddings API", "status", resp.Status, "code", resp.StatusCode, "response_text", body)
+		return nil, fmt.Errorf("API error: %s, body: %s", resp.Status, body)
+	}
+
+	var responseBody struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&responseBody); err != nil {
+		return nil, fmt.Errorf("decoding response body: %w", err)
+	}
+	if len(responseBody.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(responseBody.Data))
+	}
+
+	var out = make([][]float64, len(texts))
+	for _, d := range responseBody.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("invalid embedding index %d", d.Index)
+		}
+		out[d.Index] = d.Embedding
 	}
-	return "", fmt.Errorf("no response found")
+	return out, nil
 }
@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+func TestOpenAIClientCustomBaseURL(t *testing.T) {
+	lo := logf.New(logf.Opts{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/openai/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		assert.Equal(t, "gateway", r.Header.Get("X-Gateway"))
+
+		var body struct {
+			Model     string `json:"model"`
+			MaxTokens int    `json:"max_tokens"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "llama3", body.Model)
+		assert.Equal(t, 128, body.MaxTokens)
+
+		w.Write([]byte(`{"model":"llama3","choices":[{"message":{"content":"Hi"}}],"usage":{"prompt_tokens":20,"completion_tokens":2}}`))
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(models.ProviderConfig{
+		APIKey:    "test-key",
+		Model:     "llama3",
+		MaxTokens: 128,
+		BaseURL:   srv.URL + "/openai/v1",
+		Headers:   map[string]string{"X-Gateway": "gateway"},
+	}, 0, &lo)
+
+	resp, err := client.SendPrompt(context.Background(), PromptPayload{SystemPrompt: "system", UserPrompt: "user"})
+	assert.NoError(t, err)
+	assert.Equal(t, PromptResponse{Text: "Hi", Model: "llama3", InputTokens: 20, OutputTokens: 2}, resp)
+}
+
+func TestOpenAIClientPromptOverrides(t *testing.T) {
+	lo := logf.New(logf.Opts{})
+
+	tests := []struct {
+		name                string
+		payload             PromptPayload
+		expectedModel       string
+		expectedTemperature float64
+	}{
+		{
+			name:                "Provider defaults",
+			payload:             PromptPayload{SystemPrompt: "system", UserPrompt: "user"},
+			expectedModel:       "gpt-4o-mini",
+			expectedTemperature: defaultTemperature,
+		},
+		{
+			name:                "Prompt overrides",
+			payload:             PromptPayload{SystemPrompt: "system", UserPrompt: "user", Model: "gpt-4o", Temperature: null.Float64From(0)},
+			expectedModel:       "gpt-4o",
+			expectedTemperature: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Model       string  `json:"model"`
+					Temperature float64 `json:"temperature"`
+				}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, tt.expectedModel, body.Model)
+				assert.Equal(t, tt.expectedTemperature, body.Temperature)
+				w.Write([]byte(`{"choices":[{"message":{"content":"Hi"}}]}`))
+			}))
+			defer srv.Close()
+
+			client := NewOpenAIClient(models.ProviderConfig{APIKey: "test-key", BaseURL: srv.URL}, 0, &lo)
+			_, err := client.SendPrompt(context.Background(), tt.payload)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestOpenAIClientEmbed(t *testing.T) {
+	lo := logf.New(logf.Opts{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "text-embedding-3-small", body.Model)
+		assert.Equal(t, []string{"a", "b"}, body.Input)
+
+		// Embeddings may be returned out of order.
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(models.ProviderConfig{APIKey: "test-key", BaseURL: srv.URL + "/v1"}, 0, &lo)
+	assert.Equal(t, "text-embedding-3-small", client.EmbeddingModel())
+
+	vectors, err := client.Embed([]string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float64{{1, 0}, {0, 1}}, vectors)
+
+	_, err = NewOpenAIClient(models.ProviderConfig{}, 0, &lo).Embed([]string{"a"})
+	assert.ErrorIs(t, err, ErrApiKeyNotSet)
+}
+
+func TestOpenAIClientWithoutAPIKey(t *testing.T) {
+	lo := logf.New(logf.Opts{})
+
+	// OpenAI compatible servers at a custom base URL may not need an API key.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := r.Header["Authorization"]
+		assert.False(t, ok)
+		w.Write([]byte(`{"model":"llama3","choices":[{"message":{"content":"Hi"}}]}`))
+	}))
+	defer srv.Close()
+
+	resp, err := NewOpenAIClient(models.ProviderConfig{BaseURL: srv.URL}, 0, &lo).SendPrompt(context.Background(), PromptPayload{UserPrompt: "user"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi", resp.Text)
+
+	// OpenAI itself always needs one.
+	_, err = NewOpenAIClient(models.ProviderConfig{}, 0, &lo).SendPrompt(context.Background(), PromptPayload{UserPrompt: "user"})
+	assert.ErrorIs(t, err, ErrApiKeyNotSet)
+}
+
+func TestOpenAIClientTimeout(t *testing.T) {
+	lo := logf.New(logf.Opts{})
+
+	assert.Equal(t, openAIDefaultTimeout, NewOpenAIClient(models.ProviderConfig{}, 0, &lo).client.Timeout)
+	assert.Equal(t, time.Minute, NewOpenAIClient(models.ProviderConfig{}, time.Minute, &lo).client.Timeout)
+	// Streamed requests are bounded by their context instead.
+	assert.Zero(t, NewOpenAIClient(models.ProviderConfig{}, time.Minute, &lo).streamClient.Timeout)
+}
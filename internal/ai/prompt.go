@@ -0,0 +1,225 @@
+package ai
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+)
+
+const (
+	maxPromptKeyLen   = 140
+	maxPromptTitleLen = 140
+	maxTemperature    = 2
+)
+
+// templateStore renders prompt content with template variables.
+type templateStore interface {
+	ParseTextTemplate(content string) error
+	RenderTextTemplate(content string, data any) (string, error)
+}
+
+// SetTemplateStore sets the template store used to render prompt variables.
+func (m *Manager) SetTemplateStore(store templateStore) {
+	m.templateStore = store
+}
+
+// GetPrompts returns a list of prompts from the database.
+func (m *Manager) GetPrompts() ([]models.Prompt, error) {
+	var prompts = make([]models.Prompt, 0)
+	if err := m.q.GetPrompts.Select(&prompts); err != nil {
+		m.lo.Error("error fetching prompts", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+	return prompts, nil
+}
+
+// GetPrompt returns a prompt by ID.
+func (m *Manager) GetPrompt(id int) (models.Prompt, error) {
+	return m.getPrompt(id, "")
+}
+
+// CreatePrompt creates a new prompt and records it as the first version.
+func (m *Manager) CreatePrompt(p models.Prompt, userID int) (models.Prompt, error) {
+	if err := m.validatePrompt(&p); err != nil {
+		return models.Prompt{}, err
+	}
+
+	tx, err := m.db.BeginTxx(context.Background(), nil)
+	if err != nil {
+		m.lo.Error("error starting transaction", "error", err)
+		return models.Prompt{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+	defer tx.Rollback()
+
+	var prompt models.Prompt
+	if err := tx.Stmtx(m.q.InsertPrompt).Get(&prompt, p.Key, p.Title, p.Content, p.Model, p.Temperature, pq.Array(p.AllowedRoles)); err != nil {
+		if dbutil.IsUniqueViolationError(err) {
+			return models.Prompt{}, envelope.NewError(envelope.ConflictError, m.i18n.Ts("globals.messages.errorAlreadyExists", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+		}
+		m.lo.Error("error inserting prompt", "error", err)
+		return models.Prompt{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+	if err := m.insertPromptVersion(tx, prompt, userID); err != nil {
+		return models.Prompt{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+
+	if err := tx.Commit(); err != nil {
+		m.lo.Error("error committing transaction", "error", err)
+		return models.Prompt{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+	return prompt, nil
+}
+
+// UpdatePrompt updates a prompt, the key cannot be changed. Each update is recorded as a new version.
+func (m *Manager) UpdatePrompt(id int, p models.Prompt, userID int) (models.Prompt, error) {
+	existing, err := m.getPrompt(id, "")
+	if err != nil {
+		return models.Prompt{}, err
+	}
+	p.Key = existing.Key
+	if err := m.validatePrompt(&p); err != nil {
+		return models.Prompt{}, err
+	}
+
+	tx, err := m.db.BeginTxx(context.Background(), nil)
+	if err != nil {
+		m.lo.Error("error starting transaction", "error", err)
+		return models.Prompt{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+	defer tx.Rollback()
+
+	var prompt models.Prompt
+	if err := tx.Stmtx(m.q.UpdatePrompt).Get(&prompt, id, p.Title, p.Content, p.Model, p.Temperature, pq.Array(p.AllowedRoles)); err != nil {
+		m.lo.Error("error updating prompt", "id", id, "error", err)
+		return models.Prompt{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+	if err := m.insertPromptVersion(tx, prompt, userID); err != nil {
+		return models.Prompt{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+
+	if err := tx.Commit(); err != nil {
+		m.lo.Error("error committing transaction", "error", err)
+		return models.Prompt{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+	return prompt, nil
+}
+
+// DeletePrompt deletes a prompt along with its versions.
+func (m *Manager) DeletePrompt(id int) error {
+	res, err := m.q.DeletePrompt.Exec(id)
+	if err != nil {
+		m.lo.Error("error deleting prompt", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorDeleting", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+	return nil
+}
+
+// GetPromptVersions returns the version history of a prompt, latest first.
+func (m *Manager) GetPromptVersions(id int) ([]models.PromptVersion, error) {
+	var versions = make([]models.PromptVersion, 0)
+	if err := m.q.GetPromptVersions.Select(&versions, id); err != nil {
+		m.lo.Error("error fetching prompt versions", "id", id, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.version")), nil)
+	}
+	return versions, nil
+}
+
+// RollbackPrompt restores a prompt to the passed version. The restored content is saved as a new version so the history is never rewritten.
+func (m *Manager) RollbackPrompt(id, version, userID int) (models.Prompt, error) {
+	var v models.PromptVersion
+	if err := m.q.GetPromptVersion.Get(&v, id, version); err != nil {
+		if err == sql.ErrNoRows {
+			return models.Prompt{}, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.version")), nil)
+		}
+		m.lo.Error("error fetching prompt version", "id", id, "version", version, "error", err)
+		return models.Prompt{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.version")), nil)
+	}
+
+	return m.UpdatePrompt(id, models.Prompt{
+		Title:        v.Title,
+		Content:      v.Content,
+		Model:        v.Model,
+		Temperature:  v.Temperature,
+		AllowedRoles: v.AllowedRoles,
+	}, userID)
+}
+
+// getPrompt returns a prompt by ID or key from the database.
+func (m *Manager) getPrompt(id int, key string) (models.Prompt, error) {
+	var p models.Prompt
+	if err := m.q.GetPrompt.Get(&p, id, key); err != nil {
+		if err == sql.ErrNoRows {
+			m.lo.Error("error prompt not found", "id", id, "key", key)
+			return p, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+		}
+		m.lo.Error("error fetching prompt", "error", err)
+		return p, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+	return p, nil
+}
+
+// renderPrompt renders the template variables in the prompt content.
+func (m *Manager) renderPrompt(content string, data any) (string, error) {
+	if m.templateStore == nil || data == nil {
+		return content, nil
+	}
+	out, err := m.templateStore.RenderTextTemplate(content, data)
+	if err != nil {
+		m.lo.Error("error rendering prompt", "error", err)
+		return "", envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorParsing", "name", m.i18n.Ts("globals.terms.prompt")), nil)
+	}
+	return out, nil
+}
+
+// insertPromptVersion records the current state of the prompt as a version.
+func (m *Manager) insertPromptVersion(tx *sqlx.Tx, p models.Prompt, userID int) error {
+	if _, err := tx.Stmtx(m.q.InsertPromptVersion).Exec(p.ID, p.Version, p.Title, p.Content, p.Model, p.Temperature, pq.Array(p.AllowedRoles), userID); err != nil {
+		m.lo.Error("error inserting prompt version", "id", p.ID, "version", p.Version, "error", err)
+		return err
+	}
+	return nil
+}
+
+// validatePrompt validates and normalizes the prompt fields.
+func (m *Manager) validatePrompt(p *models.Prompt) error {
+	p.Key = strings.TrimSpace(p.Key)
+	p.Title = strings.TrimSpace(p.Title)
+	p.Model.String = strings.TrimSpace(p.Model.String)
+
+	if p.Key == "" || len(p.Key) > maxPromptKeyLen {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`key`"), nil)
+	}
+	if p.Title == "" || len(p.Title) > maxPromptTitleLen {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`title`"), nil)
+	}
+	if strings.TrimSpace(p.Content) == "" {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.empty", "name", "`content`"), nil)
+	}
+	if p.Temperature.Valid && (p.Temperature.Float64 < 0 || p.Temperature.Float64 > maxTemperature) {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`temperature`"), nil)
+	}
+	if p.Model.String == "" {
+		p.Model = null.String{}
+	}
+	if p.AllowedRoles == nil {
+		p.AllowedRoles = pq.StringArray{}
+	}
+
+	// Make sure the content is a valid template.
+	if m.templateStore != nil {
+		if err := m.templateStore.ParseTextTemplate(p.Content); err != nil {
+			return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`content`"), nil)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package ai
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/knadh/go-i18n"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+// promptRow is a row of ai_prompts or ai_prompt_versions in the fake prompt store.
+type promptRow struct {
+	id, version, createdBy int
+	key, title, content    string
+	model, temperature     driver.Value
+	allowedRoles           driver.Value
+}
+
+var (
+	promptColumns  = []string{"id", "key", "title", "content", "model", "temperature", "allowed_roles", "version"}
+	versionColumns = []string{"prompt_id", "version", "title", "content", "model", "temperature", "allowed_roles", "created_by"}
+)
+
+// fakePromptStore keeps prompts and their versions in memory and answers the prompt queries.
+type fakePromptStore struct {
+	prompts  []*promptRow
+	versions []promptRow
+}
+
+func (s *fakePromptStore) find(id int64, key string) *promptRow {
+	for _, p := range s.prompts {
+		if (id > 0 && int64(p.id) == id) || (key != "" && p.key == key) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (p *promptRow) row() []any {
+	return []any{p.id, p.key, p.title, p.content, p.model, p.temperature, p.allowedRoles, p.version}
+}
+
+func (s *fakePromptStore) handle(query string, args []driver.Value) (dbtest.Result, error) {
+	switch {
+	case strings.Contains(query, "INSERT INTO ai_prompts "):
+		if s.find(0, args[0].(string)) != nil {
+			return dbtest.Result{}, &pq.Error{Code: "23505"}
+		}
+		p := &promptRow{id: len(s.prompts) + 1, version: 1, key: args[0].(string), title: args[1].(string), content: args[2].(string),
+			model: args[3], temperature: args[4], allowedRoles: args[5]}
+		s.prompts = append(s.prompts, p)
+		return dbtest.Result{Columns: promptColumns, Rows: [][]any{p.row()}}, nil
+	case strings.Contains(query, "UPDATE ai_prompts"):
+		p := s.find(args[0].(int64), "")
+		if p == nil {
+			return dbtest.Result{Columns: promptColumns}, nil
+		}
+		p.title, p.content, p.model, p.temperature, p.allowedRoles = args[1].(string), args[2].(string), args[3], args[4], args[5]
+		p.version++
+		return dbtest.Result{Columns: promptColumns, Rows: [][]any{p.row()}}, nil
+	case strings.Contains(query, "DELETE FROM ai_prompts"):
+		for i, p := range s.prompts {
+			if int64(p.id) == args[0].(int64) {
+				s.prompts = append(s.prompts[:i], s.prompts[i+1:]...)
+				return dbtest.Result{RowsAffected: 1}, nil
+			}
+		}
+		return dbtest.Result{}, nil
+	case strings.Contains(query, "FROM ai_prompts"):
+		r := dbtest.Result{Columns: promptColumns}
+		if p := s.find(args[0].(int64), args[1].(string)); p != nil {
+			r.Rows = [][]any{p.row()}
+		}
+		return r, nil
+	case strings.Contains(query, "INSERT INTO ai_prompt_versions"):
+		s.versions = append(s.versions, promptRow{id: int(args[0].(int64)), version: int(args[1].(int64)), title: args[2].(string), content: args[3].(string),
+			model: args[4], temperature: args[5], allowedRoles: args[6], createdBy: int(args[7].(int64))})
+		return dbtest.Result{RowsAffected: 1}, nil
+	case strings.Contains(query, "FROM ai_prompt_versions"):
+		r := dbtest.Result{Columns: versionColumns}
+		for i := len(s.versions) - 1; i >= 0; i-- {
+			v := s.versions[i]
+			if int64(v.id) != args[0].(int64) || (len(args) > 1 && int64(v.version) != args[1].(int64)) {
+				continue
+			}
+			r.Rows = append(r.Rows, []any{v.id, v.version, v.title, v.content, v.model, v.temperature, v.allowedRoles, v.createdBy})
+		}
+		return r, nil
+	}
+	return dbtest.Result{}, nil
+}
+
+type fakeTemplateStore struct{}
+
+func (fakeTemplateStore) ParseTextTemplate(content string) error {
+	_, err := template.New("").Parse(content)
+	return err
+}
+
+func (fakeTemplateStore) RenderTextTemplate(content string, data any) (string, error) {
+	return content, nil
+}
+
+func newPromptManager(t *testing.T) (*Manager, *fakePromptStore) {
+	t.Helper()
+	store := &fakePromptStore{}
+	db := dbtest.New(store.handle)
+	var q queries
+	require.NoError(t, dbutil.ScanSQLFile("queries.sql", &q, db.DB, efs))
+	i18n, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	require.NoError(t, err)
+	lo := logf.New(logf.Opts{})
+	return &Manager{q: q, db: db.DB, lo: &lo, i18n: i18n, templateStore: fakeTemplateStore{}}, store
+}
+
+func assertErrorType(t *testing.T, err error, errType string) {
+	t.Helper()
+	var eerr envelope.Error
+	require.True(t, errors.As(err, &eerr), err)
+	assert.Equal(t, errType, eerr.ErrorType)
+}
+
+func TestCreatePrompt(t *testing.T) {
+	m, store := newPromptManager(t)
+
+	prompt, err := m.CreatePrompt(models.Prompt{Key: " summarize ", Title: " Summarize ", Content: "Summarize {{ .Conversation }}", Model: null.StringFrom(" ")}, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, prompt.ID)
+	assert.Equal(t, "summarize", prompt.Key)
+	assert.Equal(t, "Summarize", prompt.Title)
+	assert.Equal(t, 1, prompt.Version)
+	assert.False(t, prompt.Model.Valid)
+	assert.Empty(t, prompt.AllowedRoles)
+
+	// The first version is recorded with the creator.
+	require.Len(t, store.versions, 1)
+	assert.Equal(t, 1, store.versions[0].version)
+	assert.Equal(t, 5, store.versions[0].createdBy)
+
+	_, err = m.CreatePrompt(models.Prompt{Key: "summarize", Title: "Again", Content: "Summarize"}, 5)
+	assertErrorType(t, err, envelope.ConflictError)
+	assert.Len(t, store.versions, 1)
+}
+
+func TestCreatePromptValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt models.Prompt
+	}{
+		{"empty key", models.Prompt{Key: " ", Title: "Title", Content: "Content"}},
+		{"long key", models.Prompt{Key: strings.Repeat("k", maxPromptKeyLen+1), Title: "Title", Content: "Content"}},
+		{"empty title", models.Prompt{Key: "key", Content: "Content"}},
+		{"long title", models.Prompt{Key: "key", Title: strings.Repeat("t", maxPromptTitleLen+1), Content: "Content"}},
+		{"empty content", models.Prompt{Key: "key", Title: "Title", Content: "\n "}},
+		{"negative temperature", models.Prompt{Key: "key", Title: "Title", Content: "Content", Temperature: null.Float64From(-0.1)}},
+		{"high temperature", models.Prompt{Key: "key", Title: "Title", Content: "Content", Temperature: null.Float64From(2.1)}},
+		{"invalid template", models.Prompt{Key: "key", Title: "Title", Content: "Hi {{ .Contact"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, store := newPromptManager(t)
+			_, err := m.CreatePrompt(tt.prompt, 1)
+			assertErrorType(t, err, envelope.InputError)
+			assert.Empty(t, store.prompts)
+		})
+	}
+}
+
+func TestUpdatePrompt(t *testing.T) {
+	m, store := newPromptManager(t)
+	_, err := m.CreatePrompt(models.Prompt{Key: "summarize", Title: "Summarize", Content: "v1"}, 1)
+	require.NoError(t, err)
+
+	// The key cannot be changed and every update is a new version.
+	prompt, err := m.UpdatePrompt(1, models.Prompt{Key: "other", Title: "Summarize", Content: "v2", Temperature: null.Float64From(0.5), AllowedRoles: pq.StringArray{"Admin"}}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "summarize", prompt.Key)
+	assert.Equal(t, "v2", prompt.Content)
+	assert.Equal(t, 2, prompt.Version)
+	assert.Equal(t, null.Float64From(0.5), prompt.Temperature)
+	assert.Equal(t, pq.StringArray{"Admin"}, prompt.AllowedRoles)
+
+	versions, err := m.GetPromptVersions(1)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 2, versions[0].Version)
+	assert.Equal(t, "v2", versions[0].Content)
+	assert.Equal(t, null.IntFrom(2), versions[0].CreatedBy)
+	assert.Equal(t, 1, versions[1].Version)
+	assert.Equal(t, "v1", versions[1].Content)
+
+	_, err = m.UpdatePrompt(9, models.Prompt{Title: "Missing", Content: "v1"}, 1)
+	assertErrorType(t, err, envelope.InputError)
+
+	_, err = m.UpdatePrompt(1, models.Prompt{Title: "Summarize", Content: " "}, 1)
+	assertErrorType(t, err, envelope.InputError)
+	assert.Len(t, store.versions, 2)
+}
+
+func TestGetAndDeletePrompt(t *testing.T) {
+	m, store := newPromptManager(t)
+	_, err := m.CreatePrompt(models.Prompt{Key: "summarize", Title: "Summarize", Content: "v1"}, 1)
+	require.NoError(t, err)
+
+	prompt, err := m.GetPrompt(1)
+	require.NoError(t, err)
+	assert.Equal(t, "summarize", prompt.Key)
+
+	require.NoError(t, m.DeletePrompt(1))
+	assert.Empty(t, store.prompts)
+
+	_, err = m.GetPrompt(1)
+	assertErrorType(t, err, envelope.InputError)
+	assertErrorType(t, m.DeletePrompt(1), envelope.NotFoundError)
+}
+
+func TestRollbackPrompt(t *testing.T) {
+	m, store := newPromptManager(t)
+	_, err := m.CreatePrompt(models.Prompt{Key: "summarize", Title: "Summarize", Content: "v1", Model: null.StringFrom("gpt-4o")}, 1)
+	require.NoError(t, err)
+	_, err = m.UpdatePrompt(1, models.Prompt{Title: "Summary", Content: "v2"}, 1)
+	require.NoError(t, err)
+
+	// Rolling back restores the old version as a new version, the history is kept.
+	prompt, err := m.RollbackPrompt(1, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, prompt.Version)
+	assert.Equal(t, "Summarize", prompt.Title)
+	assert.Equal(t, "v1", prompt.Content)
+	assert.Equal(t, null.StringFrom("gpt-4o"), prompt.Model)
+
+	require.Len(t, store.versions, 3)
+	assert.Equal(t, []string{"v1", "v2", "v1"}, []string{store.versions[0].content, store.versions[1].content, store.versions[2].content})
+	assert.Equal(t, 3, store.versions[2].createdBy)
+
+	// Rolling back to a version that does not exist changes nothing.
+	_, err = m.RollbackPrompt(1, 7, 3)
+	assertErrorType(t, err, envelope.NotFoundError)
+	_, err = m.RollbackPrompt(2, 1, 3)
+	assertErrorType(t, err, envelope.NotFoundError)
+	assert.Len(t, store.versions, 3)
+	assert.Equal(t, 3, store.prompts[0].version)
+}
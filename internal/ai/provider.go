@@ -1,8 +1,37 @@
 package ai
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/volatiletech/null/v9"
+)
+
 // ProviderClient is the interface all providers should implement.
 type ProviderClient interface {
-	SendPrompt(payload PromptPayload) (string, error)
+	SendPrompt(ctx context.Context, payload PromptPayload) (PromptResponse, error)
+}
+
+// PromptResponse is the provider response to a prompt along with the token usage reported by the provider.
+type PromptResponse struct {
+	Text string
+	// Model is the model that served the prompt as reported by the provider.
+	Model        string
+	InputTokens  int
+	OutputTokens int
+}
+
+// EmbeddingClient is implemented by providers that can generate embeddings for the knowledge base.
+type EmbeddingClient interface {
+	EmbeddingModel() string
+	Embed(texts []string) ([][]float64, error)
 }
 
 // ProviderType is an enum-like type for different providers.
@@ -13,8 +42,360 @@ const (
 	ProviderClaude ProviderType = "claude"
 )
 
+// displayName returns the human readable name of the provider.
+func (p ProviderType) displayName() string {
+	switch p {
+	case ProviderOpenAI:
+		return "OpenAI"
+	case ProviderClaude:
+		return "Anthropic"
+	}
+	return string(p)
+}
+
+// valid returns true if the provider type is supported.
+func (p ProviderType) valid() bool {
+	return p == ProviderOpenAI || p == ProviderClaude
+}
+
+// defaultTemperature is the sampling temperature used when the prompt does not override it.
+const defaultTemperature = 0.7
+
 // PromptPayload represents the structured input for an LLM provider.
 type PromptPayload struct {
 	SystemPrompt string `json:"system_prompt"`
 	UserPrompt   string `json:"user_prompt"`
+	// Model and Temperature override the provider config when set.
+	Model       string       `json:"model"`
+	Temperature null.Float64 `json:"temperature"`
+	// PromptKey and UserID identify the request for auditing, they are not sent to the provider.
+	PromptKey string `json:"-"`
+	UserID    int    `json:"-"`
+}
+
+// GetProviders returns all providers with their config, without API keys.
+func (m *Manager) GetProviders() ([]models.ProviderInfo, error) {
+	providers, err := m.getProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	var out = make([]models.ProviderInfo, 0, len(providers))
+	for _, p := range providers {
+		config, err := m.parseProviderConfig(p)
+		if err != nil {
+			continue
+		}
+		headers := make(map[string]string, len(config.Headers))
+		for k := range config.Headers {
+			headers[k] = strings.Repeat(stringutil.PasswordDummy, 10)
+		}
+		info := models.ProviderInfo{
+			ID:        p.ID,
+			Name:      p.Name,
+			Provider:  p.Provider,
+			IsDefault: p.IsDefault,
+			Model:     config.Model,
+			MaxTokens: config.MaxTokens,
+			BaseURL:   config.BaseURL,
+			APIKeySet: config.APIKey != "",
+			Headers:   headers,
+		}
+		if client, err := m.newProviderClient(p); err == nil {
+			if ec, ok := client.(EmbeddingClient); ok {
+				info.EmbeddingModel = ec.EmbeddingModel()
+			}
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+// CreateProvider adds a new provider config, multiple configs of the same provider type are allowed,
+// e.g. an OpenAI compatible gateway next to OpenAI itself.
+func (m *Manager) CreateProvider(name, provider string, config models.ProviderConfig, isDefault bool) error {
+	if !ProviderType(provider).valid() {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		m.lo.Error("error marshalling provider config", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+
+	var p models.Provider
+	if err := m.q.InsertProvider.Get(&p, name, provider, b); err != nil {
+		if dbutil.IsUniqueViolationError(err) {
+			return envelope.NewError(envelope.ConflictError, m.i18n.Ts("globals.messages.errorAlreadyExists", "name", m.i18n.Ts("globals.terms.provider")), nil)
+		}
+		m.lo.Error("error inserting provider", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+
+	if isDefault {
+		return m.setDefaultProvider(name)
+	}
+	return nil
+}
+
+// UpdateProvider updates the config of the provider with the given name, only non-empty config values are updated.
+// If isDefault is true the provider is made the default provider.
+func (m *Manager) UpdateProvider(name string, config models.ProviderConfig, isDefault bool) error {
+	if err := m.updateProviderConfig(name, config); err != nil {
+		return err
+	}
+
+	if isDefault {
+		return m.setDefaultProvider(name)
+	}
+	return nil
+}
+
+// DeleteProvider deletes a provider config, the default provider cannot be deleted.
+func (m *Manager) DeleteProvider(name string) error {
+	res, err := m.q.DeleteProvider.Exec(name)
+	if err != nil {
+		m.lo.Error("error deleting provider", "name", name, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorDeleting", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.errorDeleting", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	return nil
+}
+
+// updateProviderConfig merges the non-empty config values into the stored provider config. Header values that are
+// the masked dummy value returned by GetProviders keep their stored value.
+func (m *Manager) updateProviderConfig(name string, config models.ProviderConfig) error {
+	var updates = make(map[string]any)
+	if config.APIKey != "" {
+		updates["api_key"] = config.APIKey
+	}
+	if config.Model != "" {
+		updates["model"] = config.Model
+	}
+	if config.MaxTokens > 0 {
+		updates["max_tokens"] = config.MaxTokens
+	}
+	if config.BaseURL != "" {
+		updates["base_url"] = config.BaseURL
+	}
+	if config.Headers != nil {
+		stored, err := m.getProviderConfig(name)
+		if err != nil {
+			return err
+		}
+		updates["headers"] = mergeHeaders(config.Headers, stored.Headers)
+	}
+	if config.EmbeddingModel != "" {
+		updates["embedding_model"] = config.EmbeddingModel
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	b, err := json.Marshal(updates)
+	if err != nil {
+		m.lo.Error("error marshalling provider config", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	res, err := m.q.UpdateProviderConfig.Exec(name, b)
+	if err != nil {
+		m.lo.Error("error updating provider config", "name", name, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	return nil
+}
+
+// getProviderConfig returns the stored config of the provider with the given name.
+func (m *Manager) getProviderConfig(name string) (models.ProviderConfig, error) {
+	providers, err := m.getProviders()
+	if err != nil {
+		return models.ProviderConfig{}, err
+	}
+	for _, p := range providers {
+		if p.Name == name {
+			return m.parseProviderConfig(p)
+		}
+	}
+	return models.ProviderConfig{}, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.provider")), nil)
+}
+
+// mergeHeaders returns the headers to store, headers with the masked dummy value keep their stored value and are
+// dropped if there is none. Headers left out of the update are removed.
+func mergeHeaders(headers, stored map[string]string) map[string]string {
+	var out = make(map[string]string, len(headers))
+	for k, v := range headers {
+		if strings.Contains(v, stringutil.PasswordDummy) {
+			if sv, ok := stored[k]; ok {
+				out[k] = sv
+			}
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// hasCredentials reports whether requests can be sent to the provider, either with an API key or without one to an
+// OpenAI compatible API at a custom base URL, e.g. a local model server.
+func hasCredentials(p models.Provider, config models.ProviderConfig) bool {
+	if config.APIKey != "" {
+		return true
+	}
+	baseURL := strings.TrimRight(config.BaseURL, "/")
+	return ProviderType(p.Provider) == ProviderOpenAI && baseURL != "" && baseURL != openAIDefaultBaseURL
+}
+
+// setDefaultProvider makes the provider with the given name the default provider.
+func (m *Manager) setDefaultProvider(name string) error {
+	tx, err := m.db.BeginTxx(context.Background(), nil)
+	if err != nil {
+		m.lo.Error("error starting transaction", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Stmtx(m.q.UnsetDefaultProvider).Exec(); err != nil {
+		m.lo.Error("error unsetting default provider", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	res, err := tx.Stmtx(m.q.SetDefaultProvider).Exec(name)
+	if err != nil {
+		m.lo.Error("error setting default provider", "name", name, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+
+	if err := tx.Commit(); err != nil {
+		m.lo.Error("error committing transaction", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	return nil
+}
+
+// getProviders returns all providers, default provider first.
+func (m *Manager) getProviders() ([]models.Provider, error) {
+	var providers = make([]models.Provider, 0)
+	if err := m.q.GetProviders.Select(&providers); err != nil {
+		m.lo.Error("error fetching providers", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	return providers, nil
+}
+
+// parseProviderConfig parses the JSON config of a provider.
+func (m *Manager) parseProviderConfig(p models.Provider) (models.ProviderConfig, error) {
+	var config models.ProviderConfig
+	if err := json.Unmarshal([]byte(p.Config), &config); err != nil {
+		m.lo.Error("error parsing provider config", "provider", p.Name, "error", err)
+		return config, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorParsing", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	return config, nil
+}
+
+// newProviderClient returns a ProviderClient for the passed provider.
+func (m *Manager) newProviderClient(p models.Provider) (ProviderClient, error) {
+	config, err := m.parseProviderConfig(p)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ProviderType(p.Provider) {
+	case ProviderOpenAI:
+		return NewOpenAIClient(config, m.requestTimeout, m.lo), nil
+	case ProviderClaude:
+		return NewClaudeClient(config, m.requestTimeout, m.lo), nil
+	default:
+		m.lo.Error("unsupported provider type", "provider", p.Provider)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.invalid", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+}
+
+// sendPrompt sends the payload to the default provider. If the default provider fails, the remaining
+// providers that have credentials are tried in order. The error of the default provider is returned if all fail.
+// Every provider call is recorded for metering and requests of users over their monthly budget are rejected.
+// Fallback providers are not tried once ctx is done.
+func (m *Manager) sendPrompt(ctx context.Context, payload PromptPayload) (string, error) {
+	if err := m.checkBudget(payload.UserID); err != nil {
+		return "", err
+	}
+
+	providers, err := m.getProviders()
+	if err != nil {
+		return "", err
+	}
+	if len(providers) == 0 {
+		return "", envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+
+	// PII is masked before the prompt leaves the server.
+	payload, redaction := m.redactPayload(payload)
+
+	var (
+		firstErr      error
+		firstProvider models.Provider
+	)
+	for i, p := range providers {
+		config, err := m.parseProviderConfig(p)
+		if err != nil {
+			continue
+		}
+		// Fallback providers without credentials are skipped.
+		if i > 0 && !hasCredentials(p, config) {
+			continue
+		}
+
+		client, err := m.newProviderClient(p)
+		if err != nil {
+			continue
+		}
+
+		// Model names are provider specific, fallback providers always use their configured model.
+		if i > 0 {
+			payload.Model = ""
+		}
+
+		start := time.Now()
+		response, err := client.SendPrompt(ctx, payload)
+
+		m.recordUsage(newUsageRecord(payload, p, config, response, time.Since(start), err))
+
+		if err == nil {
+			if i > 0 {
+				m.lo.Warn("prompt served by fallback provider", "provider", p.Name)
+			}
+			return m.restoreResponse(response.Text, redaction), nil
+		}
+
+		m.lo.Error("error sending prompt to provider", "provider", p.Name, "error", err)
+		if firstErr == nil {
+			firstErr, firstProvider = err, p
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if firstErr == nil {
+		return "", envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	return "", m.providerError(ProviderType(firstProvider.Provider), firstErr)
+}
+
+// providerError maps a provider client error to an envelope error.
+func (m *Manager) providerError(provider ProviderType, err error) error {
+	if errors.Is(err, ErrInvalidAPIKey) {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", provider.displayName()+" API Key"), nil)
+	}
+	if errors.Is(err, ErrApiKeyNotSet) {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("ai.apiKeyNotSet", "provider", provider.displayName()), nil)
+	}
+	return envelope.NewError(envelope.GeneralError, err.Error(), nil)
 }
@@ -0,0 +1,37 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeHeaders(t *testing.T) {
+	dummy := strings.Repeat(stringutil.PasswordDummy, 10)
+	stored := map[string]string{"X-Api-Token": "secret", "X-Removed": "old"}
+
+	headers := mergeHeaders(map[string]string{
+		"X-Api-Token": dummy,
+		"X-Gateway":   "gateway",
+		"X-Unknown":   dummy,
+	}, stored)
+
+	// Masked values keep the stored value, headers left out are removed and masked headers without a stored value are dropped.
+	assert.Equal(t, map[string]string{"X-Api-Token": "secret", "X-Gateway": "gateway"}, headers)
+	assert.Empty(t, mergeHeaders(map[string]string{}, stored))
+}
+
+func TestHasCredentials(t *testing.T) {
+	openai := models.Provider{Provider: string(ProviderOpenAI)}
+	claude := models.Provider{Provider: string(ProviderClaude)}
+
+	assert.True(t, hasCredentials(openai, models.ProviderConfig{APIKey: "key"}))
+	assert.True(t, hasCredentials(openai, models.ProviderConfig{BaseURL: "http://localhost:11434/v1"}))
+	assert.False(t, hasCredentials(openai, models.ProviderConfig{}))
+	assert.False(t, hasCredentials(openai, models.ProviderConfig{BaseURL: openAIDefaultBaseURL + "/"}))
+	assert.True(t, hasCredentials(claude, models.ProviderConfig{APIKey: "key"}))
+	assert.False(t, hasCredentials(claude, models.ProviderConfig{BaseURL: "http://localhost:8080"}))
+}
@@ -1,17 +1,241 @@
--- name: get-default-provider
-SELECT id, name, provider, config, is_default FROM ai_providers where is_default is true;
+-- name: get-providers
+-- Default provider first, the rest are used as fallbacks in the order they were created.
+SELECT id, created_at, updated_at, name, provider, config, is_default FROM ai_providers ORDER BY is_default DESC, id;
+
+-- name: insert-provider
+INSERT INTO ai_providers (name, provider, config)
+VALUES ($1, $2, $3)
+RETURNING id, created_at, updated_at, name, provider, config, is_default;
+
+-- name: delete-provider
+DELETE FROM ai_providers WHERE name = $1 AND is_default = false;
 
 -- name: get-prompt
-SELECT id, created_at, updated_at, key, title, content FROM ai_prompts where key = $1;
+SELECT id, created_at, updated_at, key, title, content, model, temperature, allowed_roles, version
+FROM ai_prompts
+WHERE ($1 > 0 AND id = $1) OR ($2 != '' AND key = $2);
 
 -- name: get-prompts
-SELECT id, created_at, updated_at, key, title FROM ai_prompts order by title;
-
--- name: set-openai-key
-UPDATE ai_providers 
-SET config = jsonb_set(
-    COALESCE(config, '{}'::jsonb),
-    '{api_key}', 
-    to_jsonb($1::text)
-) 
-WHERE provider = 'openai';
+SELECT id, created_at, updated_at, key, title, model, temperature, allowed_roles, version FROM ai_prompts order by title;
+
+-- name: insert-prompt
+INSERT INTO ai_prompts (key, title, content, model, temperature, allowed_roles)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, key, title, content, model, temperature, allowed_roles, version;
+
+-- name: update-prompt
+-- Every update bumps the version, the previous versions are kept in ai_prompt_versions.
+UPDATE ai_prompts
+SET title = $2, content = $3, model = $4, temperature = $5, allowed_roles = $6, version = version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, key, title, content, model, temperature, allowed_roles, version;
+
+-- name: delete-prompt
+DELETE FROM ai_prompts WHERE id = $1;
+
+-- name: insert-prompt-version
+INSERT INTO ai_prompt_versions (prompt_id, version, title, content, model, temperature, allowed_roles, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0));
+
+-- name: get-prompt-versions
+SELECT id, created_at, prompt_id, version, title, content, model, temperature, allowed_roles, created_by
+FROM ai_prompt_versions
+WHERE prompt_id = $1
+ORDER BY version DESC;
+
+-- name: get-prompt-version
+SELECT id, created_at, prompt_id, version, title, content, model, temperature, allowed_roles, created_by
+FROM ai_prompt_versions
+WHERE prompt_id = $1 AND version = $2;
+
+-- name: update-provider-config
+-- Merges the passed keys into the existing provider config.
+UPDATE ai_providers
+SET config = COALESCE(config, '{}'::jsonb) || $2::jsonb,
+    updated_at = NOW()
+WHERE name = $1;
+
+-- name: unset-default-provider
+UPDATE ai_providers SET is_default = false, updated_at = NOW() WHERE is_default = true;
+
+-- name: set-default-provider
+UPDATE ai_providers SET is_default = true, updated_at = NOW() WHERE name = $1;
+
+-- name: get-knowledge-documents
+SELECT s.id, s.created_at, s.updated_at, s.type, s.title, s.conversation_id, s.indexed_at,
+    (SELECT COUNT(*) FROM ai_knowledge_chunks c WHERE c.source_id = s.id) AS chunks
+FROM ai_knowledge_sources s
+WHERE s.type = 'document'
+ORDER BY s.title;
+
+-- name: get-knowledge-document
+SELECT s.id, s.created_at, s.updated_at, s.type, s.title, s.content, s.conversation_id, s.indexed_at,
+    (SELECT COUNT(*) FROM ai_knowledge_chunks c WHERE c.source_id = s.id) AS chunks
+FROM ai_knowledge_sources s
+WHERE s.id = $1 AND s.type = 'document';
+
+-- name: insert-knowledge-document
+INSERT INTO ai_knowledge_sources ("type", title, content)
+VALUES ('document', $1, $2)
+RETURNING id, created_at, updated_at, type, title, content, conversation_id, indexed_at;
+
+-- name: update-knowledge-document
+-- Updated documents are picked up again by the indexer.
+UPDATE ai_knowledge_sources
+SET title = $2, content = $3, indexed_at = NULL, updated_at = NOW()
+WHERE id = $1 AND type = 'document'
+RETURNING id, created_at, updated_at, type, title, content, conversation_id, indexed_at;
+
+-- name: delete-knowledge-document
+DELETE FROM ai_knowledge_sources WHERE id = $1 AND type = 'document';
+
+-- name: reset-knowledge-index
+UPDATE ai_knowledge_sources SET indexed_at = NULL;
+
+-- name: get-unindexed-knowledge-documents
+SELECT id, created_at, updated_at, type, title, content, conversation_id, indexed_at
+FROM ai_knowledge_sources
+WHERE type = 'document' AND indexed_at IS NULL
+ORDER BY id
+LIMIT $1;
+
+-- name: get-unindexed-resolved-conversations
+-- Resolved conversations that were never indexed or were resolved again after they were last indexed.
+SELECT c.id, c.uuid, c.reference_number, COALESCE(c.subject, '') AS subject
+FROM conversations c
+JOIN conversation_statuses cs ON cs.id = c.status_id
+LEFT JOIN ai_knowledge_sources s ON s.conversation_id = c.id
+WHERE cs.name = 'Resolved'
+AND (s.id IS NULL OR s.indexed_at IS NULL OR c.resolved_at > s.indexed_at)
+ORDER BY c.resolved_at DESC NULLS LAST
+LIMIT $1;
+
+-- name: upsert-conversation-knowledge-source
+INSERT INTO ai_knowledge_sources ("type", title, content, conversation_id)
+VALUES ('conversation', $2, $3, $1)
+ON CONFLICT (conversation_id) DO UPDATE
+SET title = EXCLUDED.title, content = EXCLUDED.content, updated_at = NOW()
+RETURNING id;
+
+-- name: delete-knowledge-chunks
+DELETE FROM ai_knowledge_chunks WHERE source_id = $1;
+
+-- name: insert-knowledge-chunk
+INSERT INTO ai_knowledge_chunks (source_id, chunk_index, content, embedding, embedding_model)
+VALUES ($1, $2, $3, $4::REAL[], $5);
+
+-- name: set-knowledge-source-indexed
+UPDATE ai_knowledge_sources SET indexed_at = NOW() WHERE id = $1;
+
+-- name: search-knowledge-chunks
+-- Embeddings are normalized so the dot product is the cosine similarity. Computing it is the expensive part, so it is
+-- only computed for the $12 most recently updated candidate chunks, and conversations indexed more than $5 seconds
+-- ago are not candidates.
+-- Conversations are limited to the ones the user can access as in the conversation lists, $6 read all, $7 read
+-- assigned to user $8, $9 read unassigned in teams $10 and $11 read unassigned.
+WITH candidates AS (
+    SELECT ch.id, ch.source_id, s.type, s.title, conv.uuid::TEXT AS conversation_uuid, ch.content, ch.embedding
+    FROM ai_knowledge_chunks ch
+    JOIN ai_knowledge_sources s ON s.id = ch.source_id
+    LEFT JOIN conversations conv ON conv.id = s.conversation_id
+    WHERE ch.embedding_model = $2
+    AND s.conversation_id IS DISTINCT FROM $3
+    AND (
+        s.conversation_id IS NULL
+        OR (
+            s.updated_at >= NOW() - make_interval(secs => $5)
+            AND (
+                $6
+                OR ($7 AND conv.assigned_user_id = $8)
+                OR ($9 AND conv.assigned_user_id IS NULL AND conv.assigned_team_id = ANY($10::INT[]))
+                OR ($11 AND conv.assigned_user_id IS NULL AND conv.assigned_team_id IS NULL)
+            )
+        )
+    )
+    ORDER BY s.updated_at DESC, ch.id DESC
+    LIMIT $12
+)
+SELECT c.id, c.source_id, c.type, c.title, c.conversation_uuid, c.content, score
+FROM candidates c
+CROSS JOIN LATERAL (
+    SELECT COALESCE(SUM(a * b), 0)::FLOAT8 AS score FROM unnest(c.embedding, $1::REAL[]) AS t(a, b)
+) sim
+ORDER BY score DESC
+LIMIT $4;
+
+-- name: insert-redaction-log
+INSERT INTO ai_redaction_logs (user_id, prompt_key, redactions)
+VALUES (NULLIF($1, 0), $2, $3);
+
+-- name: get-redaction-logs
+SELECT COUNT(*) OVER() AS total, id, created_at, user_id, prompt_key, redactions
+FROM ai_redaction_logs
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: insert-usage
+INSERT INTO ai_usage (user_id, prompt_key, provider, model, input_tokens, output_tokens, latency_ms, success)
+VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, $7, $8);
+
+-- name: get-usage-summary
+-- Usage between $1 and $2 grouped by $3, optionally limited to the members of team $4.
+SELECT
+    CASE $3
+        WHEN 'user' THEN COALESCE(u.user_id::TEXT, '')
+        WHEN 'prompt_key' THEN u.prompt_key
+        WHEN 'provider' THEN u.provider
+        WHEN 'model' THEN u.model
+        ELSE TO_CHAR(DATE_TRUNC('day', u.created_at), 'YYYY-MM-DD')
+    END AS key,
+    CASE $3
+        WHEN 'user' THEN COALESCE(NULLIF(TRIM(CONCAT(us.first_name, ' ', us.last_name)), ''), 'System')
+        WHEN 'prompt_key' THEN u.prompt_key
+        WHEN 'provider' THEN u.provider
+        WHEN 'model' THEN u.model
+        ELSE TO_CHAR(DATE_TRUNC('day', u.created_at), 'YYYY-MM-DD')
+    END AS label,
+    COUNT(*) AS requests,
+    COUNT(*) FILTER (WHERE NOT u.success) AS failed_requests,
+    COALESCE(SUM(u.input_tokens), 0) AS input_tokens,
+    COALESCE(SUM(u.output_tokens), 0) AS output_tokens,
+    COALESCE(SUM(u.input_tokens + u.output_tokens), 0) AS total_tokens,
+    COALESCE(AVG(u.latency_ms), 0)::INT AS avg_latency_ms
+FROM ai_usage u
+LEFT JOIN users us ON us.id = u.user_id
+WHERE u.created_at >= $1 AND u.created_at < $2
+AND ($4::INT = 0 OR u.user_id IN (SELECT tm.user_id FROM team_members tm WHERE tm.team_id = $4))
+GROUP BY 1, 2
+ORDER BY total_tokens DESC, key;
+
+-- name: get-budgets
+-- Budgets with the tokens used in the current month by all members of the team or role.
+-- If $1 is set only the budgets that apply to the user are returned.
+SELECT b.id, b.created_at, b.updated_at, b.team_id, b.role_id, COALESCE(t.name, r.name, '') AS scope_name, b.monthly_token_limit,
+    COALESCE((
+        SELECT SUM(u.input_tokens + u.output_tokens)
+        FROM ai_usage u
+        WHERE u.created_at >= DATE_TRUNC('month', NOW())
+        AND u.user_id IN (
+            SELECT tm.user_id FROM team_members tm WHERE tm.team_id = b.team_id
+            UNION
+            SELECT ur.user_id FROM user_roles ur WHERE ur.role_id = b.role_id
+        )
+    ), 0) AS used_tokens
+FROM ai_budgets b
+LEFT JOIN teams t ON t.id = b.team_id
+LEFT JOIN roles r ON r.id = b.role_id
+WHERE $1::INT = 0
+OR b.team_id IN (SELECT tm.team_id FROM team_members tm WHERE tm.user_id = $1)
+OR b.role_id IN (SELECT ur.role_id FROM user_roles ur WHERE ur.user_id = $1)
+ORDER BY b.id;
+
+-- name: insert-budget
+INSERT INTO ai_budgets (team_id, role_id, monthly_token_limit)
+VALUES (NULLIF($1, 0), NULLIF($2, 0), $3)
+RETURNING id;
+
+-- name: update-budget
+UPDATE ai_budgets SET monthly_token_limit = $2, updated_at = NOW() WHERE id = $1;
+
+-- name: delete-budget
+DELETE FROM ai_budgets WHERE id = $1;
@@ -0,0 +1,250 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+const (
+	DetectorCard  = "card"
+	DetectorEmail = "email"
+	DetectorPhone = "phone"
+)
+
+var (
+	reEmail = regexp.MustCompile(`(?i)[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}`)
+	reCard  = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+	rePhone = regexp.MustCompile(`\+?\(?\d[\d\s().\-]{6,18}\d`)
+	reDate  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+
+	reNonWord = regexp.MustCompile(`[^A-Z0-9]+`)
+)
+
+// detector finds PII in text, valid optionally filters out false positives of the regular expression.
+type detector struct {
+	name  string
+	re    *regexp.Regexp
+	valid func(string) bool
+}
+
+// redactor masks PII in prompts before they are sent to a provider.
+type redactor struct {
+	detectors []detector
+	restore   bool
+}
+
+// newRedactor returns a redactor for the config, nil if redaction is disabled.
+func newRedactor(cfg models.RedactionConfig) (*redactor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	r := &redactor{restore: cfg.Restore}
+	// Emails are detected first as they can contain digits, cards before phones as card numbers look like phone numbers.
+	for _, name := range []string{DetectorEmail, DetectorCard, DetectorPhone} {
+		if !containsFold(cfg.Detectors, name) {
+			continue
+		}
+		switch name {
+		case DetectorEmail:
+			r.detectors = append(r.detectors, detector{name: name, re: reEmail})
+		case DetectorCard:
+			r.detectors = append(r.detectors, detector{name: name, re: reCard, valid: validCardNumber})
+		case DetectorPhone:
+			r.detectors = append(r.detectors, detector{name: name, re: rePhone, valid: validPhoneNumber})
+		}
+	}
+	for _, d := range cfg.Detectors {
+		if !containsFold([]string{DetectorEmail, DetectorCard, DetectorPhone}, d) {
+			return nil, fmt.Errorf("unknown redaction detector %q", d)
+		}
+	}
+
+	for _, p := range cfg.Patterns {
+		if p.Name == "" || p.Pattern == "" {
+			return nil, fmt.Errorf("redaction pattern needs a name and a pattern")
+		}
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compiling redaction pattern %q: %w", p.Name, err)
+		}
+		r.detectors = append(r.detectors, detector{name: p.Name, re: re})
+	}
+	return r, nil
+}
+
+// redactionSession redacts the texts of a single request, the same value is always replaced with the same placeholder.
+type redactionSession struct {
+	detectors    []detector
+	placeholders map[string]string
+	originals    map[string]string
+	distinct     map[string]int
+	counts       map[string]int
+}
+
+// newSession returns a new redaction session.
+func (r *redactor) newSession() *redactionSession {
+	return &redactionSession{
+		detectors:    r.detectors,
+		placeholders: make(map[string]string),
+		originals:    make(map[string]string),
+		distinct:     make(map[string]int),
+		counts:       make(map[string]int),
+	}
+}
+
+// redact replaces the detected PII in text with placeholders such as [EMAIL_1].
+func (s *redactionSession) redact(text string) string {
+	for _, d := range s.detectors {
+		text = d.re.ReplaceAllStringFunc(text, func(match string) string {
+			if d.valid != nil && !d.valid(match) {
+				return match
+			}
+			s.counts[d.name]++
+			if p, ok := s.placeholders[match]; ok {
+				return p
+			}
+			s.distinct[d.name]++
+			p := fmt.Sprintf("[%s_%d]", placeholderName(d.name), s.distinct[d.name])
+			s.placeholders[match] = p
+			s.originals[p] = match
+			return p
+		})
+	}
+	return text
+}
+
+// restore replaces the placeholders in text with the original values.
+func (s *redactionSession) restore(text string) string {
+	if len(s.originals) == 0 {
+		return text
+	}
+	var pairs = make([]string, 0, len(s.originals)*2)
+	for p, orig := range s.originals {
+		pairs = append(pairs, p, orig)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// redacted returns true if anything was redacted in the session.
+func (s *redactionSession) redacted() bool {
+	return len(s.counts) > 0
+}
+
+// countsJSON returns the number of redacted values per detector as JSON.
+func (s *redactionSession) countsJSON() []byte {
+	b, _ := json.Marshal(s.counts)
+	return b
+}
+
+// GetRedactionLogs returns the redaction audit records, latest first.
+func (m *Manager) GetRedactionLogs(page, pageSize int) ([]models.RedactionLog, error) {
+	var logs = make([]models.RedactionLog, 0)
+	if err := m.q.GetRedactionLogs.Select(&logs, pageSize, (page-1)*pageSize); err != nil {
+		m.lo.Error("error fetching redaction logs", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.activityLog")), nil)
+	}
+	return logs, nil
+}
+
+// redactPayload redacts the prompts in the payload, the returned session is nil if redaction is disabled.
+// An audit record is stored if anything was redacted.
+func (m *Manager) redactPayload(payload PromptPayload) (PromptPayload, *redactionSession) {
+	if m.redactor == nil {
+		return payload, nil
+	}
+	s := m.redactor.newSession()
+	payload.SystemPrompt = s.redact(payload.SystemPrompt)
+	payload.UserPrompt = s.redact(payload.UserPrompt)
+
+	if s.redacted() {
+		if _, err := m.q.InsertRedactionLog.Exec(payload.UserID, payload.PromptKey, s.countsJSON()); err != nil {
+			m.lo.Error("error inserting redaction log", "prompt_key", payload.PromptKey, "error", err)
+		}
+	}
+	return payload, s
+}
+
+// redactTexts redacts texts sent to the provider for embedding, no audit record is stored as the
+// same content is redacted again when it is used in a prompt.
+func (m *Manager) redactTexts(texts []string) []string {
+	if m.redactor == nil {
+		return texts
+	}
+	var (
+		s   = m.redactor.newSession()
+		out = make([]string, len(texts))
+	)
+	for i, t := range texts {
+		out[i] = s.redact(t)
+	}
+	return out
+}
+
+// restoreResponse restores the redacted values in the provider response if enabled.
+func (m *Manager) restoreResponse(response string, s *redactionSession) string {
+	if s == nil || !m.redactor.restore {
+		return response
+	}
+	return s.restore(response)
+}
+
+// validCardNumber returns true if the match has 13 to 19 digits and passes the Luhn check.
+func validCardNumber(s string) bool {
+	digits := onlyDigits(s)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	var sum int
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// validPhoneNumber returns true if the match has 8 to 15 digits and does not look like a date.
+func validPhoneNumber(s string) bool {
+	if reDate.MatchString(s) {
+		return false
+	}
+	n := len(onlyDigits(s))
+	return n >= 8 && n <= 15
+}
+
+// onlyDigits returns the digits in s.
+func onlyDigits(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// placeholderName returns the upper case placeholder name for a detector.
+func placeholderName(name string) string {
+	return strings.Trim(reNonWord.ReplaceAllString(strings.ToUpper(name), "_"), "_")
+}
+
+// containsFold returns true if list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	r, err := newRedactor(models.RedactionConfig{
+		Enabled:   true,
+		Detectors: []string{"card", "email", "phone"},
+		Patterns:  []models.RedactionPattern{{Name: "order id", Pattern: `ORD-\d+`}},
+		Restore:   true,
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "Email",
+			text:     "Reach me at jane.doe+support@example.co.uk please",
+			expected: "Reach me at [EMAIL_1] please",
+		},
+		{
+			name:     "Card number passing Luhn check",
+			text:     "My card is 4111 1111 1111 1111.",
+			expected: "My card is [CARD_1].",
+		},
+		{
+			name:     "Number failing Luhn check is not a card",
+			text:     "Ticket 1234567812345678",
+			expected: "Ticket 1234567812345678",
+		},
+		{
+			name:     "Phone number",
+			text:     "Call +1 (415) 555-2671 after 5",
+			expected: "Call [PHONE_1] after 5",
+		},
+		{
+			name:     "Transcript timestamps are not phone numbers",
+			text:     "[2024-01-15 10:30] Customer: hi",
+			expected: "[2024-01-15 10:30] Customer: hi",
+		},
+		{
+			name:     "Custom pattern",
+			text:     "Order ORD-12345 is late",
+			expected: "Order [ORDER_ID_1] is late",
+		},
+		{
+			name:     "Same value gets the same placeholder",
+			text:     "a@b.io wrote to c@d.io and a@b.io",
+			expected: "[EMAIL_1] wrote to [EMAIL_2] and [EMAIL_1]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := r.newSession()
+			redacted := s.redact(tt.text)
+			assert.Equal(t, tt.expected, redacted)
+			assert.Equal(t, tt.text, s.restore(redacted))
+		})
+	}
+}
+
+func TestRedactCounts(t *testing.T) {
+	r, err := newRedactor(models.RedactionConfig{Enabled: true, Detectors: []string{"email"}})
+	assert.NoError(t, err)
+
+	s := r.newSession()
+	s.redact("a@b.io and a@b.io")
+	assert.True(t, s.redacted())
+	assert.JSONEq(t, `{"email": 2}`, string(s.countsJSON()))
+}
+
+func TestNewRedactor(t *testing.T) {
+	r, err := newRedactor(models.RedactionConfig{Detectors: []string{"email"}})
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+
+	_, err = newRedactor(models.RedactionConfig{Enabled: true, Detectors: []string{"ssn"}})
+	assert.Error(t, err)
+
+	_, err = newRedactor(models.RedactionConfig{Enabled: true, Patterns: []models.RedactionPattern{{Name: "bad", Pattern: "("}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+)
+
+const (
+	// defaultStreamTimeout is the maximum duration of a streamed completion.
+	defaultStreamTimeout = 2 * time.Minute
+	// maxPlaceholderLen is the longest text held back while waiting for a redaction placeholder to complete.
+	maxPlaceholderLen = 64
+)
+
+// errStreamDone is returned by SSE event handlers to stop reading once the provider signals the end of the stream.
+var errStreamDone = errors.New("stream done")
+
+// StreamingProviderClient is implemented by providers that can stream the response as it is generated.
+type StreamingProviderClient interface {
+	ProviderClient
+	// StreamPrompt calls onDelta with each chunk of generated text and returns the full response once done.
+	// The stream is aborted when ctx is cancelled or onDelta returns an error.
+	StreamPrompt(ctx context.Context, payload PromptPayload, onDelta func(string) error) (PromptResponse, error)
+}
+
+// CompletionStream is a completion that is validated and ready to be streamed from the provider.
+type CompletionStream struct {
+	m       *Manager
+	payload PromptPayload
+}
+
+// NewCompletionStream renders the prompt and checks the user's access and budget, so errors are returned before anything is streamed.
+func (m *Manager) NewCompletionStream(k string, prompt string, data any, user umodels.User) (*CompletionStream, error) {
+	payload, err := m.completionPayload(k, prompt, data, user)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkBudget(payload.UserID); err != nil {
+		return nil, err
+	}
+	return &CompletionStream{m: m, payload: payload}, nil
+}
+
+// Run streams the completion and calls onDelta with each chunk of the response. The provider request is cancelled
+// when ctx is done or onDelta returns an error, e.g. when the agent has closed the connection.
+func (s *CompletionStream) Run(ctx context.Context, onDelta func(string) error) error {
+	ctx, cancel := context.WithTimeout(ctx, s.m.streamTimeout)
+	defer cancel()
+	return s.m.streamPrompt(ctx, s.payload, onDelta)
+}
+
+// streamPrompt streams the payload from the default provider. Like sendPrompt the other providers are tried if the
+// default provider fails, but only as long as nothing has been streamed yet. Providers that cannot stream send the full response as a single chunk.
+func (m *Manager) streamPrompt(ctx context.Context, payload PromptPayload, onDelta func(string) error) error {
+	providers, err := m.getProviders()
+	if err != nil {
+		return err
+	}
+	if len(providers) == 0 {
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+
+	payload, redaction := m.redactPayload(payload)
+
+	var (
+		firstErr      error
+		firstProvider models.Provider
+	)
+	for i, p := range providers {
+		config, err := m.parseProviderConfig(p)
+		if err != nil {
+			continue
+		}
+		if i > 0 && !hasCredentials(p, config) {
+			continue
+		}
+
+		client, err := m.newProviderClient(p)
+		if err != nil {
+			continue
+		}
+		if i > 0 {
+			payload.Model = ""
+		}
+
+		var (
+			restorer = m.newStreamRestorer(redaction)
+			streamed bool
+			emit     = func(delta string) error {
+				if delta = restorer.write(delta); delta == "" {
+					return nil
+				}
+				streamed = true
+				return onDelta(delta)
+			}
+			start    = time.Now()
+			response PromptResponse
+		)
+		if sc, ok := client.(StreamingProviderClient); ok {
+			response, err = sc.StreamPrompt(ctx, payload, emit)
+		} else if response, err = client.SendPrompt(ctx, payload); err == nil {
+			err = emit(response.Text)
+		}
+		if err == nil {
+			if rest := restorer.flush(); rest != "" {
+				err = onDelta(rest)
+			}
+		}
+		m.recordUsage(newUsageRecord(payload, p, config, response, time.Since(start), err))
+
+		if err == nil {
+			if i > 0 {
+				m.lo.Warn("prompt streamed by fallback provider", "provider", p.Name)
+			}
+			return nil
+		}
+
+		// Nothing more can be done once the request is cancelled or part of the response has been sent.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if streamed {
+			m.lo.Error("error streaming prompt from provider", "provider", p.Name, "error", err)
+			return m.providerError(ProviderType(p.Provider), err)
+		}
+
+		m.lo.Error("error streaming prompt from provider", "provider", p.Name, "error", err)
+		if firstErr == nil {
+			firstErr, firstProvider = err, p
+		}
+	}
+
+	if firstErr == nil {
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.provider")), nil)
+	}
+	return m.providerError(ProviderType(firstProvider.Provider), firstErr)
+}
+
+// streamRestorer restores redacted values in streamed text. Text that may be the start of a placeholder
+// is held back until the placeholder is complete as placeholders can be split across chunks.
+type streamRestorer struct {
+	session *redactionSession
+	pending string
+}
+
+// newStreamRestorer returns a restorer for the redaction session, text is passed through as is if there is nothing to restore.
+func (m *Manager) newStreamRestorer(s *redactionSession) *streamRestorer {
+	if s == nil || !m.redactor.restore || len(s.originals) == 0 {
+		return &streamRestorer{}
+	}
+	return &streamRestorer{session: s}
+}
+
+// write adds a chunk of streamed text and returns the restored text that is safe to send.
+func (r *streamRestorer) write(delta string) string {
+	if r.session == nil {
+		return delta
+	}
+	r.pending += delta
+
+	cut := len(r.pending)
+	if i := strings.LastIndexByte(r.pending, '['); i >= 0 && !strings.Contains(r.pending[i:], "]") && len(r.pending)-i <= maxPlaceholderLen {
+		cut = i
+	}
+	out := r.pending[:cut]
+	r.pending = r.pending[cut:]
+	return r.session.restore(out)
+}
+
+// flush returns the remaining held back text.
+func (r *streamRestorer) flush() string {
+	if r.session == nil {
+		return ""
+	}
+	out := r.session.restore(r.pending)
+	r.pending = ""
+	return out
+}
+
+// readSSE reads server-sent events from r and calls fn with the event name and data of each event.
+// Reading stops without an error when fn returns errStreamDone.
+func readSSE(r io.Reader, fn func(event, data string) error) error {
+	var (
+		scanner = bufio.NewScanner(r)
+		event   string
+		data    []string
+	)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	dispatch := func() error {
+		if len(data) == 0 {
+			return nil
+		}
+		err := fn(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				if errors.Is(err, errStreamDone) {
+					return nil
+				}
+				return err
+			}
+			event = ""
+		case strings.HasPrefix(line, ":"):
+			// Comment, used by servers as keep-alive.
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := dispatch(); err != nil && !errors.Is(err, errStreamDone) {
+		return err
+	}
+	return nil
+}
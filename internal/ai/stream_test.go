@@ -0,0 +1,148 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/zerodha/logf"
+)
+
+func TestReadSSE(t *testing.T) {
+	type ev struct{ event, data string }
+
+	input := ": keep-alive\n\n" +
+		"event: message_start\ndata: {\"a\":1}\n\n" +
+		"data: line one\ndata: line two\n\n" +
+		"data: [DONE]\n\n" +
+		"data: ignored\n\n"
+
+	var got []ev
+	err := readSSE(strings.NewReader(input), func(event, data string) error {
+		got = append(got, ev{event, data})
+		if data == "[DONE]" {
+			return errStreamDone
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []ev{
+		{"message_start", `{"a":1}`},
+		{"", "line one\nline two"},
+		{"", "[DONE]"},
+	}, got)
+
+	errAbort := errors.New("abort")
+	err = readSSE(strings.NewReader("data: x\n\n"), func(string, string) error { return errAbort })
+	assert.ErrorIs(t, err, errAbort)
+}
+
+func TestStreamRestorer(t *testing.T) {
+	r, err := newRedactor(models.RedactionConfig{Enabled: true, Detectors: []string{"email"}, Restore: true})
+	assert.NoError(t, err)
+	s := r.newSession()
+	s.redact("Mail jane@example.com")
+
+	m := &Manager{redactor: r}
+	restorer := m.newStreamRestorer(s)
+
+	var out strings.Builder
+	for _, delta := range []string{"Hi, I wrote to [EM", "AIL_", "1] and [", "see] ", "[x"} {
+		out.WriteString(restorer.write(delta))
+	}
+	assert.Equal(t, "Hi, I wrote to jane@example.com and [see] ", out.String())
+	assert.Equal(t, "[x", restorer.flush())
+
+	// Without anything to restore the text is passed through as is.
+	restorer = m.newStreamRestorer(r.newSession())
+	assert.Equal(t, "[EM", restorer.write("[EM"))
+	assert.Equal(t, "", restorer.flush())
+}
+
+func TestOpenAIClientStreamPrompt(t *testing.T) {
+	lo := logf.New(logf.Opts{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"model":"gpt-test","choices":[{"delta":{"role":"assistant"}}]}` + "\n\n" +
+			`data: {"model":"gpt-test","choices":[{"delta":{"content":"Hel"}}]}` + "\n\n" +
+			`data: {"model":"gpt-test","choices":[{"delta":{"content":"lo"}}]}` + "\n\n" +
+			`data: {"model":"gpt-test","choices":[],"usage":{"prompt_tokens":9,"completion_tokens":2}}` + "\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(models.ProviderConfig{APIKey: "test-key", BaseURL: srv.URL}, 0, &lo)
+
+	var deltas []string
+	resp, err := client.StreamPrompt(context.Background(), PromptPayload{SystemPrompt: "system", UserPrompt: "user"}, func(d string) error {
+		deltas = append(deltas, d)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Hel", "lo"}, deltas)
+	assert.Equal(t, PromptResponse{Text: "Hello", Model: "gpt-test", InputTokens: 9, OutputTokens: 2}, resp)
+}
+
+func TestClaudeClientStreamPrompt(t *testing.T) {
+	lo := logf.New(logf.Opts{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: message_start\n" + `data: {"type":"message_start","message":{"model":"claude-test","usage":{"input_tokens":11}}}` + "\n\n" +
+			"event: ping\n" + `data: {"type":"ping"}` + "\n\n" +
+			"event: content_block_delta\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}` + "\n\n" +
+			"event: content_block_delta\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" there"}}` + "\n\n" +
+			"event: message_delta\n" + `data: {"type":"message_delta","usage":{"output_tokens":4}}` + "\n\n" +
+			"event: message_stop\n" + `data: {"type":"message_stop"}` + "\n\n"))
+	}))
+	defer srv.Close()
+
+	client := NewClaudeClient(models.ProviderConfig{APIKey: "test-key", BaseURL: srv.URL}, 0, &lo)
+
+	var deltas []string
+	resp, err := client.StreamPrompt(context.Background(), PromptPayload{SystemPrompt: "system", UserPrompt: "user"}, func(d string) error {
+		deltas = append(deltas, d)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Hi", " there"}, deltas)
+	assert.Equal(t, PromptResponse{Text: "Hi there", Model: "claude-test", InputTokens: 11, OutputTokens: 4}, resp)
+}
+
+func TestStreamPromptCancel(t *testing.T) {
+	lo := logf.New(logf.Opts{})
+
+	// The server sends a single chunk and then waits until the client goes away.
+	closed := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`data: {"choices":[{"delta":{"content":"Hi"}}]}` + "\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		close(closed)
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(models.ProviderConfig{APIKey: "test-key", BaseURL: srv.URL}, 0, &lo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := client.StreamPrompt(ctx, PromptPayload{}, func(string) error {
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("provider request was not cancelled")
+	}
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+const (
+	defaultSummaryTokenBudget = 3000
+
+	// summaryPromptKey identifies summary requests in audit records, the summary prompt is built-in.
+	summaryPromptKey = "conversation_summary"
+
+	summarySystemPrompt = `You are a helpful customer support assistant. Summarize the support conversation below for an agent taking over the ticket.
+Include the customer's issue, what has been tried or promised so far and what the next steps are. Lines marked "private note" are internal notes between agents and were never seen by the customer.
+Keep the summary short and factual, use plain text without markdown headings.`
+)
+
+// EstimateTokens returns a rough token count for the text, about four characters per token for English text.
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// BuildConversationContext formats conversation messages as a transcript for the LLM.
+// Messages are expected newest first, as returned by `GetConversationMessages`. Activity messages are skipped
+// and private notes are marked. Oldest messages are dropped once the token budget is exhausted.
+// The returned transcript is in chronological order, ok is false if no messages fit the budget.
+func BuildConversationContext(messages []cmodels.Message, tokenBudget int) (string, bool) {
+	var (
+		lines  = make([]string, 0, len(messages))
+		tokens = 0
+	)
+	for _, msg := range messages {
+		if msg.Type == cmodels.MessageActivity {
+			continue
+		}
+		text := strings.TrimSpace(msg.TextContent)
+		if text == "" {
+			continue
+		}
+
+		var sender string
+		switch {
+		case msg.Private:
+			sender = "Agent (private note)"
+		case msg.SenderType == cmodels.SenderTypeContact:
+			sender = "Customer"
+		default:
+			sender = "Agent"
+		}
+		line := fmt.Sprintf("[%s] %s: %s", msg.CreatedAt.Format("2006-01-02 15:04"), sender, text)
+
+		n := EstimateTokens(line)
+		if tokens+n > tokenBudget {
+			break
+		}
+		tokens += n
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return "", false
+	}
+	slices.Reverse(lines)
+	return strings.Join(lines, "\n\n"), true
+}
+
+// SummarizeConversation summarizes the passed conversation messages for the user using the default provider.
+func (m *Manager) SummarizeConversation(messages []cmodels.Message, userID int) (string, error) {
+	transcript, ok := BuildConversationContext(messages, m.summaryTokenBudget)
+	if !ok {
+		return "", envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.empty", "name", m.i18n.Ts("globals.terms.message")), nil)
+	}
+	return m.sendPrompt(context.Background(), PromptPayload{
+		SystemPrompt: summarySystemPrompt,
+		UserPrompt:   transcript,
+		PromptKey:    summaryPromptKey,
+		UserID:       userID,
+	})
+}
+
+// SummaryTokenBudget returns the token budget for conversation context sent for summarization.
+func (m *Manager) SummaryTokenBudget() int {
+	return m.summaryTokenBudget
+}
@@ -0,0 +1,46 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildConversationContext(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// Newest first, as returned by GetConversationMessages.
+	messages := []cmodels.Message{
+		{Type: cmodels.MessageOutgoing, SenderType: cmodels.SenderTypeAgent, Private: true, TextContent: "Escalating to billing", CreatedAt: base.Add(3 * time.Minute)},
+		{Type: cmodels.MessageActivity, SenderType: cmodels.SenderTypeAgent, TextContent: "Status changed to Open", CreatedAt: base.Add(2 * time.Minute)},
+		{Type: cmodels.MessageOutgoing, SenderType: cmodels.SenderTypeAgent, TextContent: "Looking into it", CreatedAt: base.Add(time.Minute)},
+		{Type: cmodels.MessageIncoming, SenderType: cmodels.SenderTypeContact, TextContent: "I was charged twice", CreatedAt: base},
+	}
+
+	t.Run("Full context", func(t *testing.T) {
+		out, ok := BuildConversationContext(messages, 1000)
+		assert.True(t, ok)
+		assert.NotContains(t, out, "Status changed")
+
+		lines := strings.Split(out, "\n\n")
+		assert.Len(t, lines, 3)
+		assert.Contains(t, lines[0], "Customer: I was charged twice")
+		assert.Contains(t, lines[1], "Agent: Looking into it")
+		assert.Contains(t, lines[2], "Agent (private note): Escalating to billing")
+	})
+
+	t.Run("Budget drops oldest messages", func(t *testing.T) {
+		newest := "[2025-01-01 10:03] Agent (private note): Escalating to billing"
+		out, ok := BuildConversationContext(messages, EstimateTokens(newest))
+		assert.True(t, ok)
+		assert.Equal(t, newest, out)
+	})
+
+	t.Run("Nothing fits", func(t *testing.T) {
+		_, ok := BuildConversationContext(messages, 1)
+		assert.False(t, ok)
+	})
+}
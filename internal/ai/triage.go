@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+)
+
+const (
+	defaultTriageTimeout  = 15 * time.Second
+	defaultTriageCacheTTL = time.Hour
+	maxTriageCacheSize    = 1000
+	maxTriageContentLen   = 8000
+
+	// triagePromptKey identifies triage requests in audit records, the triage prompt is built-in.
+	triagePromptKey = "ai_triage"
+
+	triageSystemPrompt = `You classify customer support conversations. Choose exactly one label for each dimension from the allowed labels below, or an empty string if none fits.
+Respond only with a JSON object of the form {"category": "", "urgency": "", "sentiment": ""} and nothing else.
+`
+)
+
+var ErrTriageTimeout = errors.New("triage timed out")
+
+type triageCacheEntry struct {
+	result  models.TriageResult
+	expires time.Time
+}
+
+// triageCache is an in-memory cache of triage results keyed by the hash of the content and taxonomy.
+type triageCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]triageCacheEntry
+}
+
+func (c *triageCache) get(key string) (models.TriageResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return models.TriageResult{}, false
+	}
+	return e.result, true
+}
+
+func (c *triageCache) set(key string, r models.TriageResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxTriageCacheSize {
+		now := time.Now()
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		// Still full, start over.
+		if len(c.entries) >= maxTriageCacheSize {
+			c.entries = make(map[string]triageCacheEntry)
+		}
+	}
+	c.entries[key] = triageCacheEntry{result: r, expires: time.Now().Add(c.ttl)}
+}
+
+// Triage classifies the content into the passed taxonomy using the default provider.
+// Results are cached, and ErrTriageTimeout is returned if the provider does not respond within the triage timeout.
+func (m *Manager) Triage(content string, taxonomy models.TriageTaxonomy) (models.TriageResult, error) {
+	if len(content) > maxTriageContentLen {
+		content = content[:maxTriageContentLen]
+	}
+
+	b, err := json.Marshal(taxonomy)
+	if err != nil {
+		return models.TriageResult{}, err
+	}
+	sum := sha256.Sum256(append(b, content...))
+	key := hex.EncodeToString(sum[:])
+	if r, ok := m.triageCache.get(key); ok {
+		return r, nil
+	}
+
+	// The provider request is cancelled once the timeout passes.
+	ctx, cancel := context.WithTimeout(context.Background(), m.triageTimeout)
+	defer cancel()
+
+	resp, err := m.sendPrompt(ctx, PromptPayload{
+		SystemPrompt: buildTriagePrompt(taxonomy),
+		UserPrompt:   content,
+		PromptKey:    triagePromptKey,
+	})
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return models.TriageResult{}, ErrTriageTimeout
+		}
+		return models.TriageResult{}, err
+	}
+
+	r, err := parseTriageResponse(resp, taxonomy)
+	if err != nil {
+		return models.TriageResult{}, err
+	}
+	m.triageCache.set(key, r)
+	return r, nil
+}
+
+// buildTriagePrompt returns the system prompt listing the allowed labels of each dimension.
+func buildTriagePrompt(taxonomy models.TriageTaxonomy) string {
+	var sb strings.Builder
+	sb.WriteString(triageSystemPrompt)
+	writeLabels := func(dimension string, labels []models.TriageLabel) {
+		if len(labels) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "\nAllowed %s labels:\n", dimension)
+		for _, l := range labels {
+			if l.Description != "" {
+				fmt.Fprintf(&sb, "- %s: %s\n", l.Name, l.Description)
+			} else {
+				fmt.Fprintf(&sb, "- %s\n", l.Name)
+			}
+		}
+	}
+	writeLabels("category", taxonomy.Categories)
+	writeLabels("urgency", taxonomy.Urgencies)
+	writeLabels("sentiment", taxonomy.Sentiments)
+	return sb.String()
+}
+
+// parseTriageResponse extracts the JSON object from the response and maps each value to a taxonomy label.
+// Values not in the taxonomy are dropped.
+func parseTriageResponse(resp string, taxonomy models.TriageTaxonomy) (models.TriageResult, error) {
+	start, end := strings.Index(resp, "{"), strings.LastIndex(resp, "}")
+	if start < 0 || end < start {
+		return models.TriageResult{}, fmt.Errorf("no JSON object in triage response: %q", resp)
+	}
+
+	var raw models.TriageResult
+	if err := json.Unmarshal([]byte(resp[start:end+1]), &raw); err != nil {
+		return models.TriageResult{}, fmt.Errorf("decoding triage response: %w", err)
+	}
+
+	return models.TriageResult{
+		Category:  matchLabel(raw.Category, taxonomy.Categories),
+		Urgency:   matchLabel(raw.Urgency, taxonomy.Urgencies),
+		Sentiment: matchLabel(raw.Sentiment, taxonomy.Sentiments),
+	}, nil
+}
+
+// matchLabel returns the label name matching v case-insensitively, or an empty string.
+func matchLabel(v string, labels []models.TriageLabel) string {
+	v = strings.TrimSpace(v)
+	for _, l := range labels {
+		if v != "" && strings.EqualFold(l.Name, v) {
+			return l.Name
+		}
+	}
+	return ""
+}
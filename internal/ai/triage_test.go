@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"database/sql/driver"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	"github.com/knadh/go-i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodha/logf"
+)
+
+func TestParseTriageResponse(t *testing.T) {
+	taxonomy := models.TriageTaxonomy{
+		Categories: []models.TriageLabel{{Name: "Billing"}, {Name: "Technical"}},
+		Urgencies:  []models.TriageLabel{{Name: "high"}, {Name: "low"}},
+		Sentiments: []models.TriageLabel{{Name: "negative"}},
+	}
+
+	tests := []struct {
+		name        string
+		resp        string
+		expected    models.TriageResult
+		expectError bool
+	}{
+		{
+			name:     "Plain JSON",
+			resp:     `{"category": "Billing", "urgency": "high", "sentiment": "negative"}`,
+			expected: models.TriageResult{Category: "Billing", Urgency: "high", Sentiment: "negative"},
+		},
+		{
+			name:     "Fenced JSON with different case",
+			resp:     "```json\n{\"category\": \"technical\", \"urgency\": \"LOW\", \"sentiment\": \"\"}\n```",
+			expected: models.TriageResult{Category: "Technical", Urgency: "low"},
+		},
+		{
+			name:     "Unknown labels are dropped",
+			resp:     `{"category": "Sales", "urgency": "high", "sentiment": "positive"}`,
+			expected: models.TriageResult{Urgency: "high"},
+		},
+		{
+			name:        "No JSON",
+			resp:        "I cannot classify this.",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := parseTriageResponse(tt.resp, taxonomy)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, r)
+		})
+	}
+}
+
+func TestTriageTimeout(t *testing.T) {
+	var (
+		requests  atomic.Int32
+		cancelled = make(chan struct{}, 2)
+	)
+	// The provider never responds, the request must be cancelled when the triage times out.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// The connection is only watched for closing once the body is read.
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+			cancelled <- struct{}{}
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	config := `{"api_key": "key", "base_url": "` + srv.URL + `"}`
+	db := dbtest.New(func(query string, args []driver.Value) (dbtest.Result, error) {
+		if strings.Contains(query, "FROM ai_providers") {
+			return dbtest.Result{
+				Columns: []string{"id", "name", "provider", "config", "is_default"},
+				Rows:    [][]any{{1, "openai", "openai", config, true}, {2, "fallback", "openai", config, false}},
+			}, nil
+		}
+		return dbtest.Result{}, nil
+	})
+	var q queries
+	require.NoError(t, dbutil.ScanSQLFile("queries.sql", &q, db.DB, efs))
+	i18n, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	require.NoError(t, err)
+	lo := logf.New(logf.Opts{})
+	m := &Manager{q: q, db: db.DB, lo: &lo, i18n: i18n, triageTimeout: 50 * time.Millisecond, triageCache: &triageCache{entries: map[string]triageCacheEntry{}}}
+
+	start := time.Now()
+	_, err = m.Triage("My invoice is wrong", models.TriageTaxonomy{Categories: []models.TriageLabel{{Name: "Billing"}}})
+	assert.ErrorIs(t, err, ErrTriageTimeout)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("provider request was not cancelled")
+	}
+	// The fallback provider is not tried after the deadline.
+	assert.EqualValues(t, 1, requests.Load())
+}
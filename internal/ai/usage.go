@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/ai/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+const (
+	UsageGroupUser      = "user"
+	UsageGroupPromptKey = "prompt_key"
+	UsageGroupProvider  = "provider"
+	UsageGroupModel     = "model"
+	UsageGroupDay       = "day"
+)
+
+// usageGroups are the dimensions the usage report can be grouped by.
+var usageGroups = []string{UsageGroupUser, UsageGroupPromptKey, UsageGroupProvider, UsageGroupModel, UsageGroupDay}
+
+// usageRecord is a single provider call recorded for metering.
+type usageRecord struct {
+	userID       int
+	promptKey    string
+	provider     string
+	model        string
+	inputTokens  int
+	outputTokens int
+	latency      time.Duration
+	success      bool
+}
+
+// newUsageRecord returns the usage record of a provider call. The model reported by the provider is
+// preferred, falling back to the requested model and then the provider's configured model.
+func newUsageRecord(payload PromptPayload, p models.Provider, config models.ProviderConfig, response PromptResponse, latency time.Duration, err error) usageRecord {
+	u := usageRecord{
+		userID:       payload.UserID,
+		promptKey:    payload.PromptKey,
+		provider:     p.Name,
+		model:        response.Model,
+		inputTokens:  response.InputTokens,
+		outputTokens: response.OutputTokens,
+		latency:      latency,
+		success:      err == nil,
+	}
+	if u.model == "" {
+		u.model = payload.Model
+	}
+	if u.model == "" {
+		u.model = config.Model
+	}
+	return u
+}
+
+// GetUsageSummary returns the AI usage between from and to grouped by groupBy, teamID optionally limits the usage to the members of a team.
+func (m *Manager) GetUsageSummary(from, to time.Time, groupBy string, teamID int) ([]models.UsageSummary, error) {
+	if !slices.Contains(usageGroups, groupBy) {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`group_by`"), nil)
+	}
+	if !from.Before(to) {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`from`"), nil)
+	}
+
+	var summary = make([]models.UsageSummary, 0)
+	if err := m.q.GetUsageSummary.Select(&summary, from, to, groupBy, teamID); err != nil {
+		m.lo.Error("error fetching AI usage", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.usage")), nil)
+	}
+	return summary, nil
+}
+
+// GetBudgets returns all budgets along with the tokens used in the current month.
+func (m *Manager) GetBudgets() ([]models.Budget, error) {
+	return m.getBudgets(0)
+}
+
+// CreateBudget creates a monthly token budget for a team or a role, exactly one of teamID and roleID must be set.
+func (m *Manager) CreateBudget(teamID, roleID int, limit int64) (models.Budget, error) {
+	if (teamID > 0) == (roleID > 0) {
+		return models.Budget{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`team_id`, `role_id`"), nil)
+	}
+	if limit <= 0 {
+		return models.Budget{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`monthly_token_limit`"), nil)
+	}
+
+	var id int
+	if err := m.q.InsertBudget.Get(&id, teamID, roleID, limit); err != nil {
+		if dbutil.IsUniqueViolationError(err) {
+			return models.Budget{}, envelope.NewError(envelope.ConflictError, m.i18n.Ts("globals.messages.errorAlreadyExists", "name", m.i18n.Ts("globals.terms.budget")), nil)
+		}
+		m.lo.Error("error inserting AI budget", "error", err)
+		return models.Budget{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", m.i18n.Ts("globals.terms.budget")), nil)
+	}
+	return m.getBudget(id)
+}
+
+// UpdateBudget updates the monthly token limit of a budget.
+func (m *Manager) UpdateBudget(id int, limit int64) (models.Budget, error) {
+	if limit <= 0 {
+		return models.Budget{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`monthly_token_limit`"), nil)
+	}
+	res, err := m.q.UpdateBudget.Exec(id, limit)
+	if err != nil {
+		m.lo.Error("error updating AI budget", "id", id, "error", err)
+		return models.Budget{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", m.i18n.Ts("globals.terms.budget")), nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Budget{}, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.budget")), nil)
+	}
+	return m.getBudget(id)
+}
+
+// DeleteBudget deletes a budget.
+func (m *Manager) DeleteBudget(id int) error {
+	res, err := m.q.DeleteBudget.Exec(id)
+	if err != nil {
+		m.lo.Error("error deleting AI budget", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorDeleting", "name", m.i18n.Ts("globals.terms.budget")), nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.budget")), nil)
+	}
+	return nil
+}
+
+// getBudget returns a budget by ID.
+func (m *Manager) getBudget(id int) (models.Budget, error) {
+	budgets, err := m.getBudgets(0)
+	if err != nil {
+		return models.Budget{}, err
+	}
+	for _, b := range budgets {
+		if b.ID == id {
+			return b, nil
+		}
+	}
+	return models.Budget{}, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", m.i18n.Ts("globals.terms.budget")), nil)
+}
+
+// getBudgets returns the budgets that apply to the user, all budgets if userID is 0.
+func (m *Manager) getBudgets(userID int) ([]models.Budget, error) {
+	var budgets = make([]models.Budget, 0)
+	if err := m.q.GetBudgets.Select(&budgets, userID); err != nil {
+		m.lo.Error("error fetching AI budgets", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.Ts("globals.terms.budget")), nil)
+	}
+	return budgets, nil
+}
+
+// checkBudget returns an error if any budget of the user's teams or roles is used up for the month.
+// Requests made by the system, e.g. automation rules, are not limited.
+func (m *Manager) checkBudget(userID int) error {
+	if userID == 0 {
+		return nil
+	}
+	budgets, err := m.getBudgets(userID)
+	if err != nil {
+		return err
+	}
+	for _, b := range budgets {
+		if b.Exceeded() {
+			return envelope.NewError(envelope.PermissionError, m.i18n.Ts("ai.budgetExceeded", "name", b.ScopeName, "limit", strconv.FormatInt(b.MonthlyTokenLimit, 10)), nil)
+		}
+	}
+	return nil
+}
+
+// recordUsage stores a provider call, errors are only logged as metering must not fail the request.
+func (m *Manager) recordUsage(u usageRecord) {
+	if _, err := m.q.InsertUsage.Exec(u.userID, u.promptKey, u.provider, u.model, u.inputTokens, u.outputTokens, u.latency.Milliseconds(), u.success); err != nil {
+		m.lo.Error("error recording AI usage", "prompt_key", u.promptKey, "provider", u.provider, "error", err)
+	}
+}
@@ -8,13 +8,17 @@ import (
 	"embed"
 	"encoding/json"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
+	aimodels "github.com/abhinavxd/libredesk/internal/ai/models"
 	"github.com/abhinavxd/libredesk/internal/automation/models"
 	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	pmodels "github.com/abhinavxd/libredesk/internal/conversation/priority/models"
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
 	"github.com/jmoiron/sqlx"
 	"github.com/knadh/go-i18n"
@@ -52,6 +56,8 @@ type Engine struct {
 	lo                *logf.Logger
 	i18n              *i18n.I18n
 	conversationStore conversationStore
+	priorityStore     priorityStore
+	teamStore         teamStore
 	taskQueue         chan ConversationTask
 	closed            bool
 	closedMu          sync.RWMutex
@@ -70,6 +76,14 @@ type conversationStore interface {
 	GetConversationsCreatedAfter(time.Time) ([]cmodels.Conversation, error)
 }
 
+type priorityStore interface {
+	Get(int) (pmodels.Priority, error)
+}
+
+type teamStore interface {
+	Get(int) (tmodels.Team, error)
+}
+
 type queries struct {
 	GetAll                  *sqlx.Stmt `query:"get-all"`
 	GetRule                 *sqlx.Stmt `query:"get-rule"`
@@ -83,13 +97,15 @@ type queries struct {
 }
 
 // New initializes a new Engine.
-func New(opt Opts) (*Engine, error) {
+func New(opt Opts, priorityStore priorityStore, teamStore teamStore) (*Engine, error) {
 	var (
 		q queries
 		e = &Engine{
-			lo:        opt.Lo,
-			i18n:      opt.I18n,
-			taskQueue: make(chan ConversationTask, MaxQueueSize),
+			lo:            opt.Lo,
+			i18n:          opt.I18n,
+			priorityStore: priorityStore,
+			teamStore:     teamStore,
+			taskQueue:     make(chan ConversationTask, MaxQueueSize),
 		}
 	)
 	if err := dbutil.ScanSQLFile("queries.sql", &q, opt.DB, efs); err != nil {
@@ -211,6 +227,9 @@ func (e *Engine) ToggleRule(id int) (models.RuleRecord, error) {
 
 // UpdateRule updates an existing rule.
 func (e *Engine) UpdateRule(id int, rule models.RuleRecord) (models.RuleRecord, error) {
+	if err := e.validateRule(rule); err != nil {
+		return models.RuleRecord{}, err
+	}
 	if rule.Events == nil {
 		rule.Events = pq.StringArray{}
 	}
@@ -226,6 +245,9 @@ func (e *Engine) UpdateRule(id int, rule models.RuleRecord) (models.RuleRecord,
 
 // CreateRule creates a new rule.
 func (e *Engine) CreateRule(rule models.RuleRecord) (models.RuleRecord, error) {
+	if err := e.validateRule(rule); err != nil {
+		return models.RuleRecord{}, err
+	}
 	if rule.Events == nil {
 		rule.Events = pq.StringArray{}
 	}
@@ -239,6 +261,77 @@ func (e *Engine) CreateRule(rule models.RuleRecord) (models.RuleRecord, error) {
 	return result, nil
 }
 
+// validateRule validates the rule conditions and actions. New conversation rules cannot match tags as tags are added after the conversation is created.
+func (e *Engine) validateRule(rule models.RuleRecord) error {
+	if len(rule.Rules) == 0 {
+		return nil
+	}
+	var rules []models.Rule
+	if err := json.Unmarshal(rule.Rules, &rules); err != nil {
+		return envelope.NewError(envelope.InputError, e.i18n.Ts("globals.messages.invalid", "name", "`rules`"), nil)
+	}
+	for _, r := range rules {
+		if rule.Type == models.RuleTypeNewConversation {
+			for _, group := range r.Groups {
+				for _, d := range group.Rules {
+					if d.FieldType == models.FieldTypeConversationField && d.Field == models.ConversationTags {
+						return envelope.NewError(envelope.InputError, e.i18n.T("automation.tagsOnNewConversation"), nil)
+					}
+				}
+			}
+		}
+		for _, action := range r.Actions {
+			if action.Type != models.ActionAITriage {
+				continue
+			}
+			if len(action.Value) != 1 {
+				return envelope.NewError(envelope.InputError, e.i18n.T("automation.triage.invalidTaxonomy"), nil)
+			}
+			if err := e.validateTriageTaxonomy(action.Value[0]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateTriageTaxonomy validates the taxonomy of an AI triage action, so that mistakes are reported when the rule
+// is saved and not when the action runs on new conversations.
+func (e *Engine) validateTriageTaxonomy(value string) error {
+	var taxonomy aimodels.TriageTaxonomy
+	if err := json.Unmarshal([]byte(value), &taxonomy); err != nil {
+		return envelope.NewError(envelope.InputError, e.i18n.T("automation.triage.invalidTaxonomy"), nil)
+	}
+	if len(taxonomy.Categories)+len(taxonomy.Urgencies)+len(taxonomy.Sentiments) == 0 {
+		return envelope.NewError(envelope.InputError, e.i18n.T("automation.triage.emptyTaxonomy"), nil)
+	}
+	for _, labels := range [][]aimodels.TriageLabel{taxonomy.Categories, taxonomy.Urgencies, taxonomy.Sentiments} {
+		names := make(map[string]bool, len(labels))
+		for _, l := range labels {
+			name := strings.TrimSpace(l.Name)
+			if name == "" || names[name] {
+				return envelope.NewError(envelope.InputError, e.i18n.Ts("automation.triage.invalidLabel", "name", l.Name), nil)
+			}
+			names[name] = true
+
+			if l.PriorityID < 0 || l.TeamID < 0 {
+				return envelope.NewError(envelope.InputError, e.i18n.Ts("automation.triage.invalidLabel", "name", l.Name), nil)
+			}
+			if l.PriorityID > 0 && e.priorityStore != nil {
+				if _, err := e.priorityStore.Get(l.PriorityID); err != nil {
+					return envelope.NewError(envelope.InputError, e.i18n.Ts("automation.triage.unknownPriority", "name", l.Name), nil)
+				}
+			}
+			if l.TeamID > 0 && e.teamStore != nil {
+				if _, err := e.teamStore.Get(l.TeamID); err != nil {
+					return envelope.NewError(envelope.InputError, e.i18n.Ts("automation.triage.unknownTeam", "name", l.Name), nil)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // DeleteRule deletes a rule by ID.
 func (e *Engine) DeleteRule(id int) error {
 	if _, err := e.q.DeleteRule.Exec(id); err != nil {
@@ -0,0 +1,126 @@
+package automation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/automation/models"
+	pmodels "github.com/abhinavxd/libredesk/internal/conversation/priority/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
+	"github.com/knadh/go-i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePriorityStore map[int]bool
+
+func (s fakePriorityStore) Get(id int) (pmodels.Priority, error) {
+	if !s[id] {
+		return pmodels.Priority{}, errors.New("priority not found")
+	}
+	return pmodels.Priority{ID: id}, nil
+}
+
+type fakeTeamStore map[int]bool
+
+func (s fakeTeamStore) Get(id int) (tmodels.Team, error) {
+	if !s[id] {
+		return tmodels.Team{}, errors.New("team not found")
+	}
+	return tmodels.Team{ID: id}, nil
+}
+
+func assertInputError(t *testing.T, err error) {
+	t.Helper()
+	var eerr envelope.Error
+	require.True(t, errors.As(err, &eerr), err)
+	assert.Equal(t, envelope.InputError, eerr.ErrorType)
+}
+
+func TestValidateRule(t *testing.T) {
+	i18n, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	require.NoError(t, err)
+	engine := createTestEngine(new(mockConversationStore))
+	engine.i18n = i18n
+
+	tagsRule := func(ruleType string) models.RuleRecord {
+		rules, err := json.Marshal([]models.Rule{{
+			GroupOperator: models.OperatorAnd,
+			Groups: []models.RuleGroup{
+				{LogicalOp: models.OperatorAnd, Rules: []models.RuleDetail{
+					{Field: models.ConversationInbox, FieldType: models.FieldTypeConversationField, Operator: models.RuleOperatorEquals, Value: "1"},
+				}},
+				{LogicalOp: models.OperatorOR, Rules: []models.RuleDetail{
+					{Field: models.ConversationTags, FieldType: models.FieldTypeConversationField, Operator: models.RuleOperatorContains, Value: "vip"},
+				}},
+			},
+		}})
+		require.NoError(t, err)
+		return models.RuleRecord{Type: ruleType, Rules: rules}
+	}
+
+	// Conversations have no tags when they are created.
+	assertInputError(t, engine.validateRule(tagsRule(models.RuleTypeNewConversation)))
+
+	assert.NoError(t, engine.validateRule(tagsRule(models.RuleTypeConversationUpdate)))
+	assert.NoError(t, engine.validateRule(tagsRule(models.RuleTypeTimeTrigger)))
+
+	// A custom attribute that happens to be named tags is allowed.
+	rule := tagsRule(models.RuleTypeNewConversation)
+	rule.Rules = json.RawMessage(`[{"groups": [{"logical_op": "AND", "rules": [{"field": "tags", "field_type": "contact_custom_attribute", "operator": "equals", "value": "vip"}]}]}]`)
+	assert.NoError(t, engine.validateRule(rule))
+
+	rule.Rules = json.RawMessage(`{`)
+	assert.Error(t, engine.validateRule(rule))
+}
+
+func TestValidateRuleTriageTaxonomy(t *testing.T) {
+	i18n, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	require.NoError(t, err)
+	engine := createTestEngine(new(mockConversationStore))
+	engine.i18n = i18n
+	engine.priorityStore = fakePriorityStore{1: true}
+	engine.teamStore = fakeTeamStore{2: true}
+
+	triageRule := func(value ...string) models.RuleRecord {
+		rules, err := json.Marshal([]models.Rule{{
+			Actions: []models.RuleAction{
+				{Type: models.ActionAddTags, Value: []string{"new"}},
+				{Type: models.ActionAITriage, Value: value},
+			},
+		}})
+		require.NoError(t, err)
+		return models.RuleRecord{Type: models.RuleTypeNewConversation, Rules: rules}
+	}
+
+	assert.NoError(t, engine.validateRule(triageRule(`{
+		"categories": [{"name": "Billing", "tags": ["billing"], "team_id": 2}, {"name": "Bug"}],
+		"urgencies": [{"name": "Urgent", "priority_id": 1}],
+		"attributes": {"category": "category"}
+	}`)))
+
+	tests := []struct {
+		name  string
+		value []string
+	}{
+		{"no value", nil},
+		{"not JSON", []string{`{"categories": [`}},
+		{"not an object", []string{`["Billing"]`}},
+		{"no labels", []string{`{"categories": [], "attributes": {"category": "category"}}`}},
+		{"label without a name", []string{`{"categories": [{"name": " ", "tags": ["billing"]}]}`}},
+		{"duplicate label", []string{`{"categories": [{"name": "Billing"}, {"name": "Billing"}]}`}},
+		{"unknown priority", []string{`{"urgencies": [{"name": "Urgent", "priority_id": 3}]}`}},
+		{"unknown team", []string{`{"categories": [{"name": "Billing", "team_id": 3}]}`}},
+		{"negative team", []string{`{"categories": [{"name": "Billing", "team_id": -1}]}`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertInputError(t, engine.validateRule(triageRule(tt.value...)))
+		})
+	}
+
+	// Labels in different groups can share a name.
+	assert.NoError(t, engine.validateRule(triageRule(`{"urgencies": [{"name": "High"}], "sentiments": [{"name": "High"}]}`)))
+}
@@ -25,18 +25,7 @@ func (e *Engine) evalConversationRules(rules []models.Rule, conversation cmodels
 			continue
 		}
 
-		var groupEvalResults []bool
-		for idx, group := range rule.Groups {
-			if len(group.Rules) == 0 {
-				e.lo.Debug("no rules found in group, skipping rule group evaluation", "group_num", idx+1, "conversation_uuid", conversation.UUID)
-				continue
-			}
-			result := e.evaluateGroup(group.Rules, group.LogicalOp, conversation)
-			e.lo.Debug("group rule evaluation complete", "logical_op", group.LogicalOp, "result", result, "conversation_uuid", conversation.UUID)
-			groupEvalResults = append(groupEvalResults, result)
-		}
-
-		if evaluateFinalResult(groupEvalResults, rule.GroupOperator) {
+		if e.EvaluateConditions(rule.Groups, rule.GroupOperator, conversation) {
 			e.lo.Debug("all rules within groups evaluated successfully, executing actions", "conversation_uuid", conversation.UUID)
 			for _, action := range rule.Actions {
 				if err := e.conversationStore.ApplyAction(action, conversation, umodels.User{}); err != nil {
@@ -48,11 +37,29 @@ func (e *Engine) evalConversationRules(rules []models.Rule, conversation cmodels
 				break
 			}
 		} else {
-			e.lo.Debug("rule evaluation failed, skipping actions", "group_eval_results", groupEvalResults, "conversation_uuid", conversation.UUID)
+			e.lo.Debug("rule evaluation failed, skipping actions", "conversation_uuid", conversation.UUID)
 		}
 	}
 }
 
+// EvaluateConditions evaluates rule groups against a conversation and combines the group results with the group operator (AND/OR).
+// Groups without rules are skipped, this lets other packages such as SLA policies reuse the automation conditions.
+func (e *Engine) EvaluateConditions(groups []models.RuleGroup, groupOperator string, conversation cmodels.Conversation) bool {
+	var groupEvalResults []bool
+	for idx, group := range groups {
+		if len(group.Rules) == 0 {
+			e.lo.Debug("no rules found in group, skipping rule group evaluation", "group_num", idx+1, "conversation_uuid", conversation.UUID)
+			continue
+		}
+		result := e.evaluateGroup(group.Rules, group.LogicalOp, conversation)
+		e.lo.Debug("group rule evaluation complete", "logical_op", group.LogicalOp, "result", result, "conversation_uuid", conversation.UUID)
+		groupEvalResults = append(groupEvalResults, result)
+	}
+	result := evaluateFinalResult(groupEvalResults, groupOperator)
+	e.lo.Debug("conditions evaluated", "group_eval_results", groupEvalResults, "result", result, "conversation_uuid", conversation.UUID)
+	return result
+}
+
 // evaluateFinalResult computes the final result of multiple group evaluations
 // based on the specified logical operator (AND/OR).
 func evaluateFinalResult(results []bool, operator string) bool {
@@ -119,6 +126,11 @@ func (e *Engine) evaluateRule(rule models.RuleDetail, conversation cmodels.Conve
 	e.lo.Debug("evaluating rule", "rule_field", rule.Field, "field_type", rule.FieldType, "rule_operator", rule.Operator,
 		"rule_value", rule.Value, "conversation_uuid", conversation.UUID)
 
+	// Tags are a list and are matched as whole tag names.
+	if rule.FieldType == models.FieldTypeConversationField && rule.Field == models.ConversationTags {
+		return e.evaluateTagsRule(rule, conversation)
+	}
+
 	// Extract the value from the conversation based on the rule's field
 	if rule.FieldType == models.FieldTypeConversationField {
 		switch rule.Field {
@@ -294,3 +306,47 @@ func (e *Engine) evaluateRule(rule models.RuleDetail, conversation cmodels.Conve
 	e.lo.Debug("conversation automation rule status", "has_met", conditionMet, "conversation_uuid", conversation.UUID)
 	return conditionMet
 }
+
+// evaluateTagsRule evaluates a rule on the conversation tags, `contains` matches if the conversation has any of the
+// comma separated tags and `not contains` if it has none of them.
+func (e *Engine) evaluateTagsRule(rule models.RuleDetail, conversation cmodels.Conversation) bool {
+	var tags []string
+	if conversation.Tags.Valid {
+		if err := json.Unmarshal(conversation.Tags.JSON, &tags); err != nil {
+			e.lo.Error("error unmarshalling conversation tags", "conversation_uuid", conversation.UUID, "error", err)
+			return false
+		}
+	}
+
+	hasTag := func(name string) bool {
+		for _, tag := range tags {
+			if tag == name || (!rule.CaseSensitiveMatch && strings.EqualFold(tag, name)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var conditionMet bool
+	switch rule.Operator {
+	case models.RuleOperatorContains, models.RuleOperatorNotContains:
+		for _, name := range strings.Split(rule.Value, ",") {
+			if name = strings.TrimSpace(name); name != "" && hasTag(name) {
+				conditionMet = true
+				break
+			}
+		}
+		if rule.Operator == models.RuleOperatorNotContains {
+			conditionMet = !conditionMet
+		}
+	case models.RuleOperatorSet:
+		conditionMet = len(tags) > 0
+	case models.RuleOperatorNotSet:
+		conditionMet = len(tags) == 0
+	default:
+		e.lo.Error("error unsupported operator for tags", "operator", rule.Operator)
+		return false
+	}
+	e.lo.Debug("conversation automation rule status", "has_met", conditionMet, "conversation_uuid", conversation.UUID)
+	return conditionMet
+}
@@ -1198,4 +1198,66 @@ func TestComplexRealWorldScenario(t *testing.T) {
 	assert.Equal(t, 2, mockStore.callCount, "Complex conditions met, both actions should trigger")
 	assert.Equal(t, models.ActionSendCSAT, mockStore.appliedActions[0].Type)
 	assert.Equal(t, models.ActionSetTags, mockStore.appliedActions[1].Type)
-}
\ No newline at end of file
+}
+
+// Test: Tags are matched as whole tag names
+func TestTagsField(t *testing.T) {
+	engine := createTestEngine(new(mockConversationStore))
+
+	conversation := createTestConversation(func(c *cmodels.Conversation) {
+		c.Tags = null.JSONFrom([]byte(`["VIP-lite", "billing"]`))
+	})
+	untagged := createTestConversation()
+
+	tests := []struct {
+		name         string
+		rule         models.RuleDetail
+		conversation cmodels.Conversation
+		expected     bool
+	}{
+		{"contains any", models.RuleDetail{Operator: models.RuleOperatorContains, Value: "vip, billing"}, conversation, true},
+		{"contains partial name", models.RuleDetail{Operator: models.RuleOperatorContains, Value: "vip"}, conversation, false},
+		{"contains case insensitive", models.RuleDetail{Operator: models.RuleOperatorContains, Value: "vip-lite"}, conversation, true},
+		{"contains case sensitive", models.RuleDetail{Operator: models.RuleOperatorContains, Value: "vip-lite", CaseSensitiveMatch: true}, conversation, false},
+		{"not contains", models.RuleDetail{Operator: models.RuleOperatorNotContains, Value: "urgent"}, conversation, true},
+		{"not contains present", models.RuleDetail{Operator: models.RuleOperatorNotContains, Value: "urgent,billing"}, conversation, false},
+		{"set", models.RuleDetail{Operator: models.RuleOperatorSet}, conversation, true},
+		{"not set", models.RuleDetail{Operator: models.RuleOperatorNotSet}, untagged, true},
+		{"unsupported operator", models.RuleDetail{Operator: models.RuleOperatorEquals, Value: "billing"}, conversation, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Field = models.ConversationTags
+			tt.rule.FieldType = models.FieldTypeConversationField
+			assert.Equal(t, tt.expected, engine.evaluateRule(tt.rule, tt.conversation))
+		})
+	}
+}
+
+// Test: Conditions can be evaluated without running any actions
+func TestEvaluateConditions(t *testing.T) {
+	mockStore := new(mockConversationStore)
+	engine := createTestEngine(mockStore)
+
+	conversation := createTestConversation(func(c *cmodels.Conversation) {
+		c.PriorityID = null.IntFrom(3)
+	})
+
+	groups := []models.RuleGroup{
+		{
+			LogicalOp: models.OperatorAnd,
+			Rules: []models.RuleDetail{
+				{Field: models.ConversationInbox, Operator: models.RuleOperatorEquals, Value: "1", FieldType: models.FieldTypeConversationField},
+				{Field: models.ConversationPriority, Operator: models.RuleOperatorEquals, Value: "3", FieldType: models.FieldTypeConversationField},
+			},
+		},
+		{LogicalOp: models.OperatorAnd},
+	}
+
+	assert.True(t, engine.EvaluateConditions(groups, models.OperatorAnd, conversation))
+
+	groups[0].Rules[1].Value = "2"
+	assert.False(t, engine.EvaluateConditions(groups, models.OperatorAnd, conversation))
+	assert.Equal(t, 0, mockStore.callCount, "EvaluateConditions should not apply any actions")
+}
@@ -20,6 +20,8 @@ const (
 	ActionSetTags         = "set_tags"
 	ActionRemoveTags      = "remove_tags"
 	ActionSendCSAT        = "send_csat"
+	// ActionAITriage classifies the conversation with the AI provider, the value is a JSON encoded triage taxonomy.
+	ActionAITriage = "ai_triage"
 
 	OperatorAnd = "AND"
 	OperatorOR  = "OR"
@@ -48,6 +50,7 @@ const (
 	ConversationHoursSinceLastReply  = "hours_since_last_reply"
 	ConversationHoursSinceResolved   = "hours_since_resolved"
 	ConversationInbox                = "inbox"
+	ConversationTags                 = "tags"
 	ContactEmail                     = "contact_email"
 
 	EventConversationUserAssigned    = "conversation.user.assigned"
@@ -5,10 +5,13 @@ import (
 	"database/sql"
 	"embed"
 	"errors"
+	"net/http"
+	"time"
 
 	"github.com/abhinavxd/libredesk/internal/business_hours/models"
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/netutil"
 	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/types"
 	"github.com/knadh/go-i18n"
@@ -23,9 +26,11 @@ var (
 )
 
 type Manager struct {
-	q    queries
-	lo   *logf.Logger
-	i18n *i18n.I18n
+	q                   queries
+	lo                  *logf.Logger
+	i18n                *i18n.I18n
+	httpClient          *http.Client
+	holidaySyncInterval time.Duration
 }
 
 // Opts contains options for initializing the Manager.
@@ -33,15 +38,20 @@ type Opts struct {
 	DB   *sqlx.DB
 	Lo   *logf.Logger
 	I18n *i18n.I18n
+	// HolidaySyncInterval is how often holidays are synced from the holidays URL of business hours.
+	HolidaySyncInterval time.Duration
 }
 
 // queries contains prepared SQL queries.
 type queries struct {
-	GetBusinessHours    *sqlx.Stmt `query:"get-business-hours"`
-	GetAllBusinessHours *sqlx.Stmt `query:"get-all-business-hours"`
-	InsertBusinessHours *sqlx.Stmt `query:"insert-business-hours"`
-	DeleteBusinessHours *sqlx.Stmt `query:"delete-business-hours"`
-	UpdateBusinessHours *sqlx.Stmt `query:"update-business-hours"`
+	GetBusinessHours                  *sqlx.Stmt `query:"get-business-hours"`
+	GetAllBusinessHours               *sqlx.Stmt `query:"get-all-business-hours"`
+	InsertBusinessHours               *sqlx.Stmt `query:"insert-business-hours"`
+	DeleteBusinessHours               *sqlx.Stmt `query:"delete-business-hours"`
+	UpdateBusinessHours               *sqlx.Stmt `query:"update-business-hours"`
+	UpdateBusinessHoursHolidays       *sqlx.Stmt `query:"update-business-hours-holidays"`
+	GetBusinessHoursHolidaysToSync    *sqlx.Stmt `query:"get-business-hours-holidays-to-sync"`
+	UpdateBusinessHoursSyncedHolidays *sqlx.Stmt `query:"update-business-hours-synced-holidays"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -50,10 +60,16 @@ func New(opts Opts) (*Manager, error) {
 	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
 		return nil, err
 	}
+	if opts.HolidaySyncInterval <= 0 {
+		opts.HolidaySyncInterval = defaultHolidaySyncInterval
+	}
 	return &Manager{
 		q:    q,
 		lo:   opts.Lo,
 		i18n: opts.I18n,
+		// Holidays URLs are set by admins, they must not reach the local network.
+		httpClient:          netutil.NewPublicClient(holidayFetchTimeout),
+		holidaySyncInterval: opts.HolidaySyncInterval,
 	}, nil
 }
 
@@ -80,9 +96,9 @@ func (m *Manager) GetAll() ([]models.BusinessHours, error) {
 }
 
 // Create creates new business hours.
-func (m *Manager) Create(name string, description null.String, isAlwaysOpen bool, workingHrs, holidays types.JSONText) (models.BusinessHours, error) {
+func (m *Manager) Create(name string, description null.String, isAlwaysOpen bool, workingHrs, holidays types.JSONText, holidaysURL null.String) (models.BusinessHours, error) {
 	var result models.BusinessHours
-	if err := m.q.InsertBusinessHours.Get(&result, name, description, isAlwaysOpen, workingHrs, holidays); err != nil {
+	if err := m.q.InsertBusinessHours.Get(&result, name, description, isAlwaysOpen, workingHrs, holidays, holidaysURL); err != nil {
 		m.lo.Error("error inserting business hours", "error", err)
 		return models.BusinessHours{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", "{globals.terms.businessHour}"), nil)
 	}
@@ -102,9 +118,9 @@ func (m *Manager) Delete(id int) error {
 }
 
 // Update updates business hours by ID.
-func (m *Manager) Update(id int, name string, description null.String, isAlwaysOpen bool, workingHrs, holidays types.JSONText) (models.BusinessHours, error) {
+func (m *Manager) Update(id int, name string, description null.String, isAlwaysOpen bool, workingHrs, holidays types.JSONText, holidaysURL null.String) (models.BusinessHours, error) {
 	var result models.BusinessHours
-	if err := m.q.UpdateBusinessHours.Get(&result, id, name, description, isAlwaysOpen, workingHrs, holidays); err != nil {
+	if err := m.q.UpdateBusinessHours.Get(&result, id, name, description, isAlwaysOpen, workingHrs, holidays, holidaysURL); err != nil {
 		m.lo.Error("error updating business hours", "error", err)
 		return models.BusinessHours{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.businessHour}"), nil)
 	}
@@ -0,0 +1,143 @@
+package businesshours
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/netutil"
+)
+
+const (
+	defaultHolidaySyncInterval = 6 * time.Hour
+	// holidaySyncCheckInterval is how often business hours are checked for holidays due to be synced.
+	holidaySyncCheckInterval = time.Minute
+	holidayFetchTimeout      = 30 * time.Second
+	// maxICSSize is the maximum size of an uploaded or fetched iCalendar file.
+	maxICSSize = 5 << 20
+)
+
+// ImportHolidays adds the holidays of an iCalendar file to the business hours, holidays already present are skipped.
+func (m *Manager) ImportHolidays(id int, r io.Reader) (models.BusinessHours, error) {
+	businessHours, err := m.Get(id)
+	if err != nil {
+		if errors.Is(err, ErrBusinessHoursNotFound) {
+			return models.BusinessHours{}, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.businessHour}"), nil)
+		}
+		m.lo.Error("error fetching business hours", "id", id, "error", err)
+		return models.BusinessHours{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.businessHour}"), nil)
+	}
+
+	imported, err := ParseICS(io.LimitReader(r, maxICSSize))
+	if err != nil {
+		m.lo.Error("error parsing holidays calendar", "id", id, "error", err)
+		return models.BusinessHours{}, envelope.NewError(envelope.InputError, m.i18n.T("admin.businessHours.invalidCalendar"), nil)
+	}
+
+	existing, err := ParseHolidays(businessHours.Holidays)
+	if err != nil {
+		m.lo.Error("error parsing holidays", "id", id, "error", err)
+		return models.BusinessHours{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.businessHour}"), nil)
+	}
+	holidays, err := json.Marshal(MergeHolidays(existing, imported))
+	if err != nil {
+		m.lo.Error("error marshalling holidays", "id", id, "error", err)
+		return models.BusinessHours{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.businessHour}"), nil)
+	}
+
+	var result models.BusinessHours
+	if err := m.q.UpdateBusinessHoursHolidays.Get(&result, id, holidays); err != nil {
+		m.lo.Error("error updating business hours holidays", "id", id, "error", err)
+		return models.BusinessHours{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.businessHour}"), nil)
+	}
+	return result, nil
+}
+
+// RunHolidaySync periodically syncs the holidays of business hours that have a holidays URL.
+// Holidays are synced once the sync interval has passed since the last attempt, or right away when the URL is changed.
+func (m *Manager) RunHolidaySync(ctx context.Context) {
+	ticker := time.NewTicker(holidaySyncCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.syncDueHolidays(ctx); err != nil {
+				m.lo.Error("error syncing holidays", "error", err)
+			}
+		}
+	}
+}
+
+// syncDueHolidays syncs the holidays of all business hours due for a sync.
+func (m *Manager) syncDueHolidays(ctx context.Context) error {
+	var due []models.BusinessHours
+	if err := m.q.GetBusinessHoursHolidaysToSync.SelectContext(ctx, &due, m.holidaySyncInterval.Seconds()); err != nil {
+		return fmt.Errorf("fetching business hours to sync holidays: %w", err)
+	}
+	for _, businessHours := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		m.syncHolidays(ctx, businessHours)
+	}
+	return nil
+}
+
+// syncHolidays replaces the holidays previously synced from the holidays URL of the business hours with the holidays currently in the calendar.
+// On failure the existing holidays are kept and the error is saved to be shown to admins.
+func (m *Manager) syncHolidays(ctx context.Context, businessHours models.BusinessHours) {
+	var (
+		holidays any
+		syncErr  any
+	)
+	synced, err := m.fetchHolidays(ctx, businessHours.HolidaysURL.String)
+	if err == nil {
+		var existing []models.Holiday
+		if existing, err = ParseHolidays(businessHours.Holidays); err == nil {
+			holidays, err = json.Marshal(ReplaceSyncedHolidays(existing, synced))
+		}
+	}
+	if err != nil {
+		m.lo.Error("error syncing holidays", "id", businessHours.ID, "url", businessHours.HolidaysURL.String, "error", err)
+		holidays, syncErr = nil, err.Error()
+	} else {
+		m.lo.Info("synced holidays", "id", businessHours.ID, "count", len(synced))
+	}
+
+	if _, err := m.q.UpdateBusinessHoursSyncedHolidays.ExecContext(ctx, businessHours.ID, businessHours.HolidaysURL.String, holidays, syncErr); err != nil {
+		m.lo.Error("error updating synced holidays", "id", businessHours.ID, "error", err)
+	}
+}
+
+// fetchHolidays fetches and parses the iCalendar file at the URL, URLs of local or private addresses are refused.
+func (m *Manager) fetchHolidays(ctx context.Context, url string) ([]models.Holiday, error) {
+	if err := netutil.ValidatePublicURL(url, "http", "https"); err != nil {
+		return nil, fmt.Errorf("fetching calendar: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, holidayFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching calendar: unexpected status %s", resp.Status)
+	}
+	return ParseICS(io.LimitReader(resp.Body, maxICSSize))
+}
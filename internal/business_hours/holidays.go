@@ -0,0 +1,124 @@
+package businesshours
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/jmoiron/sqlx/types"
+)
+
+var (
+	ErrInvalidDate     = errors.New("invalid date")
+	ErrEmptyHoliday    = errors.New("holiday name is empty")
+	ErrEmptyClosure    = errors.New("closure starts and ends at the same time")
+	ErrMissingEndTime  = errors.New("closure start and end times must both be set")
+	ErrInvalidTimezone = errors.New("invalid time zone")
+)
+
+// HolidayError is returned for an invalid holiday.
+type HolidayError struct {
+	Name string
+	Err  error
+}
+
+func (e *HolidayError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e *HolidayError) Unwrap() error {
+	return e.Err
+}
+
+// ParseHolidays parses the holidays of business hours.
+func ParseHolidays(holidays types.JSONText) ([]models.Holiday, error) {
+	var out = []models.Holiday{}
+	if len(holidays) == 0 {
+		return out, nil
+	}
+	if err := json.Unmarshal(holidays, &out); err != nil {
+		return nil, fmt.Errorf("could not unmarshal holidays: %v", err)
+	}
+	return out, nil
+}
+
+// ValidateHolidays checks that every holiday has a name and a valid date, and that partial-day closures have valid start and end times.
+func ValidateHolidays(holidays types.JSONText) error {
+	parsed, err := ParseHolidays(holidays)
+	if err != nil {
+		return err
+	}
+	for _, h := range parsed {
+		if err := validateHoliday(h); err != nil {
+			return &HolidayError{Name: h.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+func validateHoliday(h models.Holiday) error {
+	if strings.TrimSpace(h.Name) == "" {
+		return ErrEmptyHoliday
+	}
+	if _, err := time.Parse(time.DateOnly, h.Date); err != nil {
+		return ErrInvalidDate
+	}
+	if h.Timezone != "" {
+		if _, err := time.LoadLocation(h.Timezone); err != nil {
+			return ErrInvalidTimezone
+		}
+	}
+	if h.IsFullDay() {
+		return nil
+	}
+	if h.StartTime == "" || h.EndTime == "" {
+		return ErrMissingEndTime
+	}
+	start, err := parseClock(h.StartTime)
+	if err != nil {
+		return err
+	}
+	end, err := parseClock(h.EndTime)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return ErrEmptyClosure
+	}
+	return nil
+}
+
+// MergeHolidays adds the imported holidays that are not present yet to the existing holidays, sorted by date.
+func MergeHolidays(existing, imported []models.Holiday) []models.Holiday {
+	key := func(h models.Holiday) string {
+		return strings.Join([]string{h.Date, strings.ToLower(strings.TrimSpace(h.Name)), h.StartTime, h.EndTime, h.Timezone}, "|")
+	}
+	var (
+		merged = make([]models.Holiday, 0, len(existing)+len(imported))
+		seen   = make(map[string]struct{}, len(existing)+len(imported))
+	)
+	for _, h := range slices.Concat(existing, imported) {
+		if _, ok := seen[key(h)]; ok {
+			continue
+		}
+		seen[key(h)] = struct{}{}
+		merged = append(merged, h)
+	}
+	slices.SortStableFunc(merged, func(a, b models.Holiday) int { return strings.Compare(a.Date, b.Date) })
+	return merged
+}
+
+// ReplaceSyncedHolidays replaces the holidays previously synced from the holidays URL with the newly synced ones.
+func ReplaceSyncedHolidays(existing, synced []models.Holiday) []models.Holiday {
+	kept := slices.DeleteFunc(slices.Clone(existing), func(h models.Holiday) bool {
+		return h.Source == models.HolidaySourceURL
+	})
+	for i := range synced {
+		synced[i].Source = models.HolidaySourceURL
+	}
+	return MergeHolidays(kept, synced)
+}
@@ -0,0 +1,148 @@
+package businesshours
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/jmoiron/sqlx/types"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+var (
+	ErrInvalidDay           = errors.New("invalid day")
+	ErrInvalidTime          = errors.New("invalid time")
+	ErrEmptyInterval        = errors.New("interval opens and closes at the same time")
+	ErrOverlappingIntervals = errors.New("intervals overlap")
+
+	clockRegex = regexp.MustCompile(`^(?:[01]\d|2[0-3]):[0-5]\d$`)
+)
+
+// HoursError is returned for invalid working hours of a day.
+type HoursError struct {
+	Day string
+	Err error
+}
+
+func (e *HoursError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Day, e.Err)
+}
+
+func (e *HoursError) Unwrap() error {
+	return e.Err
+}
+
+// Interval is a working interval of a day in minutes since the start of the day.
+// Intervals that cross midnight end after minutesPerDay, on the next day.
+type Interval struct {
+	Start int
+	End   int
+}
+
+// ParseHours parses the working hours of business hours by day of the week, the intervals of each day are sorted by their start.
+// An interval that closes before it opens, e.g. 22:00 to 02:00, crosses midnight and belongs to the day it opens on.
+func ParseHours(hours types.JSONText) (map[time.Weekday][]Interval, error) {
+	var workingHours map[string]models.WorkingHours
+	if len(hours) > 0 {
+		if err := json.Unmarshal(hours, &workingHours); err != nil {
+			return nil, fmt.Errorf("could not unmarshal working hours: %v", err)
+		}
+	}
+
+	days := make(map[time.Weekday][]Interval, len(workingHours))
+	for day, wh := range workingHours {
+		weekday, ok := parseWeekday(day)
+		if !ok {
+			return nil, &HoursError{Day: day, Err: ErrInvalidDay}
+		}
+
+		intervals := make([]Interval, 0, len(wh.GetIntervals()))
+		for _, iv := range wh.GetIntervals() {
+			start, err := parseClock(iv.Open)
+			if err != nil {
+				return nil, &HoursError{Day: day, Err: err}
+			}
+			end, err := parseClock(iv.Close)
+			if err != nil {
+				return nil, &HoursError{Day: day, Err: err}
+			}
+			if end < start {
+				end += minutesPerDay
+			}
+			intervals = append(intervals, Interval{Start: start, End: end})
+		}
+		slices.SortFunc(intervals, func(a, b Interval) int { return a.Start - b.Start })
+		days[weekday] = intervals
+	}
+	return days, nil
+}
+
+// ValidateHours checks that the working hours are valid and that no two intervals overlap,
+// including intervals that cross midnight into the next day's hours.
+func ValidateHours(hours types.JSONText) error {
+	days, err := ParseHours(hours)
+	if err != nil {
+		return err
+	}
+
+	type weekInterval struct {
+		day        time.Weekday
+		start, end int
+	}
+	var week []weekInterval
+	for day, intervals := range days {
+		for _, iv := range intervals {
+			if iv.Start == iv.End {
+				return &HoursError{Day: day.String(), Err: ErrEmptyInterval}
+			}
+			offset := int(day) * minutesPerDay
+			week = append(week, weekInterval{day: day, start: offset + iv.Start, end: offset + iv.End})
+		}
+	}
+	if len(week) == 0 {
+		return nil
+	}
+
+	slices.SortFunc(week, func(a, b weekInterval) int { return a.start - b.start })
+	for i := 1; i < len(week); i++ {
+		if week[i].start < week[i-1].end {
+			return &HoursError{Day: week[i].day.String(), Err: ErrOverlappingIntervals}
+		}
+	}
+
+	// Saturday's intervals that cross midnight continue into Sunday.
+	if last := week[len(week)-1]; last.end-minutesPerWeek > week[0].start {
+		return &HoursError{Day: week[0].day.String(), Err: ErrOverlappingIntervals}
+	}
+	return nil
+}
+
+// parseWeekday parses a day of the week name, e.g. `Monday`.
+func parseWeekday(day string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if d.String() == day {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock parses a time string in "HH:MM" format and returns the minutes since the start of the day.
+func parseClock(s string) (int, error) {
+	if !clockRegex.MatchString(s) {
+		return 0, ErrInvalidTime
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, ErrInvalidTime
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,157 @@
+package businesshours
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustMarshalJSON(v any) types.JSONText {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return types.JSONText(data)
+}
+
+func TestParseHours(t *testing.T) {
+	days, err := ParseHours(mustMarshalJSON(map[string]models.WorkingHours{
+		"Monday": {Open: "09:00", Close: "17:00"},
+		"Tuesday": {Intervals: []models.TimeInterval{
+			{Open: "22:00", Close: "02:00"},
+			{Open: "09:00", Close: "12:00"},
+			{Open: "13:00", Close: "00:00"},
+		}},
+		"Wednesday": {},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, map[time.Weekday][]Interval{
+		time.Monday:    {{Start: 540, End: 1020}},
+		time.Tuesday:   {{Start: 540, End: 720}, {Start: 780, End: 1440}, {Start: 1320, End: 1560}},
+		time.Wednesday: {},
+	}, days)
+
+	days, err = ParseHours(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, days)
+
+	_, err = ParseHours(types.JSONText(`[]`))
+	assert.Error(t, err)
+}
+
+func TestValidateHours(t *testing.T) {
+	tests := []struct {
+		name        string
+		hours       map[string]models.WorkingHours
+		expectError error
+		expectDay   string
+	}{
+		{
+			name: "Single Interval",
+			hours: map[string]models.WorkingHours{
+				"Monday": {Open: "09:00", Close: "17:00"},
+			},
+		},
+		{
+			name: "Lunch Break",
+			hours: map[string]models.WorkingHours{
+				"Monday": {Intervals: []models.TimeInterval{{Open: "09:00", Close: "12:00"}, {Open: "13:00", Close: "18:00"}}},
+			},
+		},
+		{
+			name: "Adjacent Intervals",
+			hours: map[string]models.WorkingHours{
+				"Monday":  {Intervals: []models.TimeInterval{{Open: "09:00", Close: "12:00"}, {Open: "12:00", Close: "00:00"}}},
+				"Tuesday": {Intervals: []models.TimeInterval{{Open: "00:00", Close: "02:00"}}},
+			},
+		},
+		{
+			name: "Interval Crossing Midnight",
+			hours: map[string]models.WorkingHours{
+				"Monday":  {Intervals: []models.TimeInterval{{Open: "22:00", Close: "06:00"}}},
+				"Tuesday": {Intervals: []models.TimeInterval{{Open: "09:00", Close: "17:00"}}},
+			},
+		},
+		{
+			name:  "No Hours",
+			hours: map[string]models.WorkingHours{},
+		},
+		{
+			name: "Invalid Day",
+			hours: map[string]models.WorkingHours{
+				"Funday": {Open: "09:00", Close: "17:00"},
+			},
+			expectError: ErrInvalidDay,
+			expectDay:   "Funday",
+		},
+		{
+			name: "Invalid Time",
+			hours: map[string]models.WorkingHours{
+				"Monday": {Intervals: []models.TimeInterval{{Open: "09:00", Close: "24:00"}}},
+			},
+			expectError: ErrInvalidTime,
+			expectDay:   "Monday",
+		},
+		{
+			name: "Missing Time",
+			hours: map[string]models.WorkingHours{
+				"Monday": {Open: "09:00"},
+			},
+			expectError: ErrInvalidTime,
+			expectDay:   "Monday",
+		},
+		{
+			name: "Empty Interval",
+			hours: map[string]models.WorkingHours{
+				"Monday": {Intervals: []models.TimeInterval{{Open: "09:00", Close: "09:00"}}},
+			},
+			expectError: ErrEmptyInterval,
+			expectDay:   "Monday",
+		},
+		{
+			name: "Overlapping Intervals",
+			hours: map[string]models.WorkingHours{
+				"Monday": {Intervals: []models.TimeInterval{{Open: "09:00", Close: "13:00"}, {Open: "12:00", Close: "18:00"}}},
+			},
+			expectError: ErrOverlappingIntervals,
+			expectDay:   "Monday",
+		},
+		{
+			name: "Interval Crossing Into Next Day's Interval",
+			hours: map[string]models.WorkingHours{
+				"Monday":  {Intervals: []models.TimeInterval{{Open: "22:00", Close: "03:00"}}},
+				"Tuesday": {Intervals: []models.TimeInterval{{Open: "02:00", Close: "10:00"}}},
+			},
+			expectError: ErrOverlappingIntervals,
+			expectDay:   "Tuesday",
+		},
+		{
+			name: "Saturday Interval Crossing Into Sunday's Interval",
+			hours: map[string]models.WorkingHours{
+				"Saturday": {Intervals: []models.TimeInterval{{Open: "20:00", Close: "02:00"}}},
+				"Sunday":   {Intervals: []models.TimeInterval{{Open: "01:00", Close: "05:00"}}},
+			},
+			expectError: ErrOverlappingIntervals,
+			expectDay:   "Sunday",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHours(mustMarshalJSON(tt.hours))
+			if tt.expectError == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectError)
+			var hoursErr *HoursError
+			if assert.ErrorAs(t, err, &hoursErr) {
+				assert.Equal(t, tt.expectDay, hoursErr.Day)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package businesshours
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/business_hours/models"
+)
+
+const (
+	// maxICSLineSize is the maximum size of an unfolded iCalendar line.
+	maxICSLineSize = 1 << 20
+	// maxICSEventDays is the maximum number of days a single event is expanded to.
+	maxICSEventDays = 366
+	// maxICSHolidays is the maximum number of holidays imported from a calendar.
+	maxICSHolidays = 5000
+)
+
+var ErrInvalidICS = errors.New("invalid iCalendar file")
+
+// icsProperty is a content line of an iCalendar file, e.g. `DTSTART;VALUE=DATE:20250101`.
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// icsEvent holds the properties of a VEVENT used for holidays.
+type icsEvent struct {
+	summary string
+	status  string
+	rrule   string
+	start   *icsProperty
+	end     *icsProperty
+}
+
+// ParseICS parses the events of an iCalendar file into holidays.
+// All-day events become full-day holidays, one for every day they span. Timed events become closures in the time zone
+// of the event, UTC or its TZID, which schedules convert to their own time zone. Floating times are in the time zone of the schedule.
+// Events that repeat yearly become recurring holidays, other recurrence rules are not expanded.
+// Cancelled events and events that cannot be parsed are skipped.
+func ParseICS(r io.Reader) ([]models.Holiday, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		holidays   = []models.Holiday{}
+		event      *icsEvent
+		isCalendar bool
+	)
+	for _, line := range lines {
+		prop, ok := parseICSProperty(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCALENDAR"):
+			isCalendar = true
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT"):
+			event = &icsEvent{}
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT"):
+			if event != nil {
+				holidays = append(holidays, event.holidays()...)
+				if len(holidays) > maxICSHolidays {
+					return nil, fmt.Errorf("%w: more than %d holidays", ErrInvalidICS, maxICSHolidays)
+				}
+			}
+			event = nil
+		case event == nil:
+			continue
+		case prop.name == "SUMMARY":
+			event.summary = unescapeICSText(prop.value)
+		case prop.name == "STATUS":
+			event.status = strings.ToUpper(prop.value)
+		case prop.name == "RRULE":
+			event.rrule = strings.ToUpper(prop.value)
+		case prop.name == "DTSTART":
+			event.start = &prop
+		case prop.name == "DTEND":
+			event.end = &prop
+		}
+	}
+	if !isCalendar {
+		return nil, ErrInvalidICS
+	}
+	return holidays, nil
+}
+
+// holidays returns the holidays for the event, none if the event is cancelled or cannot be parsed.
+func (e *icsEvent) holidays() []models.Holiday {
+	if e.status == "CANCELLED" || e.start == nil {
+		return nil
+	}
+	start, allDay, timezone, err := parseICSTime(*e.start)
+	if err != nil {
+		return nil
+	}
+
+	// The event is split into days in the time zone of its start.
+	var (
+		loc = start.Location()
+		end time.Time
+	)
+	if e.end != nil {
+		if end, _, _, err = parseICSTime(*e.end); err != nil {
+			return nil
+		}
+		end = end.In(loc)
+	} else if allDay {
+		end = start.AddDate(0, 0, 1)
+	}
+	if !end.After(start) {
+		return nil
+	}
+
+	name := strings.TrimSpace(e.summary)
+	if name == "" {
+		name = "Holiday"
+	}
+	recurring := strings.Contains(";"+e.rrule+";", ";FREQ=YEARLY;")
+
+	// Split the event into full days and partial days in the time zone.
+	var (
+		holidays []models.Holiday
+		day      = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	)
+	for i := 0; day.Before(end) && i < maxICSEventDays; i++ {
+		next := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+		from, to := day, next
+		if start.After(from) {
+			from = start
+		}
+		if end.Before(to) {
+			to = end
+		}
+		holiday := models.Holiday{Name: name, Date: day.Format(time.DateOnly), Recurring: recurring, Timezone: timezone}
+		if !from.Equal(day) || !to.Equal(next) {
+			holiday.StartTime, holiday.EndTime = from.Format("15:04"), to.Format("15:04")
+		}
+		// Closures shorter than a minute are dropped.
+		if holiday.IsFullDay() || holiday.StartTime != holiday.EndTime {
+			holidays = append(holidays, holiday)
+		}
+		day = next
+	}
+	return holidays
+}
+
+// unfoldICSLines reads the content lines of an iCalendar file, joining lines folded onto continuation lines.
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	var (
+		lines   []string
+		scanner = bufio.NewScanner(r)
+	)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxICSLineSize)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidICS, err)
+	}
+	return lines, nil
+}
+
+// parseICSProperty parses a content line into its name, parameters and value.
+func parseICSProperty(line string) (icsProperty, bool) {
+	// The value starts after the first colon that is not within a quoted parameter value.
+	var (
+		quoted bool
+		sep    = -1
+	)
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			sep = i
+			break
+		}
+	}
+	if sep <= 0 {
+		return icsProperty{}, false
+	}
+
+	parts := strings.Split(line[:sep], ";")
+	prop := icsProperty{
+		name:   strings.ToUpper(strings.TrimSpace(parts[0])),
+		params: make(map[string]string, len(parts)-1),
+		value:  line[sep+1:],
+	}
+	for _, param := range parts[1:] {
+		k, v, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		prop.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return prop, true
+}
+
+// parseICSTime parses a DATE or DATE-TIME property value, returns true if the value is a date along with the time zone of
+// date-times in UTC or with a known TZID. Dates and floating date-times have no time zone and are parsed as UTC wall clock times.
+func parseICSTime(prop icsProperty) (time.Time, bool, string, error) {
+	value := strings.TrimSpace(prop.value)
+	if strings.EqualFold(prop.params["VALUE"], "DATE") || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, time.UTC)
+		return t, true, "", err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, "UTC", err
+	}
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			t, err := time.ParseInLocation("20060102T150405", value, loc)
+			return t, false, loc.String(), err
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, time.UTC)
+	return t, false, "", err
+}
+
+// unescapeICSText unescapes an iCalendar TEXT value.
+func unescapeICSText(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
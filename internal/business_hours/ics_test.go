@@ -0,0 +1,71 @@
+package businesshours
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const testICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Test//Holidays//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:New Year's Day\r\n" +
+	"DTSTART;VALUE=DATE:20250101\r\n" +
+	"DTEND;VALUE=DATE:20250102\r\n" +
+	"RRULE:FREQ=YEARLY\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Diwali\\, Festival of\r\n" +
+	"  Lights\r\n" +
+	"DTSTART;VALUE=DATE:20251020\r\n" +
+	"DTEND;VALUE=DATE:20251022\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Team Offsite\r\n" +
+	"DTSTART:20250306T063000Z\r\n" +
+	"DTEND:20250306T083000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Maintenance\r\n" +
+	"DTSTART;TZID=\"Europe/London\":20250307T180000\r\n" +
+	"DTEND;TZID=\"Europe/London\":20250308T060000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Town Hall\r\n" +
+	"DTSTART:20250310T090000\r\n" +
+	"DTEND:20250310T103000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Cancelled\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART;VALUE=DATE:20250401\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Broken\r\n" +
+	"DTSTART:not-a-date\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS(t *testing.T) {
+	holidays, err := ParseICS(strings.NewReader(testICS))
+	assert.NoError(t, err)
+	// Timed events keep their time zone, floating times have none and are in the time zone of the schedule.
+	assert.Equal(t, []models.Holiday{
+		{Name: "New Year's Day", Date: "2025-01-01", Recurring: true},
+		{Name: "Diwali, Festival of Lights", Date: "2025-10-20"},
+		{Name: "Diwali, Festival of Lights", Date: "2025-10-21"},
+		{Name: "Team Offsite", Date: "2025-03-06", StartTime: "06:30", EndTime: "08:30", Timezone: "UTC"},
+		{Name: "Maintenance", Date: "2025-03-07", StartTime: "18:00", EndTime: "00:00", Timezone: "Europe/London"},
+		{Name: "Maintenance", Date: "2025-03-08", StartTime: "00:00", EndTime: "06:00", Timezone: "Europe/London"},
+		{Name: "Town Hall", Date: "2025-03-10", StartTime: "09:00", EndTime: "10:30"},
+	}, holidays)
+
+	// Imported holidays are valid holidays.
+	assert.NoError(t, ValidateHolidays(mustMarshalJSON(holidays)))
+
+	_, err = ParseICS(strings.NewReader("not a calendar"))
+	assert.ErrorIs(t, err, ErrInvalidICS)
+}
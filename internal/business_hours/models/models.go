@@ -10,24 +10,62 @@ import (
 
 // BusinessHours represents the business in the database.
 type BusinessHours struct {
-	ID           int            `db:"id" json:"id"`
-	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt    time.Time      `db:"updated_at" json:"updated_at"`
-	Name         string         `db:"name" json:"name"`
-	Description  null.String    `db:"description" json:"description"`
-	IsAlwaysOpen bool           `db:"is_always_open" json:"is_always_open"`
-	Holidays     types.JSONText `db:"holidays" json:"holidays"`
-	Hours        types.JSONText `db:"hours" json:"hours"`
+	ID                int            `db:"id" json:"id"`
+	CreatedAt         time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time      `db:"updated_at" json:"updated_at"`
+	Name              string         `db:"name" json:"name"`
+	Description       null.String    `db:"description" json:"description"`
+	IsAlwaysOpen      bool           `db:"is_always_open" json:"is_always_open"`
+	Holidays          types.JSONText `db:"holidays" json:"holidays"`
+	Hours             types.JSONText `db:"hours" json:"hours"`
+	HolidaysURL       null.String    `db:"holidays_url" json:"holidays_url"`
+	HolidaysSyncedAt  null.Time      `db:"holidays_synced_at" json:"holidays_synced_at"`
+	HolidaysSyncError null.String    `db:"holidays_sync_error" json:"holidays_sync_error"`
 }
 
 // WorkingHours represents the working hours for a specific day.
+// Open and Close hold the single interval of business hours saved before a day could have multiple intervals.
 type WorkingHours struct {
-	Open         string `json:"open"`
-	Close        string `json:"close"`
+	Open      string         `json:"open,omitempty"`
+	Close     string         `json:"close,omitempty"`
+	Intervals []TimeInterval `json:"intervals,omitempty"`
 }
 
+// TimeInterval represents a working interval in "HH:MM" format, an interval that closes before it opens ends on the next day.
+type TimeInterval struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// GetIntervals returns the working intervals of the day.
+func (w WorkingHours) GetIntervals() []TimeInterval {
+	if len(w.Intervals) > 0 {
+		return w.Intervals
+	}
+	if w.Open == "" && w.Close == "" {
+		return nil
+	}
+	return []TimeInterval{{Open: w.Open, Close: w.Close}}
+}
+
+// HolidaySourceURL is the source of holidays synced from the iCalendar URL of business hours, they are replaced on every sync.
+const HolidaySourceURL = "url"
+
 // Holiday represents a holiday.
+// A recurring holiday falls on the same month and day every year. A holiday with a start and end time in "HH:MM" format
+// is a partial-day closure, the closure ends on the next day if the end time is before the start time.
+// The date and times are in the time zone of the schedule unless Timezone is set, e.g. for timed events imported from calendars.
 type Holiday struct {
-	Name string `json:"name"`
-	Date string `json:"date"`
+	Name      string `json:"name"`
+	Date      string `json:"date"`
+	Recurring bool   `json:"recurring,omitempty"`
+	StartTime string `json:"start_time,omitempty"`
+	EndTime   string `json:"end_time,omitempty"`
+	Timezone  string `json:"timezone,omitempty"`
+	Source    string `json:"source,omitempty"`
+}
+
+// IsFullDay returns true if the holiday closes the business for the whole day.
+func (h Holiday) IsFullDay() bool {
+	return h.StartTime == "" && h.EndTime == ""
 }
@@ -6,7 +6,10 @@ SELECT id,
     description,
     is_always_open,
     hours,
-    holidays
+    holidays,
+    holidays_url,
+    holidays_synced_at,
+    holidays_sync_error
 FROM business_hours
 WHERE id = $1;
 
@@ -18,7 +21,10 @@ SELECT id,
     description,
     is_always_open,
     hours,
-    holidays
+    holidays,
+    holidays_url,
+    holidays_synced_at,
+    holidays_sync_error
 FROM business_hours
 ORDER BY updated_at DESC;
 
@@ -28,9 +34,10 @@ INSERT INTO business_hours (
         description,
         is_always_open,
         hours,
-        holidays
+        holidays,
+        holidays_url
     )
-VALUES ($1, $2, $3, $4, $5)
+VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
 RETURNING *;
 
 -- name: delete-business-hours
@@ -44,6 +51,41 @@ SET "name" = $2,
     is_always_open = $4,
     hours = $5,
     holidays = $6,
+    -- Sync again right away when the holidays URL changes.
+    holidays_synced_at = CASE WHEN holidays_url IS DISTINCT FROM NULLIF($7, '') THEN NULL ELSE holidays_synced_at END,
+    holidays_sync_error = CASE WHEN holidays_url IS DISTINCT FROM NULLIF($7, '') THEN NULL ELSE holidays_sync_error END,
+    holidays_url = NULLIF($7, ''),
     updated_at = NOW()
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: update-business-hours-holidays
+UPDATE business_hours
+SET holidays = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: get-business-hours-holidays-to-sync
+SELECT id,
+    created_at,
+    updated_at,
+    "name",
+    description,
+    is_always_open,
+    hours,
+    holidays,
+    holidays_url,
+    holidays_synced_at,
+    holidays_sync_error
+FROM business_hours
+WHERE COALESCE(holidays_url, '') <> ''
+    AND (holidays_synced_at IS NULL OR holidays_synced_at < NOW() - make_interval(secs => $1));
+
+-- name: update-business-hours-synced-holidays
+-- Holidays are only replaced if the URL was not changed while it was being fetched.
+UPDATE business_hours
+SET holidays = COALESCE($3, holidays),
+    holidays_synced_at = NOW(),
+    holidays_sync_error = $4
+WHERE id = $1 AND holidays_url = $2;
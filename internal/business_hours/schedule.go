@@ -0,0 +1,225 @@
+package businesshours
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/business_hours/models"
+)
+
+const (
+	// recurringDateLayout is the layout of the date key of recurring holidays.
+	recurringDateLayout = "01-02"
+	// maxNextOpeningDays is how many days ahead the next opening time is looked for.
+	maxNextOpeningDays = 2 * 366
+)
+
+// Window is a period of time on a specific date during which the business is open.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Schedule resolves the working hours and holidays of business hours to the windows the business is open on specific dates in a time zone.
+type Schedule struct {
+	loc        *time.Location
+	alwaysOpen bool
+	hours      map[time.Weekday][]Interval
+	// closedDays and closures are keyed by date, or by month and day for recurring holidays.
+	closedDays map[string]struct{}
+	closures   map[string][]closure
+}
+
+// closure is a partial-day closure in the time zone of the holiday.
+type closure struct {
+	Interval
+	loc *time.Location
+}
+
+// NewSchedule returns the schedule of business hours in the given time zone.
+func NewSchedule(businessHours models.BusinessHours, timezone string) (*Schedule, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %s: %v", timezone, err)
+	}
+
+	s := &Schedule{
+		loc:        loc,
+		alwaysOpen: businessHours.IsAlwaysOpen,
+		closedDays: make(map[string]struct{}),
+		closures:   make(map[string][]closure),
+	}
+	if s.alwaysOpen {
+		return s, nil
+	}
+
+	if s.hours, err = ParseHours(businessHours.Hours); err != nil {
+		return nil, err
+	}
+
+	holidays, err := ParseHolidays(businessHours.Holidays)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range holidays {
+		date, err := time.Parse(time.DateOnly, h.Date)
+		if err != nil {
+			return nil, &HolidayError{Name: h.Name, Err: ErrInvalidDate}
+		}
+		key := h.Date
+		if h.Recurring {
+			key = date.Format(recurringDateLayout)
+		}
+		closureLoc := loc
+		if h.Timezone != "" {
+			if closureLoc, err = time.LoadLocation(h.Timezone); err != nil {
+				return nil, &HolidayError{Name: h.Name, Err: ErrInvalidTimezone}
+			}
+		}
+		sameZone := closureLoc.String() == loc.String()
+		if h.IsFullDay() && sameZone {
+			s.closedDays[key] = struct{}{}
+			continue
+		}
+		// A full day in another time zone is a closure spanning that day.
+		if h.IsFullDay() {
+			s.closures[key] = append(s.closures[key], closure{Interval: Interval{Start: 0, End: minutesPerDay}, loc: closureLoc})
+			continue
+		}
+
+		start, err := parseClock(h.StartTime)
+		if err != nil {
+			return nil, &HolidayError{Name: h.Name, Err: err}
+		}
+		end, err := parseClock(h.EndTime)
+		if err != nil {
+			return nil, &HolidayError{Name: h.Name, Err: err}
+		}
+		if end <= start {
+			end += minutesPerDay
+		}
+		s.closures[key] = append(s.closures[key], closure{Interval: Interval{Start: start, End: end}, loc: closureLoc})
+	}
+	return s, nil
+}
+
+// Location returns the time zone of the schedule.
+func (s *Schedule) Location() *time.Location {
+	return s.loc
+}
+
+// Windows returns the windows the business is open for the working intervals of the given day in chronological order.
+// Windows of intervals that cross midnight end on the next day. A full-day holiday closes the intervals that open on that day,
+// partial-day closures are cut out of the windows they overlap.
+func (s *Schedule) Windows(day time.Time) []Window {
+	day = s.dayOffset(day, 0)
+	if s.alwaysOpen {
+		return []Window{{Start: day, End: s.dayOffset(day, 1)}}
+	}
+	if s.isClosed(day) {
+		return nil
+	}
+
+	intervals := s.hours[day.Weekday()]
+	windows := make([]Window, 0, len(intervals))
+	for _, iv := range intervals {
+		windows = append(windows, Window{Start: s.at(day, iv.Start), End: s.at(day, iv.End)})
+	}
+
+	// Closures of the previous day can run past midnight and windows can run into the next day,
+	// closures in another time zone can fall a further day apart.
+	for offset := -2; offset <= 2 && len(windows) > 0; offset++ {
+		d := s.dayOffset(day, offset)
+		for _, c := range s.closuresOn(d) {
+			start := time.Date(d.Year(), d.Month(), d.Day(), 0, c.Start, 0, 0, c.loc).In(s.loc)
+			end := time.Date(d.Year(), d.Month(), d.Day(), 0, c.End, 0, 0, c.loc).In(s.loc)
+			windows = subtractWindow(windows, Window{Start: start, End: end})
+		}
+	}
+	return windows
+}
+
+// IsOpen returns true if the business is open at the given time.
+func (s *Schedule) IsOpen(t time.Time) bool {
+	if s.alwaysOpen {
+		return true
+	}
+	// Intervals of the previous day can cross midnight into the day of t.
+	for offset := -1; offset <= 0; offset++ {
+		for _, w := range s.Windows(s.dayOffset(t, offset)) {
+			if !t.Before(w.Start) && t.Before(w.End) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NextOpening returns the time the business is next open from t, t itself if it is open at t.
+// Returns false if the business does not open within the next two years.
+func (s *Schedule) NextOpening(t time.Time) (time.Time, bool) {
+	t = t.In(s.loc)
+	if s.alwaysOpen {
+		return t, true
+	}
+	// Intervals of the previous day can cross midnight into the day of t.
+	for offset := -1; offset <= maxNextOpeningDays; offset++ {
+		for _, w := range s.Windows(s.dayOffset(t, offset)) {
+			if !w.End.After(t) {
+				continue
+			}
+			if w.Start.After(t) {
+				return w.Start, true
+			}
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// isClosed returns true if the day is a full-day holiday.
+func (s *Schedule) isClosed(day time.Time) bool {
+	if _, ok := s.closedDays[day.Format(time.DateOnly)]; ok {
+		return true
+	}
+	_, ok := s.closedDays[day.Format(recurringDateLayout)]
+	return ok
+}
+
+// closuresOn returns the partial-day closures starting on the date of the day in their time zone.
+func (s *Schedule) closuresOn(day time.Time) []closure {
+	closures := s.closures[day.Format(time.DateOnly)]
+	if recurring := s.closures[day.Format(recurringDateLayout)]; len(recurring) > 0 {
+		closures = append(closures[:len(closures):len(closures)], recurring...)
+	}
+	return closures
+}
+
+// dayOffset returns the start of the day offset by the given number of days from the day of t in the schedule's time zone.
+func (s *Schedule) dayOffset(t time.Time, days int) time.Time {
+	t = t.In(s.loc)
+	return time.Date(t.Year(), t.Month(), t.Day()+days, 0, 0, 0, 0, s.loc)
+}
+
+// at returns the time the given minutes after the start of the day.
+func (s *Schedule) at(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, minutes, 0, 0, s.loc)
+}
+
+// subtractWindow removes the closed window from the windows.
+func subtractWindow(windows []Window, closed Window) []Window {
+	out := make([]Window, 0, len(windows)+1)
+	for _, w := range windows {
+		if !closed.Start.Before(w.End) || !closed.End.After(w.Start) {
+			out = append(out, w)
+			continue
+		}
+		if w.Start.Before(closed.Start) {
+			out = append(out, Window{Start: w.Start, End: closed.Start})
+		}
+		if closed.End.Before(w.End) {
+			out = append(out, Window{Start: closed.End, End: w.End})
+		}
+	}
+	return out
+}
@@ -0,0 +1,266 @@
+package businesshours
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleWindows(t *testing.T) {
+	bh := models.BusinessHours{
+		Hours: mustMarshalJSON(map[string]models.WorkingHours{
+			"Monday":    {Open: "09:00", Close: "17:00"},
+			"Tuesday":   {Open: "09:00", Close: "17:00"},
+			"Wednesday": {Open: "22:00", Close: "06:00"},
+			"Thursday":  {Open: "09:00", Close: "17:00"},
+		}),
+		Holidays: mustMarshalJSON([]models.Holiday{
+			{Name: "New Year", Date: "2020-01-01", Recurring: true},
+			{Name: "Offsite", Date: "2025-01-06", StartTime: "12:00", EndTime: "14:00"},
+			{Name: "Maintenance", Date: "2025-01-13", StartTime: "16:00", EndTime: "10:00"},
+			{Name: "Late Start", Date: "2020-01-09", StartTime: "00:00", EndTime: "02:00", Recurring: true},
+		}),
+	}
+	schedule, err := NewSchedule(bh, "UTC")
+	assert.NoError(t, err)
+
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2025, time.January, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		day      time.Time
+		expected []Window
+	}{
+		{
+			name:     "Working Day",
+			day:      at(7, 11, 0),
+			expected: []Window{{Start: at(7, 9, 0), End: at(7, 17, 0)}},
+		},
+		{
+			name:     "Non Working Day",
+			day:      at(5, 0, 0),
+			expected: []Window{},
+		},
+		{
+			name: "Recurring Holiday",
+			day:  at(1, 0, 0),
+		},
+		{
+			name:     "Partial Day Closure",
+			day:      at(6, 0, 0),
+			expected: []Window{{Start: at(6, 9, 0), End: at(6, 12, 0)}, {Start: at(6, 14, 0), End: at(6, 17, 0)}},
+		},
+		{
+			name:     "Closure Crossing Midnight",
+			day:      at(14, 0, 0),
+			expected: []Window{{Start: at(14, 10, 0), End: at(14, 17, 0)}},
+		},
+		{
+			name:     "Closure Cutting Into Previous Day's Interval",
+			day:      at(8, 0, 0),
+			expected: []Window{{Start: at(8, 22, 0), End: at(9, 0, 0)}, {Start: at(9, 2, 0), End: at(9, 6, 0)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, schedule.Windows(tt.day))
+		})
+	}
+
+	// Recurring holidays repeat every year.
+	assert.Nil(t, schedule.Windows(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	// One-off partial closures do not.
+	assert.Equal(t, []Window{{Start: time.Date(2026, time.January, 6, 9, 0, 0, 0, time.UTC), End: time.Date(2026, time.January, 6, 17, 0, 0, 0, time.UTC)}},
+		schedule.Windows(time.Date(2026, time.January, 6, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestScheduleIsOpen(t *testing.T) {
+	bh := models.BusinessHours{
+		Hours: mustMarshalJSON(map[string]models.WorkingHours{
+			"Monday": {Open: "22:00", Close: "06:00"},
+		}),
+		Holidays: mustMarshalJSON([]models.Holiday{
+			{Name: "Early Close", Date: "2025-01-07", StartTime: "04:00", EndTime: "06:00"},
+		}),
+	}
+	schedule, err := NewSchedule(bh, "Asia/Kolkata")
+	assert.NoError(t, err)
+
+	loc := schedule.Location()
+	assert.True(t, schedule.IsOpen(time.Date(2025, time.January, 6, 23, 0, 0, 0, loc)))
+	assert.True(t, schedule.IsOpen(time.Date(2025, time.January, 7, 3, 59, 0, 0, loc)))
+	assert.False(t, schedule.IsOpen(time.Date(2025, time.January, 7, 4, 0, 0, 0, loc)))
+	assert.False(t, schedule.IsOpen(time.Date(2025, time.January, 6, 21, 59, 0, 0, loc)))
+	// Same instant in UTC.
+	assert.True(t, schedule.IsOpen(time.Date(2025, time.January, 6, 17, 30, 0, 0, time.UTC)))
+
+	alwaysOpen, err := NewSchedule(models.BusinessHours{IsAlwaysOpen: true}, "UTC")
+	assert.NoError(t, err)
+	assert.True(t, alwaysOpen.IsOpen(time.Now()))
+
+	_, err = NewSchedule(bh, "Invalid/Zone")
+	assert.Error(t, err)
+}
+
+func TestScheduleNextOpening(t *testing.T) {
+	bh := models.BusinessHours{
+		Hours: mustMarshalJSON(map[string]models.WorkingHours{
+			"Monday":  {Intervals: []models.TimeInterval{{Open: "09:00", Close: "12:00"}, {Open: "13:00", Close: "17:00"}}},
+			"Tuesday": {Open: "09:00", Close: "17:00"},
+			"Friday":  {Open: "22:00", Close: "02:00"},
+		}),
+		Holidays: mustMarshalJSON([]models.Holiday{
+			{Name: "Offsite", Date: "2025-01-07", StartTime: "09:00", EndTime: "11:00"},
+		}),
+	}
+	schedule, err := NewSchedule(bh, "UTC")
+	assert.NoError(t, err)
+
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2025, time.January, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		expected time.Time
+	}{
+		{name: "Open", from: at(6, 10, 0), expected: at(6, 10, 0)},
+		{name: "Lunch Break", from: at(6, 12, 30), expected: at(6, 13, 0)},
+		{name: "After Closing", from: at(6, 18, 0), expected: at(7, 11, 0)},
+		{name: "Weekend", from: at(4, 12, 0), expected: at(6, 9, 0)},
+		{name: "Within Interval Crossing Midnight", from: at(11, 1, 0), expected: at(11, 1, 0)},
+		{name: "After Interval Crossing Midnight", from: at(11, 3, 0), expected: at(13, 9, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok := schedule.NextOpening(tt.from)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, next)
+		})
+	}
+
+	closed, err := NewSchedule(models.BusinessHours{Hours: mustMarshalJSON(map[string]models.WorkingHours{})}, "UTC")
+	assert.NoError(t, err)
+	_, ok := closed.NextOpening(at(6, 10, 0))
+	assert.False(t, ok)
+}
+
+func TestScheduleClosureTimezone(t *testing.T) {
+	bh := models.BusinessHours{
+		Hours: mustMarshalJSON(map[string]models.WorkingHours{
+			"Thursday": {Open: "09:00", Close: "17:00"},
+			"Friday":   {Open: "09:00", Close: "17:00"},
+			"Monday":   {Open: "09:00", Close: "17:00"},
+		}),
+		Holidays: mustMarshalJSON([]models.Holiday{
+			{Name: "Team Offsite", Date: "2025-03-06", StartTime: "06:30", EndTime: "08:30", Timezone: "UTC"},
+			{Name: "Outage", Date: "2025-03-07", Timezone: "UTC"},
+			{Name: "Town Hall", Date: "2025-03-10", StartTime: "09:00", EndTime: "10:30"},
+		}),
+	}
+	schedule, err := NewSchedule(bh, "Asia/Kolkata")
+	assert.NoError(t, err)
+
+	loc := schedule.Location()
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2025, time.March, day, hour, min, 0, 0, loc)
+	}
+
+	// Closures in another time zone are converted to the time zone of the schedule.
+	assert.Equal(t, []Window{{Start: at(6, 9, 0), End: at(6, 12, 0)}, {Start: at(6, 14, 0), End: at(6, 17, 0)}}, schedule.Windows(at(6, 0, 0)))
+	// A full day in UTC runs from 05:30 to 05:30 the next day.
+	assert.Empty(t, schedule.Windows(at(7, 0, 0)))
+	// Closures without a time zone are in the time zone of the schedule.
+	assert.Equal(t, []Window{{Start: at(10, 10, 30), End: at(10, 17, 0)}}, schedule.Windows(at(10, 0, 0)))
+
+	// The same closures in a UTC schedule.
+	schedule, err = NewSchedule(bh, "UTC")
+	assert.NoError(t, err)
+	assert.Equal(t, []Window{{Start: time.Date(2025, time.March, 6, 9, 0, 0, 0, time.UTC), End: time.Date(2025, time.March, 6, 17, 0, 0, 0, time.UTC)}},
+		schedule.Windows(time.Date(2025, time.March, 6, 0, 0, 0, 0, time.UTC)))
+	assert.Nil(t, schedule.Windows(time.Date(2025, time.March, 7, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestValidateHolidays(t *testing.T) {
+	tests := []struct {
+		name        string
+		holidays    []models.Holiday
+		expectError error
+	}{
+		{
+			name:     "Full Day",
+			holidays: []models.Holiday{{Name: "New Year", Date: "2025-01-01", Recurring: true}},
+		},
+		{
+			name:     "Partial Day Crossing Midnight",
+			holidays: []models.Holiday{{Name: "Maintenance", Date: "2025-01-01", StartTime: "22:00", EndTime: "02:00"}},
+		},
+		{
+			name:        "Missing Name",
+			holidays:    []models.Holiday{{Date: "2025-01-01"}},
+			expectError: ErrEmptyHoliday,
+		},
+		{
+			name:        "Invalid Date",
+			holidays:    []models.Holiday{{Name: "New Year", Date: "2025-13-01"}},
+			expectError: ErrInvalidDate,
+		},
+		{
+			name:        "Missing End Time",
+			holidays:    []models.Holiday{{Name: "Offsite", Date: "2025-01-01", StartTime: "12:00"}},
+			expectError: ErrMissingEndTime,
+		},
+		{
+			name:        "Invalid Time",
+			holidays:    []models.Holiday{{Name: "Offsite", Date: "2025-01-01", StartTime: "12:00", EndTime: "25:00"}},
+			expectError: ErrInvalidTime,
+		},
+		{
+			name:        "Empty Closure",
+			holidays:    []models.Holiday{{Name: "Offsite", Date: "2025-01-01", StartTime: "12:00", EndTime: "12:00"}},
+			expectError: ErrEmptyClosure,
+		},
+		{
+			name:     "Closure With Time Zone",
+			holidays: []models.Holiday{{Name: "Offsite", Date: "2025-01-01", StartTime: "12:00", EndTime: "14:00", Timezone: "Europe/London"}},
+		},
+		{
+			name:        "Invalid Time Zone",
+			holidays:    []models.Holiday{{Name: "Offsite", Date: "2025-01-01", StartTime: "12:00", EndTime: "14:00", Timezone: "Mars/Olympus"}},
+			expectError: ErrInvalidTimezone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHolidays(mustMarshalJSON(tt.holidays))
+			if tt.expectError == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectError)
+		})
+	}
+}
+
+func TestReplaceSyncedHolidays(t *testing.T) {
+	existing := []models.Holiday{
+		{Name: "Founders Day", Date: "2025-03-01"},
+		{Name: "Old Holiday", Date: "2025-01-01", Source: models.HolidaySourceURL},
+	}
+	synced := []models.Holiday{
+		{Name: "Republic Day", Date: "2025-01-26"},
+		{Name: "founders day", Date: "2025-03-01"},
+	}
+	assert.Equal(t, []models.Holiday{
+		{Name: "Republic Day", Date: "2025-01-26", Source: models.HolidaySourceURL},
+		{Name: "Founders Day", Date: "2025-03-01"},
+	}, ReplaceSyncedHolidays(existing, synced))
+}
@@ -15,8 +15,10 @@ import (
 	"sync"
 	"time"
 
+	aimodels "github.com/abhinavxd/libredesk/internal/ai/models"
 	"github.com/abhinavxd/libredesk/internal/automation"
 	amodels "github.com/abhinavxd/libredesk/internal/automation/models"
+	bmodels "github.com/abhinavxd/libredesk/internal/business_hours/models"
 	"github.com/abhinavxd/libredesk/internal/conversation/models"
 	pmodels "github.com/abhinavxd/libredesk/internal/conversation/priority/models"
 	smodels "github.com/abhinavxd/libredesk/internal/conversation/status/models"
@@ -32,9 +34,11 @@ import (
 	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
 	"github.com/abhinavxd/libredesk/internal/template"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	unmodels "github.com/abhinavxd/libredesk/internal/user_notification/models"
 	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
 	"github.com/abhinavxd/libredesk/internal/ws"
 	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
 	"github.com/knadh/go-i18n"
 	"github.com/lib/pq"
 	"github.com/volatiletech/null/v9"
@@ -67,6 +71,10 @@ type Manager struct {
 	settingsStore              settingsStore
 	csatStore                  csatStore
 	webhookStore               webhookStore
+	aiStore                    aiStore
+	businessHoursStore         businessHoursStore
+	notificationStore          notificationStore
+	outOfHoursReplyInterval    time.Duration
 	notifier                   *notifier.Service
 	lo                         *logf.Logger
 	db                         *sqlx.DB
@@ -83,9 +91,23 @@ type Manager struct {
 }
 
 type slaStore interface {
-	ApplySLA(startTime time.Time, conversationID, assignedTeamID, slaID int) (slaModels.SLAPolicy, error)
+	ApplySLA(startTime time.Time, conversationID, assignedTeamID, slaID int, manual bool) (slaModels.SLAPolicy, error)
 	CreateNextResponseSLAEvent(conversationID, appliedSLAID, slaPolicyID, assignedTeamID int) (time.Time, error)
 	SetLatestSLAEventMetAt(appliedSLAID int, metric string) (time.Time, error)
+	SyncPause(appliedSLAID int) error
+	GetPoliciesWithConditions() ([]slaModels.SLAPolicy, error)
+}
+
+type aiStore interface {
+	Triage(content string, taxonomy aimodels.TriageTaxonomy) (aimodels.TriageResult, error)
+}
+
+type businessHoursStore interface {
+	Get(id int) (bmodels.BusinessHours, error)
+}
+
+type notificationStore interface {
+	Notify(n unmodels.Notification) error
 }
 
 type statusStore interface {
@@ -99,6 +121,7 @@ type priorityStore interface {
 type teamStore interface {
 	Get(int) (tmodels.Team, error)
 	UserBelongsToTeam(userID, teamID int) (bool, error)
+	GetEventNotificationChannels(teamID int, event string) ([]tmodels.NotificationChannel, error)
 }
 
 type userStore interface {
@@ -123,6 +146,7 @@ type inboxStore interface {
 
 type settingsStore interface {
 	GetAppRootURL() (string, error)
+	Get(key string) (types.JSONText, error)
 }
 
 type csatStore interface {
@@ -140,6 +164,8 @@ type Opts struct {
 	Lo                       *logf.Logger
 	OutgoingMessageQueueSize int
 	IncomingMessageQueueSize int
+	// OutOfHoursReplyInterval is the minimum time between out-of-hours replies sent to a contact from an inbox.
+	OutOfHoursReplyInterval time.Duration
 }
 
 // New initializes a new conversation Manager.
@@ -165,6 +191,9 @@ func New(
 	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
 		return nil, err
 	}
+	if opts.OutOfHoursReplyInterval <= 0 {
+		opts.OutOfHoursReplyInterval = defaultOutOfHoursReplyInterval
+	}
 
 	c := &Manager{
 		q:                          q,
@@ -188,6 +217,7 @@ func New(
 		incomingMessageQueue:       make(chan models.IncomingMessage, opts.IncomingMessageQueueSize),
 		outgoingMessageQueue:       make(chan models.Message, opts.OutgoingMessageQueueSize),
 		outgoingProcessingMessages: sync.Map{},
+		outOfHoursReplyInterval:    opts.OutOfHoursReplyInterval,
 	}
 
 	return c, nil
@@ -236,6 +266,14 @@ type queries struct {
 	UpdateMessageStatus                *sqlx.Stmt `query:"update-message-status"`
 	MessageExistsBySourceID            *sqlx.Stmt `query:"message-exists-by-source-id"`
 	GetConversationByMessageID         *sqlx.Stmt `query:"get-conversation-by-message-id"`
+	GetFirstIncomingMessageText        *sqlx.Stmt `query:"get-first-incoming-message-text"`
+
+	// Out-of-hours reply queries.
+	ClaimOutOfHoursReply       *sqlx.Stmt `query:"claim-out-of-hours-reply"`
+	ReleaseOutOfHoursReply     *sqlx.Stmt `query:"release-out-of-hours-reply"`
+	InsertConversationFollower *sqlx.Stmt `query:"insert-conversation-follower"`
+	DeleteConversationFollower *sqlx.Stmt `query:"delete-conversation-follower"`
+	GetConversationFollowerIDs *sqlx.Stmt `query:"get-conversation-follower-ids"`
 }
 
 // CreateConversation creates a new conversation and returns its ID and UUID.
@@ -509,9 +547,9 @@ func (c *Manager) UpdateConversationUserAssignee(uuid string, assigneeID int, ac
 	// Evaluate automation rules.
 	c.automation.EvaluateConversationUpdateRules(conversation, amodels.EventConversationUserAssigned)
 
-	// Send email to assignee.
-	if err := c.SendAssignedConversationEmail([]int{assigneeID}, conversation); err != nil {
-		c.lo.Error("error sending assigned conversation email", "error", err)
+	// Notify the assignee.
+	if err := c.notifyAssignee(assigneeID, conversation, actor); err != nil {
+		c.lo.Error("error notifying assignee", "conversation_uuid", uuid, "assignee_id", assigneeID, "error", err)
 	}
 
 	if err := c.RecordAssigneeUserChange(uuid, assigneeID, actor); err != nil {
@@ -550,6 +588,7 @@ func (c *Manager) UpdateConversationTeamAssignee(uuid string, teamID int, actor
 		if err != nil {
 			return nil
 		}
+		c.notifyTeamChannels(team, conversation)
 		if team.SLAPolicyID.Int > 0 {
 			systemUser, err := c.userStore.GetSystemUser()
 			if err != nil {
@@ -605,9 +644,12 @@ func (c *Manager) UpdateConversationPriority(uuid string, priorityID int, priori
 		return envelope.NewError(envelope.GeneralError, c.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.conversation}"), nil)
 	}
 
-	// Evaluate automation rules for conversation priority change.
+	// Re-evaluate SLA policy conditions and automation rules for conversation priority change.
 	conversation, err := c.GetConversation(0, uuid)
 	if err == nil {
+		if err := c.ApplyMatchingSLA(conversation.ID); err != nil {
+			c.lo.Error("error applying matching SLA policy", "uuid", uuid, "error", err)
+		}
 		c.automation.EvaluateConversationUpdateRules(conversation, amodels.EventConversationPriorityChange)
 	}
 
@@ -664,6 +706,13 @@ func (c *Manager) UpdateConversationStatus(uuid string, statusID int, status, sn
 		return envelope.NewError(envelope.GeneralError, c.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.conversation}"), nil)
 	}
 
+	// Pause or resume the SLA clock, the SLA policy may pause on the new status.
+	if conversationBeforeChange.AppliedSLAID.Valid {
+		if err := c.slaStore.SyncPause(conversationBeforeChange.AppliedSLAID.Int); err != nil {
+			c.lo.Error("error syncing SLA pause", "uuid", uuid, "error", err)
+		}
+	}
+
 	// Trigger webhook for conversation status change
 	var snoozeUntilStr string
 	if !snoozeUntil.IsZero() {
@@ -801,6 +850,25 @@ func (m *Manager) SendAssignedConversationEmail(userIDs []int, conversation mode
 		return fmt.Errorf("fetching agent: %w", err)
 	}
 
+	subject, content, err := m.renderAssignedConversationEmail(agent, conversation)
+	if err != nil {
+		return err
+	}
+	nm := notifier.Message{
+		RecipientEmails: []string{agent.Email.String},
+		Subject:         subject,
+		Content:         content,
+		Provider:        notifier.ProviderEmail,
+	}
+	if err := m.notifier.Send(nm); err != nil {
+		m.lo.Error("error sending notification message", "template", template.TmplConversationAssigned, "conversation_uuid", conversation.UUID, "error", err)
+		return fmt.Errorf("sending notification message with template %s: %w", template.TmplConversationAssigned, err)
+	}
+	return nil
+}
+
+// renderAssignedConversationEmail renders the subject and content of the email for a conversation assigned to the agent.
+func (m *Manager) renderAssignedConversationEmail(agent umodels.User, conversation models.Conversation) (string, string, error) {
 	content, subject, err := m.template.RenderStoredEmailTemplate(template.TmplConversationAssigned,
 		map[string]any{
 			"Conversation": map[string]any{
@@ -831,19 +899,9 @@ func (m *Manager) SendAssignedConversationEmail(userIDs []int, conversation mode
 		})
 	if err != nil {
 		m.lo.Error("error rendering template", "template", template.TmplConversationAssigned, "conversation_uuid", conversation.UUID, "error", err)
-		return fmt.Errorf("rendering template: %w", err)
-	}
-	nm := notifier.Message{
-		RecipientEmails: []string{agent.Email.String},
-		Subject:         subject,
-		Content:         content,
-		Provider:        notifier.ProviderEmail,
-	}
-	if err := m.notifier.Send(nm); err != nil {
-		m.lo.Error("error sending notification message", "template", template.TmplConversationAssigned, "conversation_uuid", conversation.UUID, "error", err)
-		return fmt.Errorf("sending notification message with template %s: %w", template.TmplConversationAssigned, err)
+		return "", "", fmt.Errorf("rendering template: %w", err)
 	}
-	return nil
+	return subject, content, nil
 }
 
 // UnassignOpen unassigns all open conversations belonging to a user.
@@ -856,9 +914,9 @@ func (m *Manager) UnassignOpen(userID int) error {
 	return nil
 }
 
-// ApplySLA applies the SLA policy to a conversation.
+// ApplySLA applies the SLA policy to a conversation. Policies applied by agents are not replaced by matching policies.
 func (m *Manager) ApplySLA(conversation models.Conversation, policyID int, actor umodels.User) error {
-	policy, err := m.slaStore.ApplySLA(conversation.CreatedAt, conversation.ID, conversation.AssignedTeamID.Int, policyID)
+	policy, err := m.slaStore.ApplySLA(conversation.CreatedAt, conversation.ID, conversation.AssignedTeamID.Int, policyID, !actor.IsSystemUser())
 	if err != nil {
 		m.lo.Error("error applying SLA to conversation", "conversation_id", conversation.ID, "policy_id", policyID, "error", err)
 		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorApplying", "name", m.i18n.Ts("globals.terms.sla")), nil)
@@ -871,6 +929,45 @@ func (m *Manager) ApplySLA(conversation models.Conversation, policyID int, actor
 	return nil
 }
 
+// ApplyMatchingSLA applies the first SLA policy whose conditions match the conversation, deadlines are recalculated from the conversation creation time.
+// Nothing is done if no policy matches, the matching policy is already applied or an agent applied an SLA manually.
+func (m *Manager) ApplyMatchingSLA(conversationID int) error {
+	policies, err := m.slaStore.GetPoliciesWithConditions()
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	conversation, err := m.GetConversation(conversationID, "")
+	if err != nil {
+		return err
+	}
+	if conversation.SLAAppliedManually {
+		m.lo.Debug("SLA applied manually, skipping matching SLA policies", "conversation_id", conversationID)
+		return nil
+	}
+
+	for _, policy := range policies {
+		if policy.Conditions.IsEmpty() || !m.automation.EvaluateConditions(policy.Conditions.Groups, policy.Conditions.GroupOperator, conversation) {
+			continue
+		}
+		if conversation.SLAPolicyID.Int == policy.ID {
+			m.lo.Debug("matching SLA policy already applied", "conversation_id", conversationID, "policy_id", policy.ID)
+			return nil
+		}
+
+		m.lo.Info("applying matching SLA policy", "conversation_id", conversationID, "policy_id", policy.ID)
+		systemUser, err := m.userStore.GetSystemUser()
+		if err != nil {
+			return err
+		}
+		return m.ApplySLA(conversation, policy.ID, systemUser)
+	}
+	return nil
+}
+
 // ApplyAction applies an action to a conversation, this can be called from multiple packages across the app to perform actions on conversations.
 // all actions are executed on behalf of the provided user if the user is not provided, system user is used.
 func (m *Manager) ApplyAction(action amodels.RuleAction, conv models.Conversation, user umodels.User) error {
@@ -954,6 +1051,8 @@ func (m *Manager) ApplyAction(action amodels.RuleAction, conv models.Conversatio
 		return m.SetConversationTags(conv.UUID, action.Type, action.Value, user)
 	case amodels.ActionSendCSAT:
 		return m.SendCSATReply(user.ID, conv)
+	case amodels.ActionAITriage:
+		return m.applyAITriage(conv, action.Value[0], user)
 	default:
 		return fmt.Errorf("unknown action: %s", action.Type)
 	}
@@ -0,0 +1,73 @@
+package conversation
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	slaModels "github.com/abhinavxd/libredesk/internal/sla/models"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/knadh/go-i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+var errApplySLA = errors.New("apply SLA")
+
+// fakeSLAStore records the SLAs applied, applying fails so nothing else is recorded.
+type fakeSLAStore struct {
+	slaStore
+	policies []slaModels.SLAPolicy
+	applied  []bool
+}
+
+func (s *fakeSLAStore) GetPoliciesWithConditions() ([]slaModels.SLAPolicy, error) {
+	return s.policies, nil
+}
+
+func (s *fakeSLAStore) ApplySLA(_ time.Time, _, _, _ int, manual bool) (slaModels.SLAPolicy, error) {
+	s.applied = append(s.applied, manual)
+	return slaModels.SLAPolicy{}, errApplySLA
+}
+
+func newSLAManager(t *testing.T, appliedManually bool) (*Manager, *fakeSLAStore) {
+	t.Helper()
+	db := dbtest.New(func(query string, args []driver.Value) (dbtest.Result, error) {
+		return dbtest.Result{
+			Columns: []string{"id", "uuid", "sla_policy_id", "sla_applied_manually"},
+			Rows:    [][]any{{1, "c1", 2, appliedManually}},
+		}, nil
+	})
+	var q queries
+	require.NoError(t, dbutil.ScanSQLFile("queries.sql", &q, db.DB, efs))
+	i18n, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	require.NoError(t, err)
+	lo := logf.New(logf.Opts{})
+
+	sla := &fakeSLAStore{policies: []slaModels.SLAPolicy{{ID: 3}}}
+	return &Manager{q: q, lo: &lo, i18n: i18n, slaStore: sla}, sla
+}
+
+func TestApplySLAManual(t *testing.T) {
+	m, sla := newSLAManager(t, false)
+	conversation := models.Conversation{ID: 1, UUID: "c1"}
+
+	// SLAs applied by agents are marked manual, those applied by the system are not.
+	assert.Error(t, m.ApplySLA(conversation, 3, umodels.User{ID: 5, Email: null.StringFrom("agent@example.com")}))
+	assert.Error(t, m.ApplySLA(conversation, 3, umodels.User{ID: 1, Email: null.StringFrom(umodels.SystemUserEmail)}))
+	assert.Equal(t, []bool{true, false}, sla.applied)
+}
+
+func TestApplyMatchingSLASkipsManual(t *testing.T) {
+	m, sla := newSLAManager(t, true)
+
+	// Matching policies are not evaluated if an agent applied the current SLA.
+	require.NoError(t, m.ApplyMatchingSLA(1))
+	assert.Empty(t, sla.applied)
+}
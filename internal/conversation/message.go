@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/textproto"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -176,6 +177,15 @@ func (m *Manager) sendOutgoingMessage(message models.Message) {
 		message.InReplyTo = message.References[len(message.References)-1]
 	}
 
+	// Mark automatic replies so mail servers and clients don't auto-reply to them in turn.
+	if message.IsAutoReply() {
+		if message.Headers == nil {
+			message.Headers = textproto.MIMEHeader{}
+		}
+		message.Headers.Set("Auto-Submitted", "auto-replied")
+		message.Headers.Set("X-Auto-Response-Suppress", "All")
+	}
+
 	// Send message
 	err = inbox.Send(message)
 	if handleError(err, "error sending message") {
@@ -450,6 +460,7 @@ func (m *Manager) QueueReply(media []mmodels.Media, inboxID, senderID int, conve
 	if err := m.InsertMessage(&message); err != nil {
 		return models.Message{}, err
 	}
+	m.notifyFollowers(message)
 	return message, nil
 }
 
@@ -513,9 +524,11 @@ func (m *Manager) InsertMessage(message *models.Message) error {
 
 // RecordAssigneeUserChange records an activity for a user assignee change.
 func (m *Manager) RecordAssigneeUserChange(conversationUUID string, assigneeID int, actor umodels.User) error {
+	meta := map[string]any{"assignee_id": assigneeID}
+
 	// Self assignment.
 	if assigneeID == actor.ID {
-		return m.InsertConversationActivity(models.ActivitySelfAssign, conversationUUID, actor.FullName(), actor)
+		return m.insertConversationActivity(models.ActivitySelfAssign, conversationUUID, actor.FullName(), meta, actor)
 	}
 
 	// Assignment to another user.
@@ -523,7 +536,7 @@ func (m *Manager) RecordAssigneeUserChange(conversationUUID string, assigneeID i
 	if err != nil {
 		return err
 	}
-	return m.InsertConversationActivity(models.ActivityAssignedUserChange, conversationUUID, assignee.FullName(), actor)
+	return m.insertConversationActivity(models.ActivityAssignedUserChange, conversationUUID, assignee.FullName(), meta, actor)
 }
 
 // RecordAssigneeTeamChange records an activity for a team assignee change.
@@ -550,6 +563,11 @@ func (m *Manager) RecordSLASet(conversationUUID string, slaName string, actor um
 	return m.InsertConversationActivity(models.ActivitySLASet, conversationUUID, slaName, actor)
 }
 
+// RecordSLAEscalation records an activity for an SLA escalation.
+func (m *Manager) RecordSLAEscalation(conversationUUID string, escalation string, actor umodels.User) error {
+	return m.InsertConversationActivity(models.ActivitySLAEscalated, conversationUUID, escalation, actor)
+}
+
 // RecordTagAddition records an activity for a tag addition.
 func (m *Manager) RecordTagAddition(conversationUUID string, tag string, actor umodels.User) error {
 	return m.InsertConversationActivity(models.ActivityTagAdded, conversationUUID, tag, actor)
@@ -562,12 +580,28 @@ func (m *Manager) RecordTagRemoval(conversationUUID string, tag string, actor um
 
 // InsertConversationActivity inserts an activity message.
 func (m *Manager) InsertConversationActivity(activityType, conversationUUID, newValue string, actor umodels.User) error {
+	return m.insertConversationActivity(activityType, conversationUUID, newValue, nil, actor)
+}
+
+// insertConversationActivity inserts an activity message, the activity type, new value and any extra meta are
+// stored in the message meta for reports.
+func (m *Manager) insertConversationActivity(activityType, conversationUUID, newValue string, extraMeta map[string]any, actor umodels.User) error {
 	content, err := m.getMessageActivityContent(activityType, newValue, actor.FullName())
 	if err != nil {
 		m.lo.Error("error could not generate activity content", "error", err)
 		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorGenerating", "name", "{globals.terms.activityMessage}"), nil)
 	}
 
+	meta := map[string]any{"activity_type": activityType, "new_value": newValue}
+	for k, v := range extraMeta {
+		meta[k] = v
+	}
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		m.lo.Error("error marshalling activity meta", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorInserting", "name", "{globals.terms.activityMessage}"), nil)
+	}
+
 	message := models.Message{
 		Type:             models.MessageActivity,
 		Status:           models.MessageStatusSent,
@@ -577,6 +611,7 @@ func (m *Manager) InsertConversationActivity(activityType, conversationUUID, new
 		Private:          true,
 		SenderID:         actor.ID,
 		SenderType:       models.SenderTypeAgent,
+		Meta:             metaJSON,
 	}
 
 	if err := m.InsertMessage(&message); err != nil {
@@ -616,6 +651,8 @@ func (m *Manager) getMessageActivityContent(activityType, newValue, actorName st
 		content = fmt.Sprintf("%s removed tag %s", actorName, newValue)
 	case models.ActivitySLASet:
 		content = fmt.Sprintf("%s set %s SLA policy", actorName, newValue)
+	case models.ActivitySLAEscalated:
+		content = fmt.Sprintf("%s escalated the conversation on SLA %s", actorName, newValue)
 	default:
 		return "", fmt.Errorf("invalid activity type %s", activityType)
 	}
@@ -662,10 +699,18 @@ func (m *Manager) processIncomingMessage(in models.IncomingMessage) error {
 
 	// Evaluate automation rules & send webhook events.
 	if isNewConversation {
+		// Apply the SLA policy whose conditions match the new conversation.
+		if err := m.ApplyMatchingSLA(in.Message.ConversationID); err != nil {
+			m.lo.Error("error applying matching SLA policy", "conversation_id", in.Message.ConversationID, "error", err)
+		}
+
 		conversation, err := m.GetConversation(in.Message.ConversationID, "")
 		if err == nil {
 			m.webhookStore.TriggerEvent(wmodels.EventConversationCreated, conversation)
 			m.automation.EvaluateNewConversationRules(conversation)
+			if err := m.sendOutOfHoursReply(in.InboxID, time.Now(), conversation); err != nil {
+				m.lo.Error("error sending out-of-hours reply", "conversation_id", conversation.ID, "error", err)
+			}
 		}
 		return nil
 	}
@@ -680,6 +725,8 @@ func (m *Manager) processIncomingMessage(in models.IncomingMessage) error {
 		}
 	}
 
+	m.notifyFollowers(in.Message)
+
 	// Set waiting since timestamp, this gets cleared when agent replies to the conversation.
 	now := time.Now()
 	m.UpdateConversationWaitingSince(in.Message.ConversationUUID, &now)
@@ -693,6 +740,10 @@ func (m *Manager) processIncomingMessage(in models.IncomingMessage) error {
 		// Trigger automations on incoming message event.
 		m.automation.EvaluateConversationUpdateRules(conversation, amodels.EventConversationMessageIncoming)
 
+		if err := m.sendOutOfHoursReply(in.InboxID, time.Now(), conversation); err != nil {
+			m.lo.Error("error sending out-of-hours reply", "conversation_id", conversation.ID, "error", err)
+		}
+
 		if conversation.SLAPolicyID.Int == 0 {
 			m.lo.Info("no SLA policy applied to conversation, skipping next response SLA event creation")
 			return nil
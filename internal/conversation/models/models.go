@@ -47,6 +47,7 @@ var (
 	ActivityTagAdded           = "tag_added"
 	ActivityTagRemoved         = "tag_removed"
 	ActivitySLASet             = "sla_set"
+	ActivitySLAEscalated       = "sla_escalated"
 
 	ContentTypeText = "text"
 	ContentTypeHTML = "html"
@@ -127,6 +128,7 @@ type Conversation struct {
 	SLAPolicyID           null.Int               `db:"sla_policy_id" json:"sla_policy_id"`
 	SlaPolicyName         null.String            `db:"sla_policy_name" json:"sla_policy_name"`
 	AppliedSLAID          null.Int               `db:"applied_sla_id" json:"applied_sla_id"`
+	SLAAppliedManually    bool                   `db:"sla_applied_manually" json:"sla_applied_manually"`
 	FirstResponseDueAt    null.Time              `db:"first_response_deadline_at" json:"first_response_deadline_at"`
 	ResolutionDueAt       null.Time              `db:"resolution_deadline_at" json:"resolution_deadline_at"`
 	NextResponseDueAt     null.Time              `db:"next_response_deadline_at" json:"next_response_deadline_at"`
@@ -248,6 +250,16 @@ func (m *Message) HasCSAT() bool {
 	return isCsat
 }
 
+// IsAutoReply returns true if the message is an automatic reply.
+func (m *Message) IsAutoReply() bool {
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(m.Meta), &meta); err != nil {
+		return false
+	}
+	isAutoReply, _ := meta["is_auto_reply"].(bool)
+	return isAutoReply
+}
+
 // IncomingMessage links a message with the contact information and inbox id.
 type IncomingMessage struct {
 	Message Message
@@ -0,0 +1,181 @@
+package conversation
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	unmodels "github.com/abhinavxd/libredesk/internal/user_notification/models"
+	"github.com/volatiletech/null/v9"
+)
+
+const (
+	// maxNotificationBodyLength is the maximum length of the message excerpt in notifications.
+	maxNotificationBodyLength = 200
+)
+
+// SetNotificationStore sets the store used to notify agents of assignments, mentions and replies.
+func (m *Manager) SetNotificationStore(store notificationStore) {
+	m.notificationStore = store
+}
+
+// FollowConversation makes an agent follow a conversation to be notified of new replies.
+func (m *Manager) FollowConversation(uuid string, userID int) error {
+	if _, err := m.q.InsertConversationFollower.Exec(uuid, userID); err != nil {
+		m.lo.Error("error following conversation", "conversation_uuid", uuid, "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.conversation}"), nil)
+	}
+	return nil
+}
+
+// UnfollowConversation stops an agent from following a conversation.
+func (m *Manager) UnfollowConversation(uuid string, userID int) error {
+	if _, err := m.q.DeleteConversationFollower.Exec(uuid, userID); err != nil {
+		m.lo.Error("error unfollowing conversation", "conversation_uuid", uuid, "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.conversation}"), nil)
+	}
+	return nil
+}
+
+// GetConversationFollowers returns the IDs of the agents following a conversation.
+func (m *Manager) GetConversationFollowers(uuid string) ([]int, error) {
+	var ids = make([]int, 0)
+	if err := m.q.GetConversationFollowerIDs.Select(&ids, 0, uuid); err != nil {
+		m.lo.Error("error fetching conversation followers", "conversation_uuid", uuid, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.conversation}"), nil)
+	}
+	return ids, nil
+}
+
+// NotifyMentions notifies the agents mentioned in a private note, the agents are expected to have access to the conversation.
+func (m *Manager) NotifyMentions(message models.Message, agents []umodels.User, actor umodels.User) {
+	if m.notificationStore == nil || len(agents) == 0 {
+		return
+	}
+	conversation, err := m.GetConversation(message.ConversationID, message.ConversationUUID)
+	if err != nil {
+		m.lo.Error("error fetching conversation for mention notifications", "conversation_uuid", message.ConversationUUID, "error", err)
+		return
+	}
+
+	notified := make(map[int]bool, len(agents))
+	for _, agent := range agents {
+		if agent.ID == actor.ID || notified[agent.ID] {
+			continue
+		}
+		notified[agent.ID] = true
+		if err := m.notificationStore.Notify(unmodels.Notification{
+			UserID:           agent.ID,
+			Type:             unmodels.TypeMention,
+			Title:            m.i18n.Ts("notification.mention.title", "name", actor.FullName(), "reference", conversation.ReferenceNumber),
+			Body:             stringutil.Truncate(message.TextContent, maxNotificationBodyLength),
+			ConversationID:   null.IntFrom(conversation.ID),
+			ConversationUUID: null.StringFrom(conversation.UUID),
+			MessageID:        null.IntFrom(message.ID),
+			ActorID:          null.IntFrom(actor.ID),
+		}); err != nil {
+			m.lo.Error("error sending mention notification", "user_id", agent.ID, "conversation_uuid", conversation.UUID, "error", err)
+		}
+	}
+}
+
+// notifyAssignee notifies an agent of a conversation assigned to them.
+func (m *Manager) notifyAssignee(assigneeID int, conversation models.Conversation, actor umodels.User) error {
+	if m.notificationStore == nil {
+		return m.SendAssignedConversationEmail([]int{assigneeID}, conversation)
+	}
+	agent, err := m.userStore.GetAgent(assigneeID, "")
+	if err != nil {
+		return fmt.Errorf("fetching agent: %w", err)
+	}
+	subject, content, err := m.renderAssignedConversationEmail(agent, conversation)
+	if err != nil {
+		return err
+	}
+	n := unmodels.Notification{
+		UserID:           assigneeID,
+		Type:             unmodels.TypeAssigned,
+		Title:            m.i18n.Ts("notification.assigned.title", "reference", conversation.ReferenceNumber),
+		Body:             conversation.Subject.String,
+		ConversationID:   null.IntFrom(conversation.ID),
+		ConversationUUID: null.StringFrom(conversation.UUID),
+		EmailSubject:     subject,
+		EmailContent:     content,
+	}
+	if actor.ID > 0 {
+		n.ActorID = null.IntFrom(actor.ID)
+	}
+	return m.notificationStore.Notify(n)
+}
+
+// notifyFollowers notifies the agents following a conversation of a new reply, except the author of the reply.
+func (m *Manager) notifyFollowers(message models.Message) {
+	// Private notes, automatic replies and CSAT surveys are not replies followers are notified of.
+	if m.notificationStore == nil || message.Private || message.IsAutoReply() || message.HasCSAT() {
+		return
+	}
+	var followers []int
+	if err := m.q.GetConversationFollowerIDs.Select(&followers, message.ConversationID, message.ConversationUUID); err != nil {
+		m.lo.Error("error fetching conversation followers", "conversation_uuid", message.ConversationUUID, "error", err)
+		return
+	}
+	followers = slices.DeleteFunc(followers, func(id int) bool { return id == message.SenderID })
+	if len(followers) == 0 {
+		return
+	}
+
+	conversation, err := m.GetConversation(message.ConversationID, message.ConversationUUID)
+	if err != nil {
+		m.lo.Error("error fetching conversation for follower notifications", "conversation_uuid", message.ConversationUUID, "error", err)
+		return
+	}
+	for _, userID := range followers {
+		n := unmodels.Notification{
+			UserID:           userID,
+			Type:             unmodels.TypeNewReply,
+			Title:            m.i18n.Ts("notification.newReply.title", "reference", conversation.ReferenceNumber),
+			Body:             stringutil.Truncate(message.TextContent, maxNotificationBodyLength),
+			ConversationID:   null.IntFrom(conversation.ID),
+			ConversationUUID: null.StringFrom(conversation.UUID),
+			MessageID:        null.IntFrom(message.ID),
+		}
+		if message.SenderType == models.SenderTypeAgent {
+			n.ActorID = null.IntFrom(message.SenderID)
+		}
+		if err := m.notificationStore.Notify(n); err != nil {
+			m.lo.Error("error sending new reply notification", "user_id", userID, "conversation_uuid", conversation.UUID, "error", err)
+		}
+	}
+}
+
+// notifyTeamChannels posts a conversation assigned to a team to the notification channels of the team.
+func (m *Manager) notifyTeamChannels(team tmodels.Team, conversation models.Conversation) {
+	channels, err := m.teamStore.GetEventNotificationChannels(team.ID, tmodels.ChannelEventAssigned)
+	if err != nil {
+		m.lo.Error("error fetching team notification channels", "team_id", team.ID, "error", err)
+		return
+	}
+	if len(channels) == 0 {
+		return
+	}
+	rootURL, err := m.settingsStore.GetAppRootURL()
+	if err != nil {
+		m.lo.Error("error fetching app root URL", "error", err)
+	}
+	for _, channel := range channels {
+		if err := m.notifier.Send(notifier.Message{
+			Subject:    m.i18n.Ts("notification.teamAssigned.title", "reference", conversation.ReferenceNumber, "team", team.Name),
+			AltContent: conversation.Subject.String,
+			Link:       rootURL + "/inboxes/assigned/conversation/" + conversation.UUID,
+			Provider:   channel.Provider,
+			Chat:       channel.Target(),
+		}); err != nil {
+			m.lo.Error("error sending assignment to team channel", "team_id", team.ID, "channel_id", channel.ID, "error", err)
+		}
+	}
+}
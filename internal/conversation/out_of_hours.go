@@ -0,0 +1,202 @@
+package conversation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	businesshours "github.com/abhinavxd/libredesk/internal/business_hours"
+	bmodels "github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	imodels "github.com/abhinavxd/libredesk/internal/inbox/models"
+)
+
+const (
+	defaultOutOfHoursReplyInterval = 12 * time.Hour
+
+	// DefaultOutOfHoursReply is the reply sent when an inbox has out-of-hours replies enabled without a reply of its own.
+	DefaultOutOfHoursReply = `<p>Hi {{ .Contact.FirstName }},</p>
+<p>Thanks for reaching out. We are currently closed{{ if .NextOpening }} and will be back on {{ .NextOpening }}{{ end }}. We will get back to you as soon as we can.</p>`
+
+	// nextOpeningLayout is the layout of the next opening time in out-of-hours replies.
+	nextOpeningLayout = "Monday, 02 Jan 2006 15:04 MST"
+)
+
+// SetBusinessHoursStore sets the business hours store used to check if an incoming message arrived outside business hours.
+func (m *Manager) SetBusinessHoursStore(store businessHoursStore) {
+	m.businessHoursStore = store
+}
+
+// sendOutOfHoursReply replies to an incoming message that arrived outside business hours if the inbox has out-of-hours replies enabled.
+// The business hours of the inbox are used, else the business hours of the conversation's team, else the default business hours of the app.
+// A contact gets at most one out-of-hours reply from an inbox within the out-of-hours reply interval.
+func (m *Manager) sendOutOfHoursReply(inboxID int, receivedAt time.Time, conversation models.Conversation) error {
+	if m.businessHoursStore == nil {
+		return nil
+	}
+	inbox, err := m.inboxStore.GetDBRecord(inboxID)
+	if err != nil {
+		return fmt.Errorf("fetching inbox: %w", err)
+	}
+	if !inbox.OutOfHoursReplyEnabled || conversation.Contact.Email.String == "" {
+		return nil
+	}
+
+	businessHours, timezone, err := m.getInboxBusinessHours(inbox, conversation.AssignedTeamID.Int)
+	if err != nil {
+		return err
+	}
+	if businessHours.ID == 0 {
+		return nil
+	}
+	schedule, err := businesshours.NewSchedule(businessHours, timezone)
+	if err != nil {
+		return fmt.Errorf("parsing business hours %d: %w", businessHours.ID, err)
+	}
+	if schedule.IsOpen(receivedAt) {
+		return nil
+	}
+
+	// The reply is prepared before it is claimed, so that a broken reply does not use up the contact's reply.
+	var nextOpening string
+	if next, ok := schedule.NextOpening(receivedAt); ok {
+		nextOpening = next.Format(nextOpeningLayout)
+	}
+	content, err := m.renderOutOfHoursReply(inbox, businessHours, nextOpening, conversation)
+	if err != nil {
+		return err
+	}
+	systemUser, err := m.userStore.GetSystemUser()
+	if err != nil {
+		return fmt.Errorf("fetching system user: %w", err)
+	}
+
+	// Claim the reply for the contact, nothing is returned if a reply was sent recently.
+	var id int
+	if err := m.q.ClaimOutOfHoursReply.Get(&id, inbox.ID, conversation.ContactID, m.outOfHoursReplyInterval.Seconds()); err != nil {
+		if err == sql.ErrNoRows {
+			m.lo.Debug("skipping out-of-hours reply as one was sent recently", "inbox_id", inbox.ID, "contact_id", conversation.ContactID)
+			return nil
+		}
+		return fmt.Errorf("claiming out-of-hours reply: %w", err)
+	}
+
+	// Store `is_auto_reply` meta to send the reply with auto-reply headers.
+	meta := map[string]interface{}{
+		"is_auto_reply": true,
+	}
+	if _, err := m.QueueReply(nil /**media**/, inbox.ID, systemUser.ID, conversation.UUID, content, []string{conversation.Contact.Email.String}, nil, nil, meta); err != nil {
+		// Release the claim so that the next message from the contact gets a reply.
+		if _, rerr := m.q.ReleaseOutOfHoursReply.Exec(id); rerr != nil {
+			m.lo.Error("error releasing out-of-hours reply", "inbox_id", inbox.ID, "contact_id", conversation.ContactID, "error", rerr)
+		}
+		return fmt.Errorf("queueing out-of-hours reply: %w", err)
+	}
+	m.lo.Info("out-of-hours reply queued", "conversation_uuid", conversation.UUID, "inbox_id", inbox.ID, "next_opening", nextOpening)
+	return nil
+}
+
+// renderOutOfHoursReply renders the out-of-hours reply of the inbox, contact and conversation values are HTML escaped
+// as they come from the contact.
+func (m *Manager) renderOutOfHoursReply(inbox imodels.Inbox, businessHours bmodels.BusinessHours, nextOpening string, conversation models.Conversation) (string, error) {
+	reply := inbox.OutOfHoursReply
+	if reply == "" {
+		reply = DefaultOutOfHoursReply
+	}
+	data := map[string]any{
+		"Conversation": map[string]any{
+			"ReferenceNumber": conversation.ReferenceNumber,
+			"Subject":         conversation.Subject.String,
+			"UUID":            conversation.UUID,
+		},
+		"Contact": map[string]any{
+			"FirstName": conversation.Contact.FirstName,
+			"LastName":  conversation.Contact.LastName,
+			"FullName":  conversation.Contact.FullName(),
+			"Email":     conversation.Contact.Email.String,
+		},
+		"Inbox": map[string]any{
+			"Name": inbox.Name,
+		},
+		"BusinessHours": map[string]any{
+			"Name": businessHours.Name,
+		},
+		"NextOpening": nextOpening,
+	}
+	content, err := m.template.RenderHTMLTemplate(reply, data)
+	if err != nil {
+		return "", fmt.Errorf("rendering out-of-hours reply: %w", err)
+	}
+	return content, nil
+}
+
+// getInboxBusinessHours returns the business hours and timezone that apply to messages received in the inbox.
+// Business hours are looked up on the inbox, then the team, then the app settings. The timezone of the team is used if the conversation has a team,
+// else the timezone of the app. Returns empty business hours if none are configured.
+func (m *Manager) getInboxBusinessHours(inbox imodels.Inbox, teamID int) (bmodels.BusinessHours, string, error) {
+	var (
+		businessHoursID = inbox.BusinessHoursID.Int
+		timezone        string
+	)
+	if teamID > 0 {
+		team, err := m.teamStore.Get(teamID)
+		if err != nil {
+			return bmodels.BusinessHours{}, "", fmt.Errorf("fetching team: %w", err)
+		}
+		if businessHoursID == 0 {
+			businessHoursID = team.BusinessHoursID.Int
+		}
+		timezone = team.Timezone
+	}
+
+	if businessHoursID == 0 {
+		id, err := m.getAppSetting("app.business_hours_id")
+		if err != nil {
+			return bmodels.BusinessHours{}, "", err
+		}
+		businessHoursID, _ = strconv.Atoi(id)
+	}
+	if businessHoursID == 0 {
+		return bmodels.BusinessHours{}, "", nil
+	}
+	if timezone == "" {
+		tz, err := m.getAppSetting("app.timezone")
+		if err != nil {
+			return bmodels.BusinessHours{}, "", err
+		}
+		timezone = tz
+	}
+	if timezone == "" {
+		return bmodels.BusinessHours{}, "", fmt.Errorf("timezone not configured")
+	}
+
+	businessHours, err := m.businessHoursStore.Get(businessHoursID)
+	if err != nil {
+		if err == businesshours.ErrBusinessHoursNotFound {
+			return bmodels.BusinessHours{}, "", nil
+		}
+		return bmodels.BusinessHours{}, "", fmt.Errorf("fetching business hours %d: %w", businessHoursID, err)
+	}
+	return businessHours, timezone, nil
+}
+
+// getAppSetting returns an app setting as a string, settings stored as numbers are formatted.
+func (m *Manager) getAppSetting(key string) (string, error) {
+	b, err := m.settingsStore.Get(key)
+	if err != nil {
+		return "", fmt.Errorf("fetching setting %s: %w", key, err)
+	}
+	var value any
+	if err := json.Unmarshal(b, &value); err != nil {
+		return "", fmt.Errorf("parsing setting %s: %w", key, err)
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return "", nil
+}
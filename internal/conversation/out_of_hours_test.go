@@ -0,0 +1,280 @@
+package conversation
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	businesshours "github.com/abhinavxd/libredesk/internal/business_hours"
+	bmodels "github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	"github.com/abhinavxd/libredesk/internal/inbox"
+	imodels "github.com/abhinavxd/libredesk/internal/inbox/models"
+	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
+	"github.com/abhinavxd/libredesk/internal/template"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/knadh/go-i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+var errNoSystemUser = errors.New("no system user")
+
+type fakeInboxStore struct {
+	inboxes map[int]imodels.Inbox
+}
+
+func (s fakeInboxStore) Get(int) (inbox.Inbox, error) { return nil, nil }
+
+func (s fakeInboxStore) GetDBRecord(id int) (imodels.Inbox, error) {
+	return s.inboxes[id], nil
+}
+
+type fakeTeamStore struct {
+	teams map[int]tmodels.Team
+}
+
+func (s fakeTeamStore) Get(id int) (tmodels.Team, error) { return s.teams[id], nil }
+
+func (s fakeTeamStore) UserBelongsToTeam(int, int) (bool, error) { return false, nil }
+
+func (s fakeTeamStore) GetEventNotificationChannels(int, string) ([]tmodels.NotificationChannel, error) {
+	return nil, nil
+}
+
+type fakeSettingsStore struct {
+	settings map[string]string
+}
+
+func (s fakeSettingsStore) GetAppRootURL() (string, error) { return "", nil }
+
+func (s fakeSettingsStore) Get(key string) (types.JSONText, error) {
+	v, ok := s.settings[key]
+	if !ok {
+		return types.JSONText(`""`), nil
+	}
+	return types.JSONText(v), nil
+}
+
+type fakeBusinessHoursStore struct {
+	businessHours map[int]bmodels.BusinessHours
+}
+
+func (s fakeBusinessHoursStore) Get(id int) (bmodels.BusinessHours, error) {
+	bh, ok := s.businessHours[id]
+	if !ok {
+		return bmodels.BusinessHours{}, businesshours.ErrBusinessHoursNotFound
+	}
+	return bh, nil
+}
+
+type fakeUserStore struct {
+	noSystemUser bool
+}
+
+func (s *fakeUserStore) GetAgent(id int, _ string) (umodels.User, error) {
+	return umodels.User{ID: id}, nil
+}
+
+func (s *fakeUserStore) GetSystemUser() (umodels.User, error) {
+	if s.noSystemUser {
+		return umodels.User{}, errNoSystemUser
+	}
+	return umodels.User{ID: 1}, nil
+}
+
+func (s *fakeUserStore) CreateContact(*umodels.User) error { return nil }
+
+// closedHours has no working hours, so it is always closed.
+var closedHours = types.JSONText(`{}`)
+
+func newOutOfHoursManager(t *testing.T, handler dbtest.Handler) (*Manager, *dbtest.DB, *fakeUserStore) {
+	t.Helper()
+	db := dbtest.New(handler)
+	var q queries
+	require.NoError(t, dbutil.ScanSQLFile("queries.sql", &q, db.DB, efs))
+	i18n, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	require.NoError(t, err)
+	lo := logf.New(logf.Opts{})
+	tmpl, err := template.New(&lo, db.DB, nil, nil, nil, i18n)
+	require.NoError(t, err)
+
+	users := &fakeUserStore{}
+	return &Manager{
+		q:    q,
+		lo:   &lo,
+		i18n: i18n,
+		// Inboxes have no from address, so replies that get past the checks fail when they are queued.
+		inboxStore: fakeInboxStore{inboxes: map[int]imodels.Inbox{
+			1: {ID: 1, Name: "Support", BusinessHoursID: null.IntFrom(10), OutOfHoursReplyEnabled: true},
+			2: {ID: 2, Name: "Sales", OutOfHoursReplyEnabled: true},
+			3: {ID: 3, Name: "Billing", BusinessHoursID: null.IntFrom(10)},
+		}},
+		teamStore: fakeTeamStore{teams: map[int]tmodels.Team{
+			1: {ID: 1, BusinessHoursID: null.IntFrom(20), Timezone: "Asia/Kolkata"},
+			2: {ID: 2},
+		}},
+		settingsStore: fakeSettingsStore{settings: map[string]string{
+			"app.business_hours_id": "30",
+			"app.timezone":          `"UTC"`,
+		}},
+		businessHoursStore: fakeBusinessHoursStore{businessHours: map[int]bmodels.BusinessHours{
+			10: {ID: 10, Name: "Inbox hours", Hours: closedHours},
+			20: {ID: 20, Name: "Team hours", Hours: closedHours},
+			30: {ID: 30, Name: "App hours", IsAlwaysOpen: true},
+		}},
+		userStore:               users,
+		template:                tmpl,
+		outOfHoursReplyInterval: defaultOutOfHoursReplyInterval,
+	}, db, users
+}
+
+func TestGetInboxBusinessHours(t *testing.T) {
+	tests := []struct {
+		name         string
+		inboxID      int
+		teamID       int
+		settings     map[string]string
+		wantID       int
+		wantTimezone string
+	}{
+		{"inbox hours", 1, 0, nil, 10, "UTC"},
+		{"inbox hours with the team timezone", 1, 1, nil, 10, "Asia/Kolkata"},
+		{"team hours", 2, 1, nil, 20, "Asia/Kolkata"},
+		{"app hours", 2, 2, nil, 30, "UTC"},
+		{"no hours", 2, 0, map[string]string{"app.business_hours_id": `""`, "app.timezone": `"UTC"`}, 0, ""},
+		{"deleted hours", 2, 0, map[string]string{"app.business_hours_id": "40", "app.timezone": `"UTC"`}, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _, _ := newOutOfHoursManager(t, nil)
+			if tt.settings != nil {
+				m.settingsStore = fakeSettingsStore{settings: tt.settings}
+			}
+			inbox, _ := m.inboxStore.GetDBRecord(tt.inboxID)
+
+			bh, timezone, err := m.getInboxBusinessHours(inbox, tt.teamID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, bh.ID)
+			assert.Equal(t, tt.wantTimezone, timezone)
+		})
+	}
+
+	// A timezone is required to check business hours.
+	m, _, _ := newOutOfHoursManager(t, nil)
+	m.settingsStore = fakeSettingsStore{settings: map[string]string{"app.business_hours_id": "30"}}
+	inbox, _ := m.inboxStore.GetDBRecord(2)
+	_, _, err := m.getInboxBusinessHours(inbox, 0)
+	assert.Error(t, err)
+}
+
+func TestSendOutOfHoursReply(t *testing.T) {
+	conversation := func(teamID int) models.Conversation {
+		c := models.Conversation{UUID: "c1", ContactID: 7, AssignedTeamID: null.NewInt(teamID, teamID > 0)}
+		c.Contact.Email = null.StringFrom("jane@example.com")
+		return c
+	}
+
+	tests := []struct {
+		name         string
+		inboxID      int
+		conversation models.Conversation
+		claimed      bool
+		wantClaim    bool
+		wantQueued   bool
+	}{
+		{"closed", 1, conversation(0), true, true, true},
+		{"sent within the interval", 1, conversation(0), false, true, false},
+		{"open", 2, conversation(0), true, false, false},
+		{"replies disabled", 3, conversation(0), true, false, false},
+		{"contact without email", 1, models.Conversation{ContactID: 7}, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, db, _ := newOutOfHoursManager(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+				if tt.claimed {
+					return dbtest.Rows("id", 1), nil
+				}
+				// Nothing is returned if a reply was sent to the contact within the interval.
+				return dbtest.Rows("id"), nil
+			})
+
+			err := m.sendOutOfHoursReply(tt.inboxID, time.Now(), tt.conversation)
+			if tt.wantQueued {
+				assert.ErrorContains(t, err, "queueing out-of-hours reply")
+			} else {
+				assert.NoError(t, err)
+			}
+
+			claims := db.Queries("INSERT INTO out_of_hours_replies")
+			if !tt.wantClaim {
+				assert.Empty(t, claims)
+				return
+			}
+			require.Len(t, claims, 1)
+			assert.Equal(t, []driver.Value{int64(tt.inboxID), int64(7), defaultOutOfHoursReplyInterval.Seconds()}, claims[0].Args)
+
+			// The claim is released if the reply could not be queued.
+			releases := db.Queries("DELETE FROM out_of_hours_replies")
+			if !tt.wantQueued {
+				assert.Empty(t, releases)
+				return
+			}
+			require.Len(t, releases, 1)
+			assert.Equal(t, []driver.Value{int64(1)}, releases[0].Args)
+		})
+	}
+}
+
+func TestSendOutOfHoursReplyNotClaimedOnError(t *testing.T) {
+	conversation := models.Conversation{UUID: "c1", ContactID: 7}
+	conversation.Contact.Email = null.StringFrom("jane@example.com")
+	claimed := func(string, []driver.Value) (dbtest.Result, error) {
+		return dbtest.Rows("id", 1), nil
+	}
+
+	// The system user is fetched before the reply is claimed.
+	m, db, users := newOutOfHoursManager(t, claimed)
+	users.noSystemUser = true
+	assert.ErrorIs(t, m.sendOutOfHoursReply(1, time.Now(), conversation), errNoSystemUser)
+	assert.Empty(t, db.Queries("out_of_hours_replies"))
+
+	// So is the reply, a broken reply of the inbox does not use up the contact's reply.
+	m, db, _ = newOutOfHoursManager(t, claimed)
+	m.inboxStore = fakeInboxStore{inboxes: map[int]imodels.Inbox{
+		1: {ID: 1, Name: "Support", BusinessHoursID: null.IntFrom(10), OutOfHoursReplyEnabled: true, OutOfHoursReply: "{{ .Missing.Field }"},
+	}}
+	assert.ErrorContains(t, m.sendOutOfHoursReply(1, time.Now(), conversation), "rendering out-of-hours reply")
+	assert.Empty(t, db.Queries("out_of_hours_replies"))
+}
+
+func TestRenderOutOfHoursReply(t *testing.T) {
+	m, _, _ := newOutOfHoursManager(t, nil)
+	conversation := models.Conversation{ReferenceNumber: "100"}
+	conversation.Contact.FirstName = `<img src=x onerror="alert(1)">`
+	businessHours := bmodels.BusinessHours{Name: "Support hours"}
+
+	// The default reply is used if the inbox has none, contact values are escaped.
+	content, err := m.renderOutOfHoursReply(imodels.Inbox{Name: "Support"}, businessHours, "Monday, 06 Jan 2025 09:00 UTC", conversation)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(content, "<p>Hi &lt;img src=x onerror=&#34;alert(1)&#34;&gt;,</p>"), content)
+	assert.Contains(t, content, "will be back on Monday, 06 Jan 2025 09:00 UTC.")
+
+	content, err = m.renderOutOfHoursReply(imodels.Inbox{Name: "Support"}, businessHours, "", conversation)
+	require.NoError(t, err)
+	assert.Contains(t, content, "We are currently closed. We will")
+
+	content, err = m.renderOutOfHoursReply(imodels.Inbox{
+		Name:            "Support",
+		OutOfHoursReply: `<p>{{ .Inbox.Name }} is closed ({{ .BusinessHours.Name }}), ref #{{ .Conversation.ReferenceNumber }}</p>`,
+	}, businessHours, "", conversation)
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Support is closed (Support hours), ref #100</p>", content)
+}
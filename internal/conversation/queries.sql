@@ -148,6 +148,7 @@ SELECT
    as_latest.first_response_deadline_at,
    as_latest.resolution_deadline_at,
    as_latest.id as applied_sla_id,
+   COALESCE(as_latest.is_manual, false) as sla_applied_manually,
    nxt_resp_event.deadline_at AS next_response_deadline_at,
    nxt_resp_event.met_at as next_response_met_at
 FROM conversations c
@@ -158,7 +159,7 @@ LEFT JOIN teams at ON at.id = c.assigned_team_id
 LEFT JOIN conversation_statuses s ON c.status_id = s.id
 LEFT JOIN conversation_priorities p ON c.priority_id = p.id
 LEFT JOIN LATERAL (
-    SELECT id, first_response_deadline_at, resolution_deadline_at
+    SELECT id, first_response_deadline_at, resolution_deadline_at, is_manual
     FROM applied_slas
     WHERE conversation_id = c.id 
     ORDER BY created_at DESC LIMIT 1
@@ -431,6 +432,13 @@ INNER JOIN conversations c ON c.id = m.conversation_id
 WHERE m.status = 'pending' AND m.type = 'outgoing' AND m.private = false
 AND NOT(m.id = ANY($1::INT[]))
 
+-- name: get-first-incoming-message-text
+SELECT text_content
+FROM conversation_messages
+WHERE conversation_id = $1 AND type = 'incoming'
+ORDER BY created_at ASC
+LIMIT 1;
+
 -- name: get-message
 SELECT
     m.id,
@@ -555,4 +563,31 @@ AND m.type = ANY($2)
 AND m.status = ANY($3)
 AND m.private = NOT $4
 ORDER BY m.created_at DESC
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: claim-out-of-hours-reply
+-- Returns a row only if no out-of-hours reply was sent to the contact from the inbox within the interval.
+INSERT INTO out_of_hours_replies (inbox_id, contact_id)
+VALUES ($1, $2)
+ON CONFLICT (inbox_id, contact_id) DO UPDATE
+SET sent_at = NOW()
+WHERE out_of_hours_replies.sent_at < NOW() - make_interval(secs => $3)
+RETURNING id;
+
+-- name: release-out-of-hours-reply
+DELETE FROM out_of_hours_replies WHERE id = $1;
+
+-- name: insert-conversation-follower
+INSERT INTO conversation_followers (conversation_id, user_id)
+SELECT id, $2 FROM conversations WHERE uuid = $1
+ON CONFLICT (conversation_id, user_id) DO NOTHING;
+
+-- name: delete-conversation-follower
+DELETE FROM conversation_followers
+WHERE conversation_id = (SELECT id FROM conversations WHERE uuid = $1) AND user_id = $2;
+
+-- name: get-conversation-follower-ids
+SELECT f.user_id
+FROM conversation_followers f
+INNER JOIN conversations c ON c.id = f.conversation_id
+WHERE CASE WHEN $1 > 0 THEN c.id = $1 ELSE c.uuid = $2 END;
@@ -0,0 +1,110 @@
+package conversation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	aimodels "github.com/abhinavxd/libredesk/internal/ai/models"
+	amodels "github.com/abhinavxd/libredesk/internal/automation/models"
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+)
+
+// SetAIStore sets the AI store used by the AI triage action.
+func (m *Manager) SetAIStore(store aiStore) {
+	m.aiStore = store
+}
+
+// applyAITriage classifies the first message of the conversation into the taxonomy and applies the
+// tags, priority, team and custom attributes configured for the chosen labels.
+// Provider failures and timeouts are logged and the action is skipped.
+func (m *Manager) applyAITriage(conv models.Conversation, taxonomyJSON string, actor umodels.User) error {
+	var taxonomy aimodels.TriageTaxonomy
+	if err := json.Unmarshal([]byte(taxonomyJSON), &taxonomy); err != nil {
+		return fmt.Errorf("invalid AI triage taxonomy: %w", err)
+	}
+	if m.aiStore == nil {
+		m.lo.Warn("AI store not set, skipping AI triage", "conversation_uuid", conv.UUID)
+		return nil
+	}
+
+	var firstMessage string
+	if err := m.q.GetFirstIncomingMessageText.Get(&firstMessage, conv.ID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		m.lo.Error("error fetching first message for AI triage", "conversation_uuid", conv.UUID, "error", err)
+		return nil
+	}
+	content := strings.TrimSpace(conv.Subject.String + "\n\n" + firstMessage)
+	if content == "" {
+		return nil
+	}
+
+	result, err := m.aiStore.Triage(content, taxonomy)
+	if err != nil {
+		m.lo.Warn("AI triage failed, skipping", "conversation_uuid", conv.UUID, "error", err)
+		return nil
+	}
+	m.lo.Debug("AI triage result", "conversation_uuid", conv.UUID, "category", result.Category, "urgency", result.Urgency, "sentiment", result.Sentiment)
+
+	// Labels are ordered category, urgency, sentiment; the first label that sets a priority or team wins.
+	var (
+		tags       []string
+		priorityID int
+		teamID     int
+	)
+	for _, l := range taxonomy.Labels(result) {
+		tags = append(tags, l.Tags...)
+		if priorityID == 0 {
+			priorityID = l.PriorityID
+		}
+		if teamID == 0 {
+			teamID = l.TeamID
+		}
+	}
+
+	if len(tags) > 0 {
+		if err := m.SetConversationTags(conv.UUID, amodels.ActionAddTags, tags, actor); err != nil {
+			m.lo.Error("error applying AI triage tags", "conversation_uuid", conv.UUID, "error", err)
+		}
+	}
+	if priorityID > 0 {
+		if err := m.UpdateConversationPriority(conv.UUID, priorityID, "", actor); err != nil {
+			m.lo.Error("error applying AI triage priority", "conversation_uuid", conv.UUID, "error", err)
+		}
+	}
+	if teamID > 0 {
+		if err := m.UpdateConversationTeamAssignee(conv.UUID, teamID, actor); err != nil {
+			m.lo.Error("error applying AI triage team", "conversation_uuid", conv.UUID, "error", err)
+		}
+	}
+
+	// Store the chosen labels in the configured conversation custom attributes.
+	attrs := map[string]string{
+		taxonomy.Attributes.Category:  result.Category,
+		taxonomy.Attributes.Urgency:   result.Urgency,
+		taxonomy.Attributes.Sentiment: result.Sentiment,
+	}
+	delete(attrs, "")
+	for k, v := range attrs {
+		if v == "" {
+			delete(attrs, k)
+		}
+	}
+	if len(attrs) > 0 {
+		customAttributes := make(map[string]any)
+		if len(conv.CustomAttributes) > 0 {
+			if err := json.Unmarshal(conv.CustomAttributes, &customAttributes); err != nil {
+				m.lo.Error("error unmarshalling conversation custom attributes", "conversation_uuid", conv.UUID, "error", err)
+			}
+		}
+		for k, v := range attrs {
+			customAttributes[k] = v
+		}
+		if err := m.UpdateConversationCustomAttributes(conv.UUID, customAttributes); err != nil {
+			m.lo.Error("error applying AI triage custom attributes", "conversation_uuid", conv.UUID, "error", err)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@
 package csat
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"errors"
@@ -30,6 +31,7 @@ type Manager struct {
 	q    queries
 	lo   *logf.Logger
 	i18n *i18n.I18n
+	db   *sqlx.DB
 }
 
 // Opts contains options for initializing the Manager.
@@ -44,6 +46,8 @@ type queries struct {
 	Insert *sqlx.Stmt `query:"insert"`
 	Get    *sqlx.Stmt `query:"get"`
 	Update *sqlx.Stmt `query:"update"`
+
+	GetAllResponses string `query:"get-all-responses"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -56,6 +60,7 @@ func New(opts Opts) (*Manager, error) {
 		q:    q,
 		lo:   opts.Lo,
 		i18n: opts.I18n,
+		db:   opts.DB,
 	}, nil
 }
 
@@ -105,6 +110,42 @@ func (m *Manager) UpdateResponse(uuid string, score int, feedback string) error
 	return nil
 }
 
+// GetAllResponses returns a page of answered surveys, newest first unless ordered otherwise.
+func (m *Manager) GetAllResponses(order, orderBy, filtersJSON string, page, pageSize int) ([]models.ResponseListItem, error) {
+	if orderBy == "" {
+		order, orderBy = "desc", "csat_responses.response_timestamp"
+	}
+	query, qArgs, err := dbutil.BuildPaginatedQuery(m.q.GetAllResponses, nil, dbutil.PaginationOptions{
+		Order:    order,
+		OrderBy:  orderBy,
+		Page:     page,
+		PageSize: pageSize,
+	}, filtersJSON, dbutil.AllowedFields{
+		"csat_responses": {"rating", "response_timestamp", "created_at"},
+		"conversations":  {"assigned_user_id", "assigned_team_id", "inbox_id"},
+	})
+	if err != nil {
+		m.lo.Error("error creating CSAT response list query", "error", err)
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.csatResponse}"), nil)
+	}
+
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting read-only transaction", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.csatResponse}"), nil)
+	}
+	defer tx.Rollback()
+
+	var responses = make([]models.ResponseListItem, 0)
+	if err := tx.Select(&responses, query, qArgs...); err != nil {
+		m.lo.Error("error fetching CSAT responses", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.csatResponse}"), nil)
+	}
+	return responses, nil
+}
+
 // MakePublicURL returns the public URL for the given CSAT UUID.
 func (m *Manager) MakePublicURL(appBaseURL, uuid string) string {
 	return fmt.Sprintf(csatURL, appBaseURL, uuid)
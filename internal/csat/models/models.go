@@ -18,3 +18,22 @@ type CSATResponse struct {
 	Feedback          null.String `db:"feedback"`
 	ResponseTimestamp null.Time   `db:"response_timestamp"`
 }
+
+// ResponseListItem is an answered survey with the conversation it was sent for.
+type ResponseListItem struct {
+	ID                          int         `db:"id" json:"id"`
+	UUID                        string      `db:"uuid" json:"uuid"`
+	CreatedAt                   time.Time   `db:"created_at" json:"created_at"`
+	Rating                      int         `db:"rating" json:"rating"`
+	Feedback                    null.String `db:"feedback" json:"feedback"`
+	ResponseTimestamp           null.Time   `db:"response_timestamp" json:"response_timestamp"`
+	ConversationUUID            string      `db:"conversation_uuid" json:"conversation_uuid"`
+	ConversationReferenceNumber string      `db:"conversation_reference_number" json:"conversation_reference_number"`
+	ConversationSubject         null.String `db:"conversation_subject" json:"conversation_subject"`
+	ContactName                 string      `db:"contact_name" json:"contact_name"`
+	AgentName                   string      `db:"agent_name" json:"agent_name"`
+	TeamName                    string      `db:"team_name" json:"team_name"`
+	InboxName                   string      `db:"inbox_name" json:"inbox_name"`
+
+	Total int `db:"total" json:"-"`
+}
@@ -23,3 +23,30 @@ SET rating = $2,
     feedback = $3,
     response_timestamp = NOW()
 WHERE uuid = $1;
+
+-- name: get-all-responses
+-- Answered surveys, filters are appended to the WHERE clause.
+SELECT
+    COUNT(*) OVER() AS total,
+    csat_responses.id,
+    csat_responses.uuid,
+    csat_responses.created_at,
+    csat_responses.rating,
+    csat_responses.feedback,
+    csat_responses.response_timestamp,
+    conversations.uuid AS conversation_uuid,
+    conversations.reference_number AS conversation_reference_number,
+    conversations.subject AS conversation_subject,
+    CONCAT_WS(' ', contact.first_name, contact.last_name) AS contact_name,
+    COALESCE(CONCAT_WS(' ', agent.first_name, agent.last_name), '') AS agent_name,
+    COALESCE(teams.name, '') AS team_name,
+    inboxes.name AS inbox_name
+FROM
+    csat_responses
+    INNER JOIN conversations ON conversations.id = csat_responses.conversation_id
+    INNER JOIN users contact ON contact.id = conversations.contact_id
+    INNER JOIN inboxes ON inboxes.id = conversations.inbox_id
+    LEFT JOIN users agent ON agent.id = conversations.assigned_user_id
+    LEFT JOIN teams ON teams.id = conversations.assigned_team_id
+WHERE
+    csat_responses.response_timestamp IS NOT NULL
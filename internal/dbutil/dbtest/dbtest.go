@@ -0,0 +1,154 @@
+// Package dbtest provides a fake database for testing managers without Postgres. Queries are answered by a handler
+// that gets the query text and its arguments, so tests can return rows and check the arguments of the queries run.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Result is the result of a query, rows are returned for queries and the number of rows affected for statements.
+type Result struct {
+	Columns      []string
+	Rows         [][]any
+	RowsAffected int64
+}
+
+// Handler answers a query with its arguments.
+type Handler func(query string, args []driver.Value) (Result, error)
+
+// Query is a query run on the fake database along with its arguments.
+type Query struct {
+	Query string
+	Args  []driver.Value
+}
+
+// DB is a fake database.
+type DB struct {
+	*sqlx.DB
+
+	mu      sync.Mutex
+	handler Handler
+	queries []Query
+}
+
+// New returns a fake database answering queries with the handler, queries without rows get an empty result
+// if the handler is nil.
+func New(handler Handler) *DB {
+	db := &DB{handler: handler}
+	db.DB = sqlx.NewDb(sql.OpenDB(connector{db}), "postgres")
+	return db
+}
+
+// Queries returns the queries run so far that contain the substring.
+func (db *DB) Queries(substr string) []Query {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var out []Query
+	for _, q := range db.queries {
+		if strings.Contains(q.Query, substr) {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// Rows returns a result of a single column of values.
+func Rows(column string, values ...any) Result {
+	r := Result{Columns: []string{column}}
+	for _, v := range values {
+		r.Rows = append(r.Rows, []any{v})
+	}
+	return r
+}
+
+func (db *DB) run(query string, args []driver.Value) (Result, error) {
+	db.mu.Lock()
+	db.queries = append(db.queries, Query{Query: query, Args: args})
+	db.mu.Unlock()
+
+	if db.handler == nil {
+		return Result{}, nil
+	}
+	return db.handler(query, args)
+}
+
+type connector struct{ db *DB }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &conn{db: c.db}, nil }
+func (c connector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("dbtest: use dbtest.New")
+}
+
+type conn struct{ db *DB }
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) { return &stmt{db: c.db, query: query}, nil }
+func (c *conn) Close() error                              { return nil }
+func (c *conn) Begin() (driver.Tx, error)                 { return tx{}, nil }
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return tx{}, nil
+}
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type stmt struct {
+	db    *DB
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	r, err := s.db.run(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(r.RowsAffected), nil
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	r, err := s.db.run(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{result: r}, nil
+}
+
+type rows struct {
+	result Result
+	next   int
+}
+
+func (r *rows) Columns() []string { return r.result.Columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.Rows) {
+		return io.EOF
+	}
+	for i, v := range r.result.Rows[r.next] {
+		// Plain ints are accepted for convenience.
+		if n, ok := v.(int); ok {
+			v = int64(n)
+		}
+		dest[i] = v
+	}
+	r.next++
+	return nil
+}
@@ -0,0 +1,283 @@
+// Package digest sends agents scheduled daily or weekly summary emails of their conversations.
+package digest
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/digest/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	tmpl "github.com/abhinavxd/libredesk/internal/template"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/go-i18n"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+const (
+	// checkInterval is how often digests due are looked for.
+	checkInterval = time.Minute
+
+	// maxConversations is the maximum number of conversations listed in a section of a digest.
+	maxConversations = 10
+
+	// upcomingSLAWindow is how far ahead SLA deadlines are listed.
+	upcomingSLAWindow = 24 * time.Hour
+
+	defaultHour = 8
+)
+
+// Manager manages digest settings of agents and sends the digests due.
+type Manager struct {
+	q               queries
+	lo              *logf.Logger
+	i18n            *i18n.I18n
+	templateStore   templateStore
+	notifier        *notifier.Service
+	defaultTimezone string
+}
+
+type templateStore interface {
+	RenderStoredEmailTemplate(name string, data any) (string, string, error)
+}
+
+// Opts contains options for initializing the Manager.
+type Opts struct {
+	DB   *sqlx.DB
+	Lo   *logf.Logger
+	I18n *i18n.I18n
+	// DefaultTimezone is the timezone of agents who have not set digest settings.
+	DefaultTimezone string
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetSettings          *sqlx.Stmt `query:"get-settings"`
+	UpsertSettings       *sqlx.Stmt `query:"upsert-settings"`
+	GetEnabledSettings   *sqlx.Stmt `query:"get-enabled-settings"`
+	SetLastSent          *sqlx.Stmt `query:"set-last-sent"`
+	GetOpenAssignedCount *sqlx.Stmt `query:"get-open-assigned-count"`
+	GetWaitingLongest    *sqlx.Stmt `query:"get-waiting-longest"`
+	GetUpcomingSLA       *sqlx.Stmt `query:"get-upcoming-sla"`
+	GetCSATSummary       *sqlx.Stmt `query:"get-csat-summary"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(templateStore templateStore, notifier *notifier.Service, opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:               q,
+		lo:              opts.Lo,
+		i18n:            opts.I18n,
+		templateStore:   templateStore,
+		notifier:        notifier,
+		defaultTimezone: opts.DefaultTimezone,
+	}, nil
+}
+
+// GetSettings returns the digest settings of an agent, digests are off for agents who have not set them.
+func (m *Manager) GetSettings(userID int) (models.Settings, error) {
+	var s models.Settings
+	if err := m.q.GetSettings.Get(&s, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return models.Settings{
+				UserID:    userID,
+				Frequency: models.FrequencyOff,
+				Hour:      defaultHour,
+				Weekday:   int(time.Monday),
+				Timezone:  m.defaultTimezone,
+				Sections:  slices.Clone(models.Sections),
+			}, nil
+		}
+		m.lo.Error("error fetching digest settings", "user_id", userID, "error", err)
+		return s, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{notification.digest}"), nil)
+	}
+	return s, nil
+}
+
+// UpdateSettings validates and saves the digest settings of an agent.
+func (m *Manager) UpdateSettings(userID int, s models.Settings) (models.Settings, error) {
+	if !slices.Contains(models.Frequencies, s.Frequency) {
+		return s, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`frequency`"), nil)
+	}
+	if s.Hour < 0 || s.Hour > 23 {
+		return s, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`hour`"), nil)
+	}
+	if s.Weekday < 0 || s.Weekday > 6 {
+		return s, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`weekday`"), nil)
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil || s.Timezone == "" {
+		return s, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`timezone`"), nil)
+	}
+	if s.Sections == nil {
+		s.Sections = []string{}
+	}
+	for _, section := range s.Sections {
+		if !slices.Contains(models.Sections, section) {
+			return s, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`sections`"), nil)
+		}
+	}
+
+	if _, err := m.q.UpsertSettings.Exec(userID, s.Frequency, s.Hour, s.Weekday, s.Timezone, s.Sections, s.IncludeTeams); err != nil {
+		m.lo.Error("error updating digest settings", "user_id", userID, "error", err)
+		return s, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{notification.digest}"), nil)
+	}
+	return m.GetSettings(userID)
+}
+
+// Run periodically sends the digests due until the context is cancelled.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.sendDue(ctx, time.Now()); err != nil {
+				m.lo.Error("error sending digests", "error", err)
+			}
+		}
+	}
+}
+
+// sendDue sends the digests of agents that were scheduled since their last digest.
+func (m *Manager) sendDue(ctx context.Context, now time.Time) error {
+	var settings []models.Settings
+	if err := m.q.GetEnabledSettings.SelectContext(ctx, &settings); err != nil {
+		return fmt.Errorf("fetching digest settings: %w", err)
+	}
+	for _, s := range settings {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			m.lo.Error("invalid digest timezone", "user_id", s.UserID, "timezone", s.Timezone)
+			continue
+		}
+		scheduledAt := lastScheduledAt(s, loc, now)
+		if s.LastSentAt.Valid && !s.LastSentAt.Time.Before(scheduledAt) {
+			continue
+		}
+		if err := m.send(s, loc, now); err != nil {
+			m.lo.Error("error sending digest", "user_id", s.UserID, "error", err)
+		}
+		// Digests that failed are marked as sent too, so they are not retried every check until the next scheduled time.
+		if _, err := m.q.SetLastSent.Exec(s.UserID, now); err != nil {
+			m.lo.Error("error updating digest last sent time", "user_id", s.UserID, "error", err)
+		}
+	}
+	return nil
+}
+
+// send builds the digest of an agent and emails it, digests with nothing to report are skipped.
+func (m *Manager) send(s models.Settings, loc *time.Location, now time.Time) error {
+	digest, err := m.build(s, loc, now)
+	if err != nil {
+		return err
+	}
+	if digest.Empty() {
+		return nil
+	}
+
+	content, subject, err := m.templateStore.RenderStoredEmailTemplate(tmpl.TmplDigest, digest)
+	if err != nil {
+		return fmt.Errorf("rendering digest email: %w", err)
+	}
+	if err := m.notifier.Send(notifier.Message{
+		RecipientEmails: []string{s.Email},
+		Subject:         subject,
+		Content:         content,
+		Provider:        notifier.ProviderEmail,
+	}); err != nil {
+		return fmt.Errorf("sending digest email: %w", err)
+	}
+	return nil
+}
+
+// build collects the sections an agent chose to get in the digest.
+func (m *Manager) build(s models.Settings, loc *time.Location, now time.Time) (models.Digest, error) {
+	start, end := period(s, loc, now)
+	digest := models.Digest{
+		FirstName:   s.FirstName,
+		LastName:    s.LastName,
+		Frequency:   s.Frequency,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Sections:    make(map[string]bool, len(s.Sections)),
+	}
+	for _, section := range s.Sections {
+		digest.Sections[section] = true
+	}
+
+	if digest.Sections[models.SectionOpenAssigned] {
+		if err := m.q.GetOpenAssignedCount.Get(&digest.OpenAssigned, s.UserID); err != nil {
+			return digest, fmt.Errorf("fetching open assigned conversations: %w", err)
+		}
+	}
+	if digest.Sections[models.SectionWaitingLongest] {
+		if err := m.q.GetWaitingLongest.Select(&digest.WaitingLongest, s.UserID, s.IncludeTeams, maxConversations); err != nil {
+			return digest, fmt.Errorf("fetching longest waiting conversations: %w", err)
+		}
+		for i, c := range digest.WaitingLongest {
+			digest.WaitingLongest[i].Duration = stringutil.FormatDuration(now.Sub(c.WaitingSince.Time), false)
+		}
+	}
+	if digest.Sections[models.SectionUpcomingSLA] {
+		if err := m.q.GetUpcomingSLA.Select(&digest.UpcomingSLA, s.UserID, s.IncludeTeams, upcomingSLAWindow.Seconds(), maxConversations); err != nil {
+			return digest, fmt.Errorf("fetching upcoming SLA deadlines: %w", err)
+		}
+		for i, c := range digest.UpcomingSLA {
+			digest.UpcomingSLA[i].Duration = stringutil.FormatDuration(c.SLADeadlineAt.Time.Sub(now), false)
+		}
+	}
+	if digest.Sections[models.SectionCSAT] {
+		if err := m.q.GetCSATSummary.Get(&digest.CSAT, s.UserID, s.IncludeTeams, start, end); err != nil {
+			return digest, fmt.Errorf("fetching CSAT summary: %w", err)
+		}
+	}
+	return digest, nil
+}
+
+// lastScheduledAt returns the latest time at or before now the digest was scheduled for.
+func lastScheduledAt(s models.Settings, loc *time.Location, now time.Time) time.Time {
+	now = now.In(loc)
+	at := time.Date(now.Year(), now.Month(), now.Day(), s.Hour, 0, 0, 0, loc)
+	if s.Frequency == models.FrequencyWeekly {
+		at = at.AddDate(0, 0, -((int(now.Weekday()) - s.Weekday + 7) % 7))
+		if at.After(now) {
+			at = at.AddDate(0, 0, -7)
+		}
+		return at
+	}
+	if at.After(now) {
+		at = at.AddDate(0, 0, -1)
+	}
+	return at
+}
+
+// period returns the days a digest sent now reports on, yesterday for daily digests and the last seven days for weekly ones.
+func period(s models.Settings, loc *time.Location, now time.Time) (time.Time, time.Time) {
+	now = now.In(loc)
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if s.Frequency == models.FrequencyWeekly {
+		return end.AddDate(0, 0, -7), end
+	}
+	return end.AddDate(0, 0, -1), end
+}
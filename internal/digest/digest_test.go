@@ -0,0 +1,68 @@
+package digest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/digest/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastScheduledAt(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+
+	// Wednesday.
+	now := time.Date(2025, 6, 11, 10, 30, 0, 0, loc)
+	tests := []struct {
+		name     string
+		settings models.Settings
+		want     time.Time
+	}{
+		{"daily, earlier today", models.Settings{Frequency: models.FrequencyDaily, Hour: 8}, time.Date(2025, 6, 11, 8, 0, 0, 0, loc)},
+		{"daily, later today", models.Settings{Frequency: models.FrequencyDaily, Hour: 18}, time.Date(2025, 6, 10, 18, 0, 0, 0, loc)},
+		{"daily, this hour", models.Settings{Frequency: models.FrequencyDaily, Hour: 10}, time.Date(2025, 6, 11, 10, 0, 0, 0, loc)},
+		{"weekly, earlier this week", models.Settings{Frequency: models.FrequencyWeekly, Hour: 8, Weekday: int(time.Monday)}, time.Date(2025, 6, 9, 8, 0, 0, 0, loc)},
+		{"weekly, earlier today", models.Settings{Frequency: models.FrequencyWeekly, Hour: 8, Weekday: int(time.Wednesday)}, time.Date(2025, 6, 11, 8, 0, 0, 0, loc)},
+		{"weekly, later today", models.Settings{Frequency: models.FrequencyWeekly, Hour: 18, Weekday: int(time.Wednesday)}, time.Date(2025, 6, 4, 18, 0, 0, 0, loc)},
+		{"weekly, later this week", models.Settings{Frequency: models.FrequencyWeekly, Hour: 8, Weekday: int(time.Friday)}, time.Date(2025, 6, 6, 8, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(lastScheduledAt(tt.settings, loc, now)), "got %s", lastScheduledAt(tt.settings, loc, now))
+			// The current time in another timezone gives the same schedule.
+			assert.True(t, tt.want.Equal(lastScheduledAt(tt.settings, loc, now.UTC())))
+		})
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	now := time.Date(2025, 6, 11, 8, 0, 0, 0, loc)
+
+	start, end := period(models.Settings{Frequency: models.FrequencyDaily}, loc, now)
+	assert.True(t, time.Date(2025, 6, 10, 0, 0, 0, 0, loc).Equal(start))
+	assert.True(t, time.Date(2025, 6, 11, 0, 0, 0, 0, loc).Equal(end))
+
+	start, end = period(models.Settings{Frequency: models.FrequencyWeekly}, loc, now)
+	assert.True(t, time.Date(2025, 6, 4, 0, 0, 0, 0, loc).Equal(start))
+	assert.True(t, time.Date(2025, 6, 11, 0, 0, 0, 0, loc).Equal(end))
+}
+
+func TestDigestEmpty(t *testing.T) {
+	all := map[string]bool{
+		models.SectionOpenAssigned:   true,
+		models.SectionWaitingLongest: true,
+		models.SectionUpcomingSLA:    true,
+		models.SectionCSAT:           true,
+	}
+	assert.True(t, models.Digest{Sections: all}.Empty())
+	assert.False(t, models.Digest{Sections: all, OpenAssigned: 2}.Empty())
+	assert.False(t, models.Digest{Sections: all, UpcomingSLA: []models.Conversation{{UUID: "a"}}}.Empty())
+	assert.False(t, models.Digest{Sections: all, CSAT: models.CSATSummary{Responses: 1, AverageRating: 5}}.Empty())
+
+	// Counts of sections the agent did not choose are ignored.
+	assert.True(t, models.Digest{Sections: map[string]bool{models.SectionCSAT: true}, OpenAssigned: 2}.Empty())
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+)
+
+// Digest frequencies.
+const (
+	FrequencyOff    = "off"
+	FrequencyDaily  = "daily"
+	FrequencyWeekly = "weekly"
+)
+
+// Digest sections.
+const (
+	SectionOpenAssigned   = "open_assigned"
+	SectionWaitingLongest = "waiting_longest"
+	SectionUpcomingSLA    = "upcoming_sla"
+	SectionCSAT           = "csat"
+)
+
+// Frequencies is the list of all digest frequencies.
+var Frequencies = []string{FrequencyOff, FrequencyDaily, FrequencyWeekly}
+
+// Sections is the list of all digest sections.
+var Sections = []string{SectionOpenAssigned, SectionWaitingLongest, SectionUpcomingSLA, SectionCSAT}
+
+// Settings is when an agent gets the digest email and what it covers.
+type Settings struct {
+	UserID    int    `db:"user_id" json:"-"`
+	Frequency string `db:"frequency" json:"frequency"`
+	// Hour of the day the digest is sent at, in the timezone.
+	Hour int `db:"hour" json:"hour"`
+	// Weekday weekly digests are sent on, 0 is Sunday.
+	Weekday  int            `db:"weekday" json:"weekday"`
+	Timezone string         `db:"timezone" json:"timezone"`
+	Sections pq.StringArray `db:"sections" json:"sections"`
+	// IncludeTeams covers the conversations of the agent's teams, besides the ones assigned to the agent.
+	IncludeTeams bool      `db:"include_teams" json:"include_teams"`
+	LastSentAt   null.Time `db:"last_sent_at" json:"last_sent_at"`
+
+	// Recipient of the digest.
+	Email     string `db:"email" json:"-"`
+	FirstName string `db:"first_name" json:"-"`
+	LastName  string `db:"last_name" json:"-"`
+}
+
+// Conversation is a conversation listed in a digest.
+type Conversation struct {
+	UUID            string      `db:"uuid"`
+	ReferenceNumber string      `db:"reference_number"`
+	Subject         null.String `db:"subject"`
+	ContactName     string      `db:"contact_name"`
+	WaitingSince    null.Time   `db:"waiting_since"`
+	SLADeadlineAt   null.Time   `db:"next_sla_deadline_at"`
+	// Duration is how long the conversation has been waiting or how long until its SLA deadline, formatted for display.
+	Duration string `db:"-"`
+}
+
+// CSATSummary is the CSAT responses received in the period of a digest.
+type CSATSummary struct {
+	Responses     int     `db:"responses"`
+	AverageRating float64 `db:"average_rating"`
+}
+
+// Digest is the template data of a digest email.
+type Digest struct {
+	FirstName   string
+	LastName    string
+	Frequency   string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	// Sections the agent chose to get, by name.
+	Sections       map[string]bool
+	OpenAssigned   int
+	WaitingLongest []Conversation
+	UpcomingSLA    []Conversation
+	CSAT           CSATSummary
+}
+
+// Empty returns true if the sections of the digest have nothing to report.
+func (d Digest) Empty() bool {
+	return (!d.Sections[SectionOpenAssigned] || d.OpenAssigned == 0) &&
+		len(d.WaitingLongest) == 0 &&
+		len(d.UpcomingSLA) == 0 &&
+		(!d.Sections[SectionCSAT] || d.CSAT.Responses == 0)
+}
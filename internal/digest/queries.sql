@@ -0,0 +1,62 @@
+-- name: get-settings
+SELECT s.user_id, s.frequency, s.hour, s.weekday, s.timezone, s.sections, s.include_teams, s.last_sent_at,
+    COALESCE(u.email, '') AS email, u.first_name, COALESCE(u.last_name, '') AS last_name
+FROM user_digest_settings s
+JOIN users u ON u.id = s.user_id
+WHERE s.user_id = $1;
+
+-- name: upsert-settings
+-- Digests are due from the next scheduled time on, so enabling a digest does not send one right away.
+INSERT INTO user_digest_settings (user_id, frequency, hour, weekday, timezone, sections, include_teams, last_sent_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET frequency = EXCLUDED.frequency, hour = EXCLUDED.hour, weekday = EXCLUDED.weekday, timezone = EXCLUDED.timezone,
+    sections = EXCLUDED.sections, include_teams = EXCLUDED.include_teams, updated_at = NOW(),
+    last_sent_at = COALESCE(user_digest_settings.last_sent_at, NOW());
+
+-- name: get-enabled-settings
+SELECT s.user_id, s.frequency, s.hour, s.weekday, s.timezone, s.sections, s.include_teams, s.last_sent_at,
+    COALESCE(u.email, '') AS email, u.first_name, COALESCE(u.last_name, '') AS last_name
+FROM user_digest_settings s
+JOIN users u ON u.id = s.user_id
+WHERE s.frequency != 'off' AND u.type = 'agent' AND u.enabled AND u.deleted_at IS NULL
+    AND COALESCE(u.email, '') != '';
+
+-- name: set-last-sent
+UPDATE user_digest_settings SET last_sent_at = $2 WHERE user_id = $1;
+
+-- name: get-open-assigned-count
+SELECT COUNT(*)
+FROM conversations c
+WHERE c.assigned_user_id = $1
+    AND c.status_id IN (SELECT id FROM conversation_statuses WHERE name NOT IN ('Resolved', 'Closed'));
+
+-- name: get-waiting-longest
+SELECT c.uuid, c.reference_number, c.subject, c.waiting_since, c.next_sla_deadline_at,
+    TRIM(ct.first_name || ' ' || COALESCE(ct.last_name, '')) AS contact_name
+FROM conversations c
+JOIN users ct ON ct.id = c.contact_id
+WHERE (c.assigned_user_id = $1 OR ($2 AND c.assigned_team_id IN (SELECT team_id FROM team_members WHERE user_id = $1)))
+    AND c.status_id IN (SELECT id FROM conversation_statuses WHERE name NOT IN ('Resolved', 'Closed'))
+    AND c.waiting_since IS NOT NULL
+ORDER BY c.waiting_since ASC
+LIMIT $3;
+
+-- name: get-upcoming-sla
+SELECT c.uuid, c.reference_number, c.subject, c.waiting_since, c.next_sla_deadline_at,
+    TRIM(ct.first_name || ' ' || COALESCE(ct.last_name, '')) AS contact_name
+FROM conversations c
+JOIN users ct ON ct.id = c.contact_id
+WHERE (c.assigned_user_id = $1 OR ($2 AND c.assigned_team_id IN (SELECT team_id FROM team_members WHERE user_id = $1)))
+    AND c.status_id IN (SELECT id FROM conversation_statuses WHERE name NOT IN ('Resolved', 'Closed'))
+    AND c.next_sla_deadline_at BETWEEN NOW() AND NOW() + $3 * INTERVAL '1 second'
+ORDER BY c.next_sla_deadline_at ASC
+LIMIT $4;
+
+-- name: get-csat-summary
+SELECT COUNT(*) AS responses, COALESCE(AVG(r.rating), 0)::FLOAT AS average_rating
+FROM csat_responses r
+JOIN conversations c ON c.id = r.conversation_id
+WHERE (c.assigned_user_id = $1 OR ($2 AND c.assigned_team_id IN (SELECT team_id FROM team_members WHERE user_id = $1)))
+    AND r.response_timestamp >= $3 AND r.response_timestamp < $4
+    AND r.rating > 0;
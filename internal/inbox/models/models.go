@@ -6,19 +6,23 @@ import (
 	"time"
 
 	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/volatiletech/null/v9"
 )
 
 // Inbox represents a inbox record in DB.
 type Inbox struct {
-	ID          int             `db:"id" json:"id"`
-	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time       `db:"updated_at" json:"updated_at"`
-	Name        string          `db:"name" json:"name"`
-	Channel     string          `db:"channel" json:"channel"`
-	Enabled     bool            `db:"enabled" json:"enabled"`
-	CSATEnabled bool            `db:"csat_enabled" json:"csat_enabled"`
-	From        string          `db:"from" json:"from"`
-	Config      json.RawMessage `db:"config" json:"config"`
+	ID                     int             `db:"id" json:"id"`
+	CreatedAt              time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt              time.Time       `db:"updated_at" json:"updated_at"`
+	Name                   string          `db:"name" json:"name"`
+	Channel                string          `db:"channel" json:"channel"`
+	Enabled                bool            `db:"enabled" json:"enabled"`
+	CSATEnabled            bool            `db:"csat_enabled" json:"csat_enabled"`
+	From                   string          `db:"from" json:"from"`
+	Config                 json.RawMessage `db:"config" json:"config"`
+	BusinessHoursID        null.Int        `db:"business_hours_id" json:"business_hours_id"`
+	OutOfHoursReplyEnabled bool            `db:"out_of_hours_reply_enabled" json:"out_of_hours_reply_enabled"`
+	OutOfHoursReply        string          `db:"out_of_hours_reply" json:"out_of_hours_reply"`
 }
 
 // ClearPasswords masks all config passwords
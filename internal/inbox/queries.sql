@@ -1,21 +1,21 @@
 -- name: get-active-inboxes
-SELECT id, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, config, "from" FROM inboxes where enabled is TRUE and deleted_at is NULL;
+SELECT id, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, config, "from", business_hours_id, out_of_hours_reply_enabled, out_of_hours_reply FROM inboxes where enabled is TRUE and deleted_at is NULL;
 
 -- name: get-all-inboxes
-SELECT id, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, config, "from" FROM inboxes where deleted_at is NULL;
+SELECT id, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, config, "from", business_hours_id, out_of_hours_reply_enabled, out_of_hours_reply FROM inboxes where deleted_at is NULL;
 
 -- name: insert-inbox
 INSERT INTO inboxes
-(channel, config, "name", "from", csat_enabled)
-VALUES($1, $2, $3, $4, $5)
+(channel, config, "name", "from", csat_enabled, business_hours_id, out_of_hours_reply_enabled, out_of_hours_reply)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *
 
 -- name: get-inbox
-SELECT id, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, config, "from" FROM inboxes where id = $1 and deleted_at is NULL;
+SELECT id, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, config, "from", business_hours_id, out_of_hours_reply_enabled, out_of_hours_reply FROM inboxes where id = $1 and deleted_at is NULL;
 
 -- name: update
 UPDATE inboxes
-set channel = $2, config = $3, "name" = $4, "from" = $5, csat_enabled = $6, enabled = $7, updated_at = now()
+set channel = $2, config = $3, "name" = $4, "from" = $5, csat_enabled = $6, enabled = $7, business_hours_id = $8, out_of_hours_reply_enabled = $9, out_of_hours_reply = $10, updated_at = now()
 where id = $1 and deleted_at is NULL
 RETURNING *;
 
@@ -0,0 +1,723 @@
+package migrations
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/koanf/v2"
+	"github.com/knadh/stuffbin"
+)
+
+// V0_8_0 updates the database schema to v0.8.0.
+func V0_8_0(db *sqlx.DB, fs stuffbin.FileSystem, ko *koanf.Koanf) error {
+	// Create inbound webhook enum types if they don't exist
+	_, err := db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_type WHERE typname = 'inbound_webhook_action'
+			) THEN
+				CREATE TYPE inbound_webhook_action AS ENUM ('send_private_note', 'add_tags', 'set_tags', 'remove_tags', 'set_status');
+			END IF;
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_type WHERE typname = 'inbound_webhook_contact_lookup'
+			) THEN
+				CREATE TYPE inbound_webhook_contact_lookup AS ENUM ('email', 'custom_attribute');
+			END IF;
+		END
+		$$;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create inbound_webhooks table if it doesn't exist
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS inbound_webhooks (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			"uuid" UUID DEFAULT gen_random_uuid() NOT NULL UNIQUE,
+			name TEXT NOT NULL,
+			action inbound_webhook_action NOT NULL,
+			contact_lookup inbound_webhook_contact_lookup NOT NULL DEFAULT 'email',
+			mapping JSONB NOT NULL DEFAULT '{}'::jsonb,
+			secret TEXT NOT NULL,
+			is_active BOOLEAN DEFAULT true,
+			CONSTRAINT constraint_inbound_webhooks_on_name CHECK (length(name) <= 255),
+			CONSTRAINT constraint_inbound_webhooks_on_secret CHECK (length(secret) BETWEEN 1 AND 255)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Index contact custom attributes for inbound webhook contact lookups
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS index_users_on_custom_attributes ON users USING GIN (custom_attributes) WHERE type = 'contact';
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add claude to ai_provider enum
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_enum e
+				JOIN pg_type t ON t.oid = e.enumtypid
+				WHERE t.typname = 'ai_provider'
+				AND e.enumlabel = 'claude'
+			) THEN
+				ALTER TYPE ai_provider ADD VALUE 'claude';
+			END IF;
+		END
+		$$;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Insert the claude provider, openai remains the default
+	_, err = db.Exec(`
+		INSERT INTO ai_providers (name, provider, config, is_default)
+		VALUES ('claude', 'claude', '{"api_key": ""}'::jsonb, false)
+		ON CONFLICT (name) DO NOTHING;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add per-prompt overrides and versioning to ai_prompts
+	_, err = db.Exec(`
+		ALTER TABLE ai_prompts ADD COLUMN IF NOT EXISTS model TEXT NULL;
+		ALTER TABLE ai_prompts ADD COLUMN IF NOT EXISTS temperature NUMERIC(3, 2) NULL;
+		ALTER TABLE ai_prompts ADD COLUMN IF NOT EXISTS allowed_roles TEXT[] DEFAULT '{}'::TEXT[] NOT NULL;
+		ALTER TABLE ai_prompts ADD COLUMN IF NOT EXISTS version INT DEFAULT 1 NOT NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_prompt_versions (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			prompt_id INT REFERENCES ai_prompts(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			version INT NOT NULL,
+			title TEXT NOT NULL,
+			content TEXT NOT NULL,
+			model TEXT NULL,
+			temperature NUMERIC(3, 2) NULL,
+			allowed_roles TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+			created_by INT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+			CONSTRAINT constraint_ai_prompt_versions_unique UNIQUE (prompt_id, version)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Record the current prompts as their first version
+	_, err = db.Exec(`
+		INSERT INTO ai_prompt_versions (prompt_id, version, title, content)
+		SELECT id, version, title, content FROM ai_prompts
+		ON CONFLICT (prompt_id, version) DO NOTHING;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create knowledge base tables used for reply suggestions
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_type WHERE typname = 'ai_knowledge_source_type'
+			) THEN
+				CREATE TYPE ai_knowledge_source_type AS ENUM ('conversation', 'document');
+			END IF;
+		END
+		$$;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_knowledge_sources (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			"type" ai_knowledge_source_type NOT NULL,
+			title TEXT NOT NULL,
+			content TEXT NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NULL UNIQUE,
+			indexed_at TIMESTAMPTZ NULL,
+			CONSTRAINT constraint_ai_knowledge_sources_on_title CHECK (length(title) <= 255)
+		);
+		CREATE INDEX IF NOT EXISTS index_ai_knowledge_sources_on_indexed_at ON ai_knowledge_sources (indexed_at) WHERE indexed_at IS NULL;
+
+		CREATE TABLE IF NOT EXISTS ai_knowledge_chunks (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			source_id INT REFERENCES ai_knowledge_sources(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			chunk_index INT NOT NULL,
+			content TEXT NOT NULL,
+			embedding REAL[] NOT NULL,
+			embedding_model TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_ai_knowledge_chunks_on_source_id ON ai_knowledge_chunks (source_id);
+		CREATE INDEX IF NOT EXISTS index_ai_knowledge_chunks_on_embedding_model ON ai_knowledge_chunks (embedding_model);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Insert the reply suggestion prompt
+	_, err = db.Exec(`
+		INSERT INTO ai_prompts ("key", "content", title)
+		VALUES ('suggest_reply', 'You are a customer support agent. Draft a reply to the customer''s latest message in the conversation. Use the numbered knowledge sources from previously resolved conversations and internal documents where they are relevant, do not make up facts that are not in the sources or the conversation. If the sources do not answer the question, ask the customer for the details needed instead. Do not mention the sources. Reply with the message text only and sign off as {{ .Agent.FirstName }}.', 'Suggest Reply')
+		ON CONFLICT ("key") DO NOTHING;
+
+		INSERT INTO ai_prompt_versions (prompt_id, version, title, content)
+		SELECT id, version, title, content FROM ai_prompts WHERE "key" = 'suggest_reply'
+		ON CONFLICT (prompt_id, version) DO NOTHING;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create the AI redaction audit log table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_redaction_logs (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			user_id INT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+			prompt_key TEXT NOT NULL,
+			redactions JSONB DEFAULT '{}'::jsonb NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_ai_redaction_logs_on_created_at ON ai_redaction_logs (created_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create the AI usage metering and budget tables
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_usage (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			user_id INT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+			prompt_key TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			model TEXT NOT NULL,
+			input_tokens INT DEFAULT 0 NOT NULL,
+			output_tokens INT DEFAULT 0 NOT NULL,
+			latency_ms INT DEFAULT 0 NOT NULL,
+			success BOOLEAN NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_ai_usage_on_created_at ON ai_usage (created_at);
+		CREATE INDEX IF NOT EXISTS index_ai_usage_on_user_id_created_at ON ai_usage (user_id, created_at);
+
+		CREATE TABLE IF NOT EXISTS ai_budgets (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			team_id INT REFERENCES teams(id) ON DELETE CASCADE ON UPDATE CASCADE NULL UNIQUE,
+			role_id INT REFERENCES roles(id) ON DELETE CASCADE ON UPDATE CASCADE NULL UNIQUE,
+			monthly_token_limit BIGINT NOT NULL,
+			CONSTRAINT constraint_ai_budgets_on_scope CHECK ((team_id IS NULL) <> (role_id IS NULL)),
+			CONSTRAINT constraint_ai_budgets_on_monthly_token_limit CHECK (monthly_token_limit > 0)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add SLA pause statuses and the pauses recorded per applied SLA
+	_, err = db.Exec(`
+		ALTER TABLE sla_policies ADD COLUMN IF NOT EXISTS pause_statuses TEXT[] DEFAULT '{}'::TEXT[] NOT NULL;
+
+		CREATE TABLE IF NOT EXISTS sla_pauses (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			applied_sla_id BIGINT REFERENCES applied_slas(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			status TEXT NOT NULL,
+			started_at TIMESTAMPTZ NOT NULL,
+			ended_at TIMESTAMPTZ NULL,
+			paused_minutes INT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_sla_pauses_on_applied_sla_id ON sla_pauses(applied_sla_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS index_unique_sla_pauses_on_applied_sla_id_when_ended_at_is_null ON sla_pauses(applied_sla_id)
+		WHERE ended_at IS NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add SLA policy match conditions
+	_, err = db.Exec(`
+		ALTER TABLE sla_policies ADD COLUMN IF NOT EXISTS conditions JSONB DEFAULT '{}'::jsonb NOT NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Track SLAs applied manually by agents, matching policies don't replace them
+	_, err = db.Exec(`
+		ALTER TABLE applied_slas ADD COLUMN IF NOT EXISTS is_manual BOOLEAN DEFAULT false NOT NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add SLA escalations
+	_, err = db.Exec(`
+		ALTER TABLE sla_policies ADD COLUMN IF NOT EXISTS escalations JSONB DEFAULT '[]'::jsonb NOT NULL;
+
+		CREATE TABLE IF NOT EXISTS scheduled_sla_escalations (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			applied_sla_id BIGINT NOT NULL REFERENCES applied_slas(id) ON DELETE CASCADE,
+			sla_event_id BIGINT REFERENCES sla_events(id) ON DELETE CASCADE,
+			metric sla_metric NOT NULL,
+			escalation_type sla_notification_type NOT NULL,
+			actions JSONB DEFAULT '[]'::jsonb NOT NULL,
+			run_at TIMESTAMPTZ NOT NULL,
+			processed_at TIMESTAMPTZ
+		);
+		CREATE INDEX IF NOT EXISTS index_scheduled_sla_escalations_on_run_at ON scheduled_sla_escalations(run_at);
+		CREATE INDEX IF NOT EXISTS index_scheduled_sla_escalations_on_processed_at ON scheduled_sla_escalations(processed_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add holidays iCalendar URL to business hours
+	_, err = db.Exec(`
+		ALTER TABLE business_hours ADD COLUMN IF NOT EXISTS holidays_url TEXT NULL;
+		ALTER TABLE business_hours ADD COLUMN IF NOT EXISTS holidays_synced_at TIMESTAMPTZ NULL;
+		ALTER TABLE business_hours ADD COLUMN IF NOT EXISTS holidays_sync_error TEXT NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add out-of-hours replies to inboxes
+	_, err = db.Exec(`
+		ALTER TABLE inboxes ADD COLUMN IF NOT EXISTS business_hours_id INT REFERENCES business_hours(id) ON DELETE SET NULL ON UPDATE CASCADE NULL;
+		ALTER TABLE inboxes ADD COLUMN IF NOT EXISTS out_of_hours_reply_enabled BOOL DEFAULT false NOT NULL;
+		ALTER TABLE inboxes ADD COLUMN IF NOT EXISTS out_of_hours_reply TEXT DEFAULT '' NOT NULL;
+
+		CREATE TABLE IF NOT EXISTS out_of_hours_replies (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			contact_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			sent_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			CONSTRAINT constraint_out_of_hours_replies_on_inbox_id_and_contact_id_unique UNIQUE (inbox_id, contact_id)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add in-app notifications, notification preferences and conversation followers
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_type WHERE typname = 'user_notification_type'
+			) THEN
+				CREATE TYPE user_notification_type AS ENUM ('assigned', 'mention', 'sla_warning', 'sla_breach', 'new_reply');
+			END IF;
+		END
+		$$;
+
+		CREATE TABLE IF NOT EXISTS user_notifications (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			"type" user_notification_type NOT NULL,
+			title TEXT NOT NULL,
+			body TEXT DEFAULT '' NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+			message_id BIGINT REFERENCES conversation_messages(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+			actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+			read_at TIMESTAMPTZ NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_user_notifications_on_user_id_and_created_at ON user_notifications(user_id, created_at DESC);
+		CREATE INDEX IF NOT EXISTS index_user_notifications_on_user_id_where_unread ON user_notifications(user_id) WHERE read_at IS NULL;
+
+		CREATE TABLE IF NOT EXISTS user_notification_preferences (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			"type" user_notification_type NOT NULL,
+			in_app BOOLEAN NOT NULL,
+			email BOOLEAN NOT NULL,
+			CONSTRAINT constraint_user_notification_preferences_on_user_id_and_type_unique UNIQUE (user_id, "type")
+		);
+
+		CREATE TABLE IF NOT EXISTS conversation_followers (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			CONSTRAINT constraint_conversation_followers_on_conversation_id_and_user_id_unique UNIQUE (conversation_id, user_id)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add notification channels of teams for posting to chat platforms
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_type WHERE typname = 'team_notification_channel_provider'
+			) THEN
+				CREATE TYPE team_notification_channel_provider AS ENUM ('slack', 'mattermost', 'matrix');
+			END IF;
+		END
+		$$;
+
+		CREATE TABLE IF NOT EXISTS team_notification_channels (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			team_id INT REFERENCES teams(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			name TEXT NOT NULL,
+			provider team_notification_channel_provider NOT NULL,
+			config JSONB DEFAULT '{}'::jsonb NOT NULL,
+			events TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+			enabled BOOLEAN DEFAULT TRUE NOT NULL,
+			CONSTRAINT constraint_team_notification_channels_on_name CHECK (length(name) <= 140)
+		);
+		CREATE INDEX IF NOT EXISTS index_team_notification_channels_on_team_id ON team_notification_channels(team_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add Web Push subscriptions of agents and the VAPID keys they subscribe with
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_name = 'user_notification_preferences' AND column_name = 'push'
+			) THEN
+				ALTER TABLE user_notification_preferences ADD COLUMN push BOOLEAN DEFAULT FALSE NOT NULL;
+				-- Agents who set their preferences get push notifications for the types pushed by default.
+				UPDATE user_notification_preferences SET push = TRUE WHERE "type" IN ('assigned', 'mention', 'sla_breach');
+			END IF;
+		END
+		$$;
+
+		CREATE TABLE IF NOT EXISTS user_push_subscriptions (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			endpoint TEXT NOT NULL,
+			p256dh TEXT NOT NULL,
+			auth TEXT NOT NULL,
+			user_agent TEXT DEFAULT '' NOT NULL,
+			CONSTRAINT constraint_user_push_subscriptions_on_endpoint_unique UNIQUE (endpoint),
+			CONSTRAINT constraint_user_push_subscriptions_on_endpoint CHECK (length(endpoint) <= 2048)
+		);
+		CREATE INDEX IF NOT EXISTS index_user_push_subscriptions_on_user_id ON user_push_subscriptions(user_id);
+
+		INSERT INTO settings (key, value)
+		VALUES
+			('notification.webpush.vapid_public_key', '""'::jsonb),
+			('notification.webpush.vapid_private_key', '""'::jsonb)
+		ON CONFLICT DO NOTHING;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add digest emails of agents and their built-in template
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_type WHERE typname = 'user_digest_frequency'
+			) THEN
+				CREATE TYPE user_digest_frequency AS ENUM ('off', 'daily', 'weekly');
+			END IF;
+		END
+		$$;
+
+		CREATE TABLE IF NOT EXISTS user_digest_settings (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			frequency user_digest_frequency DEFAULT 'off' NOT NULL,
+			hour INT DEFAULT 8 NOT NULL,
+			weekday INT DEFAULT 1 NOT NULL,
+			timezone TEXT NOT NULL,
+			sections TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+			include_teams BOOLEAN DEFAULT FALSE NOT NULL,
+			last_sent_at TIMESTAMPTZ NULL,
+			CONSTRAINT constraint_user_digest_settings_on_user_id_unique UNIQUE (user_id),
+			CONSTRAINT constraint_user_digest_settings_on_hour CHECK (hour >= 0 AND hour <= 23),
+			CONSTRAINT constraint_user_digest_settings_on_weekday CHECK (weekday >= 0 AND weekday <= 6),
+			CONSTRAINT constraint_user_digest_settings_on_timezone CHECK (length(timezone) <= 140)
+		);
+
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM templates WHERE "name" = 'Digest') THEN
+				INSERT INTO templates
+					("type", body, is_default, "name", subject, is_builtin)
+					VALUES (
+					'email_notification'::template_type,
+					'
+					<p>Hi {{ html .FirstName }},</p>
+
+					<p>Here is your {{ .Frequency }} summary for {{ .PeriodStart.Format "Jan 2" }}{{ if eq .Frequency "weekly" }} to {{ (.PeriodEnd.AddDate 0 0 -1).Format "Jan 2" }}{{ end }}.</p>
+
+					{{ if index .Sections "open_assigned" }}
+					<p><strong>Open conversations assigned to you:</strong> {{ .OpenAssigned }}</p>
+					{{ end }}
+
+					{{ if .WaitingLongest }}
+					<p><strong>Waiting longest for a reply</strong></p>
+					<ul>
+					{{ range .WaitingLongest }}
+					    <li><a href="{{ RootURL }}/inboxes/assigned/conversation/{{ .UUID }}">#{{ .ReferenceNumber }}</a> {{ html .Subject.String }} ({{ html .ContactName }}), waiting for {{ .Duration }}</li>
+					{{ end }}
+					</ul>
+					{{ end }}
+
+					{{ if .UpcomingSLA }}
+					<p><strong>SLA deadlines in the next 24 hours</strong></p>
+					<ul>
+					{{ range .UpcomingSLA }}
+					    <li><a href="{{ RootURL }}/inboxes/assigned/conversation/{{ .UUID }}">#{{ .ReferenceNumber }}</a> {{ html .Subject.String }}, due in {{ .Duration }}</li>
+					{{ end }}
+					</ul>
+					{{ end }}
+
+					{{ if and (index .Sections "csat") .CSAT.Responses }}
+					<p><strong>CSAT:</strong> {{ .CSAT.Responses }} responses with an average rating of {{ printf "%.1f" .CSAT.AverageRating }} out of 5</p>
+					{{ end }}
+
+					<p>
+					  Best regards,<br>
+					  Libredesk
+					</p>
+
+',
+					false,
+					'Digest',
+					'Your {{ .Frequency }} Libredesk digest',
+					true
+					);
+			END IF;
+		END$$;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add agent_offline to activity_log_type enum, for agents set offline after inactivity
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_enum e
+				JOIN pg_type t ON t.oid = e.enumtypid
+				WHERE t.typname = 'activity_log_type'
+				AND e.enumlabel = 'agent_offline'
+			) THEN
+				ALTER TYPE activity_log_type ADD VALUE 'agent_offline';
+			END IF;
+		END
+		$$;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add scheduled report exports and their built-in template
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_type WHERE typname = 'scheduled_report_type'
+			) THEN
+				CREATE TYPE scheduled_report_type AS ENUM ('sla', 'agents', 'csat');
+			END IF;
+		END
+		$$;
+
+		CREATE TABLE IF NOT EXISTS scheduled_reports (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			"name" TEXT NOT NULL,
+			report_type scheduled_report_type NOT NULL,
+			filters JSONB DEFAULT '{}'::jsonb NOT NULL,
+			range_days INT DEFAULT 7 NOT NULL,
+			cron TEXT NOT NULL,
+			timezone TEXT NOT NULL,
+			recipients TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+			enabled BOOLEAN DEFAULT TRUE NOT NULL,
+			last_run_at TIMESTAMPTZ NULL,
+			last_error TEXT DEFAULT '' NOT NULL,
+			next_run_at TIMESTAMPTZ NULL,
+			CONSTRAINT constraint_scheduled_reports_on_name CHECK (length("name") <= 140),
+			CONSTRAINT constraint_scheduled_reports_on_range_days CHECK (range_days >= 1 AND range_days <= 366),
+			CONSTRAINT constraint_scheduled_reports_on_cron CHECK (length(cron) <= 100),
+			CONSTRAINT constraint_scheduled_reports_on_timezone CHECK (length(timezone) <= 140)
+		);
+		CREATE INDEX IF NOT EXISTS index_scheduled_reports_on_next_run_at ON scheduled_reports(next_run_at);
+
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM templates WHERE "name" = 'Scheduled report') THEN
+				INSERT INTO templates
+					("type", body, is_default, "name", subject, is_builtin)
+					VALUES (
+					'email_notification'::template_type,
+					'
+					<p>Hi,</p>
+
+					<p>Here is the {{ html .Name }} report for {{ .PeriodStart.Format "Jan 2, 2006" }}{{ if not (.PeriodStart.Equal .PeriodEnd) }} to {{ .PeriodEnd.Format "Jan 2, 2006" }}{{ end }}. The full report is attached as {{ .Filename }}.</p>
+
+					{{ if .Summary }}
+					<ul>
+					{{ range .Summary }}
+					    <li><strong>{{ html .Label }}:</strong> {{ html .Value }}</li>
+					{{ end }}
+					</ul>
+					{{ end }}
+
+					<p><a href="{{ RootURL }}/reports">View reports</a></p>
+
+					<p>
+					  Best regards,<br>
+					  Libredesk
+					</p>
+',
+					false,
+					'Scheduled report',
+					'{{ .Name }} report',
+					true
+					);
+			END IF;
+		END$$;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Report rollups, existing data is rolled up by running the backfill.
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS index_conversations_on_resolved_at ON conversations (resolved_at);
+		CREATE INDEX IF NOT EXISTS index_conversations_on_first_reply_at ON conversations (first_reply_at);
+
+		CREATE TABLE IF NOT EXISTS report_rollups_hourly (
+			bucket TIMESTAMPTZ NOT NULL,
+			inbox_id INT NOT NULL,
+			team_id INT NOT NULL,
+			conversations_created INT DEFAULT 0 NOT NULL,
+			conversations_resolved INT DEFAULT 0 NOT NULL,
+			PRIMARY KEY (bucket, inbox_id, team_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS report_rollups_daily (
+			"day" DATE NOT NULL,
+			inbox_id INT NOT NULL,
+			team_id INT NOT NULL,
+			conversations_created INT DEFAULT 0 NOT NULL,
+			conversations_resolved INT DEFAULT 0 NOT NULL,
+			PRIMARY KEY ("day", inbox_id, team_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS report_rollups_open (
+			inbox_id INT NOT NULL,
+			team_id INT NOT NULL,
+			"open" INT DEFAULT 0 NOT NULL,
+			awaiting_response INT DEFAULT 0 NOT NULL,
+			unassigned INT DEFAULT 0 NOT NULL,
+			pending INT DEFAULT 0 NOT NULL,
+			PRIMARY KEY (inbox_id, team_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS report_rollup_state (
+			id INT PRIMARY KEY DEFAULT 1,
+			rolled_up_at TIMESTAMPTZ NULL,
+			backfilled_at TIMESTAMPTZ NULL,
+			CONSTRAINT constraint_report_rollup_state_on_id CHECK (id = 1)
+		);
+		INSERT INTO report_rollup_state (id) VALUES (1) ON CONFLICT (id) DO NOTHING;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Activity messages record their activity type, new value and assignee in the meta for the agent performance
+	// report, set them on the activity messages inserted before from their content.
+	_, err = db.Exec(`
+		UPDATE conversation_messages
+		SET meta = COALESCE(meta, '{}'::jsonb) || jsonb_build_object(
+			'activity_type', 'status_change',
+			'new_value', substring(content FROM ' marked the conversation as (.*)$')
+		)
+		WHERE "type" = 'activity'
+			AND NOT (COALESCE(meta, '{}'::jsonb) ? 'activity_type')
+			AND content ~ ' marked the conversation as .+$';
+
+		UPDATE conversation_messages
+		SET meta = COALESCE(meta, '{}'::jsonb) || jsonb_build_object(
+			'activity_type', 'self_assign',
+			'new_value', substring(content FROM '^(.*) self-assigned this conversation$'),
+			'assignee_id', sender_id
+		)
+		WHERE "type" = 'activity'
+			AND NOT (COALESCE(meta, '{}'::jsonb) ? 'activity_type')
+			AND content ~ ' self-assigned this conversation$';
+
+		-- The assignee is found by name, content is "Assigned to <assignee> by <actor>".
+		UPDATE conversation_messages m
+		SET meta = COALESCE(m.meta, '{}'::jsonb)
+			|| jsonb_build_object('activity_type', 'assigned_user_change', 'new_value', a.assignee)
+			|| COALESCE((
+				SELECT jsonb_build_object('assignee_id', MIN(u.id))
+				FROM users u
+				WHERE u.type = 'agent' AND u.first_name || ' ' || COALESCE(u.last_name, '') = a.assignee
+				HAVING COUNT(*) = 1
+			), '{}'::jsonb)
+		FROM (
+			SELECT
+				mm.id,
+				CASE
+					WHEN right(mm.content, length(' by ' || s.first_name || ' ' || COALESCE(s.last_name, ''))) = ' by ' || s.first_name || ' ' || COALESCE(s.last_name, '')
+						THEN substring(mm.content FROM 13 FOR length(mm.content) - 12 - length(' by ' || s.first_name || ' ' || COALESCE(s.last_name, '')))
+					ELSE substring(mm.content FROM '^Assigned to (.*) by ')
+				END AS assignee
+			FROM conversation_messages mm
+			INNER JOIN users s ON s.id = mm.sender_id
+			WHERE mm."type" = 'activity'
+				AND NOT (COALESCE(mm.meta, '{}'::jsonb) ? 'activity_type')
+				AND mm.content LIKE 'Assigned to % by %'
+				AND mm.content NOT LIKE 'Assigned to % team by %'
+		) a
+		WHERE m.id = a.id;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+// Package netutil provides helpers for requests to URLs configured by users, which must not reach the local network.
+package netutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrInvalidURL       = errors.New("invalid URL")
+	ErrNonPublicAddress = errors.New("address is not public")
+)
+
+// sharedAddressSpace is the carrier-grade NAT range, RFC 6598.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicIP returns true if the IP is a globally routable unicast address. Loopback, private, link-local,
+// multicast, unspecified and carrier-grade NAT addresses are not public.
+func IsPublicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() &&
+		!ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// ValidatePublicURL checks that the URL is absolute, uses one of the schemes and that its host is not a local or
+// private address. Host names are only checked when connecting, see DialControl.
+func ValidatePublicURL(rawURL string, schemes ...string) error {
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil || !slices.Contains(schemes, u.Scheme) || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrNonPublicAddress
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !IsPublicIP(ip) {
+		return ErrNonPublicAddress
+	}
+	return nil
+}
+
+// DialControl is a net.Dialer Control function that refuses connections to addresses that are not public.
+// It runs after host names are resolved, so names that resolve to local addresses are refused as well.
+func DialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, address)
+	}
+	if !IsPublicIP(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, address)
+	}
+	return nil
+}
+
+// NewPublicClient returns an HTTP client that only connects to public addresses.
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   DialControl,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Proxies would connect on our behalf and bypass the address check.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
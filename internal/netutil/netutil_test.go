@@ -0,0 +1,81 @@
+package netutil
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:8.8.8.8", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsPublicIP(netip.MustParseAddr(tt.ip)))
+		})
+	}
+}
+
+func TestValidatePublicURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		schemes []string
+		wantErr error
+	}{
+		{"https://calendar.example.com/holidays.ics", []string{"http", "https"}, nil},
+		{"http://8.8.8.8/holidays.ics", []string{"http", "https"}, nil},
+		{"http://calendar.example.com/holidays.ics", []string{"https"}, ErrInvalidURL},
+		{"ftp://calendar.example.com/holidays.ics", []string{"http", "https"}, ErrInvalidURL},
+		{"calendar.example.com/holidays.ics", []string{"https"}, ErrInvalidURL},
+		{"https:///holidays.ics", []string{"https"}, ErrInvalidURL},
+		{"https://localhost:8080/", []string{"https"}, ErrNonPublicAddress},
+		{"https://LOCALHOST./", []string{"https"}, ErrNonPublicAddress},
+		{"https://app.localhost/", []string{"https"}, ErrNonPublicAddress},
+		{"https://127.0.0.1/", []string{"https"}, ErrNonPublicAddress},
+		{"https://[::1]:9000/", []string{"https"}, ErrNonPublicAddress},
+		{"http://169.254.169.254/latest/meta-data", []string{"http"}, ErrNonPublicAddress},
+		{"https://192.168.0.10/push", []string{"https"}, ErrNonPublicAddress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := ValidatePublicURL(tt.url, tt.schemes...)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestNewPublicClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	// The test server listens on loopback, so the connection is refused before anything is sent.
+	_, err := NewPublicClient(time.Second).Get(srv.URL)
+	assert.True(t, errors.Is(err, ErrNonPublicAddress), err)
+}
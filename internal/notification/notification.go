@@ -6,13 +6,41 @@ import (
 	"sync"
 
 	"github.com/abhinavxd/libredesk/internal/attachment"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
 	"github.com/zerodha/logf"
 )
 
 const (
-	ProviderEmail = "email"
+	ProviderEmail      = "email"
+	ProviderSlack      = "slack"
+	ProviderMattermost = "mattermost"
+	ProviderMatrix     = "matrix"
+	ProviderWebPush    = "webpush"
 )
 
+// ChatProviders are the providers that post messages to chat platforms.
+var ChatProviders = []string{ProviderSlack, ProviderMattermost, ProviderMatrix}
+
+// ChatTarget is where a chat provider posts a message.
+type ChatTarget struct {
+	// Incoming webhook URL for Slack and Mattermost, homeserver URL for Matrix
+	URL string
+	// Matrix room to post to
+	RoomID string
+	// Matrix access token of the user posting
+	AccessToken string
+}
+
+// PushSubscription is a browser's Web Push subscription.
+type PushSubscription struct {
+	// Push service URL the browser subscribed with
+	Endpoint string
+	// P-256 public key of the browser, base64url encoded
+	P256dh string
+	// Authentication secret of the browser, base64url encoded
+	Auth string
+}
+
 // Message represents a message to be sent as a notification.
 type Message struct {
 	// Email addresses of the recipients
@@ -31,6 +59,23 @@ type Message struct {
 	AltContent string
 	// Additional email headers
 	Headers map[string][]string
+	// Chat target to post the message to
+	Chat ChatTarget
+	// Link to what the message is about, posted along with chat and push messages
+	Link string
+	// Browser subscriptions to push the message to
+	PushSubscriptions []PushSubscription
+}
+
+// Text returns the plain text of the message, the alternative content if set, else the content converted to text.
+func (m Message) Text() string {
+	if m.AltContent != "" {
+		return m.AltContent
+	}
+	if m.ContentType == "plain" {
+		return m.Content
+	}
+	return stringutil.HTML2Text(m.Content)
 }
 
 // Notifier defines the interface for sending notifications through various providers.
@@ -79,6 +124,15 @@ func (s *Service) Send(message Message) error {
 	}
 }
 
+// SendNow sends a message right away using the set provider, bypassing the message channel.
+func (s *Service) SendNow(message Message) error {
+	provider, exists := s.providers[message.Provider]
+	if !exists {
+		return fmt.Errorf("unsupported provider %s", message.Provider)
+	}
+	return provider.Send(message)
+}
+
 // Run starts the worker pool to process messages.
 func (s *Service) Run(ctx context.Context) {
 	for range s.concurrency {
@@ -0,0 +1,125 @@
+// Package matrix posts messages to Matrix rooms using the client-server API.
+package matrix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/zerodha/logf"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+
+	// maxErrorBodyLength is the maximum length of the response body included in errors.
+	maxErrorBodyLength = 256
+)
+
+// Matrix implements the Notifier interface for posting messages to Matrix rooms.
+type Matrix struct {
+	lo     *logf.Logger
+	client *http.Client
+}
+
+// Opts contains options for creating a new Matrix sender.
+type Opts struct {
+	Lo      *logf.Logger
+	Timeout time.Duration
+}
+
+// event is the content of the `m.room.message` event sent to a room.
+type event struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// New initializes a new Matrix sender.
+func New(opts Opts) *Matrix {
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultTimeout
+	}
+	return &Matrix{
+		lo:     opts.Lo,
+		client: &http.Client{Timeout: opts.Timeout},
+	}
+}
+
+// Send sends a notification message to the Matrix room of the message's chat target.
+func (m *Matrix) Send(msg notifier.Message) error {
+	if msg.Chat.URL == "" || msg.Chat.RoomID == "" || msg.Chat.AccessToken == "" {
+		return fmt.Errorf("matrix homeserver URL, room ID and access token are required")
+	}
+
+	// Transaction IDs make retried requests idempotent on the homeserver.
+	txnID, err := stringutil.RandomAlphanumeric(24)
+	if err != nil {
+		return fmt.Errorf("generating transaction ID: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(msg.Chat.URL, "/"), url.PathEscape(msg.Chat.RoomID), txnID)
+
+	body, err := json.Marshal(format(msg))
+	if err != nil {
+		return fmt.Errorf("marshalling matrix event: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating matrix request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+msg.Chat.AccessToken)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending matrix event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+		return fmt.Errorf("matrix homeserver returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+// Name returns the name of the provider.
+func (m *Matrix) Name() string {
+	return notifier.ProviderMatrix
+}
+
+// format formats the message as a text event with an HTML body, with the subject in bold followed by the text and link.
+func format(msg notifier.Message) event {
+	var (
+		plain, formatted []string
+		text             = strings.TrimSpace(msg.Text())
+	)
+	if msg.Subject != "" {
+		plain = append(plain, msg.Subject)
+		formatted = append(formatted, "<strong>"+html.EscapeString(msg.Subject)+"</strong>")
+	}
+	if text != "" {
+		plain = append(plain, text)
+		formatted = append(formatted, strings.ReplaceAll(html.EscapeString(text), "\n", "<br>"))
+	}
+	if msg.Link != "" {
+		plain = append(plain, msg.Link)
+		formatted = append(formatted, `<a href="`+html.EscapeString(msg.Link)+`">`+html.EscapeString(msg.Link)+"</a>")
+	}
+	return event{
+		MsgType:       "m.text",
+		Body:          strings.Join(plain, "\n"),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: strings.Join(formatted, "<br>"),
+	}
+}
@@ -0,0 +1,61 @@
+package matrix
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSend(t *testing.T) {
+	var (
+		got  event
+		path string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+		path = r.URL.EscapedPath()
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Write([]byte(`{"event_id":"$1"}`))
+	}))
+	defer srv.Close()
+
+	m := New(Opts{})
+	err := m.Send(notifier.Message{
+		Subject:    "SLA breached for #100",
+		AltContent: "Reply to <Tom>\noverdue",
+		Link:       "https://desk.example.com/c/abc",
+		Chat: notifier.ChatTarget{
+			URL:         srv.URL + "/",
+			RoomID:      "!ops:example.com",
+			AccessToken: "secret-token",
+		},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(path, "/_matrix/client/v3/rooms/%21ops:example.com/send/m.room.message/"), path)
+	assert.Equal(t, "m.text", got.MsgType)
+	assert.Equal(t, "SLA breached for #100\nReply to <Tom>\noverdue\nhttps://desk.example.com/c/abc", got.Body)
+	assert.Equal(t, "org.matrix.custom.html", got.Format)
+	assert.Equal(t, `<strong>SLA breached for #100</strong><br>Reply to &lt;Tom&gt;<br>overdue<br><a href="https://desk.example.com/c/abc">https://desk.example.com/c/abc</a>`, got.FormattedBody)
+}
+
+func TestSendErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errcode":"M_UNKNOWN_TOKEN"}`))
+	}))
+	defer srv.Close()
+
+	m := New(Opts{})
+	err := m.Send(notifier.Message{Subject: "Test", Chat: notifier.ChatTarget{URL: srv.URL, RoomID: "!ops:example.com", AccessToken: "bad"}})
+	assert.EqualError(t, err, `matrix homeserver returned status 401: {"errcode":"M_UNKNOWN_TOKEN"}`)
+
+	assert.Error(t, m.Send(notifier.Message{Subject: "Test", Chat: notifier.ChatTarget{URL: srv.URL}}))
+}
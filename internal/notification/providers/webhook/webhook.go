@@ -0,0 +1,125 @@
+// Package webhook posts messages to Slack and Mattermost incoming webhooks.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/zerodha/logf"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+
+	// maxErrorBodyLength is the maximum length of the response body included in errors.
+	maxErrorBodyLength = 256
+)
+
+// slackEscaper escapes the control characters of Slack's mrkdwn.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Webhook implements the Notifier interface for posting messages to Slack-compatible incoming webhooks.
+type Webhook struct {
+	lo       *logf.Logger
+	provider string
+	client   *http.Client
+}
+
+// Opts contains options for creating a new Webhook sender.
+type Opts struct {
+	Lo *logf.Logger
+	// Provider is either `slack` or `mattermost`, it sets the markup of the posted messages.
+	Provider string
+	Timeout  time.Duration
+}
+
+// payload is the JSON body posted to incoming webhooks.
+type payload struct {
+	Text string `json:"text"`
+}
+
+// New initializes a new Webhook sender.
+func New(opts Opts) (*Webhook, error) {
+	if opts.Provider != notifier.ProviderSlack && opts.Provider != notifier.ProviderMattermost {
+		return nil, fmt.Errorf("unsupported webhook provider %s", opts.Provider)
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultTimeout
+	}
+	return &Webhook{
+		lo:       opts.Lo,
+		provider: opts.Provider,
+		client:   &http.Client{Timeout: opts.Timeout},
+	}, nil
+}
+
+// Send posts a notification message to the incoming webhook URL of the message's chat target.
+func (w *Webhook) Send(msg notifier.Message) error {
+	if msg.Chat.URL == "" {
+		return fmt.Errorf("webhook URL not set")
+	}
+	body, err := json.Marshal(payload{Text: w.format(msg)})
+	if err != nil {
+		return fmt.Errorf("marshalling webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, msg.Chat.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to %s webhook: %w", w.provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+		return fmt.Errorf("%s webhook returned status %d: %s", w.provider, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+// Name returns the name of the provider.
+func (w *Webhook) Name() string {
+	return w.provider
+}
+
+// format formats the message as the text of a webhook post, with the subject in bold followed by the text and link.
+func (w *Webhook) format(msg notifier.Message) string {
+	var (
+		b    strings.Builder
+		text = strings.TrimSpace(msg.Text())
+	)
+	switch w.provider {
+	case notifier.ProviderSlack:
+		if msg.Subject != "" {
+			b.WriteString("*" + slackEscaper.Replace(msg.Subject) + "*")
+		}
+		if text != "" {
+			b.WriteString("\n" + slackEscaper.Replace(text))
+		}
+		if msg.Link != "" {
+			b.WriteString("\n<" + msg.Link + ">")
+		}
+	default:
+		if msg.Subject != "" {
+			b.WriteString("**" + msg.Subject + "**")
+		}
+		if text != "" {
+			b.WriteString("\n" + text)
+		}
+		if msg.Link != "" {
+			b.WriteString("\n" + msg.Link)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		msg      notifier.Message
+		want     string
+	}{
+		{
+			name:     "slack escapes mrkdwn",
+			provider: notifier.ProviderSlack,
+			msg: notifier.Message{
+				Subject: "SLA breached for #100",
+				Content: "<p>Reply to <b>Tom & Jerry</b> overdue</p>",
+				Link:    "https://desk.example.com/inboxes/assigned/conversation/abc",
+			},
+			want: "*SLA breached for #100*\nReply to Tom &amp; Jerry overdue\n<https://desk.example.com/inboxes/assigned/conversation/abc>",
+		},
+		{
+			name:     "mattermost uses markdown",
+			provider: notifier.ProviderMattermost,
+			msg: notifier.Message{
+				Subject:    "Conversation #100 assigned to Support",
+				Content:    "<p>ignored</p>",
+				AltContent: "Printer on fire",
+			},
+			want: "**Conversation #100 assigned to Support**\nPrinter on fire",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got payload
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.Write([]byte("ok"))
+			}))
+			defer srv.Close()
+
+			w, err := New(Opts{Provider: tt.provider})
+			require.NoError(t, err)
+			assert.Equal(t, tt.provider, w.Name())
+
+			tt.msg.Chat = notifier.ChatTarget{URL: srv.URL}
+			require.NoError(t, w.Send(tt.msg))
+			assert.Equal(t, tt.want, got.Text)
+		})
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	w, err := New(Opts{Provider: notifier.ProviderSlack})
+	require.NoError(t, err)
+
+	err = w.Send(notifier.Message{Subject: "Test", Chat: notifier.ChatTarget{URL: srv.URL}})
+	assert.EqualError(t, err, "slack webhook returned status 403: invalid_token")
+
+	assert.Error(t, w.Send(notifier.Message{Subject: "Test"}))
+
+	_, err = New(Opts{Provider: "teams"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,348 @@
+// Package webpush sends Web Push messages to browsers, encrypted as per RFC 8291 and
+// authenticated with VAPID as per RFC 8292.
+package webpush
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/netutil"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/zerodha/logf"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	defaultTTL     = 24 * time.Hour
+
+	// recordSize is the record size of the encrypted content, the whole payload fits in a single record.
+	recordSize = 4096
+
+	// maxBodyLength is the maximum length of the notification body pushed, push services reject payloads over 4KB.
+	maxBodyLength = 1000
+
+	// jwtExpiry is the validity of VAPID tokens, push services reject tokens valid for more than 24 hours.
+	jwtExpiry = 12 * time.Hour
+
+	// maxErrorBodyLength is the maximum length of the response body included in errors.
+	maxErrorBodyLength = 256
+)
+
+// WebPush implements the Notifier interface for pushing messages to browsers.
+type WebPush struct {
+	lo                *logf.Logger
+	client            *http.Client
+	privateKey        *ecdsa.PrivateKey
+	publicKey         string
+	subject           string
+	ttl               time.Duration
+	subscriptionStore subscriptionStore
+}
+
+// subscriptionStore deletes subscriptions that have expired or were unsubscribed.
+type subscriptionStore interface {
+	DeletePushSubscription(endpoint string) error
+}
+
+// Opts contains options for creating a new WebPush sender.
+type Opts struct {
+	Lo *logf.Logger
+	// VAPID key pair, as generated by GenerateVAPIDKeys
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	// Subject is a `mailto:` or `https:` contact URI of the application server sent to push services
+	Subject string
+	// TTL is how long push services retain messages for browsers that are offline
+	TTL     time.Duration
+	Timeout time.Duration
+}
+
+// payload is the JSON pushed to browsers, the service worker displays it as a notification.
+type payload struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+}
+
+// GenerateVAPIDKeys generates a P-256 key pair for VAPID, returning the base64url encoded
+// uncompressed public key and private key.
+func GenerateVAPIDKeys() (string, string, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()), base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// New initializes a new WebPush sender.
+func New(opts Opts) (*WebPush, error) {
+	privateKey, err := parsePrivateKey(opts.VAPIDPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("parsing VAPID private key: %w", err)
+	}
+	pub, err := privateKey.PublicKey.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("parsing VAPID private key: %w", err)
+	}
+	publicKey := base64.RawURLEncoding.EncodeToString(pub.Bytes())
+	if opts.VAPIDPublicKey != "" && opts.VAPIDPublicKey != publicKey {
+		return nil, fmt.Errorf("VAPID public key does not match the private key")
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.TTL == 0 {
+		opts.TTL = defaultTTL
+	}
+	// Subscription endpoints are sent by browsers, the client must not reach the local network.
+	return &WebPush{
+		lo:         opts.Lo,
+		client:     netutil.NewPublicClient(opts.Timeout),
+		privateKey: privateKey,
+		publicKey:  publicKey,
+		subject:    opts.Subject,
+		ttl:        opts.TTL,
+	}, nil
+}
+
+// SetSubscriptionStore sets the store expired subscriptions are deleted from.
+func (w *WebPush) SetSubscriptionStore(store subscriptionStore) {
+	w.subscriptionStore = store
+}
+
+// PublicKey returns the base64url encoded VAPID public key browsers subscribe with.
+func (w *WebPush) PublicKey() string {
+	return w.publicKey
+}
+
+// Send pushes a notification message to each of the message's push subscriptions.
+// Subscriptions the push service reports as gone are deleted.
+func (w *WebPush) Send(msg notifier.Message) error {
+	if len(msg.PushSubscriptions) == 0 {
+		return fmt.Errorf("no push subscriptions to send to")
+	}
+	body, err := json.Marshal(payload{
+		Title: msg.Subject,
+		Body:  truncate(strings.TrimSpace(msg.Text()), maxBodyLength),
+		URL:   msg.Link,
+	})
+	if err != nil {
+		return fmt.Errorf("marshalling push payload: %w", err)
+	}
+
+	var errs []error
+	for _, sub := range msg.PushSubscriptions {
+		if err := w.push(sub, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Name returns the name of the provider.
+func (w *WebPush) Name() string {
+	return notifier.ProviderWebPush
+}
+
+// push encrypts and sends the payload to a subscription's push service.
+func (w *WebPush) push(sub notifier.PushSubscription, plaintext []byte) error {
+	uaPublic, err := decodeBase64(sub.P256dh)
+	if err != nil {
+		return fmt.Errorf("decoding subscription p256dh key: %w", err)
+	}
+	authSecret, err := decodeBase64(sub.Auth)
+	if err != nil {
+		return fmt.Errorf("decoding subscription auth secret: %w", err)
+	}
+
+	// Each message is encrypted with a new key pair and salt.
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generating push encryption key: %w", err)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("generating push encryption salt: %w", err)
+	}
+	body, err := encrypt(plaintext, uaPublic, authSecret, asPrivate, salt)
+	if err != nil {
+		return fmt.Errorf("encrypting push payload: %w", err)
+	}
+
+	authorization, err := w.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating push request: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(w.ttl.Seconds())))
+	req.Header.Set("Urgency", "high")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending push message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The browser unsubscribed or the subscription expired, it will never be valid again.
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		w.lo.Info("deleting expired push subscription", "status", resp.StatusCode)
+		if w.subscriptionStore != nil {
+			if err := w.subscriptionStore.DeletePushSubscription(sub.Endpoint); err != nil {
+				return fmt.Errorf("deleting expired push subscription: %w", err)
+			}
+		}
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+		return fmt.Errorf("push service returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+// vapidAuthorization returns the VAPID Authorization header for requests to a push service endpoint.
+func (w *WebPush) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid push subscription endpoint %q", endpoint)
+	}
+	claims := map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(jwtExpiry).Unix(),
+	}
+	if w.subject != "" {
+		claims["sub"] = w.subject
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("marshalling VAPID claims: %w", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`)) + "." + base64.RawURLEncoding.EncodeToString(c)
+	hash := sha256.Sum256([]byte(unsigned))
+	r, s, err := ecdsa.Sign(rand.Reader, w.privateKey, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("signing VAPID token: %w", err)
+	}
+	// ES256 signatures are the 32 byte big-endian R and S values concatenated.
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	return "vapid t=" + unsigned + "." + base64.RawURLEncoding.EncodeToString(sig) + ", k=" + w.publicKey, nil
+}
+
+// encrypt encrypts a push message for a browser with the `aes128gcm` content coding of RFC 8188,
+// deriving the key as per RFC 8291. It returns the message body, the coding header followed by the ciphertext.
+func encrypt(plaintext, uaPublic, authSecret []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription public key: %w", err)
+	}
+	ecdhSecret, err := asPrivate.ECDH(uaKey)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	info := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, ecdhSecret, authSecret, info, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The single, and so last, record is delimited with 0x02.
+	record := append(append(make([]byte, 0, len(plaintext)+1), plaintext...), 0x02)
+	if len(record)+gcm.Overhead() > recordSize {
+		return nil, fmt.Errorf("push payload of %d bytes too large", len(plaintext))
+	}
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// parsePrivateKey parses a base64url encoded P-256 private key.
+func parsePrivateKey(s string) (*ecdsa.PrivateKey, error) {
+	b, err := decodeBase64(s)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ecdh.P256().NewPrivateKey(b)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an ECDSA key")
+	}
+	return ecKey, nil
+}
+
+// decodeBase64 decodes base64url encoded keys with or without padding, as browsers send them.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// truncate truncates a string to max runes.
+func truncate(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max]) + "…"
+	}
+	return s
+}
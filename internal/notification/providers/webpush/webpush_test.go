@@ -0,0 +1,176 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/netutil"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodha/logf"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := decodeBase64(s)
+	require.NoError(t, err)
+	return b
+}
+
+// TestEncrypt checks encryption against the example of RFC 8291 appendix A.
+func TestEncrypt(t *testing.T) {
+	asPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	require.NoError(t, err)
+
+	body, err := encrypt(
+		[]byte("When I grow up, I want to be a watermelon"),
+		mustDecode(t, "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"),
+		mustDecode(t, "BTBZMqHH6r4Tts7J_aSIgg"),
+		asPrivate,
+		mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlw"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN",
+		base64.RawURLEncoding.EncodeToString(body))
+}
+
+func TestEncryptTooLarge(t *testing.T) {
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = encrypt(make([]byte, recordSize), asPrivate.PublicKey().Bytes(), make([]byte, 16), asPrivate, make([]byte, 16))
+	assert.Error(t, err)
+}
+
+func TestNewKeyMismatch(t *testing.T) {
+	pub, priv, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	otherPub, _, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+
+	w, err := New(Opts{VAPIDPublicKey: pub, VAPIDPrivateKey: priv})
+	require.NoError(t, err)
+	assert.Equal(t, pub, w.PublicKey())
+
+	_, err = New(Opts{VAPIDPublicKey: otherPub, VAPIDPrivateKey: priv})
+	assert.Error(t, err)
+	_, err = New(Opts{})
+	assert.Error(t, err)
+}
+
+func TestVAPIDAuthorization(t *testing.T) {
+	pub, priv, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	w, err := New(Opts{VAPIDPrivateKey: priv, Subject: "mailto:admin@example.com"})
+	require.NoError(t, err)
+
+	header, err := w.vapidAuthorization("https://push.example.com/send/abc?x=1")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(header, "vapid t="))
+	token, key, ok := strings.Cut(strings.TrimPrefix(header, "vapid t="), ", k=")
+	require.True(t, ok)
+	assert.Equal(t, pub, key)
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+	var claims map[string]any
+	require.NoError(t, json.Unmarshal(mustDecode(t, parts[1]), &claims))
+	assert.Equal(t, "https://push.example.com", claims["aud"])
+	assert.Equal(t, "mailto:admin@example.com", claims["sub"])
+
+	sig := mustDecode(t, parts[2])
+	require.Len(t, sig, 64)
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assert.True(t, ecdsa.Verify(&w.privateKey.PublicKey, hash[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])))
+
+	_, err = w.vapidAuthorization("not a url")
+	assert.Error(t, err)
+}
+
+type store struct {
+	deleted []string
+}
+
+func (s *store) DeletePushSubscription(endpoint string) error {
+	s.deleted = append(s.deleted, endpoint)
+	return nil
+}
+
+func TestSend(t *testing.T) {
+	ua, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sub := func(endpoint string) notifier.PushSubscription {
+		return notifier.PushSubscription{
+			Endpoint: endpoint,
+			P256dh:   base64.RawURLEncoding.EncodeToString(ua.PublicKey().Bytes()),
+			Auth:     base64.URLEncoding.EncodeToString([]byte("0123456789abcdef")),
+		}
+	}
+
+	var got []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "aes128gcm", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "86400", r.Header.Get("TTL"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "vapid t="))
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/error":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("bad request"))
+		default:
+			got, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer srv.Close()
+
+	_, priv, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	lo := logf.New(logf.Opts{})
+	w, err := New(Opts{Lo: &lo, VAPIDPrivateKey: priv})
+	require.NoError(t, err)
+	s := &store{}
+	w.SetSubscriptionStore(s)
+
+	msg := notifier.Message{
+		Subject:           "Conversation assigned",
+		PushSubscriptions: []notifier.PushSubscription{sub(srv.URL + "/ok")},
+	}
+	// Endpoints on the local network are refused.
+	err = w.Send(msg)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, netutil.ErrNonPublicAddress)
+	assert.Nil(t, got)
+	w.client = srv.Client()
+
+	msg = notifier.Message{
+		Subject:    "Conversation assigned",
+		AltContent: "Refund request",
+		Link:       "/inboxes/assigned/conversation/abc",
+	}
+	assert.Error(t, w.Send(msg))
+
+	msg.PushSubscriptions = []notifier.PushSubscription{sub(srv.URL + "/ok"), sub(srv.URL + "/gone")}
+	require.NoError(t, w.Send(msg))
+	assert.Equal(t, []string{srv.URL + "/gone"}, s.deleted)
+	// Body is salt, record size, key length and the 65 byte key followed by the ciphertext.
+	require.Greater(t, len(got), 86)
+	assert.Equal(t, byte(65), got[20])
+
+	msg.PushSubscriptions = []notifier.PushSubscription{sub(srv.URL + "/error")}
+	err = w.Send(msg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 400")
+}
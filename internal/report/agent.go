@@ -0,0 +1,102 @@
+package report
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/report/models"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// availabilityStatuses are the availability statuses reported, in the order of the CSV columns.
+var availabilityStatuses = []string{umodels.Online, umodels.AwayManual, umodels.AwayAndReassigning, umodels.Offline}
+
+// agentPerformanceCSVHeader is the header row of the agent performance report CSV export.
+var agentPerformanceCSVHeader = []string{
+	"user_id", "name", "assigned", "replied", "resolved", "messages_sent",
+	"avg_first_response_sec", "p50_first_response_sec", "p90_first_response_sec",
+	"avg_resolution_sec", "p50_resolution_sec", "p90_resolution_sec",
+	"reopened", "reopen_rate", "csat_responses", "csat_average",
+	"online_sec", "away_manual_sec", "away_and_reassigning_sec", "offline_sec",
+}
+
+// GetAgentPerformanceReport returns the conversation counts, response and resolution times, CSAT and time spent in each
+// availability status of agents in a period.
+func (m *Manager) GetAgentPerformanceReport(opts models.AgentPerformanceOptions) ([]models.AgentPerformanceRow, error) {
+	if !validPeriod(opts.From, opts.To) {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`from`, `to`"), nil)
+	}
+	if opts.TeamID < 0 {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`team_id`"), nil)
+	}
+	if opts.InboxID < 0 {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`inbox_id`"), nil)
+	}
+
+	var (
+		rows         = make([]models.AgentPerformanceRow, 0)
+		availability []models.AgentAvailability
+	)
+	if err := m.selectReport("agents", func(tx *sqlx.Tx) error {
+		if err := tx.Select(&rows, m.q.GetAgentPerformanceReport, opts.From, opts.To, opts.TeamID, opts.InboxID); err != nil {
+			return err
+		}
+		return tx.Select(&availability, m.q.GetAgentAvailabilityReport, opts.From, opts.To)
+	}); err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		rows[i].ReopenRate = percentage(rows[i].Reopened, rows[i].Resolved)
+	}
+	mergeAvailability(rows, availability)
+	return rows, nil
+}
+
+// mergeAvailability sets the seconds each agent spent in each availability status, statuses an agent was never in are zero.
+func mergeAvailability(rows []models.AgentPerformanceRow, availability []models.AgentAvailability) {
+	byUser := make(map[int]map[string]float64)
+	for _, a := range availability {
+		if byUser[a.UserID] == nil {
+			byUser[a.UserID] = make(map[string]float64, len(availabilityStatuses))
+		}
+		byUser[a.UserID][a.Status] += a.Seconds
+	}
+	for i := range rows {
+		rows[i].AvailabilitySec = make(map[string]float64, len(availabilityStatuses))
+		for _, status := range availabilityStatuses {
+			rows[i].AvailabilitySec[status] = byUser[rows[i].UserID][status]
+		}
+	}
+}
+
+// WriteAgentPerformanceReportCSV writes the agent performance report rows as CSV with a header row.
+func WriteAgentPerformanceReportCSV(w io.Writer, rows []models.AgentPerformanceRow) error {
+	return writeCSV(w, agentPerformanceCSVHeader, len(rows), func(i int) []string {
+		r := rows[i]
+		record := []string{
+			strconv.Itoa(r.UserID),
+			r.Name,
+			strconv.Itoa(r.Assigned),
+			strconv.Itoa(r.Replied),
+			strconv.Itoa(r.Resolved),
+			strconv.Itoa(r.MessagesSent),
+			formatFloat(r.AvgFirstResponseSec),
+			formatFloat(r.P50FirstResponseSec),
+			formatFloat(r.P90FirstResponseSec),
+			formatFloat(r.AvgResolutionSec),
+			formatFloat(r.P50ResolutionSec),
+			formatFloat(r.P90ResolutionSec),
+			strconv.Itoa(r.Reopened),
+			formatFloat(r.ReopenRate),
+			strconv.Itoa(r.CSATResponses),
+			formatFloat(r.CSATAverage),
+		}
+		for _, status := range availabilityStatuses {
+			record = append(record, formatFloat(r.AvailabilitySec[status]))
+		}
+		return record
+	})
+}
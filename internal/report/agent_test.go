@@ -0,0 +1,73 @@
+package report
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	"github.com/abhinavxd/libredesk/internal/report/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeAvailability(t *testing.T) {
+	rows := []models.AgentPerformanceRow{{UserID: 1}, {UserID: 2}}
+	mergeAvailability(rows, []models.AgentAvailability{
+		{UserID: 1, Status: "online", Seconds: 3600},
+		{UserID: 1, Status: "away_manual", Seconds: 600},
+		{UserID: 1, Status: "online", Seconds: 1800},
+		{UserID: 3, Status: "online", Seconds: 60},
+	})
+
+	assert.Equal(t, map[string]float64{"online": 5400, "away_manual": 600, "away_and_reassigning": 0, "offline": 0}, rows[0].AvailabilitySec)
+	assert.Equal(t, map[string]float64{"online": 0, "away_manual": 0, "away_and_reassigning": 0, "offline": 0}, rows[1].AvailabilitySec)
+}
+
+func TestGetAgentPerformanceReportValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		opts models.AgentPerformanceOptions
+	}{
+		{"reversed period", models.AgentPerformanceOptions{From: testTo, To: testFrom}},
+		{"period too long", models.AgentPerformanceOptions{From: testFrom, To: testFrom.AddDate(0, 0, maxReportDays+1)}},
+		{"negative team", models.AgentPerformanceOptions{From: testFrom, To: testTo, TeamID: -1}},
+		{"negative inbox", models.AgentPerformanceOptions{From: testFrom, To: testTo, InboxID: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, db := newTestManager(t, nil)
+			_, err := m.GetAgentPerformanceReport(tt.opts)
+			assertInputError(t, err)
+			assert.Empty(t, db.Queries(""))
+		})
+	}
+}
+
+func TestGetAgentPerformanceReport(t *testing.T) {
+	m, db := newTestManager(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+		if strings.Contains(query, "activity_logs") {
+			return dbtest.Result{
+				Columns: []string{"user_id", "status", "seconds"},
+				Rows:    [][]any{{1, "online", 7200.0}, {2, "offline", 60.0}},
+			}, nil
+		}
+		return dbtest.Result{
+			Columns: []string{"user_id", "name", "resolved", "reopened"},
+			Rows:    [][]any{{1, "Jane Doe", 3, 1}, {2, "John Doe", 0, 0}},
+		}, nil
+	})
+
+	rows, err := m.GetAgentPerformanceReport(models.AgentPerformanceOptions{From: testFrom, To: testTo, TeamID: 2, InboxID: 3})
+	require.NoError(t, err)
+
+	queries := db.Queries("team_members")
+	require.Len(t, queries, 1)
+	assert.Equal(t, []driver.Value{testFrom, testTo, int64(2), int64(3)}, queries[0].Args)
+
+	require.Len(t, rows, 2)
+	assert.Equal(t, 33.33, rows[0].ReopenRate)
+	assert.Equal(t, 7200.0, rows[0].AvailabilitySec["online"])
+	assert.Zero(t, rows[1].ReopenRate)
+	assert.Equal(t, 60.0, rows[1].AvailabilitySec["offline"])
+}
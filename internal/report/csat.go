@@ -0,0 +1,93 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/report/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// csatReportGroups maps the CSAT report groups to the SQL expressions of the group ID, name and the join the group needs.
+var csatReportGroups = map[string][3]string{
+	"":      {"0", "''", ""},
+	"agent": {"COALESCE(c.assigned_user_id, 0)", "CONCAT_WS(' ', u.first_name, u.last_name)", ""},
+	"team":  {"COALESCE(c.assigned_team_id, 0)", "COALESCE(t.name, '')", ""},
+	"inbox": {"c.inbox_id", "COALESCE(i.name, '')", ""},
+	"tag": {"COALESCE(tg.id, 0)", "COALESCE(tg.name, '')",
+		"LEFT JOIN conversation_tags ct ON ct.conversation_id = c.id LEFT JOIN tags tg ON tg.id = ct.tag_id"},
+}
+
+// csatReportIntervals maps the CSAT report intervals to the SQL expressions of the time bucket.
+var csatReportIntervals = map[string]string{
+	"":     "NULL::TIMESTAMPTZ",
+	"day":  "DATE_TRUNC('day', cr.created_at)",
+	"week": "DATE_TRUNC('week', cr.created_at)",
+}
+
+// csatReportCSVHeader is the header row of the CSAT report CSV export.
+var csatReportCSVHeader = []string{
+	"bucket", "group_id", "group_name", "sent", "responses", "response_rate", "average_rating",
+	"rating_1", "rating_2", "rating_3", "rating_4", "rating_5",
+}
+
+// GetCSATReport returns the surveys sent and answered, average rating and rating distribution, grouped by the given group and time interval.
+func (m *Manager) GetCSATReport(opts models.CSATReportOptions) ([]models.CSATReportRow, error) {
+	group, ok := csatReportGroups[opts.GroupBy]
+	if !ok {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`group_by`"), nil)
+	}
+	bucket, ok := csatReportIntervals[opts.Interval]
+	if !ok {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`interval`"), nil)
+	}
+	if !validPeriod(opts.From, opts.To) {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`from`, `to`"), nil)
+	}
+
+	var rows = make([]models.CSATReportRow, 0)
+	query := fmt.Sprintf(m.q.GetCSATReport, bucket, group[0], group[1], group[2])
+	if err := m.selectReport("csat", func(tx *sqlx.Tx) error {
+		return tx.Select(&rows, query, opts.From, opts.To)
+	}); err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		computeCSATRates(&rows[i])
+	}
+	return rows, nil
+}
+
+// computeCSATRates sets the response rate and average rating of a row from its survey counts and rating distribution.
+func computeCSATRates(r *models.CSATReportRow) {
+	r.ResponseRate = percentage(r.Responses, r.Sent)
+	r.AverageRating = 0
+	if r.Responses > 0 {
+		sum := r.Rating1 + 2*r.Rating2 + 3*r.Rating3 + 4*r.Rating4 + 5*r.Rating5
+		r.AverageRating = round2(float64(sum) / float64(r.Responses))
+	}
+}
+
+// WriteCSATReportCSV writes the CSAT report rows as CSV with a header row.
+func WriteCSATReportCSV(w io.Writer, rows []models.CSATReportRow) error {
+	return writeCSV(w, csatReportCSVHeader, len(rows), func(i int) []string {
+		r := rows[i]
+		return []string{
+			formatBucket(r.Bucket),
+			strconv.Itoa(r.GroupID),
+			r.GroupName,
+			strconv.Itoa(r.Sent),
+			strconv.Itoa(r.Responses),
+			formatFloat(r.ResponseRate),
+			formatFloat(r.AverageRating),
+			strconv.Itoa(r.Rating1),
+			strconv.Itoa(r.Rating2),
+			strconv.Itoa(r.Rating3),
+			strconv.Itoa(r.Rating4),
+			strconv.Itoa(r.Rating5),
+		}
+	})
+}
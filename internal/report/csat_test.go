@@ -0,0 +1,73 @@
+package report
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	"github.com/abhinavxd/libredesk/internal/report/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeCSATRates(t *testing.T) {
+	r := models.CSATReportRow{Sent: 12, Responses: 9, Rating1: 1, Rating3: 1, Rating4: 2, Rating5: 5}
+	computeCSATRates(&r)
+	assert.Equal(t, 75.0, r.ResponseRate)
+	// (1 + 3 + 8 + 25) / 9
+	assert.Equal(t, 4.11, r.AverageRating)
+
+	// Surveys without responses have no rating.
+	r = models.CSATReportRow{Sent: 3}
+	computeCSATRates(&r)
+	assert.Zero(t, r.ResponseRate)
+	assert.Zero(t, r.AverageRating)
+
+	r = models.CSATReportRow{}
+	computeCSATRates(&r)
+	assert.Zero(t, r.ResponseRate)
+}
+
+func TestGetCSATReportValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		opts models.CSATReportOptions
+	}{
+		{"unknown group", models.CSATReportOptions{GroupBy: "policy", From: testFrom, To: testTo}},
+		{"unknown interval", models.CSATReportOptions{Interval: "month", From: testFrom, To: testTo}},
+		{"reversed period", models.CSATReportOptions{From: testTo, To: testFrom}},
+		{"period too long", models.CSATReportOptions{From: testFrom, To: testFrom.AddDate(0, 0, maxReportDays+1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, db := newTestManager(t, nil)
+			_, err := m.GetCSATReport(tt.opts)
+			assertInputError(t, err)
+			assert.Empty(t, db.Queries(""))
+		})
+	}
+}
+
+func TestGetCSATReportGrouping(t *testing.T) {
+	m, db := newTestManager(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+		return dbtest.Result{
+			Columns: []string{"bucket", "group_id", "group_name", "sent", "responses", "rating_1", "rating_2", "rating_3", "rating_4", "rating_5"},
+			Rows:    [][]any{{nil, 4, "vip", 4, 2, 0, 0, 0, 1, 1}},
+		}, nil
+	})
+
+	rows, err := m.GetCSATReport(models.CSATReportOptions{GroupBy: "tag", From: testFrom, To: testTo})
+	require.NoError(t, err)
+
+	// Tag groups join the tags of the conversations.
+	queries := db.Queries("csat_responses")
+	require.Len(t, queries, 1)
+	assert.Equal(t, []driver.Value{testFrom, testTo}, queries[0].Args)
+	assert.True(t, strings.Contains(queries[0].Query, csatReportGroups["tag"][0]+" AS group_id"))
+	assert.True(t, strings.Contains(queries[0].Query, csatReportGroups["tag"][2]))
+
+	assert.Equal(t, []models.CSATReportRow{
+		{GroupID: 4, GroupName: "vip", Sent: 4, Responses: 2, ResponseRate: 50, AverageRating: 4.5, Rating4: 1, Rating5: 1},
+	}, rows)
+}
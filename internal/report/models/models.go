@@ -1,5 +1,18 @@
 package models
 
+import (
+	"time"
+
+	"github.com/volatiletech/null/v9"
+)
+
+// Report types that can be exported.
+const (
+	ReportTypeSLA    = "sla"
+	ReportTypeAgents = "agents"
+	ReportTypeCSAT   = "csat"
+)
+
 type OverviewSLA struct {
 	FirstResponseMetCount      int     `json:"first_response_met_count" db:"first_response_met_count"`
 	FirstResponseBreachedCount int     `json:"first_response_breached_count" db:"first_response_breached_count"`
@@ -11,3 +24,96 @@ type OverviewSLA struct {
 	ResolutionBreachedCount    int     `json:"resolution_breached_count" db:"resolution_breached_count"`
 	AvgResolutionTimeSec       float64 `json:"avg_resolution_time_sec" db:"avg_resolution_time_sec"`
 }
+
+// SLAReportOptions holds the options of an SLA report.
+type SLAReportOptions struct {
+	// GroupBy is one of team, agent, policy, inbox or priority, all SLAs are reported as one group if empty.
+	GroupBy string
+	// Interval is one of day or week, the whole period is reported as one bucket if empty.
+	Interval string
+	From     time.Time
+	To       time.Time
+}
+
+// SLAReportRow is the performance of an SLA metric for a group in a time bucket.
+type SLAReportRow struct {
+	Bucket             null.Time `json:"bucket" db:"bucket"`
+	GroupID            int       `json:"group_id" db:"group_id"`
+	GroupName          string    `json:"group_name" db:"group_name"`
+	Metric             string    `json:"metric" db:"metric"`
+	Total              int       `json:"total" db:"total"`
+	MetCount           int       `json:"met_count" db:"met_count"`
+	BreachedCount      int       `json:"breached_count" db:"breached_count"`
+	PendingCount       int       `json:"pending_count" db:"pending_count"`
+	MetPercentage      float64   `json:"met_percentage" db:"met_percentage"`
+	BreachedPercentage float64   `json:"breached_percentage" db:"breached_percentage"`
+	AvgTimeSec         float64   `json:"avg_time_sec" db:"avg_time_sec"`
+	P50TimeSec         float64   `json:"p50_time_sec" db:"p50_time_sec"`
+	P90TimeSec         float64   `json:"p90_time_sec" db:"p90_time_sec"`
+	P95TimeSec         float64   `json:"p95_time_sec" db:"p95_time_sec"`
+}
+
+// AgentPerformanceOptions holds the options of an agent performance report.
+type AgentPerformanceOptions struct {
+	From time.Time
+	To   time.Time
+	// TeamID limits the report to the conversations of a team and its members, all teams are reported if 0.
+	TeamID int
+	// InboxID limits the report to the conversations of an inbox, all inboxes are reported if 0.
+	InboxID int
+}
+
+// AgentPerformanceRow is the performance of an agent in a period.
+type AgentPerformanceRow struct {
+	UserID              int     `json:"user_id" db:"user_id"`
+	Name                string  `json:"name" db:"name"`
+	Assigned            int     `json:"assigned" db:"assigned"`
+	Replied             int     `json:"replied" db:"replied"`
+	Resolved            int     `json:"resolved" db:"resolved"`
+	MessagesSent        int     `json:"messages_sent" db:"messages_sent"`
+	AvgFirstResponseSec float64 `json:"avg_first_response_sec" db:"avg_first_response_sec"`
+	P50FirstResponseSec float64 `json:"p50_first_response_sec" db:"p50_first_response_sec"`
+	P90FirstResponseSec float64 `json:"p90_first_response_sec" db:"p90_first_response_sec"`
+	AvgResolutionSec    float64 `json:"avg_resolution_sec" db:"avg_resolution_sec"`
+	P50ResolutionSec    float64 `json:"p50_resolution_sec" db:"p50_resolution_sec"`
+	P90ResolutionSec    float64 `json:"p90_resolution_sec" db:"p90_resolution_sec"`
+	Reopened            int     `json:"reopened" db:"reopened"`
+	ReopenRate          float64 `json:"reopen_rate" db:"reopen_rate"`
+	CSATResponses       int     `json:"csat_responses" db:"csat_responses"`
+	CSATAverage         float64 `json:"csat_average" db:"csat_average"`
+	// AvailabilitySec maps the availability statuses to the seconds the agent spent in them.
+	AvailabilitySec map[string]float64 `json:"availability_sec" db:"-"`
+}
+
+// AgentAvailability is the time an agent spent in an availability status.
+type AgentAvailability struct {
+	UserID  int     `db:"user_id"`
+	Status  string  `db:"status"`
+	Seconds float64 `db:"seconds"`
+}
+
+// CSATReportOptions holds the options of a CSAT report.
+type CSATReportOptions struct {
+	// GroupBy is one of agent, team, inbox or tag, all surveys are reported as one group if empty.
+	GroupBy string
+	// Interval is one of day or week, the whole period is reported as one bucket if empty.
+	Interval string
+	From     time.Time
+	To       time.Time
+}
+
+// CSATReportRow is the CSAT of a group in a time bucket.
+type CSATReportRow struct {
+	Bucket        null.Time `json:"bucket" db:"bucket"`
+	GroupID       int       `json:"group_id" db:"group_id"`
+	GroupName     string    `json:"group_name" db:"group_name"`
+	Sent          int       `json:"sent" db:"sent"`
+	Responses     int       `json:"responses" db:"responses"`
+	ResponseRate  float64   `json:"response_rate" db:"response_rate"`
+	AverageRating float64   `json:"average_rating" db:"average_rating"`
+	Rating1       int       `json:"rating_1" db:"rating_1"`
+	Rating2       int       `json:"rating_2" db:"rating_2"`
+	Rating3       int       `json:"rating_3" db:"rating_3"`
+	Rating4       int       `json:"rating_4" db:"rating_4"`
+	Rating5       int       `json:"rating_5" db:"rating_5"`
+}
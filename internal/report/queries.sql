@@ -1,26 +1,15 @@
 -- name: get-overview-counts
+-- Conversation counts are read from the open conversation rollups.
 SELECT
     json_build_object(
         'open',
-        COUNT(*),
+        COALESCE(SUM(r.open), 0),
         'awaiting_response',
-        COUNT(
-            CASE
-                WHEN c.last_message_sender = 'contact' THEN 1
-            END
-        ),
+        COALESCE(SUM(r.awaiting_response), 0),
         'unassigned',
-        COUNT(
-            CASE
-                WHEN c.assigned_user_id IS NULL THEN 1
-            END
-        ),
+        COALESCE(SUM(r.unassigned), 0),
         'pending',
-        COUNT(
-            CASE
-                WHEN c.first_reply_at IS NULL THEN 1
-            END
-        ),
+        COALESCE(SUM(r.pending), 0),
         'agents_online',
         (
             SELECT
@@ -64,13 +53,17 @@ SELECT
                 availability_status = 'offline'
                 AND type = 'agent'
                 AND deleted_at is null
+        ),
+        'rolled_up_at',
+        (
+            SELECT
+                rolled_up_at
+            FROM
+                report_rollup_state
         )
     )
 FROM
-    conversations c
-    INNER JOIN conversation_statuses s ON c.status_id = s.id
-WHERE
-    s.name not in ('Resolved', 'Closed');
+    report_rollups_open r;
 
 -- name: get-overview-sla-counts
 WITH first_and_resolution AS (
@@ -172,63 +165,492 @@ FROM
     next_response nr;
 
 -- name: get-overview-charts
-WITH new_conversations AS (
+-- Daily counts are read from the daily rollups.
+WITH daily AS (
     SELECT
-        json_agg(row_to_json(agg)) AS data
+        TO_CHAR(r.day, 'YYYY-MM-DD') AS date,
+        SUM(r.conversations_created) AS created,
+        SUM(r.conversations_resolved) AS resolved
     FROM
-        (
-            SELECT
-                TO_CHAR(created_at :: date, 'YYYY-MM-DD') AS date,
-                COUNT(*) AS count
-            FROM
-                conversations c
-            WHERE
-                c.created_at >= CASE
-                    WHEN %d = 0 THEN CURRENT_DATE
-                    ELSE NOW() - INTERVAL '%d days'
-                END
-            GROUP BY
-                date
-            ORDER BY
-                date
-        ) agg
-),
-resolved_conversations AS (
-    SELECT
-        json_agg(row_to_json(agg)) AS data
-    FROM
-        (
-            SELECT
-                TO_CHAR(resolved_at :: date, 'YYYY-MM-DD') AS date,
-                COUNT(*) AS count
-            FROM
-                conversations c
-            WHERE
-                c.resolved_at IS NOT NULL
-                AND c.created_at >= CASE
-                    WHEN %d = 0 THEN CURRENT_DATE
-                    ELSE NOW() - INTERVAL '%d days'
-                END
-            GROUP BY
-                date
-            ORDER BY
-                date
-        ) agg
+        report_rollups_daily r
+    WHERE
+        r.day >= CASE
+            WHEN %d = 0 THEN CURRENT_DATE
+            ELSE (NOW() - INTERVAL '%d days') :: date
+        END
+    GROUP BY
+        r.day
 )
 SELECT
     json_build_object(
         'new_conversations',
         (
             SELECT
-                data
+                json_agg(json_build_object('date', date, 'count', created) ORDER BY date)
             FROM
-                new_conversations
+                daily
+            WHERE
+                created > 0
         ),
         'resolved_conversations',
         (
             SELECT
-                data
+                json_agg(json_build_object('date', date, 'count', resolved) ORDER BY date)
             FROM
-                resolved_conversations
+                daily
+            WHERE
+                resolved > 0
+        ),
+        'rolled_up_at',
+        s.rolled_up_at,
+        'backfilled',
+        s.backfilled_at IS NOT NULL
+    ) AS result
+FROM
+    report_rollup_state s;
+
+-- name: get-sla-report
+-- Formatted with the bucket, group ID and group name expressions. A metric is met if it was met before it breached.
+-- The pending count and percentages are computed by the report manager.
+WITH metrics AS (
+    SELECT
+        a.conversation_id,
+        a.sla_policy_id,
+        'first_response' AS metric,
+        a.created_at,
+        a.first_response_met_at AS met_at,
+        a.first_response_breached_at AS breached_at
+    FROM
+        applied_slas a
+    WHERE
+        a.first_response_deadline_at IS NOT NULL
+        AND a.created_at >= $1
+        AND a.created_at < $2
+    UNION ALL
+    SELECT
+        a.conversation_id,
+        a.sla_policy_id,
+        'resolution' AS metric,
+        a.created_at,
+        a.resolution_met_at AS met_at,
+        a.resolution_breached_at AS breached_at
+    FROM
+        applied_slas a
+    WHERE
+        a.resolution_deadline_at IS NOT NULL
+        AND a.created_at >= $1
+        AND a.created_at < $2
+    UNION ALL
+    SELECT
+        a.conversation_id,
+        e.sla_policy_id,
+        'next_response' AS metric,
+        e.created_at,
+        e.met_at,
+        e.breached_at
+    FROM
+        sla_events e
+        INNER JOIN applied_slas a ON a.id = e.applied_sla_id
+    WHERE
+        e.type = 'next_response'
+        AND e.created_at >= $1
+        AND e.created_at < $2
+),
+grouped AS (
+    SELECT
+        %s AS bucket,
+        %s AS group_id,
+        %s AS group_name,
+        m.metric,
+        COUNT(*) AS total,
+        COUNT(*) FILTER (
+            WHERE
+                m.met_at IS NOT NULL
+                AND m.breached_at IS NULL
+        ) AS met_count,
+        COUNT(*) FILTER (
+            WHERE
+                m.breached_at IS NOT NULL
+        ) AS breached_count,
+        AVG(EXTRACT(EPOCH FROM (m.met_at - m.created_at))) FILTER (
+            WHERE
+                m.met_at IS NOT NULL
+        ) AS avg_time_sec,
+        PERCENTILE_CONT(0.5) WITHIN GROUP (
+            ORDER BY
+                EXTRACT(EPOCH FROM (m.met_at - m.created_at))::FLOAT
+        ) FILTER (
+            WHERE
+                m.met_at IS NOT NULL
+        ) AS p50_time_sec,
+        PERCENTILE_CONT(0.9) WITHIN GROUP (
+            ORDER BY
+                EXTRACT(EPOCH FROM (m.met_at - m.created_at))::FLOAT
+        ) FILTER (
+            WHERE
+                m.met_at IS NOT NULL
+        ) AS p90_time_sec,
+        PERCENTILE_CONT(0.95) WITHIN GROUP (
+            ORDER BY
+                EXTRACT(EPOCH FROM (m.met_at - m.created_at))::FLOAT
+        ) FILTER (
+            WHERE
+                m.met_at IS NOT NULL
+        ) AS p95_time_sec
+    FROM
+        metrics m
+        INNER JOIN conversations c ON c.id = m.conversation_id
+        LEFT JOIN teams t ON t.id = c.assigned_team_id
+        LEFT JOIN users u ON u.id = c.assigned_user_id
+        LEFT JOIN sla_policies sp ON sp.id = m.sla_policy_id
+        LEFT JOIN inboxes i ON i.id = c.inbox_id
+        LEFT JOIN conversation_priorities p ON p.id = c.priority_id
+    GROUP BY
+        1,
+        2,
+        3,
+        4
+)
+SELECT
+    bucket,
+    group_id,
+    group_name,
+    metric,
+    total,
+    met_count,
+    breached_count,
+    COALESCE(avg_time_sec, 0) AS avg_time_sec,
+    COALESCE(p50_time_sec, 0) AS p50_time_sec,
+    COALESCE(p90_time_sec, 0) AS p90_time_sec,
+    COALESCE(p95_time_sec, 0) AS p95_time_sec
+FROM
+    grouped
+ORDER BY
+    bucket NULLS FIRST,
+    group_name,
+    metric;
+
+-- name: get-agent-performance-report
+-- Team ID and inbox ID filter the conversations reported on and are ignored if 0, a team also limits the agents to its members.
+-- Assignments and resolutions are read from the meta of conversation activity messages, the reopen rate is computed by the
+-- report manager.
+WITH agents AS (
+    SELECT
+        u.id,
+        CONCAT_WS(' ', u.first_name, u.last_name) AS name
+    FROM
+        users u
+    WHERE
+        u.type = 'agent'
+        AND u.deleted_at IS NULL
+        AND u.email != 'System'
+        AND (
+            $3 = 0
+            OR EXISTS (
+                SELECT 1 FROM team_members tm WHERE tm.user_id = u.id AND tm.team_id = $3
+            )
         )
-    ) AS result;
\ No newline at end of file
+),
+convs AS (
+    SELECT
+        c.id,
+        c.created_at,
+        c.first_reply_at,
+        c.assigned_user_id
+    FROM
+        conversations c
+    WHERE
+        ($3 = 0 OR c.assigned_team_id = $3)
+        AND ($4 = 0 OR c.inbox_id = $4)
+),
+assigned AS (
+    SELECT
+        (m.meta->>'assignee_id')::BIGINT AS user_id,
+        COUNT(DISTINCT m.conversation_id) AS assigned
+    FROM
+        conversation_messages m
+        INNER JOIN convs c ON c.id = m.conversation_id
+    WHERE
+        m.type = 'activity'
+        AND m.meta->>'activity_type' IN ('assigned_user_change', 'self_assign')
+        AND m.created_at >= $1
+        AND m.created_at < $2
+    GROUP BY
+        1
+),
+sent AS (
+    SELECT
+        m.sender_id AS user_id,
+        COUNT(DISTINCT m.conversation_id) AS replied,
+        COUNT(*) AS messages_sent
+    FROM
+        conversation_messages m
+        INNER JOIN convs c ON c.id = m.conversation_id
+    WHERE
+        m.type = 'outgoing'
+        AND m.sender_type = 'agent'
+        AND m.private = FALSE
+        AND m.created_at >= $1
+        AND m.created_at < $2
+    GROUP BY
+        1
+),
+first_replies AS (
+    SELECT DISTINCT ON (c.id)
+        m.sender_id AS user_id,
+        EXTRACT(EPOCH FROM (m.created_at - c.created_at))::FLOAT AS seconds
+    FROM
+        convs c
+        INNER JOIN conversation_messages m ON m.conversation_id = c.id
+    WHERE
+        c.first_reply_at >= $1
+        AND c.first_reply_at < $2
+        AND m.type = 'outgoing'
+        AND m.sender_type = 'agent'
+        AND m.private = FALSE
+    ORDER BY
+        c.id,
+        m.created_at
+),
+first_response AS (
+    SELECT
+        user_id,
+        AVG(seconds) AS avg_first_response_sec,
+        PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY seconds) AS p50_first_response_sec,
+        PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY seconds) AS p90_first_response_sec
+    FROM
+        first_replies
+    GROUP BY
+        1
+),
+resolutions AS (
+    SELECT
+        m.sender_id AS user_id,
+        EXTRACT(EPOCH FROM (m.created_at - c.created_at))::FLOAT AS seconds,
+        EXISTS (
+            SELECT 1
+            FROM conversation_messages r
+            WHERE
+                r.conversation_id = m.conversation_id
+                AND r.type = 'activity'
+                AND r.meta->>'activity_type' = 'status_change'
+                AND r.meta->>'new_value' = 'Open'
+                AND r.created_at > m.created_at
+        ) AS reopened
+    FROM
+        conversation_messages m
+        INNER JOIN convs c ON c.id = m.conversation_id
+    WHERE
+        m.type = 'activity'
+        AND m.meta->>'activity_type' = 'status_change'
+        AND m.meta->>'new_value' = 'Resolved'
+        AND m.created_at >= $1
+        AND m.created_at < $2
+),
+resolved AS (
+    SELECT
+        user_id,
+        COUNT(*) AS resolved,
+        COUNT(*) FILTER (WHERE reopened) AS reopened,
+        AVG(seconds) AS avg_resolution_sec,
+        PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY seconds) AS p50_resolution_sec,
+        PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY seconds) AS p90_resolution_sec
+    FROM
+        resolutions
+    GROUP BY
+        1
+),
+csat AS (
+    SELECT
+        c.assigned_user_id AS user_id,
+        COUNT(*) AS csat_responses,
+        AVG(cr.rating) AS csat_average
+    FROM
+        csat_responses cr
+        INNER JOIN convs c ON c.id = cr.conversation_id
+    WHERE
+        cr.rating > 0
+        AND cr.response_timestamp >= $1
+        AND cr.response_timestamp < $2
+    GROUP BY
+        1
+)
+SELECT
+    a.id AS user_id,
+    a.name,
+    COALESCE(asg.assigned, 0) AS assigned,
+    COALESCE(s.replied, 0) AS replied,
+    COALESCE(r.resolved, 0) AS resolved,
+    COALESCE(s.messages_sent, 0) AS messages_sent,
+    COALESCE(fr.avg_first_response_sec, 0) AS avg_first_response_sec,
+    COALESCE(fr.p50_first_response_sec, 0) AS p50_first_response_sec,
+    COALESCE(fr.p90_first_response_sec, 0) AS p90_first_response_sec,
+    COALESCE(r.avg_resolution_sec, 0) AS avg_resolution_sec,
+    COALESCE(r.p50_resolution_sec, 0) AS p50_resolution_sec,
+    COALESCE(r.p90_resolution_sec, 0) AS p90_resolution_sec,
+    COALESCE(r.reopened, 0) AS reopened,
+    COALESCE(cs.csat_responses, 0) AS csat_responses,
+    COALESCE(ROUND(cs.csat_average, 2), 0) AS csat_average
+FROM
+    agents a
+    LEFT JOIN assigned asg ON asg.user_id = a.id
+    LEFT JOIN sent s ON s.user_id = a.id
+    LEFT JOIN first_response fr ON fr.user_id = a.id
+    LEFT JOIN resolved r ON r.user_id = a.id
+    LEFT JOIN csat cs ON cs.user_id = a.id
+ORDER BY
+    a.name;
+
+-- name: get-agent-availability-report
+-- Sums the seconds agents spent in each availability status between $1 and $2, using the availability changes recorded in
+-- the activity log. The status before $1 is taken from the last change before it.
+WITH events AS (
+    SELECT
+        l.target_model_id AS user_id,
+        l.created_at,
+        CASE l.activity_type
+            WHEN 'agent_login' THEN 'online'
+            WHEN 'agent_online' THEN 'online'
+            WHEN 'agent_away' THEN 'away_manual'
+            WHEN 'agent_away_reassigned' THEN 'away_and_reassigning'
+            ELSE 'offline'
+        END AS status
+    FROM
+        activity_logs l
+    WHERE
+        l.target_model_type = 'user'
+        AND l.created_at < $2
+        AND l.created_at >= (
+            SELECT COALESCE(MAX(p.created_at), '-infinity'::TIMESTAMPTZ)
+            FROM activity_logs p
+            WHERE p.target_model_type = 'user' AND p.target_model_id = l.target_model_id AND p.created_at < $1
+        )
+),
+spans AS (
+    SELECT
+        user_id,
+        status,
+        GREATEST(created_at, $1) AS started_at,
+        LEAST(COALESCE(LEAD(created_at) OVER (PARTITION BY user_id ORDER BY created_at), $2), $2, NOW()) AS ended_at
+    FROM
+        events
+)
+SELECT
+    user_id,
+    status,
+    SUM(EXTRACT(EPOCH FROM (ended_at - started_at)))::FLOAT AS seconds
+FROM
+    spans
+WHERE
+    ended_at > started_at
+GROUP BY
+    1,
+    2;
+
+-- name: get-csat-report
+-- Formatted with the bucket, group ID, group name and group join expressions. Surveys are counted in the period they were
+-- sent, conversations with several tags are counted in the group of each tag. The response rate and average rating are
+-- computed from the counts by the report manager.
+SELECT
+    %s AS bucket,
+    %s AS group_id,
+    %s AS group_name,
+    COUNT(*) AS sent,
+    COUNT(*) FILTER (WHERE cr.rating > 0) AS responses,
+    COUNT(*) FILTER (WHERE cr.rating = 1) AS rating_1,
+    COUNT(*) FILTER (WHERE cr.rating = 2) AS rating_2,
+    COUNT(*) FILTER (WHERE cr.rating = 3) AS rating_3,
+    COUNT(*) FILTER (WHERE cr.rating = 4) AS rating_4,
+    COUNT(*) FILTER (WHERE cr.rating = 5) AS rating_5
+FROM
+    csat_responses cr
+    INNER JOIN conversations c ON c.id = cr.conversation_id
+    LEFT JOIN teams t ON t.id = c.assigned_team_id
+    LEFT JOIN users u ON u.id = c.assigned_user_id
+    LEFT JOIN inboxes i ON i.id = c.inbox_id
+    %s
+WHERE
+    cr.created_at >= $1
+    AND cr.created_at < $2
+GROUP BY
+    1,
+    2,
+    3
+ORDER BY
+    bucket NULLS FIRST,
+    group_name;
+
+-- name: get-rollup-state
+SELECT rolled_up_at, backfilled_at FROM report_rollup_state WHERE id = 1 FOR UPDATE;
+
+-- name: get-first-conversation-at
+SELECT MIN(created_at) FROM conversations;
+
+-- name: set-rolled-up-at
+UPDATE report_rollup_state SET rolled_up_at = $1 WHERE id = 1;
+
+-- name: set-backfilled-at
+UPDATE report_rollup_state SET backfilled_at = $1 WHERE id = 1;
+
+-- name: delete-hourly-rollups
+DELETE FROM report_rollups_hourly WHERE bucket >= date_trunc('hour', $1::TIMESTAMPTZ) AND bucket < $2;
+
+-- name: insert-hourly-rollups
+-- Rolls up the conversations created and resolved from the start of the hour of $1 to $2, each event is counted
+-- in the hour it happened.
+INSERT INTO report_rollups_hourly (bucket, inbox_id, team_id, conversations_created, conversations_resolved)
+SELECT
+    bucket,
+    inbox_id,
+    team_id,
+    SUM(created),
+    SUM(resolved)
+FROM (
+    SELECT
+        date_trunc('hour', c.created_at) AS bucket, c.inbox_id, COALESCE(c.assigned_team_id, 0) AS team_id,
+        1 AS created, 0 AS resolved
+    FROM conversations c
+    WHERE c.created_at >= date_trunc('hour', $1::TIMESTAMPTZ) AND c.created_at < $2
+    UNION ALL
+    SELECT
+        date_trunc('hour', c.resolved_at), c.inbox_id, COALESCE(c.assigned_team_id, 0),
+        0, 1
+    FROM conversations c
+    WHERE c.resolved_at >= date_trunc('hour', $1::TIMESTAMPTZ) AND c.resolved_at < $2
+) events
+GROUP BY bucket, inbox_id, team_id;
+
+-- name: delete-daily-rollups
+DELETE FROM report_rollups_daily WHERE "day" >= $1::TIMESTAMPTZ::date AND "day" <= $2::TIMESTAMPTZ::date;
+
+-- name: insert-daily-rollups
+-- Sums the hourly rollups of the days from $1 to $2.
+INSERT INTO report_rollups_daily ("day", inbox_id, team_id, conversations_created, conversations_resolved)
+SELECT
+    bucket::date,
+    inbox_id,
+    team_id,
+    SUM(conversations_created),
+    SUM(conversations_resolved)
+FROM report_rollups_hourly
+WHERE bucket >= $1::TIMESTAMPTZ::date AND bucket < $2::TIMESTAMPTZ::date + 1
+GROUP BY bucket::date, inbox_id, team_id;
+
+-- name: delete-open-rollups
+DELETE FROM report_rollups_open;
+
+-- name: insert-open-rollups
+-- Recounts the open conversations, the open rollups are a full refresh and not maintained incrementally. Only the
+-- conversations in open statuses are read, through the status index, so the cost grows with the open conversations
+-- and not with all the conversations ever created.
+INSERT INTO report_rollups_open (inbox_id, team_id, "open", awaiting_response, unassigned, pending)
+SELECT
+    c.inbox_id,
+    COALESCE(c.assigned_team_id, 0),
+    COUNT(*),
+    COUNT(*) FILTER (WHERE c.last_message_sender = 'contact'),
+    COUNT(*) FILTER (WHERE c.assigned_user_id IS NULL),
+    COUNT(*) FILTER (WHERE c.first_reply_at IS NULL)
+FROM conversations c
+WHERE c.status_id IN (SELECT s.id FROM conversation_statuses s WHERE s.name NOT IN ('Resolved', 'Closed'))
+GROUP BY c.inbox_id, COALESCE(c.assigned_team_id, 0);
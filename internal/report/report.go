@@ -5,17 +5,28 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
 
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/abhinavxd/libredesk/internal/report/models"
 	"github.com/jmoiron/sqlx"
 	"github.com/knadh/go-i18n"
+	"github.com/volatiletech/null/v9"
 	"github.com/zerodha/logf"
 )
 
+const (
+	// maxReportDays is the longest period a report can cover.
+	maxReportDays = 366
+)
+
 var (
 	//go:embed queries.sql
 	efs embed.FS
@@ -37,9 +48,23 @@ type Opts struct {
 
 // queries contains prepared SQL queries.
 type queries struct {
-	GetOverviewCharts string `query:"get-overview-charts"`
-	GetOverviewCounts string `query:"get-overview-counts"`
-	GetOverviewSLA    string `query:"get-overview-sla-counts"`
+	GetOverviewCharts          string `query:"get-overview-charts"`
+	GetOverviewCounts          string `query:"get-overview-counts"`
+	GetOverviewSLA             string `query:"get-overview-sla-counts"`
+	GetSLAReport               string `query:"get-sla-report"`
+	GetAgentPerformanceReport  string `query:"get-agent-performance-report"`
+	GetAgentAvailabilityReport string `query:"get-agent-availability-report"`
+	GetCSATReport              string `query:"get-csat-report"`
+	GetRollupState             string `query:"get-rollup-state"`
+	GetFirstConversationAt     string `query:"get-first-conversation-at"`
+	SetRolledUpAt              string `query:"set-rolled-up-at"`
+	SetBackfilledAt            string `query:"set-backfilled-at"`
+	DeleteHourlyRollups        string `query:"delete-hourly-rollups"`
+	InsertHourlyRollups        string `query:"insert-hourly-rollups"`
+	DeleteDailyRollups         string `query:"delete-daily-rollups"`
+	InsertDailyRollups         string `query:"insert-daily-rollups"`
+	DeleteOpenRollups          string `query:"delete-open-rollups"`
+	InsertOpenRollups          string `query:"insert-open-rollups"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -133,3 +158,90 @@ func (m *Manager) GetOverviewChart(days int) (json.RawMessage, error) {
 	}
 	return stats, nil
 }
+
+// ValidOptions reports whether a report of the type can be grouped by the group and bucketed by the interval, agent
+// reports are neither grouped nor bucketed.
+func ValidOptions(reportType, groupBy, interval string) bool {
+	switch reportType {
+	case models.ReportTypeSLA:
+		_, okGroup := slaReportGroups[groupBy]
+		_, okInterval := slaReportIntervals[interval]
+		return okGroup && okInterval
+	case models.ReportTypeCSAT:
+		_, okGroup := csatReportGroups[groupBy]
+		_, okInterval := csatReportIntervals[interval]
+		return okGroup && okInterval
+	case models.ReportTypeAgents:
+		return groupBy == "" && interval == ""
+	}
+	return false
+}
+
+// validPeriod reports whether a report can cover the period from `from` to `to`.
+func validPeriod(from, to time.Time) bool {
+	return from.Before(to) && to.Sub(from) <= maxReportDays*24*time.Hour
+}
+
+// selectReport runs the queries of a report in a read-only transaction, so they all see the same snapshot.
+func (m *Manager) selectReport(name string, fn func(tx *sqlx.Tx) error) error {
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.report}"), nil)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		m.lo.Error("error fetching report", "report", name, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.report}"), nil)
+	}
+
+	if err := tx.Commit(); err != nil {
+		m.lo.Error("error committing db txn", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.report}"), nil)
+	}
+	return nil
+}
+
+// writeCSV writes the header row and the record of each of the n report rows as CSV.
+func writeCSV(w io.Writer, header []string, n int, record func(i int) []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := cw.Write(record(i)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// percentage returns part as a percentage of whole rounded to two decimals, or zero if whole is zero.
+func percentage(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return round2(float64(part) * 100 / float64(whole))
+}
+
+// round2 rounds a float to two decimals.
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// formatFloat formats a float with at most two decimals.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(round2(f), 'f', -1, 64)
+}
+
+// formatBucket formats the time bucket of a report row as a date, rows without a bucket are formatted as empty.
+func formatBucket(bucket null.Time) string {
+	if !bucket.Valid {
+		return ""
+	}
+	return bucket.Time.Format(time.DateOnly)
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/report/models"
+	"github.com/knadh/go-i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodha/logf"
+)
+
+var (
+	testFrom = time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	testTo   = testFrom.AddDate(0, 1, 0)
+)
+
+func newTestManager(t *testing.T, handler dbtest.Handler) (*Manager, *dbtest.DB) {
+	t.Helper()
+	db := dbtest.New(handler)
+	i18n, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	require.NoError(t, err)
+	lo := logf.New(logf.Opts{})
+	m, err := New(Opts{DB: db.DB, Lo: &lo, I18n: i18n})
+	require.NoError(t, err)
+	return m, db
+}
+
+// assertInputError asserts that the error is an input error.
+func assertInputError(t *testing.T, err error) {
+	t.Helper()
+	var eerr envelope.Error
+	require.True(t, errors.As(err, &eerr), "expected an envelope error, got %v", err)
+	assert.Equal(t, envelope.InputError, eerr.ErrorType)
+}
+
+func TestValidOptions(t *testing.T) {
+	assert.True(t, ValidOptions(models.ReportTypeSLA, "policy", "week"))
+	assert.True(t, ValidOptions(models.ReportTypeSLA, "", ""))
+	assert.False(t, ValidOptions(models.ReportTypeSLA, "tag", ""))
+	assert.True(t, ValidOptions(models.ReportTypeCSAT, "tag", "day"))
+	assert.False(t, ValidOptions(models.ReportTypeCSAT, "policy", ""))
+	assert.False(t, ValidOptions(models.ReportTypeCSAT, "", "month"))
+	assert.True(t, ValidOptions(models.ReportTypeAgents, "", ""))
+	assert.False(t, ValidOptions(models.ReportTypeAgents, "team", ""))
+	assert.False(t, ValidOptions("unknown", "", ""))
+}
+
+func TestValidPeriod(t *testing.T) {
+	assert.True(t, validPeriod(testFrom, testTo))
+	assert.True(t, validPeriod(testFrom, testFrom.Add(maxReportDays*24*time.Hour)))
+	assert.False(t, validPeriod(testFrom, testFrom.Add(maxReportDays*24*time.Hour+time.Second)))
+	assert.False(t, validPeriod(testFrom, testFrom))
+	assert.False(t, validPeriod(testTo, testFrom))
+}
+
+func TestPercentage(t *testing.T) {
+	assert.Equal(t, 66.67, percentage(2, 3))
+	assert.Equal(t, 100.0, percentage(4, 4))
+	assert.Zero(t, percentage(0, 5))
+	assert.Zero(t, percentage(3, 0))
+}
+
+func TestWriteCSV(t *testing.T) {
+	rows := []string{"a", "b, c"}
+	var buf bytes.Buffer
+	require.NoError(t, writeCSV(&buf, []string{"n", "value"}, len(rows), func(i int) []string {
+		return []string{strconv.Itoa(i), rows[i]}
+	}))
+	assert.Equal(t, "n,value\n0,a\n1,\"b, c\"\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeCSV(&buf, []string{"n"}, 0, nil))
+	assert.Equal(t, "n\n", buf.String())
+}
@@ -0,0 +1,168 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/volatiletech/null/v9"
+)
+
+const (
+	// rollupInterval is how often the rollups are updated.
+	rollupInterval = time.Minute
+
+	// rollupGrace is how far before the last rollup events are rolled up again, to count events of transactions
+	// that committed after it.
+	rollupGrace = 5 * time.Minute
+
+	// backfillChunk is the period rolled up in one transaction while backfilling.
+	backfillChunk = 24 * time.Hour
+)
+
+// rollupState is when the rollups were last updated and when existing data was backfilled.
+type rollupState struct {
+	RolledUpAt   null.Time `db:"rolled_up_at"`
+	BackfilledAt null.Time `db:"backfilled_at"`
+}
+
+// RunRollups periodically rolls up the conversations created and resolved since the last rollup until the context is
+// cancelled, the overview reports read the rollups.
+func (m *Manager) RunRollups(ctx context.Context) {
+	var state rollupState
+	if err := m.db.GetContext(ctx, &state, m.q.GetRollupState); err != nil {
+		m.lo.Error("error fetching report rollup state", "error", err)
+	} else if !state.BackfilledAt.Valid {
+		m.lo.Warn("report rollups have not been backfilled, reports only include data since the rollups started. Run with `--backfill-rollups` to roll up existing data")
+	}
+
+	ticker := time.NewTicker(rollupInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.rollup(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			m.lo.Error("error updating report rollups", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rollup rolls up the events since the last rollup, and recounts the open conversations. The open counts are a full
+// refresh of the open conversations rather than maintained incrementally.
+func (m *Manager) rollup(ctx context.Context, now time.Time) error {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting db txn: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locking the state row keeps the rollups of several instances and the backfill from running at once.
+	var state rollupState
+	if err := tx.GetContext(ctx, &state, m.q.GetRollupState); err != nil {
+		return fmt.Errorf("fetching rollup state: %w", err)
+	}
+	if err := m.rollupPeriod(ctx, tx, rollupFrom(state.RolledUpAt, now), now); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, m.q.DeleteOpenRollups); err != nil {
+		return fmt.Errorf("deleting open conversation rollups: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, m.q.InsertOpenRollups); err != nil {
+		return fmt.Errorf("rolling up open conversations: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, m.q.SetRolledUpAt, now); err != nil {
+		return fmt.Errorf("updating rollup state: %w", err)
+	}
+	return tx.Commit()
+}
+
+// BackfillRollups rolls up all the events since from, or since the first conversation if from is zero, and marks
+// the rollups as backfilled. The events are rolled up a day at a time so that the server can keep running.
+func (m *Manager) BackfillRollups(ctx context.Context, from time.Time) error {
+	now := time.Now()
+	if from.IsZero() {
+		var first null.Time
+		if err := m.db.GetContext(ctx, &first, m.q.GetFirstConversationAt); err != nil {
+			return fmt.Errorf("fetching first conversation: %w", err)
+		}
+		from = now
+		if first.Valid {
+			from = first.Time
+		}
+	}
+
+	for _, chunk := range backfillChunks(from, now) {
+		if err := m.backfill(ctx, chunk[0], chunk[1]); err != nil {
+			return err
+		}
+		m.lo.Info("backfilled report rollups", "from", chunk[0], "to", chunk[1])
+	}
+
+	if _, err := m.db.ExecContext(ctx, m.q.SetBackfilledAt, now); err != nil {
+		return fmt.Errorf("updating rollup state: %w", err)
+	}
+	return nil
+}
+
+// backfill rolls up the events of a period in a transaction.
+func (m *Manager) backfill(ctx context.Context, from, to time.Time) error {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting db txn: %w", err)
+	}
+	defer tx.Rollback()
+
+	var state rollupState
+	if err := tx.GetContext(ctx, &state, m.q.GetRollupState); err != nil {
+		return fmt.Errorf("fetching rollup state: %w", err)
+	}
+	if err := m.rollupPeriod(ctx, tx, from, to); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// rollupPeriod recomputes the hourly rollups of the hours from the hour of from to to, and the daily rollups of the
+// days they are in.
+func (m *Manager) rollupPeriod(ctx context.Context, tx *sqlx.Tx, from, to time.Time) error {
+	if _, err := tx.ExecContext(ctx, m.q.DeleteHourlyRollups, from, to); err != nil {
+		return fmt.Errorf("deleting hourly rollups: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, m.q.InsertHourlyRollups, from, to); err != nil {
+		return fmt.Errorf("inserting hourly rollups: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, m.q.DeleteDailyRollups, from, to); err != nil {
+		return fmt.Errorf("deleting daily rollups: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, m.q.InsertDailyRollups, from, to); err != nil {
+		return fmt.Errorf("inserting daily rollups: %w", err)
+	}
+	return nil
+}
+
+// rollupFrom returns the time events are rolled up from, the hours since the last rollup are rolled up again as
+// they may have got events since.
+func rollupFrom(rolledUpAt null.Time, now time.Time) time.Time {
+	if !rolledUpAt.Valid || rolledUpAt.Time.After(now) {
+		return now.Add(-rollupGrace)
+	}
+	return rolledUpAt.Time.Add(-rollupGrace)
+}
+
+// backfillChunks splits the period from from to to into chunks of backfillChunk.
+func backfillChunks(from, to time.Time) [][2]time.Time {
+	var chunks [][2]time.Time
+	for start := from; start.Before(to); start = start.Add(backfillChunk) {
+		end := start.Add(backfillChunk)
+		if end.After(to) {
+			end = to
+		}
+		chunks = append(chunks, [2]time.Time{start, end})
+	}
+	return chunks
+}
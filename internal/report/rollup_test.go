@@ -0,0 +1,131 @@
+package report
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/null/v9"
+)
+
+func TestRollupFrom(t *testing.T) {
+	now := time.Date(2025, 6, 11, 10, 30, 0, 0, time.UTC)
+
+	// Rollups that never ran start from now.
+	assert.True(t, now.Add(-rollupGrace).Equal(rollupFrom(null.Time{}, now)))
+
+	// Events since shortly before the last rollup are rolled up again.
+	last := time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC)
+	assert.True(t, last.Add(-rollupGrace).Equal(rollupFrom(null.TimeFrom(last), now)))
+
+	// A last rollup in the future, from clock skew between instances, is ignored.
+	assert.True(t, now.Add(-rollupGrace).Equal(rollupFrom(null.TimeFrom(now.Add(time.Hour)), now)))
+}
+
+func TestBackfillChunks(t *testing.T) {
+	from := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 3, 18, 0, 0, 0, time.UTC)
+
+	chunks := backfillChunks(from, to)
+	assert.Equal(t, [][2]time.Time{
+		{from, from.Add(24 * time.Hour)},
+		{from.Add(24 * time.Hour), from.Add(48 * time.Hour)},
+		{from.Add(48 * time.Hour), to},
+	}, chunks)
+
+	assert.Empty(t, backfillChunks(to, to))
+	assert.Empty(t, backfillChunks(to, from))
+}
+
+// rollupHandler answers the rollup state query with the time of the last rollup, and fails the statements that
+// contain fail if it is set.
+func rollupHandler(rolledUpAt time.Time, fail string) dbtest.Handler {
+	return func(query string, args []driver.Value) (dbtest.Result, error) {
+		if fail != "" && strings.Contains(query, fail) {
+			return dbtest.Result{}, errors.New("failed")
+		}
+		if strings.Contains(query, "FROM report_rollup_state") {
+			return dbtest.Result{Columns: []string{"rolled_up_at", "backfilled_at"}, Rows: [][]any{{rolledUpAt, nil}}}, nil
+		}
+		return dbtest.Result{}, nil
+	}
+}
+
+// queryTexts returns the text of the queries run.
+func queryTexts(db *dbtest.DB) []string {
+	var out []string
+	for _, q := range db.Queries("") {
+		out = append(out, q.Query)
+	}
+	return out
+}
+
+func TestRollup(t *testing.T) {
+	now := time.Date(2025, 6, 11, 10, 30, 0, 0, time.UTC)
+	last := now.Add(-time.Minute)
+
+	m, db := newTestManager(t, rollupHandler(last, ""))
+	require.NoError(t, m.rollup(context.Background(), now))
+
+	// The hours since shortly before the last rollup are recomputed, then the open conversations are recounted.
+	assert.Equal(t, []string{
+		m.q.GetRollupState,
+		m.q.DeleteHourlyRollups,
+		m.q.InsertHourlyRollups,
+		m.q.DeleteDailyRollups,
+		m.q.InsertDailyRollups,
+		m.q.DeleteOpenRollups,
+		m.q.InsertOpenRollups,
+		m.q.SetRolledUpAt,
+	}, queryTexts(db))
+
+	from := last.Add(-rollupGrace)
+	for _, q := range []string{m.q.DeleteHourlyRollups, m.q.InsertHourlyRollups, m.q.DeleteDailyRollups, m.q.InsertDailyRollups} {
+		args := db.Queries(q)[0].Args
+		assert.True(t, from.Equal(args[0].(time.Time)))
+		assert.True(t, now.Equal(args[1].(time.Time)))
+	}
+	assert.True(t, now.Equal(db.Queries(m.q.SetRolledUpAt)[0].Args[0].(time.Time)))
+}
+
+func TestRollupError(t *testing.T) {
+	now := time.Date(2025, 6, 11, 10, 30, 0, 0, time.UTC)
+
+	m, db := newTestManager(t, rollupHandler(now.Add(-time.Minute), "INSERT INTO report_rollups_open"))
+
+	// The rollup time is not moved on if any statement fails.
+	assert.Error(t, m.rollup(context.Background(), now))
+	assert.Empty(t, db.Queries(m.q.SetRolledUpAt))
+}
+
+func TestBackfillRollups(t *testing.T) {
+	from := time.Now().Add(-50 * time.Hour)
+
+	m, db := newTestManager(t, rollupHandler(time.Time{}, ""))
+	require.NoError(t, m.BackfillRollups(context.Background(), from))
+
+	// Each day is rolled up in its own transaction, the open counts are left to the rollup worker.
+	inserts := db.Queries(m.q.InsertHourlyRollups)
+	require.Len(t, inserts, 3)
+	assert.True(t, from.Equal(inserts[0].Args[0].(time.Time)))
+	assert.True(t, from.Add(backfillChunk).Equal(inserts[0].Args[1].(time.Time)))
+	assert.True(t, from.Add(2*backfillChunk).Equal(inserts[2].Args[0].(time.Time)))
+	assert.Len(t, db.Queries(m.q.GetRollupState), 3)
+	assert.Empty(t, db.Queries(m.q.InsertOpenRollups))
+	assert.Len(t, db.Queries(m.q.SetBackfilledAt), 1)
+}
+
+func TestBackfillRollupsError(t *testing.T) {
+	m, db := newTestManager(t, rollupHandler(time.Time{}, "INSERT INTO report_rollups_daily"))
+
+	// The rollups are not marked backfilled if a day fails.
+	assert.Error(t, m.BackfillRollups(context.Background(), time.Now().Add(-50*time.Hour)))
+	assert.Len(t, db.Queries(m.q.InsertDailyRollups), 1)
+	assert.Empty(t, db.Queries(m.q.SetBackfilledAt))
+}
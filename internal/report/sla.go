@@ -0,0 +1,93 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/report/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// slaReportGroups maps the SLA report groups to the SQL expressions of the group ID and name.
+var slaReportGroups = map[string][2]string{
+	"":         {"0", "''"},
+	"team":     {"COALESCE(c.assigned_team_id, 0)", "COALESCE(t.name, '')"},
+	"agent":    {"COALESCE(c.assigned_user_id, 0)", "CONCAT_WS(' ', u.first_name, u.last_name)"},
+	"policy":   {"m.sla_policy_id", "COALESCE(sp.name, '')"},
+	"inbox":    {"c.inbox_id", "COALESCE(i.name, '')"},
+	"priority": {"COALESCE(c.priority_id, 0)", "COALESCE(p.name, '')"},
+}
+
+// slaReportIntervals maps the SLA report intervals to the SQL expressions of the time bucket.
+var slaReportIntervals = map[string]string{
+	"":     "NULL::TIMESTAMPTZ",
+	"day":  "DATE_TRUNC('day', m.created_at)",
+	"week": "DATE_TRUNC('week', m.created_at)",
+}
+
+// slaReportCSVHeader is the header row of the SLA report CSV export.
+var slaReportCSVHeader = []string{
+	"bucket", "group_id", "group_name", "metric", "total", "met_count", "breached_count", "pending_count",
+	"met_percentage", "breached_percentage", "avg_time_sec", "p50_time_sec", "p90_time_sec", "p95_time_sec",
+}
+
+// GetSLAReport returns the met and breached counts and response times of SLA metrics, grouped by the given group and time interval.
+func (m *Manager) GetSLAReport(opts models.SLAReportOptions) ([]models.SLAReportRow, error) {
+	group, ok := slaReportGroups[opts.GroupBy]
+	if !ok {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`group_by`"), nil)
+	}
+	bucket, ok := slaReportIntervals[opts.Interval]
+	if !ok {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`interval`"), nil)
+	}
+	if !validPeriod(opts.From, opts.To) {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`from`, `to`"), nil)
+	}
+
+	var rows = make([]models.SLAReportRow, 0)
+	query := fmt.Sprintf(m.q.GetSLAReport, bucket, group[0], group[1])
+	if err := m.selectReport("sla", func(tx *sqlx.Tx) error {
+		return tx.Select(&rows, query, opts.From, opts.To)
+	}); err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		computeSLARates(&rows[i])
+	}
+	return rows, nil
+}
+
+// computeSLARates sets the pending count and the met and breached percentages of a row from its counts. The percentages
+// are of the metrics that were met or breached, pending metrics are left out.
+func computeSLARates(r *models.SLAReportRow) {
+	r.PendingCount = r.Total - r.MetCount - r.BreachedCount
+	r.MetPercentage = percentage(r.MetCount, r.MetCount+r.BreachedCount)
+	r.BreachedPercentage = percentage(r.BreachedCount, r.MetCount+r.BreachedCount)
+}
+
+// WriteSLAReportCSV writes the SLA report rows as CSV with a header row.
+func WriteSLAReportCSV(w io.Writer, rows []models.SLAReportRow) error {
+	return writeCSV(w, slaReportCSVHeader, len(rows), func(i int) []string {
+		r := rows[i]
+		return []string{
+			formatBucket(r.Bucket),
+			strconv.Itoa(r.GroupID),
+			r.GroupName,
+			r.Metric,
+			strconv.Itoa(r.Total),
+			strconv.Itoa(r.MetCount),
+			strconv.Itoa(r.BreachedCount),
+			strconv.Itoa(r.PendingCount),
+			formatFloat(r.MetPercentage),
+			formatFloat(r.BreachedPercentage),
+			formatFloat(r.AvgTimeSec),
+			formatFloat(r.P50TimeSec),
+			formatFloat(r.P90TimeSec),
+			formatFloat(r.P95TimeSec),
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	"github.com/abhinavxd/libredesk/internal/report/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/null/v9"
+)
+
+func TestGetSLAReportValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		opts models.SLAReportOptions
+	}{
+		{"unknown group", models.SLAReportOptions{GroupBy: "tag", From: testFrom, To: testTo}},
+		{"unknown interval", models.SLAReportOptions{Interval: "month", From: testFrom, To: testTo}},
+		{"empty period", models.SLAReportOptions{From: testFrom, To: testFrom}},
+		{"reversed period", models.SLAReportOptions{From: testTo, To: testFrom}},
+		{"period too long", models.SLAReportOptions{From: testFrom, To: testFrom.AddDate(0, 0, maxReportDays+1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, db := newTestManager(t, nil)
+			_, err := m.GetSLAReport(tt.opts)
+			assertInputError(t, err)
+			assert.Empty(t, db.Queries(""))
+		})
+	}
+}
+
+func TestGetSLAReportGrouping(t *testing.T) {
+	week := time.Date(2025, time.March, 3, 0, 0, 0, 0, time.UTC)
+	m, db := newTestManager(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+		return dbtest.Result{
+			Columns: []string{"bucket", "group_id", "group_name", "metric", "total", "met_count", "breached_count"},
+			Rows: [][]any{
+				{week, 2, "Billing", "first_response", 5, 2, 1},
+				{week, 2, "Billing", "resolution", 3, 0, 0},
+			},
+		}, nil
+	})
+
+	rows, err := m.GetSLAReport(models.SLAReportOptions{GroupBy: "team", Interval: "week", From: testFrom, To: testTo})
+	require.NoError(t, err)
+
+	queries := db.Queries("applied_slas")
+	require.Len(t, queries, 1)
+	assert.Equal(t, []driver.Value{testFrom, testTo}, queries[0].Args)
+	assert.True(t, strings.Contains(queries[0].Query, slaReportIntervals["week"]+" AS bucket"))
+	assert.True(t, strings.Contains(queries[0].Query, slaReportGroups["team"][0]+" AS group_id"))
+	assert.True(t, strings.Contains(queries[0].Query, slaReportGroups["team"][1]+" AS group_name"))
+
+	// Rates are of the metrics met or breached, pending metrics are left out.
+	assert.Equal(t, []models.SLAReportRow{
+		{Bucket: null.TimeFrom(week), GroupID: 2, GroupName: "Billing", Metric: "first_response", Total: 5, MetCount: 2,
+			BreachedCount: 1, PendingCount: 2, MetPercentage: 66.67, BreachedPercentage: 33.33},
+		{Bucket: null.TimeFrom(week), GroupID: 2, GroupName: "Billing", Metric: "resolution", Total: 3, PendingCount: 3},
+	}, rows)
+}
@@ -0,0 +1,82 @@
+package scheduledreport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@yearly",
+	} {
+		_, err := parseCron(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// Wednesday.
+	now := time.Date(2025, 6, 11, 10, 30, 0, 0, loc)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 6, 11, 10, 31, 0, 0, loc)},
+		{"*/15 * * * *", time.Date(2025, 6, 11, 10, 45, 0, 0, loc)},
+		{"30 10 * * *", time.Date(2025, 6, 12, 10, 30, 0, 0, loc)},
+		{"@daily", time.Date(2025, 6, 12, 0, 0, 0, 0, loc)},
+		{"0 9 * * 1", time.Date(2025, 6, 16, 9, 0, 0, 0, loc)},
+		{"0 9 * * 1-5", time.Date(2025, 6, 12, 9, 0, 0, 0, loc)},
+		{"0 8 * * 0", time.Date(2025, 6, 15, 8, 0, 0, 0, loc)},
+		{"0 8 * * 7", time.Date(2025, 6, 15, 8, 0, 0, 0, loc)},
+		{"0 6 1 * *", time.Date(2025, 7, 1, 6, 0, 0, 0, loc)},
+		{"0 0 1 1,7 *", time.Date(2025, 7, 1, 0, 0, 0, 0, loc)},
+		// Restricted days of month and week match either.
+		{"0 0 20 * 5", time.Date(2025, 6, 13, 0, 0, 0, 0, loc)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := parseCron(tt.spec)
+			require.NoError(t, err)
+			got := s.next(now)
+			assert.True(t, tt.want.Equal(got), "got %s, want %s", got, tt.want)
+		})
+	}
+
+	s, err := parseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.next(now).IsZero())
+}
+
+func TestCronNextDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// 02:30 does not exist on 30 March 2025, the clocks go from 02:00 to 03:00.
+	s, err := parseCron("30 2 * * *")
+	require.NoError(t, err)
+	got := s.next(time.Date(2025, 3, 29, 12, 0, 0, 0, loc))
+	assert.True(t, time.Date(2025, 3, 31, 2, 30, 0, 0, loc).Equal(got), "got %s", got)
+
+	s, err = parseCron("0 9 * * *")
+	require.NoError(t, err)
+	got = s.next(time.Date(2025, 3, 29, 12, 0, 0, 0, loc))
+	assert.True(t, time.Date(2025, 3, 30, 9, 0, 0, 0, loc).Equal(got), "got %s", got)
+}
@@ -0,0 +1,75 @@
+// Package models has the models of scheduled report exports.
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+)
+
+// ScheduledReport is a saved report definition emailed to its recipients on a cron schedule.
+type ScheduledReport struct {
+	ID         int       `db:"id" json:"id"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	Name       string    `db:"name" json:"name"`
+	ReportType string    `db:"report_type" json:"report_type"`
+	Filters    Filters   `db:"filters" json:"filters"`
+	// RangeDays is the number of days before the day the report is sent it covers.
+	RangeDays  int            `db:"range_days" json:"range_days"`
+	Cron       string         `db:"cron" json:"cron"`
+	Timezone   string         `db:"timezone" json:"timezone"`
+	Recipients pq.StringArray `db:"recipients" json:"recipients"`
+	Enabled    bool           `db:"enabled" json:"enabled"`
+	LastRunAt  null.Time      `db:"last_run_at" json:"last_run_at"`
+	LastError  string         `db:"last_error" json:"last_error"`
+	NextRunAt  null.Time      `db:"next_run_at" json:"next_run_at"`
+}
+
+// Filters are the options of the report, group by and interval apply to SLA and CSAT reports, team and inbox to agent reports.
+type Filters struct {
+	GroupBy  string `json:"group_by"`
+	Interval string `json:"interval"`
+	TeamID   int    `json:"team_id"`
+	InboxID  int    `json:"inbox_id"`
+}
+
+// Value implements the driver.Valuer interface.
+func (f Filters) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+// Scan implements the sql.Scanner interface.
+func (f *Filters) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	case nil:
+		return nil
+	}
+	return fmt.Errorf("unsupported type for Filters: %T", src)
+}
+
+// SummaryItem is a headline figure of a report shown in the email.
+type SummaryItem struct {
+	Label string
+	Value string
+}
+
+// Email is the data the scheduled report email template is rendered with.
+type Email struct {
+	Name       string
+	ReportType string
+	// PeriodStart and PeriodEnd are the first and last days the report covers.
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Rows        int
+	Filename    string
+	Summary     []SummaryItem
+}
@@ -0,0 +1,51 @@
+-- name: get-all
+SELECT id, created_at, updated_at, "name", report_type, filters, range_days, cron, timezone, recipients, enabled, last_run_at, last_error, next_run_at
+FROM scheduled_reports
+ORDER BY "name";
+
+-- name: get
+SELECT id, created_at, updated_at, "name", report_type, filters, range_days, cron, timezone, recipients, enabled, last_run_at, last_error, next_run_at
+FROM scheduled_reports
+WHERE id = $1;
+
+-- name: get-due
+SELECT id, created_at, updated_at, "name", report_type, filters, range_days, cron, timezone, recipients, enabled, last_run_at, last_error, next_run_at
+FROM scheduled_reports
+WHERE enabled = TRUE AND next_run_at <= $1
+ORDER BY next_run_at;
+
+-- name: insert
+INSERT INTO scheduled_reports ("name", report_type, filters, range_days, cron, timezone, recipients, enabled, next_run_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, created_at, updated_at, "name", report_type, filters, range_days, cron, timezone, recipients, enabled, last_run_at, last_error, next_run_at;
+
+-- name: update
+UPDATE scheduled_reports
+SET "name" = $2,
+    report_type = $3,
+    filters = $4,
+    range_days = $5,
+    cron = $6,
+    timezone = $7,
+    recipients = $8,
+    enabled = $9,
+    next_run_at = $10,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, "name", report_type, filters, range_days, cron, timezone, recipients, enabled, last_run_at, last_error, next_run_at;
+
+-- name: delete
+DELETE FROM scheduled_reports WHERE id = $1;
+
+-- name: set-run
+UPDATE scheduled_reports
+SET last_run_at = $2,
+    last_error = $3,
+    next_run_at = $4
+WHERE id = $1;
+
+-- name: set-last-run
+UPDATE scheduled_reports
+SET last_run_at = $2,
+    last_error = $3
+WHERE id = $1;
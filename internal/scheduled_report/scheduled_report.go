@@ -0,0 +1,430 @@
+// Package scheduledreport emails saved report definitions as CSV attachments to their recipients on a cron schedule.
+package scheduledreport
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/attachment"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/report"
+	rmodels "github.com/abhinavxd/libredesk/internal/report/models"
+	"github.com/abhinavxd/libredesk/internal/scheduled_report/models"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	tmpl "github.com/abhinavxd/libredesk/internal/template"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/go-i18n"
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+const (
+	// checkInterval is how often reports due are looked for.
+	checkInterval = time.Minute
+
+	// maxRangeDays is the longest period a scheduled report can cover.
+	maxRangeDays = 366
+
+	// maxRecipients is the most recipients a scheduled report can have.
+	maxRecipients = 50
+)
+
+// Manager manages scheduled reports and sends the reports due.
+type Manager struct {
+	q             queries
+	lo            *logf.Logger
+	i18n          *i18n.I18n
+	reportStore   reportStore
+	templateStore templateStore
+	notifier      *notifier.Service
+}
+
+type reportStore interface {
+	GetSLAReport(opts rmodels.SLAReportOptions) ([]rmodels.SLAReportRow, error)
+	GetAgentPerformanceReport(opts rmodels.AgentPerformanceOptions) ([]rmodels.AgentPerformanceRow, error)
+	GetCSATReport(opts rmodels.CSATReportOptions) ([]rmodels.CSATReportRow, error)
+}
+
+type templateStore interface {
+	RenderStoredEmailTemplate(name string, data any) (string, string, error)
+}
+
+// Opts contains options for initializing the Manager.
+type Opts struct {
+	DB   *sqlx.DB
+	Lo   *logf.Logger
+	I18n *i18n.I18n
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetAll     *sqlx.Stmt `query:"get-all"`
+	Get        *sqlx.Stmt `query:"get"`
+	GetDue     *sqlx.Stmt `query:"get-due"`
+	Insert     *sqlx.Stmt `query:"insert"`
+	Update     *sqlx.Stmt `query:"update"`
+	Delete     *sqlx.Stmt `query:"delete"`
+	SetRun     *sqlx.Stmt `query:"set-run"`
+	SetLastRun *sqlx.Stmt `query:"set-last-run"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(reportStore reportStore, templateStore templateStore, notifier *notifier.Service, opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:             q,
+		lo:            opts.Lo,
+		i18n:          opts.I18n,
+		reportStore:   reportStore,
+		templateStore: templateStore,
+		notifier:      notifier,
+	}, nil
+}
+
+// GetAll returns all scheduled reports.
+func (m *Manager) GetAll() ([]models.ScheduledReport, error) {
+	var reports = make([]models.ScheduledReport, 0)
+	if err := m.q.GetAll.Select(&reports); err != nil {
+		m.lo.Error("error fetching scheduled reports", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.scheduledReport}"), nil)
+	}
+	return reports, nil
+}
+
+// Get returns a scheduled report by ID.
+func (m *Manager) Get(id int) (models.ScheduledReport, error) {
+	var r models.ScheduledReport
+	if err := m.q.Get.Get(&r, id); err != nil {
+		if err == sql.ErrNoRows {
+			return r, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.scheduledReport}"), nil)
+		}
+		m.lo.Error("error fetching scheduled report", "id", id, "error", err)
+		return r, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.scheduledReport}"), nil)
+	}
+	return r, nil
+}
+
+// Create validates and saves a scheduled report.
+func (m *Manager) Create(r models.ScheduledReport) (models.ScheduledReport, error) {
+	nextRunAt, err := m.validate(&r, time.Now())
+	if err != nil {
+		return r, err
+	}
+	var created models.ScheduledReport
+	if err := m.q.Insert.Get(&created, r.Name, r.ReportType, r.Filters, r.RangeDays, r.Cron, r.Timezone, pq.Array(r.Recipients), r.Enabled, nextRunAt); err != nil {
+		m.lo.Error("error inserting scheduled report", "error", err)
+		return r, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", "{globals.terms.scheduledReport}"), nil)
+	}
+	return created, nil
+}
+
+// Update validates and saves a scheduled report, the next run is rescheduled from now.
+func (m *Manager) Update(id int, r models.ScheduledReport) (models.ScheduledReport, error) {
+	nextRunAt, err := m.validate(&r, time.Now())
+	if err != nil {
+		return r, err
+	}
+	var updated models.ScheduledReport
+	if err := m.q.Update.Get(&updated, id, r.Name, r.ReportType, r.Filters, r.RangeDays, r.Cron, r.Timezone, pq.Array(r.Recipients), r.Enabled, nextRunAt); err != nil {
+		if err == sql.ErrNoRows {
+			return r, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.scheduledReport}"), nil)
+		}
+		m.lo.Error("error updating scheduled report", "id", id, "error", err)
+		return r, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.scheduledReport}"), nil)
+	}
+	return updated, nil
+}
+
+// Delete deletes a scheduled report by ID.
+func (m *Manager) Delete(id int) error {
+	if _, err := m.q.Delete.Exec(id); err != nil {
+		m.lo.Error("error deleting scheduled report", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorDeleting", "name", "{globals.terms.scheduledReport}"), nil)
+	}
+	return nil
+}
+
+// SendNow sends a scheduled report right away without changing its schedule.
+func (m *Manager) SendNow(id int) error {
+	r, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	sendErr := m.send(r, now)
+	if _, err := m.q.SetLastRun.Exec(r.ID, now, errorString(sendErr)); err != nil {
+		m.lo.Error("error updating scheduled report run", "id", id, "error", err)
+	}
+	if sendErr != nil {
+		m.lo.Error("error sending scheduled report", "id", id, "error", sendErr)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorSending", "name", "{globals.terms.scheduledReport}"), nil)
+	}
+	return nil
+}
+
+// Run periodically sends the reports due until the context is cancelled.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.sendDue(ctx, time.Now()); err != nil {
+				m.lo.Error("error sending scheduled reports", "error", err)
+			}
+		}
+	}
+}
+
+// sendDue sends the reports whose next run time has passed and schedules their next run.
+func (m *Manager) sendDue(ctx context.Context, now time.Time) error {
+	var reports []models.ScheduledReport
+	if err := m.q.GetDue.SelectContext(ctx, &reports, now); err != nil {
+		return fmt.Errorf("fetching scheduled reports due: %w", err)
+	}
+	for _, r := range reports {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := m.send(r, now)
+		if err != nil {
+			m.lo.Error("error sending scheduled report", "id", r.ID, "error", err)
+		}
+		// Reports that failed are not retried until their next run, the error is shown with the report. Reports whose
+		// schedule never matches again get no next run.
+		if _, err := m.q.SetRun.Exec(r.ID, now, errorString(err), nextRun(r, now)); err != nil {
+			m.lo.Error("error updating scheduled report run", "id", r.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// errorString returns the message of an error, or an empty string if there is none.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// send generates the report and emails it with the CSV attached to the recipients.
+func (m *Manager) send(r models.ScheduledReport, now time.Time) error {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return fmt.Errorf("loading timezone: %w", err)
+	}
+	from, to := period(r, loc, now)
+
+	var (
+		buf     bytes.Buffer
+		rows    int
+		summary []models.SummaryItem
+	)
+	switch r.ReportType {
+	case rmodels.ReportTypeSLA:
+		data, err := m.reportStore.GetSLAReport(rmodels.SLAReportOptions{GroupBy: r.Filters.GroupBy, Interval: r.Filters.Interval, From: from, To: to})
+		if err != nil {
+			return fmt.Errorf("generating report: %w", err)
+		}
+		if err := report.WriteSLAReportCSV(&buf, data); err != nil {
+			return fmt.Errorf("writing report CSV: %w", err)
+		}
+		rows, summary = len(data), m.slaSummary(data)
+	case rmodels.ReportTypeAgents:
+		data, err := m.reportStore.GetAgentPerformanceReport(rmodels.AgentPerformanceOptions{TeamID: r.Filters.TeamID, InboxID: r.Filters.InboxID, From: from, To: to})
+		if err != nil {
+			return fmt.Errorf("generating report: %w", err)
+		}
+		if err := report.WriteAgentPerformanceReportCSV(&buf, data); err != nil {
+			return fmt.Errorf("writing report CSV: %w", err)
+		}
+		rows, summary = len(data), m.agentSummary(data)
+	case rmodels.ReportTypeCSAT:
+		data, err := m.reportStore.GetCSATReport(rmodels.CSATReportOptions{GroupBy: r.Filters.GroupBy, Interval: r.Filters.Interval, From: from, To: to})
+		if err != nil {
+			return fmt.Errorf("generating report: %w", err)
+		}
+		if err := report.WriteCSATReportCSV(&buf, data); err != nil {
+			return fmt.Errorf("writing report CSV: %w", err)
+		}
+		rows, summary = len(data), m.csatSummary(data)
+	default:
+		return fmt.Errorf("unknown report type %q", r.ReportType)
+	}
+
+	filename := fmt.Sprintf("%s-report-%s-%s.csv", r.ReportType, from.Format(time.DateOnly), to.AddDate(0, 0, -1).Format(time.DateOnly))
+	content, subject, err := m.templateStore.RenderStoredEmailTemplate(tmpl.TmplScheduledReport, models.Email{
+		Name:        r.Name,
+		ReportType:  r.ReportType,
+		PeriodStart: from,
+		PeriodEnd:   to.AddDate(0, 0, -1),
+		Rows:        rows,
+		Filename:    filename,
+		Summary:     summary,
+	})
+	if err != nil {
+		return fmt.Errorf("rendering report email: %w", err)
+	}
+
+	if err := m.notifier.Send(notifier.Message{
+		RecipientEmails: r.Recipients,
+		Subject:         subject,
+		Content:         content,
+		Provider:        notifier.ProviderEmail,
+		Attachments: []attachment.Attachment{{
+			Name:        filename,
+			Size:        buf.Len(),
+			Content:     buf.Bytes(),
+			ContentType: "text/csv",
+			Disposition: attachment.DispositionAttachment,
+			Header:      attachment.MakeHeader("text/csv", "", filename, "base64", attachment.DispositionAttachment),
+		}},
+	}); err != nil {
+		return fmt.Errorf("sending report email: %w", err)
+	}
+	return nil
+}
+
+// validate checks a scheduled report and returns its next run time.
+func (m *Manager) validate(r *models.ScheduledReport, now time.Time) (null.Time, error) {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || len(r.Name) > 140 {
+		return null.Time{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`name`"), nil)
+	}
+	if !report.ValidOptions(r.ReportType, r.Filters.GroupBy, r.Filters.Interval) {
+		return null.Time{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`report_type`, `filters`"), nil)
+	}
+	if r.Filters.TeamID < 0 || r.Filters.InboxID < 0 {
+		return null.Time{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`filters`"), nil)
+	}
+	if r.RangeDays < 1 || r.RangeDays > maxRangeDays {
+		return null.Time{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`range_days`"), nil)
+	}
+	if _, err := parseCron(r.Cron); err != nil {
+		return null.Time{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`cron`"), err.Error())
+	}
+	if _, err := time.LoadLocation(r.Timezone); err != nil || r.Timezone == "" {
+		return null.Time{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`timezone`"), nil)
+	}
+	if len(r.Recipients) == 0 || len(r.Recipients) > maxRecipients {
+		return null.Time{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`recipients`"), nil)
+	}
+	for i, email := range r.Recipients {
+		r.Recipients[i] = strings.TrimSpace(email)
+		if !stringutil.ValidEmail(r.Recipients[i]) {
+			return null.Time{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`recipients`"), nil)
+		}
+	}
+	return nextRun(*r, now), nil
+}
+
+// nextRun returns the first time after now the report is scheduled for, in its timezone.
+func nextRun(r models.ScheduledReport, now time.Time) null.Time {
+	s, err := parseCron(r.Cron)
+	if err != nil {
+		return null.Time{}
+	}
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return null.Time{}
+	}
+	next := s.next(now.In(loc))
+	if next.IsZero() {
+		return null.Time{}
+	}
+	return null.TimeFrom(next)
+}
+
+// period returns the days a report sent now covers, the range days before today in the report's timezone.
+func period(r models.ScheduledReport, loc *time.Location, now time.Time) (time.Time, time.Time) {
+	now = now.In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	return to.AddDate(0, 0, -r.RangeDays), to
+}
+
+// slaSummary returns the met and breached counts of each SLA metric over all groups and buckets.
+func (m *Manager) slaSummary(rows []rmodels.SLAReportRow) []models.SummaryItem {
+	metrics := []struct{ metric, label string }{
+		{"first_response", "report.sla.metric.firstResponse"},
+		{"next_response", "report.sla.metric.nextResponse"},
+		{"resolution", "report.sla.metric.resolution"},
+	}
+	var summary []models.SummaryItem
+	for _, metric := range metrics {
+		var met, breached int
+		for _, r := range rows {
+			if r.Metric == metric.metric {
+				met += r.MetCount
+				breached += r.BreachedCount
+			}
+		}
+		if met+breached == 0 {
+			continue
+		}
+		summary = append(summary, models.SummaryItem{
+			Label: m.i18n.T(metric.label),
+			Value: fmt.Sprintf("%d %s, %d %s (%.1f%%)", met, strings.ToLower(m.i18n.T("report.sla.met")), breached,
+				strings.ToLower(m.i18n.T("report.sla.breached")), float64(met)*100/float64(met+breached)),
+		})
+	}
+	return summary
+}
+
+// agentSummary returns the conversation and message totals of all agents.
+func (m *Manager) agentSummary(rows []rmodels.AgentPerformanceRow) []models.SummaryItem {
+	var assigned, replied, resolved, sent int
+	for _, r := range rows {
+		assigned += r.Assigned
+		replied += r.Replied
+		resolved += r.Resolved
+		sent += r.MessagesSent
+	}
+	return []models.SummaryItem{
+		{Label: m.i18n.T("report.agent.assigned"), Value: fmt.Sprint(assigned)},
+		{Label: m.i18n.T("report.agent.replied"), Value: fmt.Sprint(replied)},
+		{Label: m.i18n.T("report.agent.resolved"), Value: fmt.Sprint(resolved)},
+		{Label: m.i18n.T("report.agent.messagesSent"), Value: fmt.Sprint(sent)},
+	}
+}
+
+// csatSummary returns the surveys sent and answered and the average rating over all groups and buckets. Conversations
+// with several tags are counted once per tag in tag groups, and so in the totals too.
+func (m *Manager) csatSummary(rows []rmodels.CSATReportRow) []models.SummaryItem {
+	var sent, responses int
+	var ratingSum float64
+	for _, r := range rows {
+		sent += r.Sent
+		responses += r.Responses
+		ratingSum += r.AverageRating * float64(r.Responses)
+	}
+	summary := []models.SummaryItem{
+		{Label: m.i18n.T("report.csat.sent"), Value: fmt.Sprint(sent)},
+		{Label: m.i18n.T("report.csat.responses"), Value: fmt.Sprint(responses)},
+	}
+	if sent > 0 {
+		summary = append(summary, models.SummaryItem{Label: m.i18n.T("report.csat.responseRate"), Value: fmt.Sprintf("%.1f%%", float64(responses)*100/float64(sent))})
+	}
+	if responses > 0 {
+		summary = append(summary, models.SummaryItem{Label: m.i18n.T("report.csat.averageRating"), Value: fmt.Sprintf("%.2f", ratingSum/float64(responses))})
+	}
+	return summary
+}
@@ -0,0 +1,33 @@
+package scheduledreport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/scheduled_report/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriod(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// 23:30 UTC on 10 June is 08:30 on 11 June in Tokyo.
+	now := time.Date(2025, 6, 10, 23, 30, 0, 0, time.UTC)
+	from, to := period(models.ScheduledReport{RangeDays: 7}, loc, now)
+	assert.True(t, time.Date(2025, 6, 4, 0, 0, 0, 0, loc).Equal(from), "got %s", from)
+	assert.True(t, time.Date(2025, 6, 11, 0, 0, 0, 0, loc).Equal(to), "got %s", to)
+}
+
+func TestNextRun(t *testing.T) {
+	now := time.Date(2025, 6, 11, 6, 0, 0, 0, time.UTC)
+
+	// Mondays at 09:00 in New York, which is 13:00 UTC in summer.
+	next := nextRun(models.ScheduledReport{Cron: "0 9 * * 1", Timezone: "America/New_York"}, now)
+	require.True(t, next.Valid)
+	assert.True(t, time.Date(2025, 6, 16, 13, 0, 0, 0, time.UTC).Equal(next.Time), "got %s", next.Time)
+
+	assert.False(t, nextRun(models.ScheduledReport{Cron: "0 0 30 2 *", Timezone: "UTC"}, now).Valid)
+	assert.False(t, nextRun(models.ScheduledReport{Cron: "bad", Timezone: "UTC"}, now).Valid)
+}
@@ -1,22 +1,21 @@
 package sla
 
 import (
-	"encoding/json"
 	"fmt"
-	"regexp"
 	"time"
 
+	businesshours "github.com/abhinavxd/libredesk/internal/business_hours"
 	"github.com/abhinavxd/libredesk/internal/business_hours/models"
 )
 
 var (
 	ErrInvalidSLADuration = fmt.Errorf("invalid SLA duration")
 	ErrMaxIterations      = fmt.Errorf("sla: exceeded maximum iterations - check configuration")
-	ErrInvalidTime        = fmt.Errorf("invalid time")
+	ErrInvalidTime        = businesshours.ErrInvalidTime
 )
 
 // CalculateDeadline computes the SLA deadline from a start time and SLA duration in minutes
-// considering the provided holidays, working hours, and time zone.
+// considering the provided holidays, partial-day closures, working hours, and time zone.
 func (m *Manager) CalculateDeadline(start time.Time, slaMinutes int, businessHours models.BusinessHours, timeZone string) (time.Time, error) {
 	if slaMinutes <= 0 {
 		return time.Time{}, ErrInvalidSLADuration
@@ -27,120 +26,97 @@ func (m *Manager) CalculateDeadline(start time.Time, slaMinutes int, businessHou
 		return start.Add(time.Duration(slaMinutes) * time.Minute), nil
 	}
 
-	// Load the specified time zone.
-	loc, err := time.LoadLocation(timeZone)
+	schedule, err := businesshours.NewSchedule(businessHours, timeZone)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid time zone %s: %v", timeZone, err)
+		return time.Time{}, fmt.Errorf("could not parse business hours for SLA deadline calculation: %w", err)
 	}
+	loc := schedule.Location()
 
 	// Convert start time to the specified time zone.
 	currentTime := start.In(loc)
 	remainingMinutes := slaMinutes
 	maxIterations := ((slaMinutes+59)/60)*24 + 1
 
-	// Unmarshal working hours.
-	var workingHours map[string]models.WorkingHours
-	if err := json.Unmarshal(businessHours.Hours, &workingHours); err != nil {
-		return time.Time{}, fmt.Errorf("could not unmarshal working hours for SLA deadline calcuation: %v", err)
-	}
-
-	// Unmarshal holidays.
-	var holidays = []models.Holiday{}
-	if len(businessHours.Holidays) > 0 {
-		if err := json.Unmarshal(businessHours.Holidays, &holidays); err != nil {
-			return time.Time{}, fmt.Errorf("could not unmarshal holidays for SLA deadline calcuation: %v", err)
-		}
-	}
-
-	// Create a map of holidays.
-	holidaysMap := make(map[string]struct{})
-	for _, holiday := range holidays {
-		holidaysMap[holiday.Date] = struct{}{}
-	}
-
-	iterations := 0
-	for remainingMinutes > 0 {
-		iterations++
+	// Start from the previous day as its intervals can cross midnight into the start day.
+	day := startOfDay(currentTime, loc).AddDate(0, 0, -1)
+	for iterations := 0; ; iterations++ {
 		if iterations > maxIterations {
 			return time.Time{}, ErrMaxIterations
 		}
 
-		// Move to next day if current day is a holiday.
-		dateStr := currentTime.Format(time.DateOnly)
-		if _, isHoliday := holidaysMap[dateStr]; isHoliday {
-			currentTime = nextDay(currentTime, loc)
-			continue
-		}
-
-		// Get working hours for the current day.
-		dayOfWeek := currentTime.Weekday().String()
-		workHours, exists := workingHours[dayOfWeek]
-
-		// Not a working day, move to next day.
-		if !exists {
-			currentTime = nextDay(currentTime, loc)
-			continue
-		}
-
-		// Parse open and close times for the current day in the specified time zone.
-		var startOfWork, endOfWork time.Time
-		var err error
-		startOfWork, err = parseTime(currentTime, workHours.Open, loc)
-		if err != nil {
-			return time.Time{}, fmt.Errorf("invalid open time %s for %s: %v", workHours.Open, dayOfWeek, err)
-		}
-		endOfWork, err = parseTime(currentTime, workHours.Close, loc)
-		if err != nil {
-			return time.Time{}, fmt.Errorf("invalid close time %s for %s: %v", workHours.Close, dayOfWeek, err)
-		}
-
-		// Adjust to start of work if current time is before it.
-		if currentTime.Before(startOfWork) {
-			currentTime = startOfWork
+		for _, w := range schedule.Windows(day) {
+			// Skip the parts of the window that have already passed.
+			if !w.End.After(currentTime) {
+				continue
+			}
+			if w.Start.After(currentTime) {
+				currentTime = w.Start
+			}
+
+			// Deduct minutes worked in this window from remaining SLA time.
+			workMinutesLeft := int(w.End.Sub(currentTime).Minutes())
+			if workMinutesLeft >= remainingMinutes {
+				return currentTime.Add(time.Duration(remainingMinutes) * time.Minute), nil
+			}
+			remainingMinutes -= workMinutesLeft
+			currentTime = w.End
 		}
+		day = nextDay(day, loc)
+	}
+}
 
-		// Move to next day if current time is after end of work.
-		if currentTime.After(endOfWork) {
-			currentTime = nextDay(startOfWork, loc)
-			continue
-		}
+// BusinessMinutesBetween returns the number of working minutes between from and to
+// considering the provided holidays, working hours, and time zone.
+func (m *Manager) BusinessMinutesBetween(from, to time.Time, businessHours models.BusinessHours, timeZone string) (int, error) {
+	if !to.After(from) {
+		return 0, nil
+	}
 
-		// Deduct minutes worked today from remaining SLA time.
-		workMinutesLeft := int(endOfWork.Sub(currentTime).Minutes())
-		if workMinutesLeft >= remainingMinutes {
-			return currentTime.Add(time.Duration(remainingMinutes) * time.Minute), nil
-		}
+	// If business is always open, every minute is a working minute.
+	if businessHours.IsAlwaysOpen {
+		return int(to.Sub(from).Minutes()), nil
+	}
 
-		remainingMinutes -= workMinutesLeft
-		if remainingMinutes == 0 {
-			return endOfWork, nil
+	schedule, err := businesshours.NewSchedule(businessHours, timeZone)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse business hours for SLA calculation: %w", err)
+	}
+	loc := schedule.Location()
+
+	from, to = from.In(loc), to.In(loc)
+
+	var (
+		// Start from the previous day as its intervals can cross midnight into from's day.
+		day    = startOfDay(from, loc).AddDate(0, 0, -1)
+		cursor = from
+		work   time.Duration
+	)
+	for ; day.Before(to); day = nextDay(day, loc) {
+		for _, w := range schedule.Windows(day) {
+			// Count only the part of the window that falls between from and to and is not counted yet.
+			start, end := w.Start, w.End
+			if start.Before(cursor) {
+				start = cursor
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				work += end.Sub(start)
+				cursor = end
+			}
 		}
-
-		currentTime = nextDay(startOfWork, loc)
 	}
+	return int(work.Minutes()), nil
+}
 
-	return currentTime, nil
+// startOfDay returns the start of the day of t in the specified time zone.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
 }
 
 // nextDay advances the time to the start of the next day in the specified time zone.
 func nextDay(t time.Time, loc *time.Location) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
 }
-
-// parseTime parses a time string in "HH:MM" format and returns a time.Time object for the given date and location.
-func parseTime(date time.Time, timeStr string, loc *time.Location) (time.Time, error) {
-	// Validate timeStr is in "HH:MM" format.
-	matched, err := regexp.MatchString(`^(?:[01]\d|2[0-3]):[0-5]\d$`, timeStr)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("could not validate time string %s: %v", timeStr, err)
-	}
-	if !matched {
-		return time.Time{}, ErrInvalidTime
-	}
-
-	parsedTime, err := time.ParseInLocation("15:04", timeStr, loc)
-	if err != nil {
-		return time.Time{}, err
-	}
-	return time.Date(date.Year(), date.Month(), date.Day(), parsedTime.Hour(), parsedTime.Minute(), 0, 0, loc), nil
-}
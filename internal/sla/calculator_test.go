@@ -511,3 +511,474 @@ func TestCalculateDeadline(t *testing.T) {
 		})
 	}
 }
+
+func TestBusinessMinutesBetween(t *testing.T) {
+	weekdays := mustMarshalJSON(map[string]models.WorkingHours{
+		"Monday":    {Open: "09:00", Close: "17:00"},
+		"Tuesday":   {Open: "09:00", Close: "17:00"},
+		"Wednesday": {Open: "09:00", Close: "17:00"},
+		"Thursday":  {Open: "09:00", Close: "17:00"},
+		"Friday":    {Open: "09:00", Close: "17:00"},
+	})
+
+	tests := []struct {
+		name          string
+		from          time.Time
+		to            time.Time
+		businessHours models.BusinessHours
+		timeZone      string
+		expected      int
+	}{
+		{
+			name:          "Always Open Business",
+			from:          time.Date(2023, 10, 10, 9, 0, 0, 0, time.UTC),
+			to:            time.Date(2023, 10, 10, 10, 30, 0, 0, time.UTC),
+			businessHours: models.BusinessHours{IsAlwaysOpen: true},
+			timeZone:      "UTC",
+			expected:      90,
+		},
+		{
+			name:          "End Before Start",
+			from:          time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC),
+			to:            time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC),
+			businessHours: models.BusinessHours{Hours: weekdays},
+			timeZone:      "UTC",
+			expected:      0,
+		},
+		{
+			name:          "Within Working Hours",
+			from:          time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC),
+			to:            time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC),
+			businessHours: models.BusinessHours{Hours: weekdays},
+			timeZone:      "UTC",
+			expected:      120,
+		},
+		{
+			name:          "Overnight",
+			from:          time.Date(2023, 10, 10, 16, 0, 0, 0, time.UTC),
+			to:            time.Date(2023, 10, 11, 10, 0, 0, 0, time.UTC),
+			businessHours: models.BusinessHours{Hours: weekdays},
+			timeZone:      "UTC",
+			expected:      120,
+		},
+		{
+			name:          "Outside Working Hours",
+			from:          time.Date(2023, 10, 10, 18, 0, 0, 0, time.UTC),
+			to:            time.Date(2023, 10, 11, 8, 0, 0, 0, time.UTC),
+			businessHours: models.BusinessHours{Hours: weekdays},
+			timeZone:      "UTC",
+			expected:      0,
+		},
+		{
+			name: "Skips Holiday",
+			from: time.Date(2023, 10, 10, 16, 0, 0, 0, time.UTC),
+			to:   time.Date(2023, 10, 12, 10, 0, 0, 0, time.UTC),
+			businessHours: models.BusinessHours{
+				Hours:    weekdays,
+				Holidays: mustMarshalJSON([]models.Holiday{{Date: "2023-10-11"}}),
+			},
+			timeZone: "UTC",
+			expected: 120,
+		},
+		{
+			name:          "Skips Weekend",
+			from:          time.Date(2023, 10, 13, 16, 0, 0, 0, time.UTC), // Friday
+			to:            time.Date(2023, 10, 16, 10, 0, 0, 0, time.UTC), // Monday
+			businessHours: models.BusinessHours{Hours: weekdays},
+			timeZone:      "UTC",
+			expected:      120,
+		},
+		{
+			name:          "Different Time Zone",
+			from:          time.Date(2023, 10, 10, 3, 0, 0, 0, time.UTC), // 08:30 IST
+			to:            time.Date(2023, 10, 10, 5, 30, 0, 0, time.UTC), // 11:00 IST
+			businessHours: models.BusinessHours{Hours: weekdays},
+			timeZone:      "Asia/Kolkata",
+			expected:      120,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{}
+			result, err := m.BusinessMinutesBetween(tt.from, tt.to, tt.businessHours, tt.timeZone)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCalculateDeadlineWithIntervals(t *testing.T) {
+	locUTC := time.UTC
+	locIST, _ := time.LoadLocation("Asia/Kolkata")
+
+	lunchBreak := mustMarshalJSON(map[string]models.WorkingHours{
+		"Tuesday": {Intervals: []models.TimeInterval{
+			{Open: "09:00", Close: "12:00"},
+			{Open: "13:00", Close: "18:00"},
+		}},
+	})
+	splitShift := mustMarshalJSON(map[string]models.WorkingHours{
+		"Tuesday": {Intervals: []models.TimeInterval{
+			{Open: "00:00", Close: "02:00"},
+			{Open: "09:00", Close: "18:00"},
+		}},
+		"Wednesday": {Intervals: []models.TimeInterval{
+			{Open: "00:00", Close: "02:00"},
+			{Open: "09:00", Close: "18:00"},
+		}},
+	})
+	nightShift := mustMarshalJSON(map[string]models.WorkingHours{
+		"Monday": {Intervals: []models.TimeInterval{{Open: "22:00", Close: "06:00"}}},
+	})
+	nightShifts := mustMarshalJSON(map[string]models.WorkingHours{
+		"Monday":  {Intervals: []models.TimeInterval{{Open: "22:00", Close: "06:00"}}},
+		"Tuesday": {Intervals: []models.TimeInterval{{Open: "22:00", Close: "06:00"}}},
+	})
+
+	tests := []struct {
+		name           string
+		startTime      time.Time
+		slaMinutes     int
+		businessHours  models.BusinessHours
+		timeZone       string
+		expectedResult time.Time
+		expectError    error
+	}{
+		{
+			name:           "Deadline Within First Interval",
+			startTime:      time.Date(2023, 10, 10, 10, 0, 0, 0, locUTC), // Tuesday
+			slaMinutes:     60,
+			businessHours:  models.BusinessHours{Hours: lunchBreak},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 10, 11, 0, 0, 0, locUTC),
+		},
+		{
+			name:           "Deadline Ends Exactly At Lunch",
+			startTime:      time.Date(2023, 10, 10, 11, 0, 0, 0, locUTC),
+			slaMinutes:     60,
+			businessHours:  models.BusinessHours{Hours: lunchBreak},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 10, 12, 0, 0, 0, locUTC),
+		},
+		{
+			name:       "Skips Lunch Break",
+			startTime:  time.Date(2023, 10, 10, 11, 30, 0, 0, locUTC),
+			slaMinutes: 60,
+			businessHours: models.BusinessHours{
+				Hours: lunchBreak,
+			},
+			timeZone: "UTC",
+			// 30 minutes before lunch, 30 minutes after.
+			expectedResult: time.Date(2023, 10, 10, 13, 30, 0, 0, locUTC),
+		},
+		{
+			name:           "Start During Lunch Break",
+			startTime:      time.Date(2023, 10, 10, 12, 15, 0, 0, locUTC),
+			slaMinutes:     30,
+			businessHours:  models.BusinessHours{Hours: lunchBreak},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 10, 13, 30, 0, 0, locUTC),
+		},
+		{
+			name:       "Spans Both Intervals And Next Week",
+			startTime:  time.Date(2023, 10, 10, 8, 0, 0, 0, locUTC),
+			slaMinutes: 540,
+			businessHours: models.BusinessHours{
+				Hours: lunchBreak,
+			},
+			timeZone: "UTC",
+			// 8 hours on Tuesday, the last hour on the next Tuesday.
+			expectedResult: time.Date(2023, 10, 17, 10, 0, 0, 0, locUTC),
+		},
+		{
+			name:       "Unsorted Intervals",
+			startTime:  time.Date(2023, 10, 10, 8, 0, 0, 0, locUTC),
+			slaMinutes: 240,
+			businessHours: models.BusinessHours{
+				Hours: mustMarshalJSON(map[string]models.WorkingHours{
+					"Tuesday": {Intervals: []models.TimeInterval{
+						{Open: "13:00", Close: "18:00"},
+						{Open: "09:00", Close: "12:00"},
+					}},
+				}),
+			},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 10, 14, 0, 0, 0, locUTC),
+		},
+		{
+			name:       "Split Shift Continues After Midnight",
+			startTime:  time.Date(2023, 10, 10, 17, 30, 0, 0, locUTC),
+			slaMinutes: 60,
+			businessHours: models.BusinessHours{
+				Hours: splitShift,
+			},
+			timeZone: "UTC",
+			// 30 minutes on Tuesday, 30 minutes in Wednesday's 00:00 to 02:00 interval.
+			expectedResult: time.Date(2023, 10, 11, 0, 30, 0, 0, locUTC),
+		},
+		{
+			name:           "Split Shift Early Interval",
+			startTime:      time.Date(2023, 10, 10, 1, 0, 0, 0, locUTC),
+			slaMinutes:     90,
+			businessHours:  models.BusinessHours{Hours: splitShift},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 10, 9, 30, 0, 0, locUTC),
+		},
+		{
+			name:       "Interval Crossing Midnight",
+			startTime:  time.Date(2023, 10, 9, 23, 0, 0, 0, locUTC), // Monday
+			slaMinutes: 120,
+			businessHours: models.BusinessHours{
+				Hours: nightShift,
+			},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 10, 1, 0, 0, 0, locUTC),
+		},
+		{
+			name:       "Start Before Interval Crossing Midnight",
+			startTime:  time.Date(2023, 10, 9, 12, 0, 0, 0, locUTC),
+			slaMinutes: 480,
+			businessHours: models.BusinessHours{
+				Hours: nightShift,
+			},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 10, 6, 0, 0, 0, locUTC),
+		},
+		{
+			name:       "Start In Previous Day's Interval",
+			startTime:  time.Date(2023, 10, 10, 5, 0, 0, 0, locUTC), // Tuesday, within Monday's night shift.
+			slaMinutes: 120,
+			businessHours: models.BusinessHours{
+				Hours: nightShift,
+			},
+			timeZone: "UTC",
+			// 60 minutes until 06:00, the rest in the next Monday's night shift.
+			expectedResult: time.Date(2023, 10, 16, 23, 0, 0, 0, locUTC),
+		},
+		{
+			name:       "Holiday Skips Interval Opening On It",
+			startTime:  time.Date(2023, 10, 9, 23, 0, 0, 0, locUTC),
+			slaMinutes: 60,
+			businessHours: models.BusinessHours{
+				Hours:    nightShifts,
+				Holidays: mustMarshalJSON([]models.Holiday{{Date: "2023-10-09"}}),
+			},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 10, 23, 0, 0, 0, locUTC),
+		},
+		{
+			name:       "Holiday Does Not Cut Previous Day's Interval",
+			startTime:  time.Date(2023, 10, 9, 23, 0, 0, 0, locUTC),
+			slaMinutes: 180,
+			businessHours: models.BusinessHours{
+				Hours:    nightShift,
+				Holidays: mustMarshalJSON([]models.Holiday{{Date: "2023-10-10"}}),
+			},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 10, 2, 0, 0, 0, locUTC),
+		},
+		{
+			name:       "Interval Closing At Midnight",
+			startTime:  time.Date(2023, 10, 10, 23, 30, 0, 0, locUTC),
+			slaMinutes: 60,
+			businessHours: models.BusinessHours{
+				Hours: mustMarshalJSON(map[string]models.WorkingHours{
+					"Tuesday": {Intervals: []models.TimeInterval{{Open: "18:00", Close: "00:00"}}},
+				}),
+			},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 17, 18, 30, 0, 0, locUTC),
+		},
+		{
+			name:       "Saturday Interval Crossing Into Sunday",
+			startTime:  time.Date(2023, 10, 14, 23, 0, 0, 0, locUTC), // Saturday
+			slaMinutes: 240,
+			businessHours: models.BusinessHours{
+				Hours: mustMarshalJSON(map[string]models.WorkingHours{
+					"Saturday": {Intervals: []models.TimeInterval{{Open: "20:00", Close: "02:00"}}},
+					"Sunday":   {Intervals: []models.TimeInterval{{Open: "08:00", Close: "12:00"}}},
+				}),
+			},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 15, 9, 0, 0, 0, locUTC),
+		},
+		{
+			name:       "Legacy And Interval Formats",
+			startTime:  time.Date(2023, 10, 9, 16, 0, 0, 0, locUTC), // Monday
+			slaMinutes: 120,
+			businessHours: models.BusinessHours{
+				Hours: mustMarshalJSON(map[string]models.WorkingHours{
+					"Monday":  {Open: "09:00", Close: "17:00"},
+					"Tuesday": {Intervals: []models.TimeInterval{{Open: "00:00", Close: "00:30"}, {Open: "10:00", Close: "12:00"}}},
+				}),
+			},
+			timeZone:       "UTC",
+			expectedResult: time.Date(2023, 10, 10, 10, 30, 0, 0, locUTC),
+		},
+		{
+			name:       "Interval Crossing Midnight In Time Zone",
+			startTime:  time.Date(2023, 10, 9, 18, 0, 0, 0, locUTC), // Monday 23:30 IST
+			slaMinutes: 60,
+			businessHours: models.BusinessHours{
+				Hours: mustMarshalJSON(map[string]models.WorkingHours{
+					"Monday": {Intervals: []models.TimeInterval{{Open: "22:00", Close: "02:00"}}},
+				}),
+			},
+			timeZone:       "Asia/Kolkata",
+			expectedResult: time.Date(2023, 10, 10, 0, 30, 0, 0, locIST),
+		},
+		{
+			name:       "Invalid Interval Time",
+			startTime:  time.Date(2023, 10, 10, 9, 0, 0, 0, locUTC),
+			slaMinutes: 60,
+			businessHours: models.BusinessHours{
+				Hours: mustMarshalJSON(map[string]models.WorkingHours{
+					"Tuesday": {Intervals: []models.TimeInterval{{Open: "09:00", Close: "12:00"}, {Open: "13:00", Close: "24:00"}}},
+				}),
+			},
+			timeZone:    "UTC",
+			expectError: ErrInvalidTime,
+		},
+		{
+			name:       "Only Empty Intervals",
+			startTime:  time.Date(2023, 10, 10, 9, 0, 0, 0, locUTC),
+			slaMinutes: 60,
+			businessHours: models.BusinessHours{
+				Hours: mustMarshalJSON(map[string]models.WorkingHours{
+					"Tuesday": {Intervals: []models.TimeInterval{{Open: "09:00", Close: "09:00"}}},
+				}),
+			},
+			timeZone:    "UTC",
+			expectError: ErrMaxIterations,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{}
+			result, err := m.CalculateDeadline(tt.startTime, tt.slaMinutes, tt.businessHours, tt.timeZone)
+
+			if tt.expectError != nil {
+				assert.ErrorContains(t, err, tt.expectError.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestBusinessMinutesBetweenWithIntervals(t *testing.T) {
+	hours := mustMarshalJSON(map[string]models.WorkingHours{
+		"Monday": {Intervals: []models.TimeInterval{{Open: "22:00", Close: "06:00"}}},
+		"Tuesday": {Intervals: []models.TimeInterval{
+			{Open: "09:00", Close: "12:00"},
+			{Open: "13:00", Close: "18:00"},
+		}},
+		"Wednesday": {Intervals: []models.TimeInterval{
+			{Open: "00:00", Close: "02:00"},
+			{Open: "09:00", Close: "18:00"},
+		}},
+	})
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		expected int
+	}{
+		{
+			name:     "Across Lunch Break",
+			from:     time.Date(2023, 10, 10, 11, 0, 0, 0, time.UTC),
+			to:       time.Date(2023, 10, 10, 14, 0, 0, 0, time.UTC),
+			expected: 120,
+		},
+		{
+			name:     "Within Lunch Break",
+			from:     time.Date(2023, 10, 10, 12, 10, 0, 0, time.UTC),
+			to:       time.Date(2023, 10, 10, 12, 50, 0, 0, time.UTC),
+			expected: 0,
+		},
+		{
+			name:     "Interval Crossing Midnight",
+			from:     time.Date(2023, 10, 9, 21, 0, 0, 0, time.UTC),
+			to:       time.Date(2023, 10, 10, 7, 0, 0, 0, time.UTC),
+			expected: 480,
+		},
+		{
+			name:     "Within Previous Day's Interval",
+			from:     time.Date(2023, 10, 10, 1, 0, 0, 0, time.UTC),
+			to:       time.Date(2023, 10, 10, 3, 0, 0, 0, time.UTC),
+			expected: 120,
+		},
+		{
+			name:     "Split Shift After Midnight",
+			from:     time.Date(2023, 10, 10, 17, 0, 0, 0, time.UTC),
+			to:       time.Date(2023, 10, 11, 1, 0, 0, 0, time.UTC),
+			expected: 120,
+		},
+		{
+			name:     "Whole Week",
+			from:     time.Date(2023, 10, 8, 0, 0, 0, 0, time.UTC),
+			to:       time.Date(2023, 10, 15, 0, 0, 0, 0, time.UTC),
+			expected: 480 + 480 + 660,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{}
+			result, err := m.BusinessMinutesBetween(tt.from, tt.to, models.BusinessHours{Hours: hours}, "UTC")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCalculateDeadlineWithRecurringHolidaysAndClosures(t *testing.T) {
+	businessHours := models.BusinessHours{
+		Hours: mustMarshalJSON(map[string]models.WorkingHours{
+			"Monday":    {Open: "09:00", Close: "17:00"},
+			"Tuesday":   {Open: "09:00", Close: "17:00"},
+			"Wednesday": {Open: "09:00", Close: "17:00"},
+			"Thursday":  {Open: "09:00", Close: "17:00"},
+			"Friday":    {Open: "09:00", Close: "17:00"},
+		}),
+		Holidays: mustMarshalJSON([]models.Holiday{
+			{Name: "Christmas", Date: "2000-12-25", Recurring: true},
+			{Name: "Offsite", Date: "2024-12-24", StartTime: "12:00", EndTime: "16:00"},
+		}),
+	}
+
+	tests := []struct {
+		name       string
+		startTime  time.Time
+		slaMinutes int
+		expected   time.Time
+	}{
+		{
+			// 3 hours on Tuesday before the closure, 1 hour after it, then Christmas is skipped.
+			name:       "Across Closure And Recurring Holiday",
+			startTime:  time.Date(2024, 12, 24, 9, 0, 0, 0, time.UTC),
+			slaMinutes: 5 * 60,
+			expected:   time.Date(2024, 12, 26, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Recurring Holiday In Another Year",
+			startTime:  time.Date(2030, 12, 24, 16, 0, 0, 0, time.UTC),
+			slaMinutes: 120,
+			expected:   time.Date(2030, 12, 26, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{}
+			result, err := m.CalculateDeadline(tt.startTime, tt.slaMinutes, businessHours, "UTC")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
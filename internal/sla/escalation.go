@@ -0,0 +1,189 @@
+package sla
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	amodels "github.com/abhinavxd/libredesk/internal/automation/models"
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/sla/models"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/volatiletech/null/v9"
+)
+
+// conversationStore runs escalation actions on conversations.
+type conversationStore interface {
+	GetConversation(id int, uuid string) (cmodels.Conversation, error)
+	ApplyAction(action amodels.RuleAction, conv cmodels.Conversation, user umodels.User) error
+	RecordSLAEscalation(conversationUUID, escalation string, actor umodels.User) error
+}
+
+// SetConversationStore sets the conversation store used to run SLA escalations.
+func (m *Manager) SetConversationStore(store conversationStore) {
+	m.conversationStore = store
+}
+
+// SetNotificationStore sets the store used to notify agents of SLA warnings and breaches.
+func (m *Manager) SetNotificationStore(store notificationStore) {
+	m.notificationStore = store
+}
+
+// createEscalationSchedule schedules the escalations of an SLA policy in database for the applied SLA to be run later.
+func (m *Manager) createEscalationSchedule(escalations models.SLAEscalations, appliedSLAID int, slaEventID null.Int, deadlines Deadlines, breaches Breaches) {
+	for _, esc := range escalations {
+		if len(esc.Actions) == 0 {
+			continue
+		}
+
+		var delay time.Duration
+		if esc.TimeDelay != "" {
+			d, err := time.ParseDuration(esc.TimeDelay)
+			if err != nil {
+				m.lo.Error("error parsing sla escalation delay", "error", err)
+				continue
+			}
+			delay = d
+		}
+
+		for _, st := range scheduleTimes(esc.Type, esc.Metric, delay, deadlines, breaches) {
+			// Make sure the run time is not too far in the past.
+			if st.at.Before(time.Now().Add(-5 * time.Minute)) {
+				m.lo.Warn("skipping scheduling escalation as it is in the past", "run_at", st.at, "applied_sla_id", appliedSLAID, "metric", st.metric, "type", esc.Type)
+				continue
+			}
+			m.lo.Info("scheduling SLA escalation", "run_at", st.at, "applied_sla_id", appliedSLAID, "metric", st.metric, "type", esc.Type)
+			if _, err := m.q.InsertScheduledSLAEscalation.Exec(appliedSLAID, slaEventID, st.metric, esc.Type, esc.Actions, st.at); err != nil {
+				m.lo.Error("error inserting scheduled SLA escalation", "error", err)
+			}
+		}
+	}
+}
+
+// runSLAEscalations periodically runs the due SLA escalations.
+func (m *Manager) runSLAEscalations(ctx context.Context, interval time.Duration) {
+	defer m.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.processSLAEscalations(ctx); err != nil {
+				m.lo.Error("error processing SLA escalations", "error", err)
+			}
+		}
+	}
+}
+
+// processSLAEscalations runs all scheduled SLA escalations that are due.
+func (m *Manager) processSLAEscalations(ctx context.Context) error {
+	var escalations []models.ScheduledSLAEscalation
+	if err := m.q.GetScheduledSLAEscalations.SelectContext(ctx, &escalations); err != nil {
+		return fmt.Errorf("fetching scheduled SLA escalations: %w", err)
+	}
+	if len(escalations) == 0 {
+		return nil
+	}
+	m.lo.Info("found scheduled SLA escalations", "count", len(escalations))
+	for _, escalation := range escalations {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := m.runEscalation(escalation); err != nil {
+			m.lo.Error("error running SLA escalation", "scheduled_escalation_id", escalation.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// runEscalation applies the actions of a scheduled escalation to the conversation as the system user and records it as a conversation activity.
+// The escalation is marked processed before the actions run so that it is never run twice.
+func (m *Manager) runEscalation(escalation models.ScheduledSLAEscalation) error {
+	if m.conversationStore == nil {
+		return fmt.Errorf("conversation store not set")
+	}
+
+	var id int
+	if err := m.q.UpdateSLAEscalationProcessed.Get(&id, escalation.ID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("marking SLA escalation as processed: %w", err)
+	}
+
+	var appliedSLA models.AppliedSLA
+	if err := m.q.GetAppliedSLA.Get(&appliedSLA, escalation.AppliedSLAID); err != nil {
+		return fmt.Errorf("fetching applied SLA for escalation: %w", err)
+	}
+
+	// Resolved or closed conversations are not escalated.
+	if appliedSLA.ConversationStatus == cmodels.StatusResolved || appliedSLA.ConversationStatus == cmodels.StatusClosed {
+		m.lo.Info("skipping SLA escalation as the conversation is resolved/closed", "status", appliedSLA.ConversationStatus, "scheduled_escalation_id", escalation.ID)
+		return nil
+	}
+
+	// Warning escalations are rescheduled for the extended deadlines once the pause ends.
+	if appliedSLA.PausedAt.Valid && escalation.EscalationType == NotificationTypeWarning {
+		m.lo.Info("skipping SLA warning escalation as the SLA is paused", "applied_sla_id", appliedSLA.ID, "scheduled_escalation_id", escalation.ID)
+		return nil
+	}
+
+	// Skip if the metric is already met.
+	var met bool
+	switch escalation.Metric {
+	case MetricFirstResponse:
+		met = appliedSLA.FirstResponseMetAt.Valid
+	case MetricResolution:
+		met = appliedSLA.ResolutionMetAt.Valid
+	case MetricNextResponse:
+		var slaEvent models.SLAEvent
+		if err := m.q.GetSLAEvent.Get(&slaEvent, escalation.SlaEventID.Int); err != nil {
+			return fmt.Errorf("fetching SLA event for escalation: %w", err)
+		}
+		met = slaEvent.MetAt.Valid
+	default:
+		return fmt.Errorf("unknown metric type: %s", escalation.Metric)
+	}
+	if met {
+		m.lo.Info("skipping SLA escalation as the metric is already met", "applied_sla_id", appliedSLA.ID, "metric", escalation.Metric, "scheduled_escalation_id", escalation.ID)
+		return nil
+	}
+
+	conversation, err := m.conversationStore.GetConversation(0, appliedSLA.ConversationUUID)
+	if err != nil {
+		return fmt.Errorf("fetching conversation for escalation: %w", err)
+	}
+	systemUser, err := m.userStore.GetSystemUser()
+	if err != nil {
+		return fmt.Errorf("fetching system user: %w", err)
+	}
+
+	var applied int
+	for _, action := range escalation.Actions {
+		if err := m.conversationStore.ApplyAction(action, conversation, systemUser); err != nil {
+			m.lo.Error("error applying SLA escalation action", "scheduled_escalation_id", escalation.ID, "action", action.Type, "conversation_uuid", conversation.UUID, "error", err)
+			continue
+		}
+		applied++
+	}
+	if applied == 0 {
+		return fmt.Errorf("no SLA escalation action applied for conversation %s", conversation.UUID)
+	}
+
+	m.lo.Info("SLA escalated", "applied_sla_id", appliedSLA.ID, "conversation_uuid", conversation.UUID, "metric", escalation.Metric, "type", escalation.EscalationType)
+	return m.conversationStore.RecordSLAEscalation(conversation.UUID, escalationLabel(escalation.Metric, escalation.EscalationType), systemUser)
+}
+
+// escalationLabel returns a human readable label for an escalation e.g. `first response breach`.
+func escalationLabel(metric, escalationType string) string {
+	label, ok := metricLabels[metric]
+	if !ok {
+		label = metric
+	}
+	return strings.ToLower(label) + " " + escalationType
+}
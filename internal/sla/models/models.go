@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	amodels "github.com/abhinavxd/libredesk/internal/automation/models"
 	"github.com/lib/pq"
 	"github.com/volatiletech/null/v9"
 )
@@ -21,6 +22,45 @@ type SLAPolicy struct {
 	NextResponseTime  null.String      `db:"next_response_time" json:"next_response_time"`
 	ResolutionTime    null.String      `db:"resolution_time" json:"resolution_time"`
 	Notifications     SlaNotifications `db:"notifications" json:"notifications"`
+	PauseStatuses     pq.StringArray   `db:"pause_statuses" json:"pause_statuses"`
+	Conditions        SLAConditions    `db:"conditions" json:"conditions"`
+	Escalations       SLAEscalations   `db:"escalations" json:"escalations"`
+}
+
+// SLAConditions are the automation rule groups a conversation must match for the SLA policy to be applied automatically.
+type SLAConditions struct {
+	GroupOperator string              `json:"group_operator"`
+	Groups        []amodels.RuleGroup `json:"groups"`
+}
+
+// IsEmpty returns true if there are no rules to match, such policies are never applied automatically.
+func (sc SLAConditions) IsEmpty() bool {
+	for _, g := range sc.Groups {
+		if len(g.Rules) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Value implements the driver.Valuer interface.
+func (sc SLAConditions) Value() (driver.Value, error) {
+	return json.Marshal(sc)
+}
+
+// Scan implements the sql.Scanner interface.
+func (sc *SLAConditions) Scan(src any) error {
+	var data []byte
+
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unsupported type: %T", src)
+	}
+	return json.Unmarshal(data, sc)
 }
 
 type SlaNotifications []SlaNotification
@@ -54,6 +94,72 @@ type SlaNotification struct {
 	Metric        string   `db:"metric" json:"metric"`
 }
 
+type SLAEscalations []SLAEscalation
+
+// Value implements the driver.Valuer interface.
+func (se SLAEscalations) Value() (driver.Value, error) {
+	return json.Marshal(se)
+}
+
+// Scan implements the sql.Scanner interface.
+func (se *SLAEscalations) Scan(src any) error {
+	var data []byte
+
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unsupported type: %T", src)
+	}
+	return json.Unmarshal(data, se)
+}
+
+// SLAEscalation represents the conversation actions run on an SLA warning or breach.
+type SLAEscalation struct {
+	Type      string               `json:"type"`
+	Metric    string               `json:"metric"`
+	TimeDelay string               `json:"time_delay"`
+	Actions   SLAEscalationActions `json:"actions"`
+}
+
+type SLAEscalationActions []amodels.RuleAction
+
+// Value implements the driver.Valuer interface.
+func (sa SLAEscalationActions) Value() (driver.Value, error) {
+	return json.Marshal(sa)
+}
+
+// Scan implements the sql.Scanner interface.
+func (sa *SLAEscalationActions) Scan(src any) error {
+	var data []byte
+
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unsupported type: %T", src)
+	}
+	return json.Unmarshal(data, sa)
+}
+
+// ScheduledSLAEscalation represents a scheduled SLA escalation, actions are copied from the policy when the escalation is scheduled.
+type ScheduledSLAEscalation struct {
+	ID             int                  `db:"id" json:"id"`
+	CreatedAt      time.Time            `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time            `db:"updated_at" json:"updated_at"`
+	AppliedSLAID   int                  `db:"applied_sla_id" json:"applied_sla_id"`
+	SlaEventID     null.Int             `db:"sla_event_id" json:"sla_event_id"`
+	Metric         string               `db:"metric" json:"metric"`
+	EscalationType string               `db:"escalation_type" json:"escalation_type"`
+	Actions        SLAEscalationActions `db:"actions" json:"actions"`
+	RunAt          time.Time            `db:"run_at" json:"run_at"`
+	ProcessedAt    null.Time            `db:"processed_at" json:"processed_at,omitempty"`
+}
+
 // ScheduledSLANotification represents a scheduled SLA notification
 type ScheduledSLANotification struct {
 	ID               int            `db:"id" json:"id"`
@@ -90,6 +196,13 @@ type AppliedSLA struct {
 	ConversationSubject         string    `db:"conversation_subject"`
 	ConversationAssignedUserID  null.Int  `db:"conversation_assigned_user_id"`
 	ConversationStatus          string    `db:"conversation_status"`
+	ConversationAssignedTeamID  null.Int  `db:"conversation_assigned_team_id"`
+
+	// SLA policy fields.
+	PauseStatuses pq.StringArray `db:"pause_statuses"`
+
+	// PausedAt is the start of the open pause, if the SLA is paused.
+	PausedAt null.Time `db:"paused_at"`
 }
 
 type SLAEvent struct {
@@ -103,3 +216,14 @@ type SLAEvent struct {
 	MetAt        null.Time `db:"met_at"`
 	BreachedAt   null.Time `db:"breached_at"`
 }
+
+// SLAPause represents a period during which an applied SLA was paused.
+type SLAPause struct {
+	ID            int       `db:"id" json:"id"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	AppliedSLAID  int       `db:"applied_sla_id" json:"applied_sla_id"`
+	Status        string    `db:"status" json:"status"`
+	StartedAt     time.Time `db:"started_at" json:"started_at"`
+	EndedAt       null.Time `db:"ended_at" json:"ended_at"`
+	PausedMinutes null.Int  `db:"paused_minutes" json:"paused_minutes"`
+}
@@ -0,0 +1,188 @@
+package sla
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/sla/models"
+	"github.com/volatiletech/null/v9"
+)
+
+// SyncPause pauses or resumes the SLA clock of an applied SLA based on the current status of the conversation.
+func (m *Manager) SyncPause(appliedSLAID int) error {
+	var appliedSLA models.AppliedSLA
+	if err := m.q.GetAppliedSLA.Get(&appliedSLA, appliedSLAID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		m.lo.Error("error fetching applied SLA", "applied_sla_id", appliedSLAID, "error", err)
+		return fmt.Errorf("fetching applied SLA: %w", err)
+	}
+	if _, err := m.syncPause(&appliedSLA); err != nil {
+		m.lo.Error("error syncing SLA pause", "applied_sla_id", appliedSLAID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// syncPause starts a pause if the conversation is in one of the policy's pause statuses and ends the open pause once it is not.
+// Deadlines of the applied SLA are extended when a pause ends, returns true if the SLA is paused.
+func (m *Manager) syncPause(appliedSLA *models.AppliedSLA) (bool, error) {
+	isPauseStatus := appliedSLA.ConversationStatus != "" && slices.Contains(appliedSLA.PauseStatuses, appliedSLA.ConversationStatus)
+	switch {
+	case isPauseStatus && appliedSLA.PausedAt.Valid:
+		return true, nil
+	case isPauseStatus:
+		if _, err := m.q.InsertSLAPause.Exec(appliedSLA.ID, appliedSLA.ConversationStatus); err != nil {
+			return false, fmt.Errorf("inserting SLA pause: %w", err)
+		}
+		m.lo.Info("SLA paused", "applied_sla_id", appliedSLA.ID, "conversation_id", appliedSLA.ConversationID, "status", appliedSLA.ConversationStatus)
+
+		// Clear the conversation's next SLA deadline while the clock is paused.
+		if _, err := m.q.UpdateConversationNextSLADeadline.Exec(appliedSLA.ConversationID, nil); err != nil {
+			return true, fmt.Errorf("setting conversation next SLA deadline: %w", err)
+		}
+		return true, nil
+	case appliedSLA.PausedAt.Valid:
+		return false, m.resumeSLA(appliedSLA)
+	}
+	return false, nil
+}
+
+// resumeSLA ends the open pause of an applied SLA and extends its pending deadlines by the paused minutes within business hours.
+// Unsent warning notifications and escalations are rescheduled for the new deadlines.
+func (m *Manager) resumeSLA(appliedSLA *models.AppliedSLA) error {
+	var pause models.SLAPause
+	if err := m.q.GetOpenSLAPause.Get(&pause, appliedSLA.ID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("fetching open SLA pause: %w", err)
+	}
+
+	businessHrs, timezone, err := m.getBusinessHoursAndTimezone(appliedSLA.ConversationAssignedTeamID.Int)
+	if err != nil {
+		return err
+	}
+
+	endedAt := time.Now()
+	pausedMinutes, err := m.BusinessMinutesBetween(pause.StartedAt, endedAt, businessHrs, timezone)
+	if err != nil {
+		return fmt.Errorf("calculating paused minutes: %w", err)
+	}
+
+	// Helper function to extend a deadline by the business minutes paused since `since`.
+	extendDeadline := func(deadline time.Time, since time.Time) (time.Time, error) {
+		minutes := pausedMinutes
+		if since.After(pause.StartedAt) {
+			var err error
+			if minutes, err = m.BusinessMinutesBetween(since, endedAt, businessHrs, timezone); err != nil {
+				return deadline, err
+			}
+		}
+		if minutes <= 0 {
+			return deadline, nil
+		}
+		return m.CalculateDeadline(deadline, minutes, businessHrs, timezone)
+	}
+
+	// Only deadlines that are neither met nor breached are extended.
+	var deadlines Deadlines
+	if appliedSLA.FirstResponseDeadlineAt.Valid && !appliedSLA.FirstResponseMetAt.Valid && !appliedSLA.FirstResponseBreachedAt.Valid {
+		d, err := extendDeadline(appliedSLA.FirstResponseDeadlineAt.Time, pause.StartedAt)
+		if err != nil {
+			return err
+		}
+		deadlines.FirstResponse = null.TimeFrom(d)
+	}
+	if appliedSLA.ResolutionDeadlineAt.Valid && !appliedSLA.ResolutionMetAt.Valid && !appliedSLA.ResolutionBreachedAt.Valid {
+		d, err := extendDeadline(appliedSLA.ResolutionDeadlineAt.Time, pause.StartedAt)
+		if err != nil {
+			return err
+		}
+		deadlines.Resolution = null.TimeFrom(d)
+	}
+
+	// Next response events created during the pause are only extended from the time they were created.
+	var events []models.SLAEvent
+	if err := m.q.GetUnmetSLAEvents.Select(&events, appliedSLA.ID); err != nil {
+		return fmt.Errorf("fetching unmet SLA events: %w", err)
+	}
+	for i := range events {
+		if events[i].DeadlineAt, err = extendDeadline(events[i].DeadlineAt, events[i].CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	tx, err := m.opts.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Stmtx(m.q.EndSLAPause).Exec(pause.ID, endedAt, pausedMinutes)
+	if err != nil {
+		return fmt.Errorf("ending SLA pause: %w", err)
+	}
+	// Pause already ended elsewhere.
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	firstResponse, resolution := appliedSLA.FirstResponseDeadlineAt, appliedSLA.ResolutionDeadlineAt
+	if deadlines.FirstResponse.Valid {
+		firstResponse = deadlines.FirstResponse
+	}
+	if deadlines.Resolution.Valid {
+		resolution = deadlines.Resolution
+	}
+	if _, err := tx.Stmtx(m.q.UpdateAppliedSLADeadlines).Exec(appliedSLA.ID, firstResponse, resolution); err != nil {
+		return fmt.Errorf("updating applied SLA deadlines: %w", err)
+	}
+	for _, event := range events {
+		if _, err := tx.Stmtx(m.q.UpdateSLAEventDeadline).Exec(event.ID, event.DeadlineAt); err != nil {
+			return fmt.Errorf("updating SLA event deadline: %w", err)
+		}
+	}
+	if _, err := tx.Stmtx(m.q.DeleteUnsentSLAWarnings).Exec(appliedSLA.ID); err != nil {
+		return fmt.Errorf("deleting unsent SLA warnings: %w", err)
+	}
+	if _, err := tx.Stmtx(m.q.DeleteUnsentSLAWarningEscalations).Exec(appliedSLA.ID); err != nil {
+		return fmt.Errorf("deleting unsent SLA warning escalations: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	m.lo.Info("SLA resumed", "applied_sla_id", appliedSLA.ID, "conversation_id", appliedSLA.ConversationID, "paused_minutes", pausedMinutes)
+
+	appliedSLA.FirstResponseDeadlineAt = firstResponse
+	appliedSLA.ResolutionDeadlineAt = resolution
+	appliedSLA.PausedAt = null.Time{}
+
+	// Reschedule the warnings and warning escalations for the extended deadlines.
+	sla, err := m.Get(appliedSLA.SLAPolicyID)
+	if err != nil {
+		return err
+	}
+	m.createNotificationSchedule(sla.Notifications, appliedSLA.ID, null.Int{}, deadlines, Breaches{})
+	m.createEscalationSchedule(sla.Escalations, appliedSLA.ID, null.Int{}, deadlines, Breaches{})
+
+	var nextResponse null.Time
+	for _, event := range events {
+		eventDeadlines := Deadlines{NextResponse: null.TimeFrom(event.DeadlineAt)}
+		m.createNotificationSchedule(sla.Notifications, appliedSLA.ID, null.IntFrom(event.ID), eventDeadlines, Breaches{})
+		m.createEscalationSchedule(sla.Escalations, appliedSLA.ID, null.IntFrom(event.ID), eventDeadlines, Breaches{})
+		if !nextResponse.Valid || event.DeadlineAt.Before(nextResponse.Time) {
+			nextResponse = null.TimeFrom(event.DeadlineAt)
+		}
+	}
+
+	// Update the conversation next SLA deadline.
+	if _, err := m.q.UpdateConversationNextSLADeadline.Exec(appliedSLA.ConversationID, nextResponse); err != nil {
+		return fmt.Errorf("setting conversation next SLA deadline: %w", err)
+	}
+	return nil
+}
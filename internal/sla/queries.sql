@@ -1,5 +1,12 @@
 -- name: get-sla-policy
-SELECT id, name, description, first_response_time, resolution_time, next_response_time, notifications, created_at, updated_at FROM sla_policies WHERE id = $1;
+SELECT id, name, description, first_response_time, resolution_time, next_response_time, notifications, pause_statuses, conditions, escalations, created_at, updated_at FROM sla_policies WHERE id = $1;
+
+-- name: get-sla-policies-with-conditions
+-- Policies are matched in the order they were created.
+SELECT id, name, description, first_response_time, resolution_time, next_response_time, notifications, pause_statuses, conditions, escalations, created_at, updated_at
+FROM sla_policies
+WHERE jsonb_array_length(COALESCE(conditions->'groups', '[]'::jsonb)) > 0
+ORDER BY id;
 
 -- name: get-all-sla-policies
 SELECT id, name, created_at, updated_at FROM sla_policies ORDER BY updated_at DESC;
@@ -11,8 +18,11 @@ INSERT INTO sla_policies (
    first_response_time,
    resolution_time,
    next_response_time,
-   notifications
-) VALUES ($1, $2, $3, $4, $5, $6)
+   notifications,
+   pause_statuses,
+   conditions,
+   escalations
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING *;
 
 -- name: update-sla-policy
@@ -23,6 +33,9 @@ UPDATE sla_policies SET
    resolution_time = $5,
    next_response_time = $6,
    notifications = $7,
+   pause_statuses = $8,
+   conditions = $9,
+   escalations = $10,
    updated_at = NOW()
 WHERE id = $1
 RETURNING *;
@@ -36,8 +49,9 @@ WITH new_sla AS (
     conversation_id,
     sla_policy_id,
     first_response_deadline_at,
-    resolution_deadline_at
-  ) VALUES ($1, $2, $3, $4)
+    resolution_deadline_at,
+    is_manual
+  ) VALUES ($1, $2, $3, $4, $5)
   RETURNING conversation_id, id
 )
 -- update the conversation with the new SLA policy and next SLA deadline.
@@ -52,9 +66,13 @@ RETURNING ns.id;
 -- name: get-pending-applied-sla
 -- Get all the applied SLAs (applied to a conversation) that are pending
 SELECT a.id, a.first_response_deadline_at, c.first_reply_at as conversation_first_response_at, a.sla_policy_id,
-a.resolution_deadline_at, c.resolved_at as conversation_resolved_at, c.id as conversation_id, a.first_response_met_at, a.resolution_met_at, a.first_response_breached_at, a.resolution_breached_at
+a.resolution_deadline_at, c.resolved_at as conversation_resolved_at, c.id as conversation_id, a.first_response_met_at, a.resolution_met_at, a.first_response_breached_at, a.resolution_breached_at,
+s.name as conversation_status, c.assigned_team_id as conversation_assigned_team_id, sp.pause_statuses,
+(SELECT p.started_at FROM sla_pauses p WHERE p.applied_sla_id = a.id AND p.ended_at IS NULL) as paused_at
 FROM applied_slas a 
 JOIN conversations c ON a.conversation_id = c.id and c.sla_policy_id = a.sla_policy_id
+JOIN sla_policies sp ON a.sla_policy_id = sp.id
+LEFT JOIN conversation_statuses s ON c.status_id = s.id
 WHERE a.status = 'pending'::applied_sla_status;
 
 -- name: update-applied-sla-breached-at
@@ -77,6 +95,9 @@ SET next_sla_deadline_at = CASE
     -- If resolved or closed, clear the deadline
     WHEN c.status_id IN (SELECT id FROM conversation_statuses WHERE name IN ('Resolved', 'Closed')) THEN NULL
 
+    -- If the SLA is paused, clear the deadline until the pause ends.
+    WHEN EXISTS (SELECT 1 FROM sla_pauses p WHERE p.applied_sla_id = a.id AND p.ended_at IS NULL) THEN NULL
+
     -- If an external timestamp ($2) is provided (e.g. next_response), use the earliest of $2.
     WHEN $2::TIMESTAMPTZ IS NOT NULL THEN LEAST(
         $2::TIMESTAMPTZ,
@@ -148,8 +169,12 @@ SELECT a.id,
    c.reference_number as conversation_reference_number,
    c.subject as conversation_subject,
    c.assigned_user_id as conversation_assigned_user_id,
-   s.name as conversation_status
+   c.assigned_team_id as conversation_assigned_team_id,
+   s.name as conversation_status,
+   sp.pause_statuses,
+   (SELECT p.started_at FROM sla_pauses p WHERE p.applied_sla_id = a.id AND p.ended_at IS NULL) as paused_at
 FROM applied_slas a INNER JOIN conversations c on a.conversation_id = c.id
+INNER JOIN sla_policies sp ON a.sla_policy_id = sp.id
 LEFT JOIN conversation_statuses s ON c.status_id = s.id
 WHERE a.id = $1;
 
@@ -196,6 +221,74 @@ FROM sla_events
 WHERE id = $1;
 
 -- name: get-pending-sla-events
+-- Events of paused SLAs are skipped, their deadlines are extended once the pause ends.
 SELECT id
+FROM sla_events e
+WHERE status = 'pending' AND deadline_at IS NOT NULL
+AND NOT EXISTS (SELECT 1 FROM sla_pauses p WHERE p.applied_sla_id = e.applied_sla_id AND p.ended_at IS NULL);
+
+-- name: get-unmet-sla-events
+SELECT id, created_at, updated_at, applied_sla_id, sla_policy_id, type, deadline_at, met_at, breached_at
 FROM sla_events
-WHERE status = 'pending' AND deadline_at IS NOT NULL;
+WHERE applied_sla_id = $1 AND status = 'pending' AND met_at IS NULL;
+
+-- name: update-sla-event-deadline
+UPDATE sla_events
+SET deadline_at = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: update-applied-sla-deadlines
+UPDATE applied_slas SET
+   first_response_deadline_at = $2,
+   resolution_deadline_at = $3,
+   updated_at = NOW()
+WHERE id = $1;
+
+-- name: insert-sla-pause
+INSERT INTO sla_pauses (applied_sla_id, status, started_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (applied_sla_id) WHERE ended_at IS NULL DO NOTHING;
+
+-- name: get-open-sla-pause
+SELECT id, created_at, applied_sla_id, status, started_at, ended_at, paused_minutes
+FROM sla_pauses
+WHERE applied_sla_id = $1 AND ended_at IS NULL;
+
+-- name: end-sla-pause
+UPDATE sla_pauses
+SET ended_at = $2,
+    paused_minutes = $3
+WHERE id = $1 AND ended_at IS NULL;
+
+-- name: delete-unsent-sla-warnings
+DELETE FROM scheduled_sla_notifications
+WHERE applied_sla_id = $1 AND notification_type = 'warning' AND processed_at IS NULL;
+
+-- name: delete-unsent-sla-warning-escalations
+DELETE FROM scheduled_sla_escalations
+WHERE applied_sla_id = $1 AND escalation_type = 'warning' AND processed_at IS NULL;
+
+-- name: insert-scheduled-sla-escalation
+INSERT INTO scheduled_sla_escalations (
+   applied_sla_id,
+   sla_event_id,
+   metric,
+   escalation_type,
+   actions,
+   run_at
+) VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: get-scheduled-sla-escalations
+SELECT id, created_at, updated_at, applied_sla_id, sla_event_id, metric, escalation_type, actions, run_at, processed_at
+FROM scheduled_sla_escalations
+WHERE run_at <= NOW() AND processed_at IS NULL
+ORDER BY run_at;
+
+-- name: update-escalation-processed
+-- Returns no rows if the escalation was already processed.
+UPDATE scheduled_sla_escalations
+SET processed_at = NOW(),
+      updated_at = NOW()
+WHERE id = $1 AND processed_at IS NULL
+RETURNING id;
@@ -22,6 +22,7 @@ import (
 	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
 	"github.com/abhinavxd/libredesk/internal/template"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	unmodels "github.com/abhinavxd/libredesk/internal/user_notification/models"
 	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/types"
 	"github.com/knadh/go-i18n"
@@ -45,6 +46,10 @@ const (
 
 	NotificationTypeWarning = "warning"
 	NotificationTypeBreach  = "breach"
+
+	// Notification recipients besides agent IDs.
+	RecipientAssignedUser = "assigned_user"
+	RecipientAssignedTeam = "assigned_team"
 )
 
 var metricLabels = map[string]string{
@@ -54,17 +59,19 @@ var metricLabels = map[string]string{
 }
 
 type Manager struct {
-	q                queries
-	lo               *logf.Logger
-	i18n             *i18n.I18n
-	teamStore        teamStore
-	userStore        userStore
-	appSettingsStore appSettingsStore
-	businessHrsStore businessHrsStore
-	notifier         *notifier.Service
-	template         *template.Manager
-	wg               sync.WaitGroup
-	opts             Opts
+	q                 queries
+	lo                *logf.Logger
+	i18n              *i18n.I18n
+	teamStore         teamStore
+	userStore         userStore
+	conversationStore conversationStore
+	notificationStore notificationStore
+	appSettingsStore  appSettingsStore
+	businessHrsStore  businessHrsStore
+	notifier          *notifier.Service
+	template          *template.Manager
+	wg                sync.WaitGroup
+	opts              Opts
 }
 
 // Opts defines the options for creating SLA manager.
@@ -90,24 +97,32 @@ type Breaches struct {
 
 type teamStore interface {
 	Get(id int) (tmodels.Team, error)
+	GetEventNotificationChannels(teamID int, event string) ([]tmodels.NotificationChannel, error)
 }
 
 type userStore interface {
 	GetAgent(int, string) (umodels.User, error)
+	GetSystemUser() (umodels.User, error)
 }
 
 type appSettingsStore interface {
 	GetByPrefix(prefix string) (types.JSONText, error)
+	GetAppRootURL() (string, error)
 }
 
 type businessHrsStore interface {
 	Get(id int) (bmodels.BusinessHours, error)
 }
 
+type notificationStore interface {
+	Notify(n unmodels.Notification) error
+}
+
 // queries hold prepared SQL queries.
 type queries struct {
 	GetSLAPolicy                      *sqlx.Stmt `query:"get-sla-policy"`
 	GetAllSLAPolicies                 *sqlx.Stmt `query:"get-all-sla-policies"`
+	GetSLAPoliciesWithConditions      *sqlx.Stmt `query:"get-sla-policies-with-conditions"`
 	GetAppliedSLA                     *sqlx.Stmt `query:"get-applied-sla"`
 	GetSLAEvent                       *sqlx.Stmt `query:"get-sla-event"`
 	GetScheduledSLANotifications      *sqlx.Stmt `query:"get-scheduled-sla-notifications"`
@@ -127,6 +142,17 @@ type queries struct {
 	SetLatestSLAEventMetAt            *sqlx.Stmt `query:"set-latest-sla-event-met-at"`
 	ApplySLA                          *sqlx.Stmt `query:"apply-sla"`
 	DeleteSLAPolicy                   *sqlx.Stmt `query:"delete-sla-policy"`
+	GetUnmetSLAEvents                 *sqlx.Stmt `query:"get-unmet-sla-events"`
+	UpdateSLAEventDeadline            *sqlx.Stmt `query:"update-sla-event-deadline"`
+	UpdateAppliedSLADeadlines         *sqlx.Stmt `query:"update-applied-sla-deadlines"`
+	InsertSLAPause                    *sqlx.Stmt `query:"insert-sla-pause"`
+	GetOpenSLAPause                   *sqlx.Stmt `query:"get-open-sla-pause"`
+	EndSLAPause                       *sqlx.Stmt `query:"end-sla-pause"`
+	DeleteUnsentSLAWarnings           *sqlx.Stmt `query:"delete-unsent-sla-warnings"`
+	DeleteUnsentSLAWarningEscalations *sqlx.Stmt `query:"delete-unsent-sla-warning-escalations"`
+	InsertScheduledSLAEscalation      *sqlx.Stmt `query:"insert-scheduled-sla-escalation"`
+	GetScheduledSLAEscalations        *sqlx.Stmt `query:"get-scheduled-sla-escalations"`
+	UpdateSLAEscalationProcessed      *sqlx.Stmt `query:"update-escalation-processed"`
 }
 
 // New creates a new SLA manager.
@@ -185,10 +211,20 @@ func (m *Manager) GetAll() ([]models.SLAPolicy, error) {
 	return slas, nil
 }
 
+// GetPoliciesWithConditions returns the SLA policies that have match conditions, in the order they are matched.
+func (m *Manager) GetPoliciesWithConditions() ([]models.SLAPolicy, error) {
+	var slas = make([]models.SLAPolicy, 0)
+	if err := m.q.GetSLAPoliciesWithConditions.Select(&slas); err != nil {
+		m.lo.Error("error fetching SLAs with conditions", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", m.i18n.P("globals.terms.sla")), nil)
+	}
+	return slas, nil
+}
+
 // Create creates a new SLA policy.
-func (m *Manager) Create(name, description string, firstResponseTime, resolutionTime, nextResponseTime null.String, notifications models.SlaNotifications) (models.SLAPolicy, error) {
+func (m *Manager) Create(name, description string, firstResponseTime, resolutionTime, nextResponseTime null.String, notifications models.SlaNotifications, pauseStatuses []string, conditions models.SLAConditions, escalations models.SLAEscalations) (models.SLAPolicy, error) {
 	var result models.SLAPolicy
-	if err := m.q.InsertSLAPolicy.Get(&result, name, description, firstResponseTime, resolutionTime, nextResponseTime, notifications); err != nil {
+	if err := m.q.InsertSLAPolicy.Get(&result, name, description, firstResponseTime, resolutionTime, nextResponseTime, notifications, pq.Array(pauseStatuses), conditions, escalations); err != nil {
 		m.lo.Error("error inserting SLA", "error", err)
 		return models.SLAPolicy{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", "{globals.terms.sla}"), nil)
 	}
@@ -196,9 +232,9 @@ func (m *Manager) Create(name, description string, firstResponseTime, resolution
 }
 
 // Update updates a SLA policy.
-func (m *Manager) Update(id int, name, description string, firstResponseTime, resolutionTime, nextResponseTime null.String, notifications models.SlaNotifications) (models.SLAPolicy, error) {
+func (m *Manager) Update(id int, name, description string, firstResponseTime, resolutionTime, nextResponseTime null.String, notifications models.SlaNotifications, pauseStatuses []string, conditions models.SLAConditions, escalations models.SLAEscalations) (models.SLAPolicy, error) {
 	var result models.SLAPolicy
-	if err := m.q.UpdateSLAPolicy.Get(&result, id, name, description, firstResponseTime, resolutionTime, nextResponseTime, notifications); err != nil {
+	if err := m.q.UpdateSLAPolicy.Get(&result, id, name, description, firstResponseTime, resolutionTime, nextResponseTime, notifications, pq.Array(pauseStatuses), conditions, escalations); err != nil {
 		m.lo.Error("error updating SLA", "error", err)
 		return models.SLAPolicy{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.sla}"), nil)
 	}
@@ -259,7 +295,8 @@ func (m *Manager) GetDeadlines(startTime time.Time, slaPolicyID, assignedTeamID
 }
 
 // ApplySLA applies an SLA policy to a conversation by calculating and setting the deadlines.
-func (m *Manager) ApplySLA(startTime time.Time, conversationID, assignedTeamID, slaPolicyID int) (models.SLAPolicy, error) {
+// manual is set for policies applied by agents, these are not replaced by policies matched by conditions.
+func (m *Manager) ApplySLA(startTime time.Time, conversationID, assignedTeamID, slaPolicyID int, manual bool) (models.SLAPolicy, error) {
 	var sla models.SLAPolicy
 
 	// Get deadlines for the SLA policy and assigned team.
@@ -277,6 +314,7 @@ func (m *Manager) ApplySLA(startTime time.Time, conversationID, assignedTeamID,
 		slaPolicyID,
 		deadlines.FirstResponse,
 		deadlines.Resolution,
+		manual,
 	).Scan(&appliedSLAID); err != nil {
 		m.lo.Error("error applying SLA", "error", err)
 		return sla, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorApplying", "name", "{globals.terms.sla}"), nil)
@@ -289,6 +327,7 @@ func (m *Manager) ApplySLA(startTime time.Time, conversationID, assignedTeamID,
 		return sla, err
 	}
 	m.createNotificationSchedule(sla.Notifications, appliedSLAID, null.Int{}, deadlines, Breaches{})
+	m.createEscalationSchedule(sla.Escalations, appliedSLAID, null.Int{}, deadlines, Breaches{})
 
 	return sla, nil
 }
@@ -361,6 +400,7 @@ func (m *Manager) CreateNextResponseSLAEvent(conversationID, appliedSLAID, slaPo
 	deadlines.FirstResponse = null.Time{}
 	deadlines.Resolution = null.Time{}
 	m.createNotificationSchedule(slaPolicy.Notifications, appliedSLAID, null.IntFrom(slaEventID), deadlines, Breaches{})
+	m.createEscalationSchedule(slaPolicy.Escalations, appliedSLAID, null.IntFrom(slaEventID), deadlines, Breaches{})
 
 	return deadlines.NextResponse.Time, nil
 }
@@ -442,19 +482,20 @@ func (m *Manager) evaluatePendingSLAEvents(ctx context.Context) error {
 				}
 				slaPolicyCache[event.SlaPolicyID] = slaPolicy
 			}
-			m.createNotificationSchedule(slaPolicy.Notifications, event.AppliedSLAID, null.IntFrom(event.ID), Deadlines{}, Breaches{
-				NextResponse: null.TimeFrom(time.Now()),
-			})
+			breaches := Breaches{NextResponse: null.TimeFrom(time.Now())}
+			m.createNotificationSchedule(slaPolicy.Notifications, event.AppliedSLAID, null.IntFrom(event.ID), Deadlines{}, breaches)
+			m.createEscalationSchedule(slaPolicy.Escalations, event.AppliedSLAID, null.IntFrom(event.ID), Deadlines{}, breaches)
 		}
 	}
 	return nil
 }
 
-// Run starts Applied SLA and SLA event evaluation loops in separate goroutines.
+// Run starts Applied SLA and SLA event evaluation and escalation loops in separate goroutines.
 func (m *Manager) Run(ctx context.Context, interval time.Duration) {
-	m.wg.Add(2)
+	m.wg.Add(3)
 	go m.runSLAEvaluation(ctx, interval)
 	go m.runSLAEventEvaluation(ctx, interval)
+	go m.runSLAEscalations(ctx, interval)
 }
 
 // runSLAEvaluation periodically evaluates pending SLAs.
@@ -552,7 +593,72 @@ func (m *Manager) SendNotification(scheduledNotification models.ScheduledSLANoti
 		return nil
 	}
 
-	// Send to all recipients (agents).
+	// Warnings are not sent while the SLA is paused, they are rescheduled for the extended deadlines once the pause ends.
+	if appliedSLA.PausedAt.Valid && scheduledNotification.NotificationType == NotificationTypeWarning {
+		m.lo.Info("marking sla warning as processed as the SLA is paused", "applied_sla_id", appliedSLA.ID, "scheduled_notification_id", scheduledNotification.ID)
+		if _, err := m.q.UpdateSLANotificationProcessed.Exec(scheduledNotification.ID); err != nil {
+			m.lo.Error("error marking notification as processed", "error", err)
+		}
+		return nil
+	}
+
+	var (
+		dueIn, overdueBy string
+		tmpl             string
+		notificationType string
+	)
+	// Set the template based on the notification type.
+	switch scheduledNotification.NotificationType {
+	case NotificationTypeBreach:
+		tmpl = template.TmplSLABreached
+		notificationType = unmodels.TypeSLABreach
+	case NotificationTypeWarning:
+		tmpl = template.TmplSLABreachWarning
+		notificationType = unmodels.TypeSLAWarning
+	default:
+		m.lo.Error("unknown notification type", "notification_type", scheduledNotification.NotificationType)
+		return fmt.Errorf("unknown notification type: %s", scheduledNotification.NotificationType)
+	}
+
+	// Set the dueIn and overdueBy values based on the metric.
+	// These are relative to the current time as setting exact time would require agent's timezone.
+	getFriendlyDuration := func(target time.Time) string {
+		d := time.Until(target)
+		if d < 0 {
+			return stringutil.FormatDuration(-d, false)
+		}
+		return stringutil.FormatDuration(d, false)
+	}
+
+	switch scheduledNotification.Metric {
+	case MetricFirstResponse:
+		dueIn = getFriendlyDuration(appliedSLA.FirstResponseDeadlineAt.Time)
+		overdueBy = getFriendlyDuration(appliedSLA.FirstResponseBreachedAt.Time)
+	case MetricResolution:
+		dueIn = getFriendlyDuration(appliedSLA.ResolutionDeadlineAt.Time)
+		overdueBy = getFriendlyDuration(appliedSLA.ResolutionBreachedAt.Time)
+	case MetricNextResponse:
+		dueIn = getFriendlyDuration(slaEvent.DeadlineAt)
+		overdueBy = getFriendlyDuration(slaEvent.BreachedAt.Time)
+	default:
+		m.lo.Error("unknown metric type", "metric", scheduledNotification.Metric)
+		return fmt.Errorf("unknown metric type: %s", scheduledNotification.Metric)
+	}
+
+	// Set the metric label.
+	var metricLabel string
+	if label, ok := metricLabels[scheduledNotification.Metric]; ok {
+		metricLabel = label
+	}
+
+	var title string
+	if notificationType == unmodels.TypeSLABreach {
+		title = m.i18n.Ts("notification.slaBreach.title", "metric", metricLabel, "reference", appliedSLA.ConversationReferenceNumber)
+	} else {
+		title = m.i18n.Ts("notification.slaWarning.title", "metric", metricLabel, "reference", appliedSLA.ConversationReferenceNumber, "duration", dueIn)
+	}
+
+	// Send to all recipients, agents or the assigned team.
 	for _, recipientS := range scheduledNotification.Recipients {
 		// Check if SLA is already met, if met mark notification as processed and return.
 		switch scheduledNotification.Metric {
@@ -589,9 +695,18 @@ func (m *Manager) SendNotification(scheduledNotification models.ScheduledSLANoti
 			continue
 		}
 
+		// Post to the notification channels of the assigned team.
+		if recipientS == RecipientAssignedTeam {
+			m.notifyTeamChannels(appliedSLA, notificationType, title)
+			if _, err := m.q.UpdateSLANotificationProcessed.Exec(scheduledNotification.ID); err != nil {
+				m.lo.Error("error marking notification as processed", "error", err)
+			}
+			continue
+		}
+
 		// Get recipient agent, recipient can be a specific agent or assigned user.
 		recipientID, err := strconv.Atoi(recipientS)
-		if recipientS == "assigned_user" {
+		if recipientS == RecipientAssignedUser {
 			recipientID = appliedSLA.ConversationAssignedUserID.Int
 		} else if err != nil {
 			m.lo.Error("error parsing recipient ID", "error", err, "recipient_id", recipientS)
@@ -615,52 +730,6 @@ func (m *Manager) SendNotification(scheduledNotification models.ScheduledSLANoti
 			continue
 		}
 
-		var (
-			dueIn, overdueBy string
-			tmpl             string
-		)
-		// Set the template based on the notification type.
-		switch scheduledNotification.NotificationType {
-		case NotificationTypeBreach:
-			tmpl = template.TmplSLABreached
-		case NotificationTypeWarning:
-			tmpl = template.TmplSLABreachWarning
-		default:
-			m.lo.Error("unknown notification type", "notification_type", scheduledNotification.NotificationType)
-			return fmt.Errorf("unknown notification type: %s", scheduledNotification.NotificationType)
-		}
-
-		// Set the dueIn and overdueBy values based on the metric.
-		// These are relative to the current time as setting exact time would require agent's timezone.
-		getFriendlyDuration := func(target time.Time) string {
-			d := time.Until(target)
-			if d < 0 {
-				return stringutil.FormatDuration(-d, false)
-			}
-			return stringutil.FormatDuration(d, false)
-		}
-
-		switch scheduledNotification.Metric {
-		case MetricFirstResponse:
-			dueIn = getFriendlyDuration(appliedSLA.FirstResponseDeadlineAt.Time)
-			overdueBy = getFriendlyDuration(appliedSLA.FirstResponseBreachedAt.Time)
-		case MetricResolution:
-			dueIn = getFriendlyDuration(appliedSLA.ResolutionDeadlineAt.Time)
-			overdueBy = getFriendlyDuration(appliedSLA.ResolutionBreachedAt.Time)
-		case MetricNextResponse:
-			dueIn = getFriendlyDuration(slaEvent.DeadlineAt)
-			overdueBy = getFriendlyDuration(slaEvent.BreachedAt.Time)
-		default:
-			m.lo.Error("unknown metric type", "metric", scheduledNotification.Metric)
-			return fmt.Errorf("unknown metric type: %s", scheduledNotification.Metric)
-		}
-
-		// Set the metric label.
-		var metricLabel string
-		if label, ok := metricLabels[scheduledNotification.Metric]; ok {
-			metricLabel = label
-		}
-
 		// Render the email template.
 		content, subject, err := m.template.RenderStoredEmailTemplate(tmpl,
 			map[string]any{
@@ -695,8 +764,21 @@ func (m *Manager) SendNotification(scheduledNotification models.ScheduledSLANoti
 			continue
 		}
 
-		// Enqueue email notification.
-		if err := m.notifier.Send(notifier.Message{
+		// Notify the agent in-app and by email as per their preferences.
+		if m.notificationStore != nil {
+			if err := m.notificationStore.Notify(unmodels.Notification{
+				UserID:           agent.ID,
+				Type:             notificationType,
+				Title:            title,
+				Body:             appliedSLA.ConversationSubject,
+				ConversationID:   null.IntFrom(appliedSLA.ConversationID),
+				ConversationUUID: null.StringFrom(appliedSLA.ConversationUUID),
+				EmailSubject:     subject,
+				EmailContent:     content,
+			}); err != nil {
+				m.lo.Error("error sending SLA notification", "error", err)
+			}
+		} else if err := m.notifier.Send(notifier.Message{
 			RecipientEmails: []string{
 				agent.Email.String,
 			},
@@ -715,6 +797,41 @@ func (m *Manager) SendNotification(scheduledNotification models.ScheduledSLANoti
 	return nil
 }
 
+// notifyTeamChannels posts an SLA notification to the notification channels of the conversation's assigned team.
+func (m *Manager) notifyTeamChannels(appliedSLA models.AppliedSLA, notificationType, title string) {
+	teamID := appliedSLA.ConversationAssignedTeamID.Int
+	if teamID == 0 {
+		return
+	}
+	event := tmodels.ChannelEventSLAWarning
+	if notificationType == unmodels.TypeSLABreach {
+		event = tmodels.ChannelEventSLABreach
+	}
+	channels, err := m.teamStore.GetEventNotificationChannels(teamID, event)
+	if err != nil {
+		m.lo.Error("error fetching team notification channels", "team_id", teamID, "error", err)
+		return
+	}
+	if len(channels) == 0 {
+		return
+	}
+	rootURL, err := m.appSettingsStore.GetAppRootURL()
+	if err != nil {
+		m.lo.Error("error fetching app root URL", "error", err)
+	}
+	for _, channel := range channels {
+		if err := m.notifier.Send(notifier.Message{
+			Subject:    title,
+			AltContent: appliedSLA.ConversationSubject,
+			Link:       rootURL + "/inboxes/assigned/conversation/" + appliedSLA.ConversationUUID,
+			Provider:   channel.Provider,
+			Chat:       channel.Target(),
+		}); err != nil {
+			m.lo.Error("error sending SLA notification to team channel", "team_id", teamID, "channel_id", channel.ID, "error", err)
+		}
+	}
+}
+
 // Close closes the SLA evaluation loop by stopping the worker pool.
 func (m *Manager) Close() error {
 	m.wg.Wait()
@@ -798,33 +915,43 @@ func (m *Manager) createNotificationSchedule(notifications models.SlaNotificatio
 			}
 		}
 
-		if notif.Metric == "" {
-			notif.Metric = MetricAll
+		for _, st := range scheduleTimes(notif.Type, notif.Metric, delayDur, deadlines, breaches) {
+			scheduleNotification(st.at, st.metric, notif.Type, notif.Recipients)
 		}
+	}
+}
 
-		schedule := func(target null.Time, metricType string) {
-			if target.Valid && (notif.Metric == metricType || notif.Metric == MetricAll) {
-				var sendAt time.Time
-				if notif.Type == NotificationTypeWarning {
-					sendAt = target.Time.Add(-delayDur)
-				} else {
-					sendAt = target.Time.Add(delayDur)
-				}
-				scheduleNotification(sendAt, metricType, notif.Type, notif.Recipients)
-			}
-		}
+// scheduledTime is the time a notification or escalation for a metric is due.
+type scheduledTime struct {
+	metric string
+	at     time.Time
+}
+
+// scheduleTimes returns the due times of a warning or breach for the metric, `all` or an empty metric matches every metric.
+// Warnings are due delay before the deadline and breaches delay after the breach.
+func scheduleTimes(typ, metric string, delay time.Duration, deadlines Deadlines, breaches Breaches) []scheduledTime {
+	if metric == "" {
+		metric = MetricAll
+	}
 
-		switch notif.Type {
-		case NotificationTypeWarning:
-			schedule(deadlines.FirstResponse, MetricFirstResponse)
-			schedule(deadlines.Resolution, MetricResolution)
-			schedule(deadlines.NextResponse, MetricNextResponse)
-		case NotificationTypeBreach:
-			schedule(breaches.FirstResponse, MetricFirstResponse)
-			schedule(breaches.Resolution, MetricResolution)
-			schedule(breaches.NextResponse, MetricNextResponse)
+	var targets [3]null.Time
+	switch typ {
+	case NotificationTypeWarning:
+		targets = [3]null.Time{deadlines.FirstResponse, deadlines.Resolution, deadlines.NextResponse}
+		delay = -delay
+	case NotificationTypeBreach:
+		targets = [3]null.Time{breaches.FirstResponse, breaches.Resolution, breaches.NextResponse}
+	default:
+		return nil
+	}
+
+	var times []scheduledTime
+	for i, metricType := range []string{MetricFirstResponse, MetricResolution, MetricNextResponse} {
+		if targets[i].Valid && (metric == metricType || metric == MetricAll) {
+			times = append(times, scheduledTime{metric: metricType, at: targets[i].Time.Add(delay)})
 		}
 	}
+	return times
 }
 
 // evaluatePendingSLAs fetches pending SLAs and evaluates them, pending SLAs are applied SLAs that have not breached or met yet.
@@ -852,6 +979,17 @@ func (m *Manager) evaluatePendingSLAs(ctx context.Context) error {
 // evaluateSLA evaluates an SLA policy on an applied SLA.
 func (m *Manager) evaluateSLA(appliedSLA models.AppliedSLA) error {
 	m.lo.Debug("evaluating SLA", "conversation_id", appliedSLA.ConversationID, "applied_sla_id", appliedSLA.ID)
+
+	// Pause or resume the SLA clock, deadlines are not checked while the SLA is paused.
+	paused, err := m.syncPause(&appliedSLA)
+	if err != nil {
+		return fmt.Errorf("syncing SLA pause: %w", err)
+	}
+	if paused {
+		m.lo.Debug("SLA paused, skipping evaluation", "conversation_id", appliedSLA.ConversationID, "applied_sla_id", appliedSLA.ID)
+		return nil
+	}
+
 	checkDeadline := func(deadline time.Time, metAt null.Time, metric string) error {
 		if deadline.IsZero() {
 			m.lo.Warn("deadline zero, skipping checking the deadline", "conversation_id", appliedSLA.ConversationID, "applied_sla_id", appliedSLA.ID, "metric", metric)
@@ -913,7 +1051,7 @@ func (m *Manager) evaluateSLA(appliedSLA models.AppliedSLA) error {
 }
 
 // handleSLABreach processes a breach for the given SLA metric on an applied SLA.
-// It updates the breach timestamp and schedules breach notifications if applicable.
+// It updates the breach timestamp and schedules breach notifications and escalations if applicable.
 func (m *Manager) handleSLABreach(appliedSLAID, slaPolicyID int, metric string) error {
 	if _, err := m.q.UpdateAppliedSLABreachedAt.Exec(appliedSLAID, metric); err != nil {
 		return err
@@ -933,11 +1071,13 @@ func (m *Manager) handleSLABreach(appliedSLAID, slaPolicyID int, metric string)
 		resolution = null.TimeFrom(time.Now())
 	}
 
-	// Create notification schedule.
-	m.createNotificationSchedule(sla.Notifications, appliedSLAID, null.Int{}, Deadlines{}, Breaches{
+	// Create notification and escalation schedule.
+	breaches := Breaches{
 		FirstResponse: firstResponse,
 		Resolution:    resolution,
-	})
+	}
+	m.createNotificationSchedule(sla.Notifications, appliedSLAID, null.Int{}, Deadlines{}, breaches)
+	m.createEscalationSchedule(sla.Escalations, appliedSLAID, null.Int{}, Deadlines{}, breaches)
 
 	return nil
 }
@@ -0,0 +1,78 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/volatiletech/null/v9"
+)
+
+func TestScheduleTimes(t *testing.T) {
+	var (
+		now       = time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+		deadlines = Deadlines{
+			FirstResponse: null.TimeFrom(now.Add(time.Hour)),
+			Resolution:    null.TimeFrom(now.Add(8 * time.Hour)),
+		}
+		breaches = Breaches{NextResponse: null.TimeFrom(now)}
+	)
+
+	tests := []struct {
+		name     string
+		typ      string
+		metric   string
+		delay    time.Duration
+		expected []scheduledTime
+	}{
+		{
+			name:   "warning for all metrics",
+			typ:    NotificationTypeWarning,
+			metric: MetricAll,
+			delay:  10 * time.Minute,
+			expected: []scheduledTime{
+				{metric: MetricFirstResponse, at: now.Add(50 * time.Minute)},
+				{metric: MetricResolution, at: now.Add(7*time.Hour + 50*time.Minute)},
+			},
+		},
+		{
+			name:   "empty metric matches all metrics",
+			typ:    NotificationTypeWarning,
+			metric: "",
+			expected: []scheduledTime{
+				{metric: MetricFirstResponse, at: now.Add(time.Hour)},
+				{metric: MetricResolution, at: now.Add(8 * time.Hour)},
+			},
+		},
+		{
+			name:     "warning for a single metric",
+			typ:      NotificationTypeWarning,
+			metric:   MetricResolution,
+			delay:    time.Hour,
+			expected: []scheduledTime{{metric: MetricResolution, at: now.Add(7 * time.Hour)}},
+		},
+		{
+			name:     "breach after delay",
+			typ:      NotificationTypeBreach,
+			metric:   MetricAll,
+			delay:    15 * time.Minute,
+			expected: []scheduledTime{{metric: MetricNextResponse, at: now.Add(15 * time.Minute)}},
+		},
+		{
+			name:   "breach without a matching metric",
+			typ:    NotificationTypeBreach,
+			metric: MetricFirstResponse,
+		},
+		{
+			name:   "unknown type",
+			typ:    "unknown",
+			metric: MetricAll,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, scheduleTimes(tt.typ, tt.metric, tt.delay, deadlines, breaches))
+		})
+	}
+}
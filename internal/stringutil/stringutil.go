@@ -155,6 +155,16 @@ func RemoveItemByValue(slice []string, value string) []string {
 	return result
 }
 
+// Truncate shortens a string to at most n characters, adding an ellipsis if it was shortened.
+func Truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n])) + "…"
+}
+
 // FormatDuration formats a duration as a string.
 func FormatDuration(d time.Duration, includeSeconds bool) string {
 	d = d.Round(time.Second)
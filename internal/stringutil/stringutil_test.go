@@ -101,3 +101,26 @@ func TestFormatDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		n        int
+		expected string
+	}{
+		{name: "shorter", input: "hello", n: 10, expected: "hello"},
+		{name: "exact", input: "hello", n: 5, expected: "hello"},
+		{name: "longer", input: "hello world", n: 6, expected: "hello…"},
+		{name: "multibyte", input: "héllo wörld", n: 8, expected: "héllo wö…"},
+		{name: "surrounding space", input: "  hi  ", n: 5, expected: "hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Truncate(tt.input, tt.n); result != tt.expected {
+				t.Errorf("got %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
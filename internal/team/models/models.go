@@ -6,9 +6,21 @@ import (
 	"fmt"
 	"time"
 
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/lib/pq"
 	"github.com/volatiletech/null/v9"
 )
 
+// Events teams can get notified of on their notification channels.
+const (
+	ChannelEventAssigned   = "assigned"
+	ChannelEventSLAWarning = "sla_warning"
+	ChannelEventSLABreach  = "sla_breach"
+)
+
+// ChannelEvents is the list of all notification channel events.
+var ChannelEvents = []string{ChannelEventAssigned, ChannelEventSLAWarning, ChannelEventSLABreach}
+
 type Team struct {
 	ID                           int         `db:"id" json:"id"`
 	CreatedAt                    time.Time   `db:"created_at" json:"created_at"`
@@ -63,3 +75,50 @@ func (t *TeamsCompact) Scan(src interface{}) error {
 func (t TeamsCompact) Value() (driver.Value, error) {
 	return json.Marshal(t)
 }
+
+// NotificationChannel is a chat platform a team gets notified on.
+type NotificationChannel struct {
+	ID        int                       `db:"id" json:"id"`
+	CreatedAt time.Time                 `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time                 `db:"updated_at" json:"updated_at"`
+	TeamID    int                       `db:"team_id" json:"team_id"`
+	Name      string                    `db:"name" json:"name"`
+	Provider  string                    `db:"provider" json:"provider"`
+	Config    NotificationChannelConfig `db:"config" json:"config"`
+	Events    pq.StringArray            `db:"events" json:"events"`
+	Enabled   bool                      `db:"enabled" json:"enabled"`
+}
+
+// NotificationChannelConfig holds where a notification channel posts to.
+type NotificationChannelConfig struct {
+	// Incoming webhook URL for Slack and Mattermost, homeserver URL for Matrix.
+	URL         string `json:"url"`
+	RoomID      string `json:"room_id,omitempty"`
+	AccessToken string `json:"access_token,omitempty"`
+}
+
+// Target returns the chat target of the notification channel.
+func (c NotificationChannel) Target() notifier.ChatTarget {
+	return notifier.ChatTarget{
+		URL:         c.Config.URL,
+		RoomID:      c.Config.RoomID,
+		AccessToken: c.Config.AccessToken,
+	}
+}
+
+// Scan implements the sql.Scanner interface for NotificationChannelConfig
+func (c *NotificationChannelConfig) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, c)
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("unsupported type for NotificationChannelConfig: %T", src)
+	}
+}
+
+// Value implements the driver.Valuer interface for NotificationChannelConfig
+func (c NotificationChannelConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
@@ -0,0 +1,81 @@
+package team
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/team/models"
+	"github.com/lib/pq"
+)
+
+// GetNotificationChannels retrieves the notification channels of a team.
+func (u *Manager) GetNotificationChannels(teamID int) ([]models.NotificationChannel, error) {
+	var channels = make([]models.NotificationChannel, 0)
+	if err := u.q.GetNotificationChannels.Select(&channels, teamID); err != nil {
+		u.lo.Error("error fetching team notification channels", "team_id", teamID, "error", err)
+		return channels, envelope.NewError(envelope.GeneralError, u.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.notificationChannel}"), nil)
+	}
+	return channels, nil
+}
+
+// GetNotificationChannel retrieves a notification channel of a team by ID.
+func (u *Manager) GetNotificationChannel(teamID, id int) (models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	if err := u.q.GetNotificationChannel.Get(&channel, teamID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return channel, envelope.NewError(envelope.NotFoundError, u.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.notificationChannel}"), nil)
+		}
+		u.lo.Error("error fetching team notification channel", "team_id", teamID, "id", id, "error", err)
+		return channel, envelope.NewError(envelope.GeneralError, u.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.notificationChannel}"), nil)
+	}
+	return channel, nil
+}
+
+// GetEventNotificationChannels retrieves the enabled notification channels of a team that get notified of an event.
+func (u *Manager) GetEventNotificationChannels(teamID int, event string) ([]models.NotificationChannel, error) {
+	var channels = make([]models.NotificationChannel, 0)
+	if err := u.q.GetEventNotificationChannels.Select(&channels, teamID, event); err != nil {
+		return nil, fmt.Errorf("fetching team notification channels: %w", err)
+	}
+	return channels, nil
+}
+
+// CreateNotificationChannel creates a notification channel for a team.
+func (u *Manager) CreateNotificationChannel(c models.NotificationChannel) (models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	if c.Events == nil {
+		c.Events = pq.StringArray{}
+	}
+	if err := u.q.InsertNotificationChannel.Get(&channel, c.TeamID, c.Name, c.Provider, c.Config, c.Events, c.Enabled); err != nil {
+		u.lo.Error("error inserting team notification channel", "team_id", c.TeamID, "error", err)
+		return channel, envelope.NewError(envelope.GeneralError, u.i18n.Ts("globals.messages.errorCreating", "name", "{globals.terms.notificationChannel}"), nil)
+	}
+	return channel, nil
+}
+
+// UpdateNotificationChannel updates a notification channel of a team.
+func (u *Manager) UpdateNotificationChannel(c models.NotificationChannel) (models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	if c.Events == nil {
+		c.Events = pq.StringArray{}
+	}
+	if err := u.q.UpdateNotificationChannel.Get(&channel, c.TeamID, c.ID, c.Name, c.Provider, c.Config, c.Events, c.Enabled); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return channel, envelope.NewError(envelope.NotFoundError, u.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.notificationChannel}"), nil)
+		}
+		u.lo.Error("error updating team notification channel", "team_id", c.TeamID, "id", c.ID, "error", err)
+		return channel, envelope.NewError(envelope.GeneralError, u.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.notificationChannel}"), nil)
+	}
+	return channel, nil
+}
+
+// DeleteNotificationChannel deletes a notification channel of a team.
+func (u *Manager) DeleteNotificationChannel(teamID, id int) error {
+	if _, err := u.q.DeleteNotificationChannel.Exec(teamID, id); err != nil {
+		u.lo.Error("error deleting team notification channel", "team_id", teamID, "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, u.i18n.Ts("globals.messages.errorDeleting", "name", "{globals.terms.notificationChannel}"), nil)
+	}
+	return nil
+}
@@ -42,4 +42,27 @@ SELECT 1;
 DELETE FROM teams where id = $1;
 
 -- name: user-belongs-to-team
-SELECT EXISTS(SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2);
\ No newline at end of file
+SELECT EXISTS(SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2);
+
+-- name: get-notification-channels
+SELECT id, created_at, updated_at, team_id, name, provider, config, events, enabled FROM team_notification_channels WHERE team_id = $1 ORDER BY id;
+
+-- name: get-notification-channel
+SELECT id, created_at, updated_at, team_id, name, provider, config, events, enabled FROM team_notification_channels WHERE team_id = $1 AND id = $2;
+
+-- name: get-event-notification-channels
+SELECT id, created_at, updated_at, team_id, name, provider, config, events, enabled FROM team_notification_channels WHERE team_id = $1 AND enabled = TRUE AND $2 = ANY(events) ORDER BY id;
+
+-- name: insert-notification-channel
+INSERT INTO team_notification_channels (team_id, name, provider, config, events, enabled)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, team_id, name, provider, config, events, enabled;
+
+-- name: update-notification-channel
+UPDATE team_notification_channels
+SET name = $3, provider = $4, config = $5, events = $6, enabled = $7, updated_at = NOW()
+WHERE team_id = $1 AND id = $2
+RETURNING id, created_at, updated_at, team_id, name, provider, config, events, enabled;
+
+-- name: delete-notification-channel
+DELETE FROM team_notification_channels WHERE team_id = $1 AND id = $2;
@@ -48,6 +48,13 @@ type queries struct {
 	GetTeamMembers    *sqlx.Stmt `query:"get-team-members"`
 	UpsertUserTeams   *sqlx.Stmt `query:"upsert-user-teams"`
 	UserBelongsToTeam *sqlx.Stmt `query:"user-belongs-to-team"`
+
+	GetNotificationChannels      *sqlx.Stmt `query:"get-notification-channels"`
+	GetNotificationChannel       *sqlx.Stmt `query:"get-notification-channel"`
+	GetEventNotificationChannels *sqlx.Stmt `query:"get-event-notification-channels"`
+	InsertNotificationChannel    *sqlx.Stmt `query:"insert-notification-channel"`
+	UpdateNotificationChannel    *sqlx.Stmt `query:"update-notification-channel"`
+	DeleteNotificationChannel    *sqlx.Stmt `query:"delete-notification-channel"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -3,6 +3,7 @@ package template
 import (
 	"bytes"
 	"fmt"
+	htmltemplate "html/template"
 	"strings"
 	"text/template"
 
@@ -14,6 +15,8 @@ const (
 	TmplConversationAssigned = "Conversation assigned"
 	TmplSLABreachWarning     = "SLA breach warning"
 	TmplSLABreached          = "SLA breached"
+	TmplDigest               = "Digest"
+	TmplScheduledReport      = "Scheduled report"
 
 	// Built-in templates fetched from memory stored in `static` directory.
 	TmplResetPassword = "reset-password"
@@ -120,6 +123,61 @@ func (m *Manager) RenderStoredEmailTemplate(name string, data any) (string, stri
 	return rendered.String(), subject, nil
 }
 
+// ParseTextTemplate returns an error if the passed plain text template content cannot be parsed.
+func (m *Manager) ParseTextTemplate(content string) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if _, err := template.New(TmplContent).Funcs(m.funcMap).Parse(content); err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+	return nil
+}
+
+// RenderTextTemplate renders the passed plain text template content with data, it is not wrapped in any base template.
+func (m *Manager) RenderTextTemplate(content string, data any) (string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	tmpl, err := template.New(TmplContent).Funcs(m.funcMap).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("parsing template: %w", err)
+	}
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("executing template: %w", err)
+	}
+	return rendered.String(), nil
+}
+
+// ParseHTMLTemplate returns an error if the passed HTML template content cannot be parsed.
+func (m *Manager) ParseHTMLTemplate(content string) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if _, err := htmltemplate.New(TmplContent).Funcs(m.funcMap).Parse(content); err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+	return nil
+}
+
+// RenderHTMLTemplate renders the passed HTML template content with data, values are escaped for the HTML context
+// they are used in. It is not wrapped in any base template.
+func (m *Manager) RenderHTMLTemplate(content string, data any) (string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	tmpl, err := htmltemplate.New(TmplContent).Funcs(m.funcMap).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("parsing template: %w", err)
+	}
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("executing template: %w", err)
+	}
+	return rendered.String(), nil
+}
+
 // RenderInMemoryTemplate executes an in-memory template with data and returns the rendered content.
 // This is for system emails like reset password and welcome email etc.
 func (m *Manager) RenderInMemoryTemplate(name string, data interface{}) (string, error) {
@@ -1,6 +1,8 @@
 package user
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -41,6 +43,19 @@ func (u *Manager) GetContact(id int, email string) (models.User, error) {
 	return u.Get(id, email, models.UserTypeContact)
 }
 
+// GetContactByCustomAttribute retrieves the most recently updated contact whose custom attribute `key` equals `value`.
+func (u *Manager) GetContactByCustomAttribute(key, value string) (models.User, error) {
+	var id int
+	if err := u.q.GetContactIDByAttr.Get(&id, key, value); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, envelope.NewError(envelope.NotFoundError, u.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.contact}"), nil)
+		}
+		u.lo.Error("error fetching contact by custom attribute", "key", key, "error", err)
+		return models.User{}, envelope.NewError(envelope.GeneralError, u.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.contact}"), nil)
+	}
+	return u.GetContact(id, "")
+}
+
 // GetAllContacts returns a list of all contacts.
 func (u *Manager) GetContacts(page, pageSize int, order, orderBy string, filtersJSON string) ([]models.UserCompact, error) {
 	if pageSize > maxListPageSize {
@@ -108,18 +108,37 @@ SET availability_status = $2
 WHERE id = $1;
 
 -- name: update-last-active-at
-UPDATE users
-SET last_active_at = now(),
-availability_status = CASE WHEN availability_status = 'offline' THEN 'online' ELSE availability_status END
-WHERE id = $1;
+-- Agents that were offline come back online, which is recorded in the activity log.
+WITH prev AS (
+    SELECT id, availability_status FROM users WHERE id = $1
+),
+updated AS (
+    UPDATE users
+    SET last_active_at = now(),
+    availability_status = CASE WHEN availability_status = 'offline' THEN 'online' ELSE availability_status END
+    WHERE id = $1
+    RETURNING id, email, type
+)
+INSERT INTO activity_logs (activity_type, activity_description, actor_id, target_model_type, target_model_id)
+SELECT 'agent_online', CONCAT(u.email, ' (#', u.id, ') is online'), u.id, 'user', u.id
+FROM updated u
+INNER JOIN prev p ON p.id = u.id
+WHERE u.type = 'agent' AND p.availability_status = 'offline';
 
 -- name: update-inactive-offline
-UPDATE users
-SET availability_status = 'offline'
-WHERE 
-type = 'agent' 
-AND (last_active_at IS NULL OR last_active_at < NOW() - INTERVAL '5 minutes')
-AND availability_status NOT IN ('offline', 'away_and_reassigning', 'away_manual');
+-- Agents set offline are recorded in the activity log.
+WITH offline AS (
+    UPDATE users
+    SET availability_status = 'offline'
+    WHERE 
+    type = 'agent' 
+    AND (last_active_at IS NULL OR last_active_at < NOW() - INTERVAL '5 minutes')
+    AND availability_status NOT IN ('offline', 'away_and_reassigning', 'away_manual')
+    RETURNING id, email
+)
+INSERT INTO activity_logs (activity_type, activity_description, actor_id, target_model_type, target_model_id)
+SELECT 'agent_offline', CONCAT(email, ' (#', id, ') went offline after inactivity'), id, 'user', id
+FROM offline;
 
 -- name: set-reset-password-token
 UPDATE users
@@ -267,4 +286,12 @@ WHERE id = $1;
 -- name: update-api-key-last-used
 UPDATE users 
 SET api_key_last_used_at = now()
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: get-contact-id-by-custom-attribute
+-- Containment is used so the lookup can use index_users_on_custom_attributes, the attribute must hold a string value.
+SELECT id
+FROM users
+WHERE type = 'contact' AND deleted_at IS NULL AND custom_attributes @> jsonb_build_object($1::TEXT, $2::TEXT)
+ORDER BY updated_at DESC
+LIMIT 1;
@@ -82,6 +82,7 @@ type queries struct {
 	InsertContact          *sqlx.Stmt `query:"insert-contact"`
 	InsertNote             *sqlx.Stmt `query:"insert-note"`
 	ToggleEnable           *sqlx.Stmt `query:"toggle-enable"`
+	GetContactIDByAttr     *sqlx.Stmt `query:"get-contact-id-by-custom-attribute"`
 	// API key queries
 	GetUserByAPIKey      *sqlx.Stmt `query:"get-user-by-api-key"`
 	SetAPIKey            *sqlx.Stmt `query:"set-api-key"`
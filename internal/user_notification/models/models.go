@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/volatiletech/null/v9"
+)
+
+// Notification types.
+const (
+	TypeAssigned   = "assigned"
+	TypeMention    = "mention"
+	TypeSLAWarning = "sla_warning"
+	TypeSLABreach  = "sla_breach"
+	TypeNewReply   = "new_reply"
+)
+
+// Types is the list of all notification types.
+var Types = []string{TypeAssigned, TypeMention, TypeSLAWarning, TypeSLABreach, TypeNewReply}
+
+// Notification is an in-app notification of an agent.
+type Notification struct {
+	ID                          int64       `db:"id" json:"id"`
+	CreatedAt                   time.Time   `db:"created_at" json:"created_at"`
+	UserID                      int         `db:"user_id" json:"user_id"`
+	Type                        string      `db:"type" json:"type"`
+	Title                       string      `db:"title" json:"title"`
+	Body                        string      `db:"body" json:"body"`
+	ConversationID              null.Int    `db:"conversation_id" json:"conversation_id"`
+	ConversationUUID            null.String `db:"conversation_uuid" json:"conversation_uuid"`
+	ConversationReferenceNumber null.String `db:"conversation_reference_number" json:"conversation_reference_number"`
+	MessageID                   null.Int    `db:"message_id" json:"message_id"`
+	ActorID                     null.Int    `db:"actor_id" json:"actor_id"`
+	ActorFirstName              null.String `db:"actor_first_name" json:"actor_first_name"`
+	ActorLastName               null.String `db:"actor_last_name" json:"actor_last_name"`
+	ReadAt                      null.Time   `db:"read_at" json:"read_at"`
+
+	// EmailSubject and EmailContent are sent when the agent gets the notification type by email,
+	// the title and body are sent if not set.
+	EmailSubject string `db:"-" json:"-"`
+	EmailContent string `db:"-" json:"-"`
+
+	Total int `db:"total" json:"-"`
+}
+
+// Preference is the channels an agent gets a notification type on.
+type Preference struct {
+	Type  string `db:"type" json:"type"`
+	InApp bool   `db:"in_app" json:"in_app"`
+	Email bool   `db:"email" json:"email"`
+	Push  bool   `db:"push" json:"push"`
+}
+
+// PushSubscription is a browser's Web Push subscription of an agent.
+type PushSubscription struct {
+	ID        int       `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	UserID    int       `db:"user_id" json:"user_id"`
+	Endpoint  string    `db:"endpoint" json:"endpoint"`
+	P256dh    string    `db:"p256dh" json:"-"`
+	Auth      string    `db:"auth" json:"-"`
+	UserAgent string    `db:"user_agent" json:"user_agent"`
+}
@@ -0,0 +1,81 @@
+package usernotification
+
+import (
+	"fmt"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/user_notification/models"
+)
+
+// SavePushSubscription saves a browser's push subscription for an agent, a browser subscribing again replaces its subscription.
+func (m *Manager) SavePushSubscription(userID int, endpoint, p256dh, auth, userAgent string) (models.PushSubscription, error) {
+	var sub models.PushSubscription
+	if err := m.q.UpsertPushSubscription.Get(&sub, userID, endpoint, p256dh, auth, userAgent); err != nil {
+		m.lo.Error("error saving push subscription", "user_id", userID, "error", err)
+		return sub, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", "{notification.push.subscription}"), nil)
+	}
+	return sub, nil
+}
+
+// GetPushSubscriptions returns the push subscriptions of an agent.
+func (m *Manager) GetPushSubscriptions(userID int) ([]models.PushSubscription, error) {
+	var subs = make([]models.PushSubscription, 0)
+	if err := m.q.GetPushSubscriptions.Select(&subs, userID); err != nil {
+		m.lo.Error("error fetching push subscriptions", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{notification.push.subscription}"), nil)
+	}
+	return subs, nil
+}
+
+// DeleteUserPushSubscription deletes a push subscription of an agent.
+func (m *Manager) DeleteUserPushSubscription(userID int, endpoint string) error {
+	if _, err := m.q.DeleteUserPushSubscription.Exec(userID, endpoint); err != nil {
+		m.lo.Error("error deleting push subscription", "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorDeleting", "name", "{notification.push.subscription}"), nil)
+	}
+	return nil
+}
+
+// DeletePushSubscription deletes a push subscription by its endpoint, the push service reports
+// subscriptions that expired or the browser unsubscribed as gone.
+func (m *Manager) DeletePushSubscription(endpoint string) error {
+	if _, err := m.q.DeletePushSubscription.Exec(endpoint); err != nil {
+		m.lo.Error("error deleting push subscription", "error", err)
+		return err
+	}
+	return nil
+}
+
+// sendPush pushes the notification to the browsers of the agent through the notifier's worker pool.
+func (m *Manager) sendPush(n models.Notification) error {
+	var subs []models.PushSubscription
+	if err := m.q.GetPushSubscriptions.Select(&subs, n.UserID); err != nil {
+		return fmt.Errorf("fetching push subscriptions: %w", err)
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	msg := notifier.Message{
+		Subject:     n.Title,
+		Content:     n.Body,
+		ContentType: "plain",
+		Provider:    notifier.ProviderWebPush,
+	}
+	if n.ConversationUUID.String != "" {
+		msg.Link = "/inboxes/assigned/conversation/" + n.ConversationUUID.String
+	}
+	for _, sub := range subs {
+		msg.PushSubscriptions = append(msg.PushSubscriptions, notifier.PushSubscription{
+			Endpoint: sub.Endpoint,
+			P256dh:   sub.P256dh,
+			Auth:     sub.Auth,
+		})
+	}
+
+	if err := m.notifier.Send(msg); err != nil {
+		return fmt.Errorf("sending push notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+-- name: insert-notification
+WITH n AS (
+    INSERT INTO user_notifications (user_id, "type", title, body, conversation_id, message_id, actor_id)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING *
+)
+SELECT n.id, n.created_at, n.user_id, n."type", n.title, n.body, n.conversation_id, c.uuid AS conversation_uuid,
+    c.reference_number AS conversation_reference_number, n.message_id, n.actor_id, a.first_name AS actor_first_name,
+    a.last_name AS actor_last_name, n.read_at
+FROM n
+LEFT JOIN conversations c ON c.id = n.conversation_id
+LEFT JOIN users a ON a.id = n.actor_id;
+
+-- name: get-notifications
+SELECT COUNT(*) OVER() AS total, n.id, n.created_at, n.user_id, n."type", n.title, n.body, n.conversation_id, c.uuid AS conversation_uuid,
+    c.reference_number AS conversation_reference_number, n.message_id, n.actor_id, a.first_name AS actor_first_name,
+    a.last_name AS actor_last_name, n.read_at
+FROM user_notifications n
+LEFT JOIN conversations c ON c.id = n.conversation_id
+LEFT JOIN users a ON a.id = n.actor_id
+WHERE n.user_id = $1 AND ($2 = FALSE OR n.read_at IS NULL)
+ORDER BY n.created_at DESC, n.id DESC
+LIMIT $3 OFFSET $4;
+
+-- name: get-unread-count
+SELECT COUNT(*) FROM user_notifications WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: mark-read
+UPDATE user_notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = $1 AND user_id = $2
+RETURNING id;
+
+-- name: mark-all-read
+UPDATE user_notifications
+SET read_at = NOW()
+WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: get-preferences
+SELECT "type", in_app, email, push FROM user_notification_preferences WHERE user_id = $1;
+
+-- name: upsert-preference
+INSERT INTO user_notification_preferences (user_id, "type", in_app, email, push)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, "type") DO UPDATE
+SET in_app = EXCLUDED.in_app, email = EXCLUDED.email, push = EXCLUDED.push, updated_at = NOW();
+
+-- name: upsert-push-subscription
+INSERT INTO user_push_subscriptions (user_id, endpoint, p256dh, auth, user_agent)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (endpoint) DO UPDATE
+SET user_id = EXCLUDED.user_id, p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth,
+    user_agent = EXCLUDED.user_agent, updated_at = NOW()
+RETURNING *;
+
+-- name: get-push-subscriptions
+SELECT * FROM user_push_subscriptions WHERE user_id = $1 ORDER BY id;
+
+-- name: delete-push-subscription
+DELETE FROM user_push_subscriptions WHERE endpoint = $1;
+
+-- name: delete-user-push-subscription
+DELETE FROM user_push_subscriptions WHERE user_id = $1 AND endpoint = $2;
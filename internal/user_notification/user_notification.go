@@ -0,0 +1,300 @@
+// Package usernotification manages in-app notifications of agents and the channels agents get them on.
+package usernotification
+
+import (
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	"slices"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/abhinavxd/libredesk/internal/user_notification/models"
+	wsmodels "github.com/abhinavxd/libredesk/internal/ws/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/go-i18n"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+
+	// defaultPreferences are the channels of notification types an agent has not set preferences for.
+	defaultPreferences = map[string]models.Preference{
+		models.TypeAssigned:   {Type: models.TypeAssigned, InApp: true, Email: true, Push: true},
+		models.TypeMention:    {Type: models.TypeMention, InApp: true, Email: true, Push: true},
+		models.TypeSLAWarning: {Type: models.TypeSLAWarning, InApp: true, Email: true, Push: false},
+		models.TypeSLABreach:  {Type: models.TypeSLABreach, InApp: true, Email: true, Push: true},
+		models.TypeNewReply:   {Type: models.TypeNewReply, InApp: true, Email: false, Push: false},
+	}
+)
+
+const (
+	maxPageSize = 100
+
+	// emailContent is the content of notification emails for notifications without an email of their own.
+	emailContent = `<p>{{ .Title }}</p>
+{{ if .Body }}<p>{{ .Body }}</p>{{ end }}
+{{ if .ConversationUUID }}<p><a href="{{ RootURL }}/inboxes/assigned/conversation/{{ .ConversationUUID }}">View Conversation</a></p>{{ end }}`
+)
+
+type Manager struct {
+	q             queries
+	lo            *logf.Logger
+	i18n          *i18n.I18n
+	wsHub         wsHub
+	notifier      *notifier.Service
+	templateStore templateStore
+	userStore     userStore
+}
+
+type wsHub interface {
+	BroadcastMessage(msg wsmodels.BroadcastMessage)
+}
+
+type templateStore interface {
+	RenderEmailWithTemplate(data any, content string) (string, error)
+}
+
+type userStore interface {
+	GetAgent(int, string) (umodels.User, error)
+}
+
+// Opts contains options for initializing the Manager.
+type Opts struct {
+	DB   *sqlx.DB
+	Lo   *logf.Logger
+	I18n *i18n.I18n
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	InsertNotification *sqlx.Stmt `query:"insert-notification"`
+	GetNotifications   *sqlx.Stmt `query:"get-notifications"`
+	GetUnreadCount     *sqlx.Stmt `query:"get-unread-count"`
+	MarkRead           *sqlx.Stmt `query:"mark-read"`
+	MarkAllRead        *sqlx.Stmt `query:"mark-all-read"`
+	GetPreferences     *sqlx.Stmt `query:"get-preferences"`
+	UpsertPreference   *sqlx.Stmt `query:"upsert-preference"`
+
+	UpsertPushSubscription     *sqlx.Stmt `query:"upsert-push-subscription"`
+	GetPushSubscriptions       *sqlx.Stmt `query:"get-push-subscriptions"`
+	DeletePushSubscription     *sqlx.Stmt `query:"delete-push-subscription"`
+	DeleteUserPushSubscription *sqlx.Stmt `query:"delete-user-push-subscription"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(wsHub wsHub, notifier *notifier.Service, templateStore templateStore, userStore userStore, opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:             q,
+		lo:            opts.Lo,
+		i18n:          opts.I18n,
+		wsHub:         wsHub,
+		notifier:      notifier,
+		templateStore: templateStore,
+		userStore:     userStore,
+	}, nil
+}
+
+// Notify notifies an agent on the channels the agent gets the notification type on.
+// In-app notifications are saved and pushed to the agent's open sessions, push notifications
+// are sent to the browsers the agent subscribed.
+func (m *Manager) Notify(n models.Notification) error {
+	pref, err := m.getPreference(n.UserID, n.Type)
+	if err != nil {
+		return err
+	}
+
+	if pref.InApp {
+		var created models.Notification
+		if err := m.q.InsertNotification.Get(&created, n.UserID, n.Type, n.Title, n.Body, n.ConversationID, n.MessageID, n.ActorID); err != nil {
+			return fmt.Errorf("inserting notification: %w", err)
+		}
+		m.broadcast(n.UserID, wsmodels.MessageTypeNotification, created)
+		n.ConversationUUID = created.ConversationUUID
+	}
+
+	if pref.Email {
+		if err := m.sendEmail(n); err != nil {
+			return err
+		}
+	}
+
+	if pref.Push {
+		if err := m.sendPush(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAll returns the notifications of an agent, newest first, along with the total count.
+func (m *Manager) GetAll(userID int, unreadOnly bool, page, pageSize int) ([]models.Notification, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	var notifications = make([]models.Notification, 0)
+	if err := m.q.GetNotifications.Select(&notifications, userID, unreadOnly, pageSize, (page-1)*pageSize); err != nil {
+		m.lo.Error("error fetching notifications", "user_id", userID, "error", err)
+		return nil, 0, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.notification}"), nil)
+	}
+	var total int
+	if len(notifications) > 0 {
+		total = notifications[0].Total
+	}
+	return notifications, total, nil
+}
+
+// UnreadCount returns the number of unread notifications of an agent.
+func (m *Manager) UnreadCount(userID int) (int, error) {
+	var count int
+	if err := m.q.GetUnreadCount.Get(&count, userID); err != nil {
+		m.lo.Error("error fetching unread notification count", "user_id", userID, "error", err)
+		return 0, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.notification}"), nil)
+	}
+	return count, nil
+}
+
+// MarkRead marks a notification of an agent as read.
+func (m *Manager) MarkRead(userID int, id int64) error {
+	if err := m.q.MarkRead.Get(&id, id, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.notification}"), nil)
+		}
+		m.lo.Error("error marking notification as read", "id", id, "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.notification}"), nil)
+	}
+	m.broadcast(userID, wsmodels.MessageTypeNotificationRead, map[string]any{"id": id})
+	return nil
+}
+
+// MarkAllRead marks all notifications of an agent as read.
+func (m *Manager) MarkAllRead(userID int) error {
+	if _, err := m.q.MarkAllRead.Exec(userID); err != nil {
+		m.lo.Error("error marking all notifications as read", "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.notification}"), nil)
+	}
+	m.broadcast(userID, wsmodels.MessageTypeNotificationRead, map[string]any{"all": true})
+	return nil
+}
+
+// GetPreferences returns the channels an agent gets each notification type on.
+func (m *Manager) GetPreferences(userID int) ([]models.Preference, error) {
+	prefs, err := m.getPreferences(userID)
+	if err != nil {
+		m.lo.Error("error fetching notification preferences", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.notification}"), nil)
+	}
+	var out = make([]models.Preference, 0, len(models.Types))
+	for _, typ := range models.Types {
+		out = append(out, prefs[typ])
+	}
+	return out, nil
+}
+
+// UpdatePreferences sets the channels an agent gets notification types on.
+func (m *Manager) UpdatePreferences(userID int, prefs []models.Preference) error {
+	for _, pref := range prefs {
+		if !slices.Contains(models.Types, pref.Type) {
+			return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "`type`"), nil)
+		}
+	}
+	for _, pref := range prefs {
+		if _, err := m.q.UpsertPreference.Exec(userID, pref.Type, pref.InApp, pref.Email, pref.Push); err != nil {
+			m.lo.Error("error updating notification preference", "user_id", userID, "type", pref.Type, "error", err)
+			return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.notification}"), nil)
+		}
+	}
+	return nil
+}
+
+// getPreference returns the channels an agent gets a notification type on.
+func (m *Manager) getPreference(userID int, typ string) (models.Preference, error) {
+	prefs, err := m.getPreferences(userID)
+	if err != nil {
+		return models.Preference{}, fmt.Errorf("fetching notification preferences: %w", err)
+	}
+	pref, ok := prefs[typ]
+	if !ok {
+		return models.Preference{}, fmt.Errorf("unknown notification type %s", typ)
+	}
+	return pref, nil
+}
+
+// getPreferences returns the preferences of an agent by notification type, with defaults for types the agent has not set.
+func (m *Manager) getPreferences(userID int) (map[string]models.Preference, error) {
+	var stored []models.Preference
+	if err := m.q.GetPreferences.Select(&stored, userID); err != nil {
+		return nil, err
+	}
+	prefs := make(map[string]models.Preference, len(defaultPreferences))
+	for typ, pref := range defaultPreferences {
+		prefs[typ] = pref
+	}
+	for _, pref := range stored {
+		prefs[pref.Type] = pref
+	}
+	return prefs, nil
+}
+
+// sendEmail emails the notification to the agent.
+func (m *Manager) sendEmail(n models.Notification) error {
+	agent, err := m.userStore.GetAgent(n.UserID, "")
+	if err != nil {
+		return fmt.Errorf("fetching agent: %w", err)
+	}
+	if agent.Email.String == "" {
+		return nil
+	}
+
+	subject, content := n.EmailSubject, n.EmailContent
+	if content == "" {
+		subject = n.Title
+		content, err = m.templateStore.RenderEmailWithTemplate(map[string]any{
+			"Title":            html.EscapeString(n.Title),
+			"Body":             html.EscapeString(n.Body),
+			"ConversationUUID": n.ConversationUUID.String,
+		}, emailContent)
+		if err != nil {
+			return fmt.Errorf("rendering notification email: %w", err)
+		}
+	}
+
+	if err := m.notifier.Send(notifier.Message{
+		RecipientEmails: []string{agent.Email.String},
+		Subject:         subject,
+		Content:         content,
+		Provider:        notifier.ProviderEmail,
+	}); err != nil {
+		return fmt.Errorf("sending notification email: %w", err)
+	}
+	return nil
+}
+
+// broadcast pushes a message to the open sessions of an agent.
+func (m *Manager) broadcast(userID int, typ string, data any) {
+	b, err := json.Marshal(wsmodels.Message{
+		Type: typ,
+		Data: data,
+	})
+	if err != nil {
+		m.lo.Error("error marshalling WS message", "error", err)
+		return
+	}
+	m.wsHub.BroadcastMessage(wsmodels.BroadcastMessage{
+		Data:  b,
+		Users: []int{userID},
+	})
+}
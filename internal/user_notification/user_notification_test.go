@@ -0,0 +1,213 @@
+package usernotification
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/abhinavxd/libredesk/internal/user_notification/models"
+	wsmodels "github.com/abhinavxd/libredesk/internal/ws/models"
+	"github.com/knadh/go-i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodha/logf"
+)
+
+type fakeHub struct {
+	messages []wsmodels.BroadcastMessage
+}
+
+func (h *fakeHub) BroadcastMessage(msg wsmodels.BroadcastMessage) {
+	h.messages = append(h.messages, msg)
+}
+
+// types returns the types of the messages broadcast.
+func (h *fakeHub) types(t *testing.T) []string {
+	var out []string
+	for _, msg := range h.messages {
+		var m wsmodels.Message
+		require.NoError(t, json.Unmarshal(msg.Data, &m))
+		out = append(out, m.Type)
+	}
+	return out
+}
+
+type fakeUserStore struct{}
+
+func (fakeUserStore) GetAgent(id int, _ string) (umodels.User, error) {
+	return umodels.User{ID: id}, nil
+}
+
+var notificationColumns = []string{"id", "created_at", "user_id", "type", "title", "body", "conversation_id", "conversation_uuid"}
+
+func newTestManager(t *testing.T, handler dbtest.Handler) (*Manager, *dbtest.DB, *fakeHub) {
+	t.Helper()
+	db := dbtest.New(handler)
+	i18n, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	require.NoError(t, err)
+	lo := logf.New(logf.Opts{})
+	hub := &fakeHub{}
+	m, err := New(hub, nil, nil, fakeUserStore{}, Opts{DB: db.DB, Lo: &lo, I18n: i18n})
+	require.NoError(t, err)
+	return m, db, hub
+}
+
+// preferences answers the preference query with the stored preference of a type.
+func preferences(query string, pref *models.Preference) (dbtest.Result, bool) {
+	if !strings.Contains(query, "FROM user_notification_preferences") {
+		return dbtest.Result{}, false
+	}
+	r := dbtest.Result{Columns: []string{"type", "in_app", "email", "push"}}
+	if pref != nil {
+		r.Rows = [][]any{{pref.Type, pref.InApp, pref.Email, pref.Push}}
+	}
+	return r, true
+}
+
+func TestNotify(t *testing.T) {
+	tests := []struct {
+		name       string
+		pref       *models.Preference
+		typ        string
+		wantInApp  bool
+		wantPushed bool
+	}{
+		// New replies are in-app only by default.
+		{"default preference", nil, models.TypeNewReply, true, false},
+		{"in-app and push", &models.Preference{Type: models.TypeMention, InApp: true, Push: true}, models.TypeMention, true, true},
+		{"all channels off", &models.Preference{Type: models.TypeMention}, models.TypeMention, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, db, hub := newTestManager(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+				if r, ok := preferences(query, tt.pref); ok {
+					return r, nil
+				}
+				if strings.Contains(query, "INSERT INTO user_notifications") {
+					return dbtest.Result{Columns: notificationColumns, Rows: [][]any{{1, time.Now(), args[0], args[1], args[2], args[3], nil, nil}}}, nil
+				}
+				// The agent has no push subscriptions.
+				return dbtest.Result{Columns: []string{"id"}}, nil
+			})
+
+			err := m.Notify(models.Notification{UserID: 5, Type: tt.typ, Title: "Mentioned", Body: "Note"})
+			require.NoError(t, err)
+
+			inserts := db.Queries("INSERT INTO user_notifications")
+			if !tt.wantInApp {
+				assert.Empty(t, inserts)
+				assert.Empty(t, hub.messages)
+			} else {
+				require.Len(t, inserts, 1)
+				assert.Equal(t, []driver.Value{int64(5), tt.typ, "Mentioned", "Note"}, inserts[0].Args[:4])
+				assert.Equal(t, []string{wsmodels.MessageTypeNotification}, hub.types(t))
+				assert.Equal(t, []int{5}, hub.messages[0].Users)
+			}
+			assert.Equal(t, tt.wantPushed, len(db.Queries("FROM user_push_subscriptions")) == 1)
+		})
+	}
+}
+
+func TestNotifyUnknownType(t *testing.T) {
+	m, db, _ := newTestManager(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+		r, _ := preferences(query, nil)
+		return r, nil
+	})
+	assert.Error(t, m.Notify(models.Notification{UserID: 5, Type: "unknown"}))
+	assert.Empty(t, db.Queries("INSERT INTO user_notifications"))
+}
+
+func TestGetAll(t *testing.T) {
+	m, db, _ := newTestManager(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+		columns := append([]string{"total"}, notificationColumns...)
+		return dbtest.Result{Columns: columns, Rows: [][]any{
+			{3, 2, time.Now(), 5, models.TypeMention, "b", "", nil, nil},
+			{3, 1, time.Now(), 5, models.TypeMention, "a", "", nil, nil},
+		}}, nil
+	})
+
+	notifications, total, err := m.GetAll(5, true, 0, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, notifications, 2)
+	assert.Equal(t, int64(2), notifications[0].ID)
+
+	// Pages start at 1 and page sizes are capped.
+	queries := db.Queries("FROM user_notifications")
+	require.Len(t, queries, 1)
+	assert.Equal(t, []driver.Value{int64(5), true, int64(maxPageSize), int64(0)}, queries[0].Args)
+
+	_, _, err = m.GetAll(5, false, 3, 10)
+	require.NoError(t, err)
+	queries = db.Queries("FROM user_notifications")
+	assert.Equal(t, []driver.Value{int64(5), false, int64(10), int64(20)}, queries[1].Args)
+}
+
+func TestGetAllEmpty(t *testing.T) {
+	m, _, _ := newTestManager(t, nil)
+	notifications, total, err := m.GetAll(5, false, 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, notifications)
+	assert.Zero(t, total)
+}
+
+func TestMarkRead(t *testing.T) {
+	m, db, hub := newTestManager(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+		// Only notification 7 belongs to the agent.
+		if args[0] == int64(7) {
+			return dbtest.Rows("id", 7), nil
+		}
+		return dbtest.Rows("id"), nil
+	})
+
+	require.NoError(t, m.MarkRead(5, 7))
+	assert.Equal(t, []driver.Value{int64(7), int64(5)}, db.Queries("UPDATE user_notifications")[0].Args)
+	assert.Equal(t, []string{wsmodels.MessageTypeNotificationRead}, hub.types(t))
+
+	err := m.MarkRead(5, 8)
+	var eerr envelope.Error
+	require.True(t, errors.As(err, &eerr))
+	assert.Equal(t, envelope.NotFoundError, eerr.ErrorType)
+	assert.Len(t, hub.messages, 1)
+}
+
+func TestMarkAllRead(t *testing.T) {
+	m, db, hub := newTestManager(t, nil)
+	require.NoError(t, m.MarkAllRead(5))
+	assert.Len(t, db.Queries("read_at IS NULL"), 1)
+	assert.Equal(t, []string{wsmodels.MessageTypeNotificationRead}, hub.types(t))
+}
+
+func TestPreferences(t *testing.T) {
+	stored := &models.Preference{Type: models.TypeNewReply, InApp: false, Email: true}
+	m, db, _ := newTestManager(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+		r, _ := preferences(query, stored)
+		return r, nil
+	})
+
+	prefs, err := m.GetPreferences(5)
+	require.NoError(t, err)
+	require.Len(t, prefs, len(models.Types))
+	for i, typ := range models.Types {
+		assert.Equal(t, typ, prefs[i].Type)
+	}
+	// Stored preferences replace the defaults.
+	assert.Equal(t, *stored, prefs[4])
+	assert.Equal(t, defaultPreferences[models.TypeMention], prefs[1])
+
+	// Unknown types are rejected before any preference is saved.
+	err = m.UpdatePreferences(5, []models.Preference{{Type: models.TypeMention, InApp: true}, {Type: "unknown"}})
+	assert.Error(t, err)
+	assert.Empty(t, db.Queries("INSERT INTO user_notification_preferences"))
+
+	require.NoError(t, m.UpdatePreferences(5, []models.Preference{{Type: models.TypeMention, InApp: true, Push: true}}))
+	upserts := db.Queries("INSERT INTO user_notification_preferences")
+	require.Len(t, upserts, 1)
+	assert.Equal(t, []driver.Value{int64(5), models.TypeMention, true, false, true}, upserts[0].Args)
+}
@@ -0,0 +1,306 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	mmodels "github.com/abhinavxd/libredesk/internal/media/models"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/abhinavxd/libredesk/internal/webhook/models"
+	guuid "github.com/google/uuid"
+)
+
+type conversationStore interface {
+	GetConversation(id int, uuid string) (cmodels.Conversation, error)
+	GetContactPreviousConversations(contactID int, limit int) ([]cmodels.PreviousConversation, error)
+	SendPrivateNote(media []mmodels.Media, senderID int, conversationUUID, content string) (cmodels.Message, error)
+	SetConversationTags(uuid string, action string, tagNames []string, actor umodels.User) error
+	UpdateConversationStatus(uuid string, statusID int, status, snoozeDur string, actor umodels.User) error
+}
+
+type userStore interface {
+	GetContact(id int, email string) (umodels.User, error)
+	GetContactByCustomAttribute(key, value string) (umodels.User, error)
+	GetSystemUser() (umodels.User, error)
+}
+
+// SetConversationStore sets the conversation store used by inbound webhooks.
+func (m *Manager) SetConversationStore(store conversationStore) {
+	m.conversationStore = store
+}
+
+// SetUserStore sets the user store used by inbound webhooks to look up contacts.
+func (m *Manager) SetUserStore(store userStore) {
+	m.userStore = store
+}
+
+// GetAllInbound retrieves all inbound webhooks.
+func (m *Manager) GetAllInbound() ([]models.InboundWebhook, error) {
+	var webhooks = make([]models.InboundWebhook, 0)
+	if err := m.q.GetAllInboundWebhooks.Select(&webhooks); err != nil {
+		m.lo.Error("error fetching inbound webhooks", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.webhook}"), nil)
+	}
+	return webhooks, nil
+}
+
+// GetInbound retrieves an inbound webhook by ID or UUID.
+func (m *Manager) GetInbound(id int, uuid string) (models.InboundWebhook, error) {
+	var (
+		webhook   models.InboundWebhook
+		uuidParam any
+	)
+	if uuid != "" {
+		if err := guuid.Validate(uuid); err != nil {
+			return webhook, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.webhook}"), nil)
+		}
+		uuidParam = uuid
+	}
+	if err := m.q.GetInboundWebhook.Get(&webhook, id, uuidParam); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhook, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.webhook}"), nil)
+		}
+		m.lo.Error("error fetching inbound webhook", "error", err)
+		return webhook, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorFetching", "name", "{globals.terms.webhook}"), nil)
+	}
+	return webhook, nil
+}
+
+// CreateInbound creates a new inbound webhook.
+func (m *Manager) CreateInbound(webhook models.InboundWebhook) (models.InboundWebhook, error) {
+	var result models.InboundWebhook
+	if err := m.q.InsertInboundWebhook.Get(&result, webhook.Name, webhook.Action, webhook.ContactLookup, webhook.Mapping, webhook.Secret, webhook.IsActive); err != nil {
+		m.lo.Error("error inserting inbound webhook", "error", err)
+		return models.InboundWebhook{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorCreating", "name", "{globals.terms.webhook}"), nil)
+	}
+	return result, nil
+}
+
+// UpdateInbound updates an inbound webhook by ID.
+func (m *Manager) UpdateInbound(id int, webhook models.InboundWebhook) (models.InboundWebhook, error) {
+	var result models.InboundWebhook
+	if err := m.q.UpdateInboundWebhook.Get(&result, id, webhook.Name, webhook.Action, webhook.ContactLookup, webhook.Mapping, webhook.Secret, webhook.IsActive); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.InboundWebhook{}, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.webhook}"), nil)
+		}
+		m.lo.Error("error updating inbound webhook", "error", err)
+		return models.InboundWebhook{}, envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorUpdating", "name", "{globals.terms.webhook}"), nil)
+	}
+	return result, nil
+}
+
+// DeleteInbound deletes an inbound webhook by ID.
+func (m *Manager) DeleteInbound(id int) error {
+	if _, err := m.q.DeleteInboundWebhook.Exec(id); err != nil {
+		m.lo.Error("error deleting inbound webhook", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.Ts("globals.messages.errorDeleting", "name", "{globals.terms.webhook}"), nil)
+	}
+	return nil
+}
+
+// ProcessInbound verifies the signature of an inbound webhook payload and applies the configured
+// action to the conversation of the contact resolved from the payload.
+func (m *Manager) ProcessInbound(uuid string, body []byte, signature string) (models.InboundResult, error) {
+	webhook, err := m.GetInbound(0, uuid)
+	if err != nil {
+		return models.InboundResult{}, err
+	}
+	if !webhook.IsActive {
+		return models.InboundResult{}, envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.webhook}"), nil)
+	}
+
+	// Verify the payload signature.
+	expected := m.generateSignature(body, webhook.Secret)
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature))) {
+		m.lo.Warn("inbound webhook signature mismatch", "webhook_id", webhook.ID)
+		return models.InboundResult{}, envelope.NewError(envelope.UnauthorizedError, m.i18n.Ts("globals.messages.invalid", "name", "{globals.terms.signature}"), nil)
+	}
+
+	var payload any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err != nil {
+		return models.InboundResult{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.errorParsing", "name", "{globals.terms.request}"), nil)
+	}
+
+	contact, err := m.resolveInboundContact(webhook, payload)
+	if err != nil {
+		return models.InboundResult{}, err
+	}
+
+	conversationUUID, err := m.resolveInboundConversation(webhook, payload, contact)
+	if err != nil {
+		return models.InboundResult{}, err
+	}
+
+	actor, err := m.userStore.GetSystemUser()
+	if err != nil {
+		return models.InboundResult{}, err
+	}
+
+	var mapping = webhook.Mapping
+	switch webhook.Action {
+	case models.InboundActionSendPrivateNote:
+		content := payloadString(payload, mapping.ContentField, mapping.Content)
+		if content == "" {
+			return models.InboundResult{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.empty", "name", "{globals.terms.content}"), nil)
+		}
+		if _, err := m.conversationStore.SendPrivateNote([]mmodels.Media{}, actor.ID, conversationUUID, content); err != nil {
+			return models.InboundResult{}, err
+		}
+	case models.InboundActionAddTags, models.InboundActionSetTags, models.InboundActionRemoveTags:
+		tags := payloadStrings(payload, mapping.TagsField, mapping.Tags)
+		if len(tags) == 0 && webhook.Action != models.InboundActionSetTags {
+			return models.InboundResult{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.empty", "name", "{globals.terms.tag}"), nil)
+		}
+		// Inbound actions share names with the automation tag actions.
+		if err := m.conversationStore.SetConversationTags(conversationUUID, webhook.Action, tags, actor); err != nil {
+			return models.InboundResult{}, err
+		}
+	case models.InboundActionSetStatus:
+		status := payloadString(payload, mapping.StatusField, mapping.Status)
+		if status == "" {
+			return models.InboundResult{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.empty", "name", "{globals.terms.status}"), nil)
+		}
+		// Snoozing needs a duration, the conversation store rejects a missing or invalid one.
+		snoozeDur := payloadString(payload, mapping.SnoozeDurationField, mapping.SnoozeDuration)
+		if err := m.conversationStore.UpdateConversationStatus(conversationUUID, 0, status, snoozeDur, actor); err != nil {
+			return models.InboundResult{}, err
+		}
+	default:
+		return models.InboundResult{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.invalid", "name", "{globals.terms.action}"), nil)
+	}
+
+	m.lo.Info("inbound webhook processed", "webhook_id", webhook.ID, "action", webhook.Action, "contact_id", contact.ID, "conversation_uuid", conversationUUID)
+
+	return models.InboundResult{
+		Action:           webhook.Action,
+		ContactID:        contact.ID,
+		ConversationUUID: conversationUUID,
+	}, nil
+}
+
+// resolveInboundContact looks up the contact referenced by the payload, either by email or by a custom attribute.
+func (m *Manager) resolveInboundContact(webhook models.InboundWebhook, payload any) (umodels.User, error) {
+	value := payloadString(payload, webhook.Mapping.ContactField, "")
+	if value == "" {
+		return umodels.User{}, envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.empty", "name", "{globals.terms.contact}"), nil)
+	}
+
+	switch webhook.ContactLookup {
+	case models.ContactLookupCustomAttribute:
+		return m.userStore.GetContactByCustomAttribute(webhook.Mapping.ContactAttribute, value)
+	default:
+		return m.userStore.GetContact(0, strings.ToLower(value))
+	}
+}
+
+// resolveInboundConversation returns the conversation UUID from the payload if mapped, otherwise the contact's latest conversation.
+func (m *Manager) resolveInboundConversation(webhook models.InboundWebhook, payload any, contact umodels.User) (string, error) {
+	if uuid := payloadString(payload, webhook.Mapping.ConversationField, ""); uuid != "" {
+		conversation, err := m.conversationStore.GetConversation(0, uuid)
+		if err != nil {
+			return "", err
+		}
+		// Only allow acting on conversations belonging to the resolved contact.
+		if conversation.ContactID != contact.ID {
+			return "", envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.conversation}"), nil)
+		}
+		return conversation.UUID, nil
+	}
+
+	conversations, err := m.conversationStore.GetContactPreviousConversations(contact.ID, 1)
+	if err != nil {
+		return "", err
+	}
+	if len(conversations) == 0 {
+		return "", envelope.NewError(envelope.NotFoundError, m.i18n.Ts("globals.messages.notFound", "name", "{globals.terms.conversation}"), nil)
+	}
+	return conversations[0].UUID, nil
+}
+
+// lookupPath returns the value at the dot separated path in the decoded JSON payload.
+// Numeric path segments index into arrays, e.g. `items.0.name`.
+func lookupPath(data any, path string) (any, bool) {
+	if path == "" {
+		return nil, false
+	}
+	cur := data
+	for _, key := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			cur = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, cur != nil
+}
+
+// payloadString returns the scalar at path as a string, or fallback if the path is unset or missing.
+func payloadString(payload any, path, fallback string) string {
+	v, ok := lookupPath(payload, path)
+	if !ok {
+		return fallback
+	}
+	if s, ok := scalarString(v); ok {
+		return s
+	}
+	return fallback
+}
+
+// payloadStrings returns the value at path as a list of strings. Both JSON arrays and comma separated strings are accepted.
+func payloadStrings(payload any, path string, fallback []string) []string {
+	v, ok := lookupPath(payload, path)
+	if !ok {
+		return fallback
+	}
+
+	var out []string
+	switch t := v.(type) {
+	case []any:
+		for _, item := range t {
+			if s, ok := scalarString(item); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+	case string:
+		for _, s := range strings.Split(t, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	default:
+		return fallback
+	}
+	return out
+}
+
+// scalarString converts a decoded JSON scalar to a trimmed string.
+func scalarString(v any) (string, bool) {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t), true
+	case json.Number, bool:
+		return fmt.Sprint(t), true
+	}
+	return "", false
+}
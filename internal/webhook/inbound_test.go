@@ -0,0 +1,251 @@
+package webhook
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/dbutil/dbtest"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	mmodels "github.com/abhinavxd/libredesk/internal/media/models"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/abhinavxd/libredesk/internal/webhook/models"
+	"github.com/knadh/go-i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodha/logf"
+)
+
+const (
+	testWebhookUUID = "8f14e45f-ceea-467f-a0e6-bd6f1b3a6f4c"
+	testSecret      = "s3cret"
+)
+
+type statusUpdate struct {
+	uuid, status, snoozeDur string
+}
+
+type fakeConversationStore struct {
+	updates []statusUpdate
+}
+
+func (s *fakeConversationStore) GetConversation(int, string) (cmodels.Conversation, error) {
+	return cmodels.Conversation{}, nil
+}
+
+func (s *fakeConversationStore) GetContactPreviousConversations(int, int) ([]cmodels.PreviousConversation, error) {
+	return []cmodels.PreviousConversation{{UUID: "c1"}}, nil
+}
+
+func (s *fakeConversationStore) SendPrivateNote([]mmodels.Media, int, string, string) (cmodels.Message, error) {
+	return cmodels.Message{}, nil
+}
+
+func (s *fakeConversationStore) SetConversationTags(string, string, []string, umodels.User) error {
+	return nil
+}
+
+func (s *fakeConversationStore) UpdateConversationStatus(uuid string, _ int, status, snoozeDur string, _ umodels.User) error {
+	s.updates = append(s.updates, statusUpdate{uuid, status, snoozeDur})
+	return nil
+}
+
+type fakeUserStore struct{}
+
+func (fakeUserStore) GetContact(int, string) (umodels.User, error) { return umodels.User{ID: 7}, nil }
+
+func (fakeUserStore) GetContactByCustomAttribute(string, string) (umodels.User, error) {
+	return umodels.User{ID: 7}, nil
+}
+
+func (fakeUserStore) GetSystemUser() (umodels.User, error) { return umodels.User{ID: 1}, nil }
+
+// newInboundManager returns a manager whose only inbound webhook sets the status with the given mapping.
+func newInboundManager(t *testing.T, mapping models.InboundMapping) (*Manager, *fakeConversationStore) {
+	t.Helper()
+	b, err := json.Marshal(mapping)
+	require.NoError(t, err)
+	db := dbtest.New(func(query string, args []driver.Value) (dbtest.Result, error) {
+		return dbtest.Result{
+			Columns: []string{"id", "uuid", "name", "action", "contact_lookup", "mapping", "secret", "is_active"},
+			Rows:    [][]any{{int64(1), testWebhookUUID, "CRM", models.InboundActionSetStatus, models.ContactLookupEmail, b, testSecret, true}},
+		}, nil
+	})
+	var q queries
+	require.NoError(t, dbutil.ScanSQLFile("queries.sql", &q, db.DB, efs))
+	i18n, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	require.NoError(t, err)
+	lo := logf.New(logf.Opts{})
+
+	conversations := &fakeConversationStore{}
+	m := &Manager{q: q, lo: &lo, i18n: i18n, db: db.DB}
+	m.SetConversationStore(conversations)
+	m.SetUserStore(fakeUserStore{})
+	return m, conversations
+}
+
+func TestProcessInboundSignature(t *testing.T) {
+	body := []byte(`{"email": "jane@example.com", "status": "Resolved"}`)
+	m, _ := newInboundManager(t, models.InboundMapping{ContactField: "email", StatusField: "status"})
+
+	tests := []struct {
+		name      string
+		signature string
+		wantErr   bool
+	}{
+		{"good signature", m.generateSignature(body, testSecret), false},
+		{"good signature with whitespace", " " + m.generateSignature(body, testSecret) + "\n", false},
+		{"bad signature", m.generateSignature(body, "other"), true},
+		{"signature of another body", m.generateSignature([]byte(`{}`), testSecret), true},
+		{"missing header", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, conversations := newInboundManager(t, models.InboundMapping{ContactField: "email", StatusField: "status"})
+
+			res, err := m.ProcessInbound(testWebhookUUID, body, tt.signature)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Equal(t, models.InboundResult{Action: models.InboundActionSetStatus, ContactID: 7, ConversationUUID: "c1"}, res)
+				assert.Len(t, conversations.updates, 1)
+				return
+			}
+			var eerr envelope.Error
+			require.True(t, errors.As(err, &eerr), err)
+			assert.Equal(t, envelope.UnauthorizedError, eerr.ErrorType)
+			assert.Empty(t, conversations.updates)
+		})
+	}
+}
+
+func TestProcessInboundSnooze(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping models.InboundMapping
+		body    string
+		want    statusUpdate
+	}{
+		{
+			"duration from the payload",
+			models.InboundMapping{ContactField: "email", Status: cmodels.StatusSnoozed, SnoozeDurationField: "snooze.for", SnoozeDuration: "1h"},
+			`{"email": "jane@example.com", "snooze": {"for": "4h"}}`,
+			statusUpdate{"c1", cmodels.StatusSnoozed, "4h"},
+		},
+		{
+			"static duration",
+			models.InboundMapping{ContactField: "email", Status: cmodels.StatusSnoozed, SnoozeDurationField: "snooze.for", SnoozeDuration: "1h"},
+			`{"email": "jane@example.com"}`,
+			statusUpdate{"c1", cmodels.StatusSnoozed, "1h"},
+		},
+		{
+			"no duration for other statuses",
+			models.InboundMapping{ContactField: "email", StatusField: "status"},
+			`{"email": "jane@example.com", "status": "Open"}`,
+			statusUpdate{"c1", "Open", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, conversations := newInboundManager(t, tt.mapping)
+			body := []byte(tt.body)
+
+			_, err := m.ProcessInbound(testWebhookUUID, body, m.generateSignature(body, testSecret))
+			require.NoError(t, err)
+			assert.Equal(t, []statusUpdate{tt.want}, conversations.updates)
+		})
+	}
+}
+
+// decodePayload decodes a payload the way ProcessInbound does.
+func decodePayload(t *testing.T, body string) any {
+	t.Helper()
+	var payload any
+	dec := json.NewDecoder(bytes.NewReader([]byte(body)))
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&payload))
+	return payload
+}
+
+func TestLookupPath(t *testing.T) {
+	payload := decodePayload(t, `{
+		"customer": {"email": "jane@example.com", "address": {"city": "Pune"}, "note": null},
+		"items": [{"name": "first"}, {"name": "second"}]
+	}`)
+
+	tests := []struct {
+		name   string
+		path   string
+		want   any
+		wantOK bool
+	}{
+		{"top level", "customer", payload.(map[string]any)["customer"], true},
+		{"nested", "customer.email", "jane@example.com", true},
+		{"deeply nested", "customer.address.city", "Pune", true},
+		{"array index", "items.1.name", "second", true},
+		{"empty path", "", nil, false},
+		{"missing key", "customer.phone", nil, false},
+		{"missing parent", "order.id", nil, false},
+		{"null value", "customer.note", nil, false},
+		{"path through a scalar", "customer.email.domain", nil, false},
+		{"index out of range", "items.2.name", nil, false},
+		{"negative index", "items.-1.name", nil, false},
+		{"non numeric index", "items.first.name", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := lookupPath(payload, tt.path)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPayloadString(t *testing.T) {
+	payload := decodePayload(t, `{"ticket": {"status": " Resolved ", "id": 42, "urgent": true, "tags": ["a"]}}`)
+
+	tests := []struct {
+		name     string
+		path     string
+		fallback string
+		want     string
+	}{
+		{"nested string is trimmed", "ticket.status", "Open", "Resolved"},
+		{"number", "ticket.id", "", "42"},
+		{"bool", "ticket.urgent", "", "true"},
+		{"missing path uses the fallback", "ticket.priority", "Low", "Low"},
+		{"empty path uses the fallback", "", "Open", "Open"},
+		{"non scalar uses the fallback", "ticket.tags", "none", "none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, payloadString(payload, tt.path, tt.fallback))
+		})
+	}
+}
+
+func TestPayloadStrings(t *testing.T) {
+	payload := decodePayload(t, `{"data": {"tags": ["billing", " vip ", "", 3, {"x": 1}], "labels": "billing, vip,,", "count": 2, "empty": []}}`)
+	fallback := []string{"default"}
+
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{"nested array", "data.tags", []string{"billing", "vip", "3"}},
+		{"comma separated string", "data.labels", []string{"billing", "vip"}},
+		{"empty array", "data.empty", nil},
+		{"missing path uses the fallback", "data.missing", fallback},
+		{"empty path uses the fallback", "", fallback},
+		{"non list uses the fallback", "data.count", fallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, payloadStrings(payload, tt.path, fallback))
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/lib/pq"
@@ -36,3 +39,78 @@ const (
 	// Test event
 	EventWebhookTest WebhookEvent = "webhook.test"
 )
+
+// Inbound webhook actions.
+const (
+	InboundActionSendPrivateNote = "send_private_note"
+	InboundActionAddTags         = "add_tags"
+	InboundActionSetTags         = "set_tags"
+	InboundActionRemoveTags      = "remove_tags"
+	InboundActionSetStatus       = "set_status"
+)
+
+// Inbound webhook contact lookup modes.
+const (
+	ContactLookupEmail           = "email"
+	ContactLookupCustomAttribute = "custom_attribute"
+)
+
+// InboundWebhook represents an inbound webhook that lets external systems act on conversations.
+type InboundWebhook struct {
+	ID            int            `db:"id" json:"id"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at" json:"updated_at"`
+	UUID          string         `db:"uuid" json:"uuid"`
+	Name          string         `db:"name" json:"name"`
+	Action        string         `db:"action" json:"action"`
+	ContactLookup string         `db:"contact_lookup" json:"contact_lookup"`
+	Mapping       InboundMapping `db:"mapping" json:"mapping"`
+	Secret        string         `db:"secret" json:"secret"`
+	IsActive      bool           `db:"is_active" json:"is_active"`
+}
+
+// InboundMapping maps fields of an inbound payload to action inputs.
+// Fields ending in `Field` are dot separated paths into the JSON payload, e.g. `data.customer.email`.
+// Static values are used when the corresponding path is empty or missing in the payload.
+type InboundMapping struct {
+	// ContactField holds the contact email or the custom attribute value depending on the lookup mode.
+	ContactField string `json:"contact_field"`
+	// ContactAttribute is the contact custom attribute key used when looking up contacts by custom attribute, its value must be a string.
+	ContactAttribute string `json:"contact_attribute"`
+	// ConversationField optionally holds a conversation UUID, when empty the contact's latest conversation is used.
+	ConversationField string `json:"conversation_field"`
+	ContentField      string `json:"content_field"`
+	TagsField         string `json:"tags_field"`
+	StatusField       string `json:"status_field"`
+	// SnoozeDurationField holds the snooze duration, e.g. `4h`, required when the status is Snoozed.
+	SnoozeDurationField string   `json:"snooze_duration_field"`
+	Content             string   `json:"content"`
+	Tags                []string `json:"tags"`
+	Status              string   `json:"status"`
+	SnoozeDuration      string   `json:"snooze_duration"`
+}
+
+// Value implements the driver.Valuer interface.
+func (m InboundMapping) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+// Scan implements the sql.Scanner interface.
+func (m *InboundMapping) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	case nil:
+		return nil
+	}
+	return fmt.Errorf("unsupported type for InboundMapping: %T", src)
+}
+
+// InboundResult is returned after an inbound webhook payload is processed.
+type InboundResult struct {
+	Action           string `json:"action"`
+	ContactID        int    `json:"contact_id"`
+	ConversationUUID string `json:"conversation_uuid"`
+}
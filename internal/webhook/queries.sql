@@ -95,3 +95,64 @@ SET
 WHERE
     id = $1
 RETURNING *;
+
+-- name: get-all-inbound-webhooks
+SELECT
+    id,
+    created_at,
+    updated_at,
+    uuid,
+    name,
+    action,
+    contact_lookup,
+    mapping,
+    secret,
+    is_active
+FROM
+    inbound_webhooks
+ORDER BY created_at DESC;
+
+-- name: get-inbound-webhook
+SELECT
+    id,
+    created_at,
+    updated_at,
+    uuid,
+    name,
+    action,
+    contact_lookup,
+    mapping,
+    secret,
+    is_active
+FROM
+    inbound_webhooks
+WHERE
+    ($1 > 0 AND id = $1) OR ($2::uuid IS NOT NULL AND uuid = $2::uuid);
+
+-- name: insert-inbound-webhook
+INSERT INTO
+    inbound_webhooks (name, action, contact_lookup, mapping, secret, is_active)
+VALUES
+    ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: update-inbound-webhook
+UPDATE
+    inbound_webhooks
+SET
+    name = $2,
+    action = $3,
+    contact_lookup = $4,
+    mapping = $5,
+    secret = $6,
+    is_active = $7,
+    updated_at = NOW()
+WHERE
+    id = $1
+RETURNING *;
+
+-- name: delete-inbound-webhook
+DELETE FROM
+    inbound_webhooks
+WHERE
+    id = $1;
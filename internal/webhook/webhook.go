@@ -44,6 +44,9 @@ type Manager struct {
 	closed        bool
 	closedMu      sync.RWMutex
 	wg            sync.WaitGroup
+
+	conversationStore conversationStore
+	userStore         userStore
 }
 
 // Opts contains options for initializing the Manager.
@@ -72,6 +75,12 @@ type queries struct {
 	UpdateWebhook      *sqlx.Stmt `query:"update-webhook"`
 	DeleteWebhook      *sqlx.Stmt `query:"delete-webhook"`
 	ToggleWebhook      *sqlx.Stmt `query:"toggle-webhook"`
+
+	GetAllInboundWebhooks *sqlx.Stmt `query:"get-all-inbound-webhooks"`
+	GetInboundWebhook     *sqlx.Stmt `query:"get-inbound-webhook"`
+	InsertInboundWebhook  *sqlx.Stmt `query:"insert-inbound-webhook"`
+	UpdateInboundWebhook  *sqlx.Stmt `query:"update-inbound-webhook"`
+	DeleteInboundWebhook  *sqlx.Stmt `query:"delete-inbound-webhook"`
 }
 
 // New creates and returns a new instance of the Manager.
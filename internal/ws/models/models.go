@@ -6,6 +6,8 @@ const (
 	MessageTypeConversationPropertyUpdate = "conversation_prop_update"
 	MessageTypeNewMessage                 = "new_message"
 	MessageTypeNewConversation            = "new_conversation"
+	MessageTypeNotification               = "notification"
+	MessageTypeNotificationRead           = "notification_read"
 	MessageTypeError                      = "error"
 )
 
@@ -8,7 +8,8 @@ DROP TYPE IF EXISTS "content_type" CASCADE; CREATE TYPE "content_type" AS ENUM (
 DROP TYPE IF EXISTS "conversation_assignment_type" CASCADE; CREATE TYPE "conversation_assignment_type" AS ENUM ('Round robin','Manual');
 DROP TYPE IF EXISTS "template_type" CASCADE; CREATE TYPE "template_type" AS ENUM ('email_outgoing', 'email_notification');
 DROP TYPE IF EXISTS "user_type" CASCADE; CREATE TYPE "user_type" AS ENUM ('agent', 'contact');
-DROP TYPE IF EXISTS "ai_provider" CASCADE; CREATE TYPE "ai_provider" AS ENUM ('openai');
+DROP TYPE IF EXISTS "ai_provider" CASCADE; CREATE TYPE "ai_provider" AS ENUM ('openai', 'claude');
+DROP TYPE IF EXISTS "ai_knowledge_source_type" CASCADE; CREATE TYPE "ai_knowledge_source_type" AS ENUM ('conversation', 'document');
 DROP TYPE IF EXISTS "automation_execution_mode" CASCADE; CREATE TYPE "automation_execution_mode" AS ENUM ('all', 'first_match');
 DROP TYPE IF EXISTS "macro_visibility" CASCADE; CREATE TYPE "macro_visibility" AS ENUM ('all', 'team', 'user');
 DROP TYPE IF EXISTS "media_disposition" CASCADE; CREATE TYPE "media_disposition" AS ENUM ('inline', 'attachment');
@@ -18,7 +19,7 @@ DROP TYPE IF EXISTS "applied_sla_status" CASCADE; CREATE TYPE "applied_sla_statu
 DROP TYPE IF EXISTS "sla_event_status" CASCADE; CREATE TYPE "sla_event_status" AS ENUM ('pending', 'breached', 'met');
 DROP TYPE IF EXISTS "sla_metric" CASCADE; CREATE TYPE "sla_metric" AS ENUM ('first_response', 'resolution', 'next_response');
 DROP TYPE IF EXISTS "sla_notification_type" CASCADE; CREATE TYPE "sla_notification_type" AS ENUM ('warning', 'breach');
-DROP TYPE IF EXISTS "activity_log_type" CASCADE; CREATE TYPE "activity_log_type" AS ENUM ('agent_login', 'agent_logout', 'agent_away', 'agent_away_reassigned', 'agent_online');
+DROP TYPE IF EXISTS "activity_log_type" CASCADE; CREATE TYPE "activity_log_type" AS ENUM ('agent_login', 'agent_logout', 'agent_away', 'agent_away_reassigned', 'agent_online', 'agent_offline');
 DROP TYPE IF EXISTS "macro_visible_when" CASCADE; CREATE TYPE "macro_visible_when" AS ENUM ('replying', 'starting_conversation', 'adding_private_note');
 DROP TYPE IF EXISTS "webhook_event" CASCADE; CREATE TYPE webhook_event AS ENUM (
 	'conversation.created',
@@ -29,6 +30,12 @@ DROP TYPE IF EXISTS "webhook_event" CASCADE; CREATE TYPE webhook_event AS ENUM (
 	'message.created',
 	'message.updated'
 );
+DROP TYPE IF EXISTS "inbound_webhook_action" CASCADE; CREATE TYPE "inbound_webhook_action" AS ENUM ('send_private_note', 'add_tags', 'set_tags', 'remove_tags', 'set_status');
+DROP TYPE IF EXISTS "inbound_webhook_contact_lookup" CASCADE; CREATE TYPE "inbound_webhook_contact_lookup" AS ENUM ('email', 'custom_attribute');
+DROP TYPE IF EXISTS "user_notification_type" CASCADE; CREATE TYPE "user_notification_type" AS ENUM ('assigned', 'mention', 'sla_warning', 'sla_breach', 'new_reply');
+DROP TYPE IF EXISTS "team_notification_channel_provider" CASCADE; CREATE TYPE "team_notification_channel_provider" AS ENUM ('slack', 'mattermost', 'matrix');
+DROP TYPE IF EXISTS "user_digest_frequency" CASCADE; CREATE TYPE "user_digest_frequency" AS ENUM ('off', 'daily', 'weekly');
+DROP TYPE IF EXISTS "scheduled_report_type" CASCADE; CREATE TYPE "scheduled_report_type" AS ENUM ('sla', 'agents', 'csat');
 
 -- Sequence to generate reference number for conversations.
 DROP SEQUENCE IF EXISTS conversation_reference_number_sequence; CREATE SEQUENCE conversation_reference_number_sequence START 100;
@@ -52,6 +59,12 @@ CREATE TABLE sla_policies (
 	resolution_time TEXT NOT NULL,
 	next_response_time TEXT NULL,
 	notifications JSONB DEFAULT '[]'::jsonb NOT NULL,
+	-- Conversation statuses that pause the SLA clock.
+	pause_statuses TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	-- Automation rule groups for applying the policy to matching conversations.
+	conditions JSONB DEFAULT '{}'::jsonb NOT NULL,
+	-- Conversation actions run on SLA warnings and breaches.
+	escalations JSONB DEFAULT '[]'::jsonb NOT NULL,
 	CONSTRAINT constraint_sla_policies_on_name CHECK (length(name) <= 140),
 	CONSTRAINT constraint_sla_policies_on_description CHECK (length(description) <= 300)
 );
@@ -66,6 +79,9 @@ CREATE TABLE business_hours (
 	is_always_open BOOL DEFAULT false NOT NULL,
 	hours JSONB NOT NULL,
 	holidays JSONB DEFAULT '{}'::jsonb NOT NULL,
+	holidays_url TEXT NULL,
+	holidays_synced_at TIMESTAMPTZ NULL,
+	holidays_sync_error TEXT NULL,
 	CONSTRAINT constraint_business_hours_on_name CHECK (length(name) <= 140),
 	CONSTRAINT constraint_business_hours_on_description CHECK (length(description) <= 300)
 );
@@ -82,6 +98,9 @@ CREATE TABLE inboxes (
 	csat_enabled bool DEFAULT false NOT NULL,
 	config jsonb DEFAULT '{}'::jsonb NOT NULL,
 	"from" TEXT NULL,
+	business_hours_id INT REFERENCES business_hours(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+	out_of_hours_reply_enabled bool DEFAULT false NOT NULL,
+	out_of_hours_reply TEXT DEFAULT '' NOT NULL,
 	CONSTRAINT constraint_inboxes_on_name CHECK (length("name") <= 140)
 );
 
@@ -155,6 +174,7 @@ CREATE UNIQUE INDEX index_unique_users_on_email_and_type_when_deleted_at_is_null
 WHERE deleted_at IS NULL;
 CREATE INDEX index_tgrm_users_on_email ON users USING GIN (email gin_trgm_ops);
 CREATE INDEX index_users_on_api_key ON users(api_key);
+CREATE INDEX index_users_on_custom_attributes ON users USING GIN (custom_attributes) WHERE type = 'contact';
 
 DROP TABLE IF EXISTS user_roles CASCADE;
 CREATE TABLE user_roles (
@@ -254,6 +274,8 @@ CREATE INDEX index_conversations_on_created_at ON conversations (created_at);
 CREATE INDEX index_conversations_on_last_message_at ON conversations (last_message_at);
 CREATE INDEX index_conversations_on_next_sla_deadline_at ON conversations (next_sla_deadline_at);
 CREATE INDEX index_conversations_on_waiting_since ON conversations (waiting_since);
+CREATE INDEX index_conversations_on_resolved_at ON conversations (resolved_at);
+CREATE INDEX index_conversations_on_first_reply_at ON conversations (first_reply_at);
 
 DROP TABLE IF EXISTS conversation_messages CASCADE;
 CREATE TABLE conversation_messages (
@@ -461,6 +483,9 @@ CREATE TABLE applied_slas (
 
 	status applied_sla_status DEFAULT 'pending' NOT NULL,
 
+	-- Set when an agent applied the policy, policies matched by conditions don't replace it.
+	is_manual BOOLEAN DEFAULT false NOT NULL,
+
 	-- Cascade deletes when conversation or SLA policy is deleted.
 	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
 	sla_policy_id INT REFERENCES sla_policies(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
@@ -491,6 +516,23 @@ CREATE TABLE sla_events (
 CREATE INDEX index_sla_events_on_applied_sla_id ON sla_events(applied_sla_id);
 CREATE INDEX index_sla_events_on_status ON sla_events(status);
 
+DROP TABLE IF EXISTS sla_pauses CASCADE;
+CREATE TABLE sla_pauses (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	applied_sla_id BIGINT REFERENCES applied_slas(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- Conversation status that paused the SLA.
+	status TEXT NOT NULL,
+	started_at TIMESTAMPTZ NOT NULL,
+	ended_at TIMESTAMPTZ NULL,
+	-- Paused minutes within business hours, set when the pause ends.
+	paused_minutes INT NULL
+);
+CREATE INDEX index_sla_pauses_on_applied_sla_id ON sla_pauses(applied_sla_id);
+-- Only one open pause per applied SLA.
+CREATE UNIQUE INDEX index_unique_sla_pauses_on_applied_sla_id_when_ended_at_is_null ON sla_pauses(applied_sla_id)
+WHERE ended_at IS NULL;
+
 DROP TABLE IF EXISTS scheduled_sla_notifications CASCADE;
 CREATE TABLE scheduled_sla_notifications (
   id BIGSERIAL PRIMARY KEY,
@@ -507,6 +549,22 @@ CREATE TABLE scheduled_sla_notifications (
 CREATE INDEX index_scheduled_sla_notifications_on_send_at ON scheduled_sla_notifications(send_at);
 CREATE INDEX index_scheduled_sla_notifications_on_processed_at ON scheduled_sla_notifications(processed_at);
 
+DROP TABLE IF EXISTS scheduled_sla_escalations CASCADE;
+CREATE TABLE scheduled_sla_escalations (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  applied_sla_id BIGINT NOT NULL REFERENCES applied_slas(id) ON DELETE CASCADE,
+  sla_event_id BIGINT REFERENCES sla_events(id) ON DELETE CASCADE,
+  metric sla_metric NOT NULL,
+  escalation_type sla_notification_type NOT NULL,
+  actions JSONB DEFAULT '[]'::jsonb NOT NULL,
+  run_at TIMESTAMPTZ NOT NULL,
+  processed_at TIMESTAMPTZ
+);
+CREATE INDEX index_scheduled_sla_escalations_on_run_at ON scheduled_sla_escalations(run_at);
+CREATE INDEX index_scheduled_sla_escalations_on_processed_at ON scheduled_sla_escalations(processed_at);
+
 DROP TABLE IF EXISTS ai_providers CASCADE;
 CREATE TABLE ai_providers (
 	id SERIAL PRIMARY KEY,
@@ -529,11 +587,104 @@ CREATE TABLE ai_prompts (
 	title TEXT NOT NULL,
     key TEXT NOT NULL UNIQUE,
     content TEXT NOT NULL,
+	-- Optional per-prompt overrides of the provider model and temperature.
+	model TEXT NULL,
+	temperature NUMERIC(3, 2) NULL,
+	-- Roles allowed to use the prompt, empty allows all roles.
+	allowed_roles TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	version INT DEFAULT 1 NOT NULL,
 	CONSTRAINT constraint_prompts_on_title CHECK (length(title) <= 140),
     CONSTRAINT constraint_prompts_on_key CHECK (length(key) <= 140)
 );
 CREATE INDEX index_ai_prompts_on_key ON ai_prompts USING btree (key);
 
+DROP TABLE IF EXISTS ai_prompt_versions CASCADE;
+CREATE TABLE ai_prompt_versions (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	prompt_id INT REFERENCES ai_prompts(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	version INT NOT NULL,
+	title TEXT NOT NULL,
+	content TEXT NOT NULL,
+	model TEXT NULL,
+	temperature NUMERIC(3, 2) NULL,
+	allowed_roles TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	created_by INT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+	CONSTRAINT constraint_ai_prompt_versions_unique UNIQUE (prompt_id, version)
+);
+
+DROP TABLE IF EXISTS ai_knowledge_sources CASCADE;
+CREATE TABLE ai_knowledge_sources (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	"type" ai_knowledge_source_type NOT NULL,
+	title TEXT NOT NULL,
+	content TEXT NOT NULL,
+	-- Set for resolved conversations, documents are uploaded by admins.
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NULL UNIQUE,
+	-- NULL until the source has been chunked and embedded.
+	indexed_at TIMESTAMPTZ NULL,
+	CONSTRAINT constraint_ai_knowledge_sources_on_title CHECK (length(title) <= 255)
+);
+CREATE INDEX index_ai_knowledge_sources_on_indexed_at ON ai_knowledge_sources (indexed_at) WHERE indexed_at IS NULL;
+
+DROP TABLE IF EXISTS ai_knowledge_chunks CASCADE;
+CREATE TABLE ai_knowledge_chunks (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	source_id INT REFERENCES ai_knowledge_sources(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	chunk_index INT NOT NULL,
+	content TEXT NOT NULL,
+	-- Normalized embedding vector and the model it was generated with, only chunks of the current model are searched.
+	embedding REAL[] NOT NULL,
+	embedding_model TEXT NOT NULL
+);
+CREATE INDEX index_ai_knowledge_chunks_on_source_id ON ai_knowledge_chunks (source_id);
+CREATE INDEX index_ai_knowledge_chunks_on_embedding_model ON ai_knowledge_chunks (embedding_model);
+
+DROP TABLE IF EXISTS ai_redaction_logs CASCADE;
+CREATE TABLE ai_redaction_logs (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	user_id INT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+	prompt_key TEXT NOT NULL,
+	-- Number of redacted values per detector, the values are never stored.
+	redactions JSONB DEFAULT '{}'::jsonb NOT NULL
+);
+CREATE INDEX index_ai_redaction_logs_on_created_at ON ai_redaction_logs (created_at);
+
+DROP TABLE IF EXISTS ai_usage CASCADE;
+CREATE TABLE ai_usage (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	-- NULL for requests made by the system, e.g. automation rules.
+	user_id INT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+	prompt_key TEXT NOT NULL,
+	-- Name of the provider config that served the request.
+	provider TEXT NOT NULL,
+	model TEXT NOT NULL,
+	input_tokens INT DEFAULT 0 NOT NULL,
+	output_tokens INT DEFAULT 0 NOT NULL,
+	latency_ms INT DEFAULT 0 NOT NULL,
+	success BOOLEAN NOT NULL
+);
+CREATE INDEX index_ai_usage_on_created_at ON ai_usage (created_at);
+CREATE INDEX index_ai_usage_on_user_id_created_at ON ai_usage (user_id, created_at);
+
+DROP TABLE IF EXISTS ai_budgets CASCADE;
+CREATE TABLE ai_budgets (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	-- A budget applies to either a team or a role and is shared by all its members.
+	team_id INT REFERENCES teams(id) ON DELETE CASCADE ON UPDATE CASCADE NULL UNIQUE,
+	role_id INT REFERENCES roles(id) ON DELETE CASCADE ON UPDATE CASCADE NULL UNIQUE,
+	monthly_token_limit BIGINT NOT NULL,
+	CONSTRAINT constraint_ai_budgets_on_scope CHECK ((team_id IS NULL) <> (role_id IS NULL)),
+	CONSTRAINT constraint_ai_budgets_on_monthly_token_limit CHECK (monthly_token_limit > 0)
+);
+
 DROP TABLE IF EXISTS custom_attribute_definitions CASCADE;
 CREATE TABLE custom_attribute_definitions (
 	id SERIAL PRIMARY KEY,
@@ -600,9 +751,201 @@ CREATE TABLE webhooks (
 	CONSTRAINT constraint_webhooks_on_events_not_empty CHECK (array_length(events, 1) > 0)
 );
 
+DROP TABLE IF EXISTS inbound_webhooks CASCADE;
+CREATE TABLE inbound_webhooks (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	"uuid" UUID DEFAULT gen_random_uuid() NOT NULL UNIQUE,
+	name TEXT NOT NULL,
+	action inbound_webhook_action NOT NULL,
+	contact_lookup inbound_webhook_contact_lookup NOT NULL DEFAULT 'email',
+	mapping JSONB NOT NULL DEFAULT '{}'::jsonb,
+	secret TEXT NOT NULL,
+	is_active BOOLEAN DEFAULT true,
+	CONSTRAINT constraint_inbound_webhooks_on_name CHECK (length(name) <= 255),
+	CONSTRAINT constraint_inbound_webhooks_on_secret CHECK (length(secret) BETWEEN 1 AND 255)
+);
+
+DROP TABLE IF EXISTS out_of_hours_replies CASCADE;
+CREATE TABLE out_of_hours_replies (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	contact_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- Last time an out-of-hours reply was sent to the contact from the inbox.
+	sent_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	CONSTRAINT constraint_out_of_hours_replies_on_inbox_id_and_contact_id_unique UNIQUE (inbox_id, contact_id)
+);
+
+DROP TABLE IF EXISTS user_notifications CASCADE;
+CREATE TABLE user_notifications (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	"type" user_notification_type NOT NULL,
+	title TEXT NOT NULL,
+	body TEXT DEFAULT '' NOT NULL,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+	message_id BIGINT REFERENCES conversation_messages(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+	-- User whose action caused the notification, NULL for system notifications.
+	actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+	read_at TIMESTAMPTZ NULL
+);
+CREATE INDEX index_user_notifications_on_user_id_and_created_at ON user_notifications(user_id, created_at DESC);
+CREATE INDEX index_user_notifications_on_user_id_where_unread ON user_notifications(user_id) WHERE read_at IS NULL;
+
+DROP TABLE IF EXISTS user_notification_preferences CASCADE;
+CREATE TABLE user_notification_preferences (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	"type" user_notification_type NOT NULL,
+	in_app BOOLEAN NOT NULL,
+	email BOOLEAN NOT NULL,
+	push BOOLEAN DEFAULT FALSE NOT NULL,
+	CONSTRAINT constraint_user_notification_preferences_on_user_id_and_type_unique UNIQUE (user_id, "type")
+);
+
+DROP TABLE IF EXISTS user_push_subscriptions CASCADE;
+CREATE TABLE user_push_subscriptions (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- Push service URL of the browser, unique per browser.
+	endpoint TEXT NOT NULL,
+	p256dh TEXT NOT NULL,
+	auth TEXT NOT NULL,
+	user_agent TEXT DEFAULT '' NOT NULL,
+	CONSTRAINT constraint_user_push_subscriptions_on_endpoint_unique UNIQUE (endpoint),
+	CONSTRAINT constraint_user_push_subscriptions_on_endpoint CHECK (length(endpoint) <= 2048)
+);
+CREATE INDEX index_user_push_subscriptions_on_user_id ON user_push_subscriptions(user_id);
+
+DROP TABLE IF EXISTS user_digest_settings CASCADE;
+CREATE TABLE user_digest_settings (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	frequency user_digest_frequency DEFAULT 'off' NOT NULL,
+	-- Hour of the day and day of the week (0 is Sunday) digests are sent at, in the timezone.
+	hour INT DEFAULT 8 NOT NULL,
+	weekday INT DEFAULT 1 NOT NULL,
+	timezone TEXT NOT NULL,
+	sections TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	include_teams BOOLEAN DEFAULT FALSE NOT NULL,
+	last_sent_at TIMESTAMPTZ NULL,
+	CONSTRAINT constraint_user_digest_settings_on_user_id_unique UNIQUE (user_id),
+	CONSTRAINT constraint_user_digest_settings_on_hour CHECK (hour >= 0 AND hour <= 23),
+	CONSTRAINT constraint_user_digest_settings_on_weekday CHECK (weekday >= 0 AND weekday <= 6),
+	CONSTRAINT constraint_user_digest_settings_on_timezone CHECK (length(timezone) <= 140)
+);
+
+DROP TABLE IF EXISTS scheduled_reports CASCADE;
+CREATE TABLE scheduled_reports (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	"name" TEXT NOT NULL,
+	report_type scheduled_report_type NOT NULL,
+	filters JSONB DEFAULT '{}'::jsonb NOT NULL,
+	-- Number of days before the day the report is sent it covers.
+	range_days INT DEFAULT 7 NOT NULL,
+	-- Five field cron expression, in the timezone.
+	cron TEXT NOT NULL,
+	timezone TEXT NOT NULL,
+	recipients TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	enabled BOOLEAN DEFAULT TRUE NOT NULL,
+	last_run_at TIMESTAMPTZ NULL,
+	last_error TEXT DEFAULT '' NOT NULL,
+	next_run_at TIMESTAMPTZ NULL,
+	CONSTRAINT constraint_scheduled_reports_on_name CHECK (length("name") <= 140),
+	CONSTRAINT constraint_scheduled_reports_on_range_days CHECK (range_days >= 1 AND range_days <= 366),
+	CONSTRAINT constraint_scheduled_reports_on_cron CHECK (length(cron) <= 100),
+	CONSTRAINT constraint_scheduled_reports_on_timezone CHECK (length(timezone) <= 140)
+);
+CREATE INDEX index_scheduled_reports_on_next_run_at ON scheduled_reports(next_run_at);
+
+-- Conversations created and resolved in each inbox and team per hour, maintained by the report rollup worker.
+DROP TABLE IF EXISTS report_rollups_hourly CASCADE;
+CREATE TABLE report_rollups_hourly (
+	bucket TIMESTAMPTZ NOT NULL,
+	inbox_id INT NOT NULL,
+	-- 0 for conversations not assigned to a team.
+	team_id INT NOT NULL,
+	conversations_created INT DEFAULT 0 NOT NULL,
+	conversations_resolved INT DEFAULT 0 NOT NULL,
+	PRIMARY KEY (bucket, inbox_id, team_id)
+);
+
+-- Hourly rollups summed per day.
+DROP TABLE IF EXISTS report_rollups_daily CASCADE;
+CREATE TABLE report_rollups_daily (
+	"day" DATE NOT NULL,
+	inbox_id INT NOT NULL,
+	team_id INT NOT NULL,
+	conversations_created INT DEFAULT 0 NOT NULL,
+	conversations_resolved INT DEFAULT 0 NOT NULL,
+	PRIMARY KEY ("day", inbox_id, team_id)
+);
+
+-- Counts of the open conversations of each inbox and team as of the last rollup, recounted in full on every rollup.
+DROP TABLE IF EXISTS report_rollups_open CASCADE;
+CREATE TABLE report_rollups_open (
+	inbox_id INT NOT NULL,
+	team_id INT NOT NULL,
+	"open" INT DEFAULT 0 NOT NULL,
+	awaiting_response INT DEFAULT 0 NOT NULL,
+	unassigned INT DEFAULT 0 NOT NULL,
+	pending INT DEFAULT 0 NOT NULL,
+	PRIMARY KEY (inbox_id, team_id)
+);
+
+-- Single row recording when the rollups were last updated and whether existing data was backfilled.
+DROP TABLE IF EXISTS report_rollup_state CASCADE;
+CREATE TABLE report_rollup_state (
+	id INT PRIMARY KEY DEFAULT 1,
+	rolled_up_at TIMESTAMPTZ NULL,
+	backfilled_at TIMESTAMPTZ NULL,
+	CONSTRAINT constraint_report_rollup_state_on_id CHECK (id = 1)
+);
+-- A new install has no data to backfill.
+INSERT INTO report_rollup_state (id, backfilled_at) VALUES (1, NOW());
+
+DROP TABLE IF EXISTS conversation_followers CASCADE;
+CREATE TABLE conversation_followers (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	CONSTRAINT constraint_conversation_followers_on_conversation_id_and_user_id_unique UNIQUE (conversation_id, user_id)
+);
+
+DROP TABLE IF EXISTS team_notification_channels CASCADE;
+CREATE TABLE team_notification_channels (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	team_id INT REFERENCES teams(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	name TEXT NOT NULL,
+	provider team_notification_channel_provider NOT NULL,
+	-- Webhook URL for Slack and Mattermost; homeserver URL, room ID and access token for Matrix.
+	config JSONB DEFAULT '{}'::jsonb NOT NULL,
+	events TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	enabled BOOLEAN DEFAULT TRUE NOT NULL,
+	CONSTRAINT constraint_team_notification_channels_on_name CHECK (length(name) <= 140)
+);
+CREATE INDEX index_team_notification_channels_on_team_id ON team_notification_channels(team_id);
+
 INSERT INTO ai_providers
 ("name", provider, config, is_default)
-VALUES('openai', 'openai', '{"api_key": ""}'::jsonb, true);
+VALUES
+('openai', 'openai', '{"api_key": ""}'::jsonb, true),
+('claude', 'claude', '{"api_key": ""}'::jsonb, false);
 
 -- Default AI prompts
 INSERT INTO ai_prompts ("key", "content", title)
@@ -611,7 +954,12 @@ VALUES
 ('make_concise', 'Simplify the text to make it more concise and to the point.', 'Make Concise'),
 ('add_empathy', 'Add empathy to the text while retaining the original meaning.', 'Add Empathy'),
 ('adjust_positive_tone', 'Adjust the tone of the text to make it sound more positive and reassuring.', 'Adjust Positive Tone'),
-('make_professional', 'Rephrase the text to make it sound more formal and professional and to the point.', 'Make Professional');
+('make_professional', 'Rephrase the text to make it sound more formal and professional and to the point.', 'Make Professional'),
+('suggest_reply', 'You are a customer support agent. Draft a reply to the customer''s latest message in the conversation. Use the numbered knowledge sources from previously resolved conversations and internal documents where they are relevant, do not make up facts that are not in the sources or the conversation. If the sources do not answer the question, ask the customer for the details needed instead. Do not mention the sources. Reply with the message text only and sign off as {{ .Agent.FirstName }}.', 'Suggest Reply');
+
+-- Initial version of the default prompts
+INSERT INTO ai_prompt_versions (prompt_id, version, title, content)
+SELECT id, version, title, content FROM ai_prompts;
 
 -- Default settings
 INSERT INTO settings ("key", value)
@@ -638,7 +986,9 @@ VALUES
 	('notification.email.hello_hostname', '""'::jsonb),
     ('notification.email.email_address', '"admin@yourcompany.com"'::jsonb),
     ('notification.email.max_msg_retries', '3'::jsonb),
-    ('notification.email.enabled', 'false'::jsonb);
+    ('notification.email.enabled', 'false'::jsonb),
+    ('notification.webpush.vapid_public_key', '""'::jsonb),
+    ('notification.webpush.vapid_private_key', '""'::jsonb);
 
 -- Default conversation priorities
 INSERT INTO conversation_priorities (name) VALUES
@@ -757,3 +1107,80 @@ VALUES (
   'Urgent: SLA Breach for Conversation {{ .Conversation.ReferenceNumber }} for {{ .SLA.Metric }}',
   true
 );
+
+INSERT INTO templates
+("type", body, is_default, "name", subject, is_builtin)
+VALUES (
+  'email_notification'::template_type,
+  '
+<p>Hi {{ html .FirstName }},</p>
+
+<p>Here is your {{ .Frequency }} summary for {{ .PeriodStart.Format "Jan 2" }}{{ if eq .Frequency "weekly" }} to {{ (.PeriodEnd.AddDate 0 0 -1).Format "Jan 2" }}{{ end }}.</p>
+
+{{ if index .Sections "open_assigned" }}
+<p><strong>Open conversations assigned to you:</strong> {{ .OpenAssigned }}</p>
+{{ end }}
+
+{{ if .WaitingLongest }}
+<p><strong>Waiting longest for a reply</strong></p>
+<ul>
+{{ range .WaitingLongest }}
+    <li><a href="{{ RootURL }}/inboxes/assigned/conversation/{{ .UUID }}">#{{ .ReferenceNumber }}</a> {{ html .Subject.String }} ({{ html .ContactName }}), waiting for {{ .Duration }}</li>
+{{ end }}
+</ul>
+{{ end }}
+
+{{ if .UpcomingSLA }}
+<p><strong>SLA deadlines in the next 24 hours</strong></p>
+<ul>
+{{ range .UpcomingSLA }}
+    <li><a href="{{ RootURL }}/inboxes/assigned/conversation/{{ .UUID }}">#{{ .ReferenceNumber }}</a> {{ html .Subject.String }}, due in {{ .Duration }}</li>
+{{ end }}
+</ul>
+{{ end }}
+
+{{ if and (index .Sections "csat") .CSAT.Responses }}
+<p><strong>CSAT:</strong> {{ .CSAT.Responses }} responses with an average rating of {{ printf "%.1f" .CSAT.AverageRating }} out of 5</p>
+{{ end }}
+
+<p>
+  Best regards,<br>
+  Libredesk
+</p>
+
+',
+  false,
+  'Digest',
+  'Your {{ .Frequency }} Libredesk digest',
+  true
+);
+
+INSERT INTO templates
+("type", body, is_default, "name", subject, is_builtin)
+VALUES (
+  'email_notification'::template_type,
+  '
+<p>Hi,</p>
+
+<p>Here is the {{ html .Name }} report for {{ .PeriodStart.Format "Jan 2, 2006" }}{{ if not (.PeriodStart.Equal .PeriodEnd) }} to {{ .PeriodEnd.Format "Jan 2, 2006" }}{{ end }}. The full report is attached as {{ .Filename }}.</p>
+
+{{ if .Summary }}
+<ul>
+{{ range .Summary }}
+    <li><strong>{{ html .Label }}:</strong> {{ html .Value }}</li>
+{{ end }}
+</ul>
+{{ end }}
+
+<p><a href="{{ RootURL }}/reports">View reports</a></p>
+
+<p>
+  Best regards,<br>
+  Libredesk
+</p>
+',
+  false,
+  'Scheduled report',
+  '{{ .Name }} report',
+  true
+);